@@ -0,0 +1,473 @@
+package quartz
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Calendar is used to specify blocks of time that are excluded from the firing schedule of a Trigger.
+//
+// Calendars may be chained with a base calendar, a time is only included
+// when it is included by the calendar itself and all of its base calendars.
+type Calendar interface {
+	Cloneable
+
+	Description() string
+
+	SetDescription(desc string)
+
+	BaseCalendar() Calendar
+
+	SetBaseCalendar(base Calendar)
+
+	// Determine whether the given time is 'included' by the Calendar.
+	IsTimeIncluded(t time.Time) bool
+
+	// Determine the next time that is 'included' by the Calendar,
+	// returns the given time itself when it is included, or zero if there is none.
+	NextIncludedTime(t time.Time) time.Time
+}
+
+// cloneCalendar returns a copy of the calendar, or nil if there is none.
+func cloneCalendar(cal Calendar) Calendar {
+	if cal == nil {
+		return nil
+	}
+
+	return cal.Clone().(Calendar)
+}
+
+type baseCalendar struct {
+	base     Calendar
+	desc     string
+	location *time.Location
+}
+
+func (c *baseCalendar) Description() string { return c.desc }
+
+func (c *baseCalendar) SetDescription(desc string) { c.desc = desc }
+
+func (c *baseCalendar) BaseCalendar() Calendar { return c.base }
+
+func (c *baseCalendar) SetBaseCalendar(base Calendar) { c.base = base }
+
+func (c *baseCalendar) Location() *time.Location {
+	if c.location == nil {
+		return time.Local
+	}
+
+	return c.location
+}
+
+func (c *baseCalendar) SetLocation(loc *time.Location) { c.location = loc }
+
+func (c *baseCalendar) IsTimeIncluded(t time.Time) bool {
+	return c.base == nil || c.base.IsTimeIncluded(t)
+}
+
+func (c *baseCalendar) NextIncludedTime(t time.Time) time.Time {
+	if c.base == nil {
+		return t
+	}
+
+	return c.base.NextIncludedTime(t)
+}
+
+func (c *baseCalendar) clone() baseCalendar {
+	clone := *c
+
+	if c.base != nil {
+		clone.base = c.base.Clone().(Calendar)
+	}
+
+	return clone
+}
+
+// nextIncludedTime walks forward from t, using skip to jump over the times excluded by the calendar itself,
+// until it reaches a time which is included by the base calendar too.
+//
+// It gives up with zero after yearToGiveUpSchedulingAt, when the calendar and its base calendars
+// together exclude every time.
+func (c *baseCalendar) nextIncludedTime(t time.Time, excluded func(time.Time) bool, skip func(time.Time) time.Time) time.Time {
+	for !t.IsZero() {
+		if t.Year() > yearToGiveUpSchedulingAt {
+			return zero
+		} else if excluded(t) {
+			t = skip(t)
+		} else if c.base != nil && !c.base.IsTimeIncluded(t) {
+			t = c.base.NextIncludedTime(t)
+		} else {
+			break
+		}
+	}
+
+	return t
+}
+
+// TimeOfDay represents a time in hour, minute and second of any given day.
+type TimeOfDay struct {
+	Hour, Minute, Second int
+}
+
+func NewTimeOfDay(hour, minute, second int) (TimeOfDay, error) {
+	if hour < 0 || hour > 23 {
+		return TimeOfDay{}, fmt.Errorf("Hour must be from 0 to 23, got %d", hour)
+	}
+
+	if minute < 0 || minute > 59 {
+		return TimeOfDay{}, fmt.Errorf("Minute must be from 0 to 59, got %d", minute)
+	}
+
+	if second < 0 || second > 59 {
+		return TimeOfDay{}, fmt.Errorf("Second must be from 0 to 59, got %d", second)
+	}
+
+	return TimeOfDay{hour, minute, second}, nil
+}
+
+// ParseTimeOfDay parses a time of day in the "HH:mm:ss" or "HH:mm" format.
+func ParseTimeOfDay(s string) (TimeOfDay, error) {
+	var hour, minute, second int
+
+	if _, err := fmt.Sscanf(s, "%d:%d:%d", &hour, &minute, &second); err != nil {
+		second = 0
+
+		if _, err := fmt.Sscanf(s, "%d:%d", &hour, &minute); err != nil {
+			return TimeOfDay{}, fmt.Errorf("Invalid time of day '%s', expected HH:mm:ss", s)
+		}
+	}
+
+	return NewTimeOfDay(hour, minute, second)
+}
+
+func (t TimeOfDay) String() string {
+	return fmt.Sprintf("%02d:%02d:%02d", t.Hour, t.Minute, t.Second)
+}
+
+// On returns the time at this time of day on the date of the given time, in its location.
+func (t TimeOfDay) On(date time.Time) time.Time {
+	year, month, day := date.Date()
+
+	return time.Date(year, month, day, t.Hour, t.Minute, t.Second, 0, date.Location())
+}
+
+func (t TimeOfDay) seconds() int { return t.Hour*3600 + t.Minute*60 + t.Second }
+
+func timeOfDaySeconds(t time.Time) int { return t.Hour()*3600 + t.Minute()*60 + t.Second() }
+
+// DailyCalendar excludes a specified time range each day,
+// or includes only that range when the time range is inverted.
+//
+// The range is [RangeStart, RangeEnd) in the calendar's location,
+// a range start after the range end wraps past midnight, e.g. 22:00 - 02:00.
+type DailyCalendar struct {
+	baseCalendar
+
+	rangeStart, rangeEnd TimeOfDay
+	invertTimeRange      bool
+}
+
+func NewDailyCalendar(base Calendar, rangeStart, rangeEnd TimeOfDay) *DailyCalendar {
+	return &DailyCalendar{
+		baseCalendar: baseCalendar{base: base},
+		rangeStart:   rangeStart,
+		rangeEnd:     rangeEnd,
+	}
+}
+
+// ParseDailyCalendar creates a DailyCalendar with a time range in the "HH:mm:ss" format.
+func ParseDailyCalendar(base Calendar, rangeStart, rangeEnd string) (*DailyCalendar, error) {
+	start, err := ParseTimeOfDay(rangeStart)
+
+	if err != nil {
+		return nil, err
+	}
+
+	end, err := ParseTimeOfDay(rangeEnd)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NewDailyCalendar(base, start, end), nil
+}
+
+func (c *DailyCalendar) RangeStart() TimeOfDay { return c.rangeStart }
+
+func (c *DailyCalendar) RangeEnd() TimeOfDay { return c.rangeEnd }
+
+func (c *DailyCalendar) SetTimeRange(rangeStart, rangeEnd TimeOfDay) {
+	c.rangeStart = rangeStart
+	c.rangeEnd = rangeEnd
+}
+
+func (c *DailyCalendar) InvertTimeRange() bool { return c.invertTimeRange }
+
+// SetInvertTimeRange includes only the time range instead of excluding it.
+func (c *DailyCalendar) SetInvertTimeRange(invert bool) { c.invertTimeRange = invert }
+
+func (c *DailyCalendar) wrapped() bool { return c.rangeStart.seconds() > c.rangeEnd.seconds() }
+
+func (c *DailyCalendar) inRange(t time.Time) bool {
+	secs := timeOfDaySeconds(t.In(c.Location()))
+
+	if c.wrapped() {
+		return secs >= c.rangeStart.seconds() || secs < c.rangeEnd.seconds()
+	}
+
+	return secs >= c.rangeStart.seconds() && secs < c.rangeEnd.seconds()
+}
+
+func (c *DailyCalendar) excluded(t time.Time) bool { return c.inRange(t) != c.invertTimeRange }
+
+// skip returns the boundary after t where the excluded part ends,
+// the range end normally or the range start when the time range is inverted.
+func (c *DailyCalendar) skip(t time.Time) time.Time {
+	tod := c.rangeEnd
+
+	if c.invertTimeRange {
+		tod = c.rangeStart
+	}
+
+	lt := t.In(c.Location())
+
+	next := tod.On(lt)
+
+	if !next.After(lt) {
+		next = tod.On(lt.AddDate(0, 0, 1))
+	}
+
+	return next
+}
+
+func (c *DailyCalendar) IsTimeIncluded(t time.Time) bool {
+	return c.baseCalendar.IsTimeIncluded(t) && !c.excluded(t)
+}
+
+func (c *DailyCalendar) NextIncludedTime(t time.Time) time.Time {
+	if c.invertTimeRange && c.rangeStart == c.rangeEnd {
+		return zero
+	}
+
+	return c.nextIncludedTime(t, c.excluded, c.skip)
+}
+
+func (c *DailyCalendar) Clone() interface{} {
+	clone := *c
+
+	clone.baseCalendar = c.baseCalendar.clone()
+
+	return &clone
+}
+
+func nextDay(t time.Time, loc *time.Location) time.Time {
+	year, month, day := t.In(loc).Date()
+
+	return time.Date(year, month, day+1, 0, 0, 0, 0, loc)
+}
+
+type monthDay struct {
+	month time.Month
+	day   int
+}
+
+// AnnualCalendar excludes a set of days of the year, e.g. the 1st of January, regardless of the year.
+type AnnualCalendar struct {
+	baseCalendar
+
+	excludeDays Set
+}
+
+func NewAnnualCalendar(base Calendar) *AnnualCalendar {
+	return &AnnualCalendar{
+		baseCalendar: baseCalendar{base: base},
+		excludeDays:  NewHashSet(),
+	}
+}
+
+func (c *AnnualCalendar) IsDayExcluded(month time.Month, day int) bool {
+	return c.excludeDays.Contains(monthDay{month, day})
+}
+
+// SetDayExcluded excludes or includes the day of the month, a day which doesn't exist in the month,
+// e.g. the 30th of February, is ignored, and the 29th of February only applies to the leap years.
+func (c *AnnualCalendar) SetDayExcluded(month time.Month, day int, excluded bool) {
+	if !validMonthDay(month, day) {
+		return
+	}
+
+	if excluded {
+		c.excludeDays.Add(monthDay{month, day})
+	} else {
+		c.excludeDays.Remove(monthDay{month, day})
+	}
+}
+
+// validMonthDay checks whether the day exists in the month of a leap year.
+func validMonthDay(month time.Month, day int) bool {
+	return month >= time.January && month <= time.December &&
+		day >= 1 && day <= time.Date(2000, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func (c *AnnualCalendar) excluded(t time.Time) bool {
+	_, month, day := t.In(c.Location()).Date()
+
+	return c.IsDayExcluded(month, day)
+}
+
+func (c *AnnualCalendar) IsTimeIncluded(t time.Time) bool {
+	return c.baseCalendar.IsTimeIncluded(t) && !c.excluded(t)
+}
+
+func (c *AnnualCalendar) NextIncludedTime(t time.Time) time.Time {
+	// only the existing days can be excluded, so 366 of them cover a whole leap year.
+	if c.excludeDays.Len() >= 366 {
+		return zero
+	}
+
+	return c.nextIncludedTime(t, c.excluded, func(t time.Time) time.Time {
+		return nextDay(t, c.Location())
+	})
+}
+
+func (c *AnnualCalendar) Clone() interface{} {
+	return &AnnualCalendar{
+		baseCalendar: c.baseCalendar.clone(),
+		excludeDays:  c.excludeDays.Clone().(Set),
+	}
+}
+
+// MonthlyCalendar excludes a set of days of the month, e.g. the 1st and 15th of every month.
+//
+// An excluded day which doesn't exist in a month, e.g. the 31st of April, simply doesn't apply to it.
+type MonthlyCalendar struct {
+	baseCalendar
+
+	excludeDays [31]bool
+}
+
+func NewMonthlyCalendar(base Calendar) *MonthlyCalendar {
+	return &MonthlyCalendar{baseCalendar: baseCalendar{base: base}}
+}
+
+func (c *MonthlyCalendar) IsDayExcluded(day int) bool {
+	return day >= 1 && day <= 31 && c.excludeDays[day-1]
+}
+
+// SetDayExcluded excludes or includes the day of the month, a day out of 1 to 31 is ignored.
+func (c *MonthlyCalendar) SetDayExcluded(day int, excluded bool) {
+	if day >= 1 && day <= 31 {
+		c.excludeDays[day-1] = excluded
+	}
+}
+
+func (c *MonthlyCalendar) DaysExcluded() (days []int) {
+	for i, excluded := range c.excludeDays {
+		if excluded {
+			days = append(days, i+1)
+		}
+	}
+
+	return
+}
+
+// AreAllDaysExcluded checks whether all the days of the month are excluded, so no time is ever included.
+func (c *MonthlyCalendar) AreAllDaysExcluded() bool {
+	for _, excluded := range c.excludeDays {
+		if !excluded {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (c *MonthlyCalendar) excluded(t time.Time) bool {
+	return c.IsDayExcluded(t.In(c.Location()).Day())
+}
+
+func (c *MonthlyCalendar) IsTimeIncluded(t time.Time) bool {
+	return c.baseCalendar.IsTimeIncluded(t) && !c.excluded(t)
+}
+
+func (c *MonthlyCalendar) NextIncludedTime(t time.Time) time.Time {
+	if c.AreAllDaysExcluded() {
+		return zero
+	}
+
+	return c.nextIncludedTime(t, c.excluded, func(t time.Time) time.Time {
+		return nextDay(t, c.Location())
+	})
+}
+
+func (c *MonthlyCalendar) Clone() interface{} {
+	clone := *c
+
+	clone.baseCalendar = c.baseCalendar.clone()
+
+	return &clone
+}
+
+type yearMonthDay struct {
+	year  int
+	month time.Month
+	day   int
+}
+
+// HolidayCalendar excludes a set of dates, e.g. the public holidays of a year.
+type HolidayCalendar struct {
+	baseCalendar
+
+	excludeDates Set
+}
+
+func NewHolidayCalendar(base Calendar) *HolidayCalendar {
+	return &HolidayCalendar{
+		baseCalendar: baseCalendar{base: base},
+		excludeDates: NewHashSet(),
+	}
+}
+
+func (c *HolidayCalendar) dateOf(t time.Time) yearMonthDay {
+	year, month, day := t.In(c.Location()).Date()
+
+	return yearMonthDay{year, month, day}
+}
+
+// AddExcludedDate excludes the date of the time in the location of the calendar, its time of the day is ignored.
+func (c *HolidayCalendar) AddExcludedDate(date time.Time) { c.excludeDates.Add(c.dateOf(date)) }
+
+func (c *HolidayCalendar) RemoveExcludedDate(date time.Time) { c.excludeDates.Remove(c.dateOf(date)) }
+
+// ExcludedDates returns the start of the excluded dates in the location of the calendar, in chronological order.
+func (c *HolidayCalendar) ExcludedDates() (dates []time.Time) {
+	for _, v := range c.excludeDates.Keys() {
+		date := v.(yearMonthDay)
+
+		dates = append(dates, time.Date(date.year, date.month, date.day, 0, 0, 0, 0, c.Location()))
+	}
+
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+
+	return
+}
+
+func (c *HolidayCalendar) excluded(t time.Time) bool { return c.excludeDates.Contains(c.dateOf(t)) }
+
+func (c *HolidayCalendar) IsTimeIncluded(t time.Time) bool {
+	return c.baseCalendar.IsTimeIncluded(t) && !c.excluded(t)
+}
+
+func (c *HolidayCalendar) NextIncludedTime(t time.Time) time.Time {
+	return c.nextIncludedTime(t, c.excluded, func(t time.Time) time.Time {
+		return nextDay(t, c.Location())
+	})
+}
+
+func (c *HolidayCalendar) Clone() interface{} {
+	return &HolidayCalendar{
+		baseCalendar: c.baseCalendar.clone(),
+		excludeDates: c.excludeDates.Clone().(Set),
+	}
+}
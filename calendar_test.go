@@ -0,0 +1,371 @@
+package quartz
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTimeOfDay(t *testing.T) {
+	Convey("Given a time of day string", t, func() {
+		tod, err := ParseTimeOfDay("08:30:15")
+
+		So(err, ShouldBeNil)
+		So(tod, ShouldResemble, TimeOfDay{8, 30, 15})
+		So(tod.String(), ShouldEqual, "08:30:15")
+
+		tod, err = ParseTimeOfDay("22:00")
+
+		So(err, ShouldBeNil)
+		So(tod, ShouldResemble, TimeOfDay{22, 0, 0})
+
+		Convey("Invalid time of day", func() {
+			_, err := ParseTimeOfDay("24:00:00")
+
+			So(err, ShouldNotBeNil)
+
+			_, err = ParseTimeOfDay("noon")
+
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestDailyCalendar(t *testing.T) {
+	loc := time.FixedZone("UTC+8", 8*3600)
+
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2016, time.March, day, hour, minute, 0, 0, loc)
+	}
+
+	Convey("Given a DailyCalendar excluding 00:00 - 06:00", t, func() {
+		cal, err := ParseDailyCalendar(nil, "00:00:00", "06:00:00")
+
+		So(err, ShouldBeNil)
+
+		cal.SetLocation(loc)
+
+		So(cal.IsTimeIncluded(at(1, 0, 0)), ShouldBeFalse)
+		So(cal.IsTimeIncluded(at(1, 3, 0)), ShouldBeFalse)
+		So(cal.IsTimeIncluded(at(1, 6, 0)), ShouldBeTrue)
+		So(cal.IsTimeIncluded(at(1, 23, 59)), ShouldBeTrue)
+
+		So(cal.NextIncludedTime(at(1, 3, 0)), ShouldResemble, at(1, 6, 0))
+		So(cal.NextIncludedTime(at(1, 12, 0)), ShouldResemble, at(1, 12, 0))
+
+		Convey("The range is evaluated in the calendar location", func() {
+			So(cal.IsTimeIncluded(at(1, 3, 0).UTC()), ShouldBeFalse)
+			So(cal.NextIncludedTime(at(1, 3, 0).UTC()).Equal(at(1, 6, 0)), ShouldBeTrue)
+		})
+	})
+
+	Convey("Given a DailyCalendar wrapping past midnight", t, func() {
+		cal := NewDailyCalendar(nil, TimeOfDay{22, 0, 0}, TimeOfDay{2, 0, 0})
+
+		cal.SetLocation(loc)
+
+		So(cal.IsTimeIncluded(at(1, 21, 59)), ShouldBeTrue)
+		So(cal.IsTimeIncluded(at(1, 22, 0)), ShouldBeFalse)
+		So(cal.IsTimeIncluded(at(2, 1, 59)), ShouldBeFalse)
+		So(cal.IsTimeIncluded(at(2, 2, 0)), ShouldBeTrue)
+
+		So(cal.NextIncludedTime(at(1, 23, 0)), ShouldResemble, at(2, 2, 0))
+		So(cal.NextIncludedTime(at(2, 1, 0)), ShouldResemble, at(2, 2, 0))
+	})
+
+	Convey("Given an inverted DailyCalendar including only 09:00 - 17:00", t, func() {
+		cal := NewDailyCalendar(nil, TimeOfDay{9, 0, 0}, TimeOfDay{17, 0, 0})
+
+		cal.SetLocation(loc)
+		cal.SetInvertTimeRange(true)
+
+		So(cal.InvertTimeRange(), ShouldBeTrue)
+
+		So(cal.IsTimeIncluded(at(1, 8, 59)), ShouldBeFalse)
+		So(cal.IsTimeIncluded(at(1, 9, 0)), ShouldBeTrue)
+		So(cal.IsTimeIncluded(at(1, 16, 59)), ShouldBeTrue)
+		So(cal.IsTimeIncluded(at(1, 17, 0)), ShouldBeFalse)
+
+		So(cal.NextIncludedTime(at(1, 6, 0)), ShouldResemble, at(1, 9, 0))
+		So(cal.NextIncludedTime(at(1, 12, 0)), ShouldResemble, at(1, 12, 0))
+		So(cal.NextIncludedTime(at(1, 18, 0)), ShouldResemble, at(2, 9, 0))
+
+		Convey("Invert a range crossing midnight", func() {
+			cal.SetTimeRange(TimeOfDay{22, 0, 0}, TimeOfDay{2, 0, 0})
+
+			So(cal.IsTimeIncluded(at(1, 23, 0)), ShouldBeTrue)
+			So(cal.IsTimeIncluded(at(2, 1, 0)), ShouldBeTrue)
+			So(cal.IsTimeIncluded(at(2, 12, 0)), ShouldBeFalse)
+
+			So(cal.NextIncludedTime(at(2, 2, 0)), ShouldResemble, at(2, 22, 0))
+		})
+
+		Convey("Invert an empty range", func() {
+			cal.SetTimeRange(TimeOfDay{9, 0, 0}, TimeOfDay{9, 0, 0})
+
+			So(cal.IsTimeIncluded(at(1, 9, 0)), ShouldBeFalse)
+			So(cal.NextIncludedTime(at(1, 9, 0)).IsZero(), ShouldBeTrue)
+		})
+	})
+
+	Convey("Given a DailyCalendar with a base calendar", t, func() {
+		base := NewDailyCalendar(nil, TimeOfDay{6, 0, 0}, TimeOfDay{8, 0, 0})
+		base.SetLocation(loc)
+
+		cal := NewDailyCalendar(base, TimeOfDay{0, 0, 0}, TimeOfDay{6, 0, 0})
+		cal.SetLocation(loc)
+
+		So(cal.IsTimeIncluded(at(1, 7, 0)), ShouldBeFalse)
+		So(cal.NextIncludedTime(at(1, 3, 0)), ShouldResemble, at(1, 8, 0))
+
+		Convey("Clone the calendar", func() {
+			clone := cal.Clone().(*DailyCalendar)
+
+			clone.SetTimeRange(TimeOfDay{0, 0, 0}, TimeOfDay{1, 0, 0})
+
+			So(cal.RangeEnd(), ShouldResemble, TimeOfDay{6, 0, 0})
+			So(clone.BaseCalendar(), ShouldNotEqual, base)
+			So(clone.NextIncludedTime(at(1, 0, 30)), ShouldResemble, at(1, 1, 0))
+		})
+	})
+}
+
+func TestAnnualCalendar(t *testing.T) {
+	Convey("Given an AnnualCalendar excluding New Year's Day and Christmas", t, func() {
+		cal := NewAnnualCalendar(nil)
+		cal.SetLocation(time.UTC)
+
+		cal.SetDayExcluded(time.January, 1, true)
+		cal.SetDayExcluded(time.December, 25, true)
+
+		So(cal.IsDayExcluded(time.January, 1), ShouldBeTrue)
+		So(cal.IsDayExcluded(time.January, 2), ShouldBeFalse)
+
+		Convey("The year should be ignored", func() {
+			for _, year := range []int{2015, 2016, 2020} {
+				So(cal.IsTimeIncluded(time.Date(year, time.January, 1, 12, 0, 0, 0, time.UTC)), ShouldBeFalse)
+				So(cal.IsTimeIncluded(time.Date(year, time.December, 25, 0, 0, 0, 0, time.UTC)), ShouldBeFalse)
+				So(cal.IsTimeIncluded(time.Date(year, time.December, 26, 0, 0, 0, 0, time.UTC)), ShouldBeTrue)
+			}
+		})
+
+		Convey("The next included time should roll over the year", func() {
+			cal.SetDayExcluded(time.December, 31, true)
+
+			So(cal.NextIncludedTime(time.Date(2015, time.December, 31, 10, 0, 0, 0, time.UTC)),
+				ShouldResemble, time.Date(2016, time.January, 2, 0, 0, 0, 0, time.UTC))
+			So(cal.NextIncludedTime(time.Date(2015, time.December, 30, 10, 0, 0, 0, time.UTC)),
+				ShouldResemble, time.Date(2015, time.December, 30, 10, 0, 0, 0, time.UTC))
+		})
+
+		Convey("Include an excluded day again", func() {
+			cal.SetDayExcluded(time.January, 1, false)
+
+			So(cal.IsTimeIncluded(time.Date(2016, time.January, 1, 0, 0, 0, 0, time.UTC)), ShouldBeTrue)
+		})
+
+		Convey("The days which don't exist should be ignored", func() {
+			cal.SetDayExcluded(time.February, 30, true)
+			cal.SetDayExcluded(time.April, 31, true)
+			cal.SetDayExcluded(time.Month(13), 1, true)
+
+			So(cal.IsDayExcluded(time.February, 30), ShouldBeFalse)
+			So(cal.IsDayExcluded(time.April, 31), ShouldBeFalse)
+
+			cal.SetDayExcluded(time.February, 29, true)
+
+			So(cal.IsDayExcluded(time.February, 29), ShouldBeTrue)
+		})
+
+		Convey("Exclude all the days but one", func() {
+			for day := time.Date(2016, time.January, 1, 0, 0, 0, 0, time.UTC); day.Year() == 2016; day = day.AddDate(0, 0, 1) {
+				cal.SetDayExcluded(day.Month(), day.Day(), true)
+			}
+
+			cal.SetDayExcluded(time.March, 1, false)
+			cal.SetDayExcluded(time.February, 30, true)
+
+			So(cal.NextIncludedTime(time.Date(2016, time.January, 1, 0, 0, 0, 0, time.UTC)),
+				ShouldResemble, time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC))
+
+			cal.SetDayExcluded(time.March, 1, true)
+
+			So(cal.NextIncludedTime(time.Date(2016, time.January, 1, 0, 0, 0, 0, time.UTC)).IsZero(), ShouldBeTrue)
+		})
+
+		Convey("Clone the calendar", func() {
+			clone := cal.Clone().(*AnnualCalendar)
+
+			clone.SetDayExcluded(time.July, 4, true)
+
+			So(clone.IsDayExcluded(time.January, 1), ShouldBeTrue)
+			So(cal.IsDayExcluded(time.July, 4), ShouldBeFalse)
+		})
+	})
+}
+
+func TestMonthlyCalendar(t *testing.T) {
+	Convey("Given a MonthlyCalendar excluding the last days of a month", t, func() {
+		cal := NewMonthlyCalendar(nil)
+		cal.SetLocation(time.UTC)
+
+		cal.SetDayExcluded(30, true)
+		cal.SetDayExcluded(31, true)
+		cal.SetDayExcluded(32, true)
+
+		So(cal.DaysExcluded(), ShouldResemble, []int{30, 31})
+
+		Convey("The day 31 should be skipped in short months", func() {
+			So(cal.IsTimeIncluded(time.Date(2016, time.April, 29, 0, 0, 0, 0, time.UTC)), ShouldBeTrue)
+			So(cal.NextIncludedTime(time.Date(2016, time.April, 30, 8, 0, 0, 0, time.UTC)),
+				ShouldResemble, time.Date(2016, time.May, 1, 0, 0, 0, 0, time.UTC))
+			So(cal.NextIncludedTime(time.Date(2016, time.February, 29, 8, 0, 0, 0, time.UTC)),
+				ShouldResemble, time.Date(2016, time.February, 29, 8, 0, 0, 0, time.UTC))
+		})
+
+		Convey("The next included time should roll over the month", func() {
+			cal.SetDayExcluded(1, true)
+
+			So(cal.NextIncludedTime(time.Date(2016, time.January, 30, 8, 0, 0, 0, time.UTC)),
+				ShouldResemble, time.Date(2016, time.February, 2, 0, 0, 0, 0, time.UTC))
+			So(cal.NextIncludedTime(time.Date(2016, time.December, 31, 8, 0, 0, 0, time.UTC)),
+				ShouldResemble, time.Date(2017, time.January, 2, 0, 0, 0, 0, time.UTC))
+		})
+
+		Convey("Chain with a base calendar", func() {
+			base := NewAnnualCalendar(nil)
+			base.SetLocation(time.UTC)
+			base.SetDayExcluded(time.May, 1, true)
+
+			cal.SetBaseCalendar(base)
+
+			So(cal.NextIncludedTime(time.Date(2016, time.April, 30, 8, 0, 0, 0, time.UTC)),
+				ShouldResemble, time.Date(2016, time.May, 2, 0, 0, 0, 0, time.UTC))
+		})
+
+		Convey("Exclude all the days", func() {
+			for day := 1; day <= 31; day++ {
+				cal.SetDayExcluded(day, true)
+			}
+
+			So(cal.AreAllDaysExcluded(), ShouldBeTrue)
+			So(cal.NextIncludedTime(time.Date(2016, time.April, 30, 8, 0, 0, 0, time.UTC)).IsZero(), ShouldBeTrue)
+		})
+
+		Convey("Chain with a base calendar excluding all the other days", func() {
+			for day := 1; day <= 15; day++ {
+				cal.SetDayExcluded(day, true)
+			}
+
+			base := NewMonthlyCalendar(nil)
+			base.SetLocation(time.UTC)
+
+			for day := 16; day <= 31; day++ {
+				base.SetDayExcluded(day, true)
+			}
+
+			cal.SetBaseCalendar(base)
+
+			So(cal.AreAllDaysExcluded(), ShouldBeFalse)
+			So(base.AreAllDaysExcluded(), ShouldBeFalse)
+			So(cal.NextIncludedTime(time.Date(2016, time.April, 30, 8, 0, 0, 0, time.UTC)).IsZero(), ShouldBeTrue)
+		})
+	})
+}
+
+func TestMonthlyCalendarExcludedDays(t *testing.T) {
+	Convey("Given a MonthlyCalendar excluding the 1st and 15th", t, func() {
+		cal := NewMonthlyCalendar(nil)
+		cal.SetLocation(time.UTC)
+
+		cal.SetDayExcluded(1, true)
+		cal.SetDayExcluded(15, true)
+
+		So(cal.DaysExcluded(), ShouldResemble, []int{1, 15})
+		So(cal.AreAllDaysExcluded(), ShouldBeFalse)
+
+		Convey("The days should be excluded in every month", func() {
+			for month := time.January; month <= time.December; month++ {
+				So(cal.IsTimeIncluded(time.Date(2016, month, 1, 12, 0, 0, 0, time.UTC)), ShouldBeFalse)
+				So(cal.IsTimeIncluded(time.Date(2016, month, 15, 12, 0, 0, 0, time.UTC)), ShouldBeFalse)
+				So(cal.IsTimeIncluded(time.Date(2016, month, 2, 12, 0, 0, 0, time.UTC)), ShouldBeTrue)
+
+				So(cal.NextIncludedTime(time.Date(2016, month, 1, 12, 0, 0, 0, time.UTC)),
+					ShouldResemble, time.Date(2016, month, 2, 0, 0, 0, 0, time.UTC))
+				So(cal.NextIncludedTime(time.Date(2016, month, 15, 12, 0, 0, 0, time.UTC)),
+					ShouldResemble, time.Date(2016, month, 16, 0, 0, 0, 0, time.UTC))
+			}
+		})
+
+		Convey("The next included day should skip the 1st of the next month", func() {
+			cal.SetDayExcluded(31, true)
+
+			So(cal.NextIncludedTime(time.Date(2016, time.January, 31, 8, 0, 0, 0, time.UTC)),
+				ShouldResemble, time.Date(2016, time.February, 2, 0, 0, 0, 0, time.UTC))
+			So(cal.NextIncludedTime(time.Date(2016, time.February, 29, 8, 0, 0, 0, time.UTC)),
+				ShouldResemble, time.Date(2016, time.February, 29, 8, 0, 0, 0, time.UTC))
+			So(cal.NextIncludedTime(time.Date(2016, time.April, 30, 8, 0, 0, 0, time.UTC)),
+				ShouldResemble, time.Date(2016, time.April, 30, 8, 0, 0, 0, time.UTC))
+		})
+
+		Convey("Include the day again", func() {
+			cal.SetDayExcluded(15, false)
+
+			So(cal.IsDayExcluded(15), ShouldBeFalse)
+			So(cal.IsTimeIncluded(time.Date(2016, time.March, 15, 12, 0, 0, 0, time.UTC)), ShouldBeTrue)
+		})
+	})
+}
+
+func TestHolidayCalendar(t *testing.T) {
+	Convey("Given a HolidayCalendar excluding Christmas and Boxing Day of 2015", t, func() {
+		cal := NewHolidayCalendar(nil)
+		cal.SetLocation(time.UTC)
+
+		cal.AddExcludedDate(time.Date(2015, time.December, 26, 18, 0, 0, 0, time.UTC))
+		cal.AddExcludedDate(time.Date(2015, time.December, 25, 0, 0, 0, 0, time.UTC))
+
+		So(cal.ExcludedDates(), ShouldResemble, []time.Time{
+			time.Date(2015, time.December, 25, 0, 0, 0, 0, time.UTC),
+			time.Date(2015, time.December, 26, 0, 0, 0, 0, time.UTC),
+		})
+
+		Convey("The whole excluded dates should be excluded, but not of the other years", func() {
+			So(cal.IsTimeIncluded(time.Date(2015, time.December, 25, 12, 0, 0, 0, time.UTC)), ShouldBeFalse)
+			So(cal.IsTimeIncluded(time.Date(2015, time.December, 26, 23, 59, 59, 0, time.UTC)), ShouldBeFalse)
+			So(cal.IsTimeIncluded(time.Date(2015, time.December, 27, 0, 0, 0, 0, time.UTC)), ShouldBeTrue)
+			So(cal.IsTimeIncluded(time.Date(2016, time.December, 25, 12, 0, 0, 0, time.UTC)), ShouldBeTrue)
+		})
+
+		Convey("The next included time should skip the consecutive excluded dates", func() {
+			So(cal.NextIncludedTime(time.Date(2015, time.December, 25, 10, 0, 0, 0, time.UTC)),
+				ShouldResemble, time.Date(2015, time.December, 27, 0, 0, 0, 0, time.UTC))
+			So(cal.NextIncludedTime(time.Date(2015, time.December, 24, 10, 0, 0, 0, time.UTC)),
+				ShouldResemble, time.Date(2015, time.December, 24, 10, 0, 0, 0, time.UTC))
+		})
+
+		Convey("The dates should be excluded in the location of the calendar", func() {
+			tokyo := time.FixedZone("Tokyo", 9*60*60)
+
+			So(cal.IsTimeIncluded(time.Date(2015, time.December, 27, 8, 0, 0, 0, tokyo)), ShouldBeFalse)
+			So(cal.IsTimeIncluded(time.Date(2015, time.December, 27, 9, 0, 0, 0, tokyo)), ShouldBeTrue)
+		})
+
+		Convey("Remove an excluded date", func() {
+			cal.RemoveExcludedDate(time.Date(2015, time.December, 26, 8, 0, 0, 0, time.UTC))
+
+			So(cal.IsTimeIncluded(time.Date(2015, time.December, 26, 0, 0, 0, 0, time.UTC)), ShouldBeTrue)
+			So(cal.ExcludedDates(), ShouldHaveLength, 1)
+		})
+
+		Convey("Clone the calendar", func() {
+			clone := cal.Clone().(*HolidayCalendar)
+
+			clone.AddExcludedDate(time.Date(2016, time.January, 1, 0, 0, 0, 0, time.UTC))
+
+			So(clone.ExcludedDates(), ShouldHaveLength, 3)
+			So(cal.ExcludedDates(), ShouldHaveLength, 2)
+		})
+	})
+}
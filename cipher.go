@@ -0,0 +1,178 @@
+package quartz
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrMissingDataMapCipher is returned when the persisted JobDataMap values are encrypted but the store has no cipher.
+var ErrMissingDataMapCipher = errors.New("The JobDataMap values are encrypted but no DataMapCipher is set.")
+
+// DataMapCipher encrypts the serialized values of the JobDataMaps persisted by a JobStore,
+// the keys of the JobDataMaps stay in clear text.
+type DataMapCipher interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+type aesGCMCipher struct {
+	aead cipher.AEAD
+}
+
+// NewAESGCMCipher returns a DataMapCipher using AES-GCM with a random nonce prepended to each ciphertext,
+// the key must be 16, 24 or 32 bytes to select AES-128, AES-192 or AES-256.
+func NewAESGCMCipher(key []byte) (DataMapCipher, error) {
+	block, err := aes.NewCipher(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &aesGCMCipher{aead}, nil
+}
+
+func (c *aesGCMCipher) Encrypt(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize(), c.aead.NonceSize()+len(plaintext)+c.aead.Overhead())
+
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return c.aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func (c *aesGCMCipher) Decrypt(ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) < c.aead.NonceSize() {
+		return nil, errors.New("The ciphertext is too short.")
+	}
+
+	nonce, sealed := ciphertext[:c.aead.NonceSize()], ciphertext[c.aead.NonceSize():]
+
+	return c.aead.Open(nil, nonce, sealed, nil)
+}
+
+// encryptedValue is a persisted JobDataMap value encrypted by the DataMapCipher of the store.
+type encryptedValue struct {
+	Data []byte
+}
+
+// encryptEntries replaces the persisted entries with their encrypted values, if the cipher is set.
+func encryptEntries(c DataMapCipher, entries map[string]interface{}) error {
+	if c == nil {
+		return nil
+	}
+
+	for key, value := range entries {
+		plaintext, err := gobEncode(gobValue{value})
+
+		if err != nil {
+			return fmt.Errorf("Unable to encode the value of key '%s': %w", key, err)
+		}
+
+		data, err := c.Encrypt(plaintext)
+
+		if err != nil {
+			return fmt.Errorf("Unable to encrypt the value of key '%s': %w", key, err)
+		}
+
+		entries[key] = &encryptedValue{data}
+	}
+
+	return nil
+}
+
+// decryptEntries replaces the encrypted values of the persisted entries with the decrypted ones.
+func decryptEntries(c DataMapCipher, entries map[string]interface{}) error {
+	for key, value := range entries {
+		v, ok := value.(*encryptedValue)
+
+		if !ok {
+			continue
+		}
+
+		if c == nil {
+			return ErrMissingDataMapCipher
+		}
+
+		plaintext, err := c.Decrypt(v.Data)
+
+		if err != nil {
+			return fmt.Errorf("Unable to decrypt the value of key '%s': %w", key, err)
+		}
+
+		var gv gobValue
+
+		if err := gobDecode(plaintext, &gv); err != nil {
+			return fmt.Errorf("Unable to decode the value of key '%s': %w", key, err)
+		}
+
+		entries[key] = gv.Value
+	}
+
+	return nil
+}
+
+// encodeJob encodes the record of the job with its JobDataMap values encrypted by the cipher, if set.
+func encodeJob(job JobDetail, c DataMapCipher) ([]byte, error) {
+	r := newJobRecord(job)
+
+	if err := encryptEntries(c, r.JobData); err != nil {
+		return nil, fmt.Errorf("Couldn't encrypt the job (%s): %w", job.Key(), err)
+	}
+
+	return gobEncode(r)
+}
+
+func decodeJob(key string, data []byte, c DataMapCipher) (JobDetail, error) {
+	var r jobRecord
+
+	if err := gobDecode(data, &r); err != nil {
+		return nil, fmt.Errorf("Couldn't decode the job (%s): %w", key, err)
+	}
+
+	if err := decryptEntries(c, r.JobData); err != nil {
+		return nil, fmt.Errorf("Couldn't decrypt the job (%s): %w", key, err)
+	}
+
+	return r.jobDetail(), nil
+}
+
+// encodeTrigger encodes the record of the trigger with its JobDataMap values encrypted by the cipher, if set.
+func encodeTrigger(trigger OperableTrigger, c DataMapCipher) ([]byte, error) {
+	r, err := newTriggerRecord(trigger)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := encryptEntries(c, r.JobData); err != nil {
+		return nil, fmt.Errorf("Couldn't encrypt the trigger (%s): %w", trigger.Key(), err)
+	}
+
+	return gobEncode(r)
+}
+
+func decodeTrigger(key string, data []byte, c DataMapCipher) (OperableTrigger, error) {
+	var r triggerRecord
+
+	if err := gobDecode(data, &r); err != nil {
+		return nil, fmt.Errorf("Couldn't decode the trigger (%s): %w", key, err)
+	}
+
+	if err := decryptEntries(c, r.JobData); err != nil {
+		return nil, fmt.Errorf("Couldn't decrypt the trigger (%s): %w", key, err)
+	}
+
+	return r.trigger(), nil
+}
@@ -0,0 +1,162 @@
+package quartz
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAESGCMCipher(t *testing.T) {
+	Convey("Given an AES-GCM cipher", t, func() {
+		cipher, err := NewAESGCMCipher(bytes.Repeat([]byte{1}, 32))
+
+		So(err, ShouldBeNil)
+
+		Convey("The plaintext should round trip", func() {
+			ciphertext, err := cipher.Encrypt([]byte("secret"))
+
+			So(err, ShouldBeNil)
+			So(bytes.Contains(ciphertext, []byte("secret")), ShouldBeFalse)
+
+			plaintext, err := cipher.Decrypt(ciphertext)
+
+			So(err, ShouldBeNil)
+			So(string(plaintext), ShouldEqual, "secret")
+
+			Convey("The nonce should be random", func() {
+				other, err := cipher.Encrypt([]byte("secret"))
+
+				So(err, ShouldBeNil)
+				So(bytes.Equal(ciphertext, other), ShouldBeFalse)
+			})
+
+			Convey("Another key should fail to decrypt it", func() {
+				other, err := NewAESGCMCipher(bytes.Repeat([]byte{2}, 32))
+
+				So(err, ShouldBeNil)
+
+				_, err = other.Decrypt(ciphertext)
+
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("The short ciphertext should fail to decrypt", func() {
+			_, err := cipher.Decrypt([]byte("short"))
+
+			So(err, ShouldNotBeNil)
+		})
+	})
+
+	Convey("Given an invalid key", t, func() {
+		_, err := NewAESGCMCipher([]byte("short"))
+
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestEncryptedFileJobStore(t *testing.T) {
+	Convey("Given an encrypted FileJobStore in a temporary directory", t, func() {
+		dir, err := os.MkdirTemp("", "quartz")
+
+		So(err, ShouldBeNil)
+
+		defer os.RemoveAll(dir)
+
+		path := filepath.Join(dir, "jobs.gob")
+
+		cipher, err := NewAESGCMCipher(bytes.Repeat([]byte{1}, 16))
+
+		So(err, ShouldBeNil)
+
+		store, err := NewEncryptedFileJobStore(path, cipher)
+
+		So(err, ShouldBeNil)
+
+		job := (&JobBuilder{}).
+			WithIdentity("job").
+			UsingJobData("password", "job-plaintext-secret").
+			UsingJobData("names", []string{"foo", "bar"}).
+			Build()
+
+		trigger := (&TriggerBuilder{}).
+			WithIdentity("trigger").
+			ForJobDetail(job).
+			UsingJobData("token", "trigger-plaintext-secret").
+			StartAt(time.Date(2016, time.March, 1, 8, 0, 0, 0, time.UTC)).
+			Build().(OperableTrigger)
+
+		So(store.StoreJobAndTrigger(job, trigger), ShouldBeNil)
+
+		Convey("The file should only keep the keys in clear text", func() {
+			content, err := os.ReadFile(path)
+
+			So(err, ShouldBeNil)
+			So(bytes.Contains(content, []byte("plaintext-secret")), ShouldBeFalse)
+			So(bytes.Contains(content, []byte("password")), ShouldBeTrue)
+			So(bytes.Contains(content, []byte("token")), ShouldBeTrue)
+		})
+
+		Convey("The values should round trip with the cipher", func() {
+			reloaded, err := NewEncryptedFileJobStore(path, cipher)
+
+			So(err, ShouldBeNil)
+
+			job, err := reloaded.RetrieveJob(job.Key())
+
+			So(err, ShouldBeNil)
+			So(job.JobDataMap().Get("password"), ShouldEqual, "job-plaintext-secret")
+			So(job.JobDataMap().Get("names"), ShouldResemble, []string{"foo", "bar"})
+
+			trigger, err := reloaded.RetrieveTrigger(trigger.Key())
+
+			So(err, ShouldBeNil)
+			So(trigger.JobDataMap().Get("token"), ShouldEqual, "trigger-plaintext-secret")
+		})
+
+		Convey("The file should not be loaded without the cipher", func() {
+			_, err := NewFileJobStore(path)
+
+			So(errors.Is(err, ErrMissingDataMapCipher), ShouldBeTrue)
+		})
+	})
+}
+
+func TestEncryptedRedisJobStore(t *testing.T) {
+	Convey("Given an encrypted RedisJobStore over a fake Redis", t, func() {
+		client := newFakeRedis()
+		store := NewRedisJobStore(client, "")
+
+		cipher, err := NewAESGCMCipher(bytes.Repeat([]byte{1}, 16))
+
+		So(err, ShouldBeNil)
+
+		store.SetDataMapCipher(cipher)
+
+		job := (&JobBuilder{}).WithIdentity("job").StoreDurably(true).UsingJobData("password", "plaintext-secret").Build()
+
+		So(store.StoreJob(job, false), ShouldBeNil)
+
+		Convey("The stored data should not contain the value", func() {
+			data, exists, err := client.HGet(store.jobsKey(), job.Key().String())
+
+			So(err, ShouldBeNil)
+			So(exists, ShouldBeTrue)
+			So(strings.Contains(data, "plaintext-secret"), ShouldBeFalse)
+			So(strings.Contains(data, "password"), ShouldBeTrue)
+		})
+
+		Convey("The value should round trip", func() {
+			job, err := store.RetrieveJob(job.Key())
+
+			So(err, ShouldBeNil)
+			So(job.JobDataMap().Get("password"), ShouldEqual, "plaintext-secret")
+		})
+	})
+}
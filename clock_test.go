@@ -0,0 +1,44 @@
+package quartz
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestManualClock(t *testing.T) {
+	Convey("Given a ManualClock", t, func() {
+		now := time.Date(2016, time.March, 1, 8, 0, 0, 0, time.UTC)
+
+		clock := NewManualClock(now)
+
+		So(clock.Now(), ShouldResemble, now)
+
+		timer := clock.NewTimer(time.Minute)
+
+		Convey("The timer should fire when the clock is advanced past it", func() {
+			clock.Advance(30 * time.Second)
+
+			So(clock.Now(), ShouldResemble, now.Add(30*time.Second))
+			So(timer.C(), ShouldBeEmpty)
+
+			clock.Advance(30 * time.Second)
+
+			So(<-timer.C(), ShouldResemble, now.Add(time.Minute))
+			So(timer.Stop(), ShouldBeFalse)
+		})
+
+		Convey("The stopped timer should never fire", func() {
+			So(timer.Stop(), ShouldBeTrue)
+
+			clock.Advance(time.Hour)
+
+			So(timer.C(), ShouldBeEmpty)
+		})
+
+		Convey("The timer without duration should fire at once", func() {
+			So(<-clock.NewTimer(0).C(), ShouldResemble, now)
+		})
+	})
+}
@@ -0,0 +1,176 @@
+package quartz
+
+import (
+	"database/sql"
+	"time"
+)
+
+// NewClusteredSQLJobStore creates a SQLJobStore shared by the scheduler instances of a cluster.
+//
+// Each instance checks in every checkinInterval, and the jobs fired by an instance which missed two check-ins
+// are recovered by another instance, the instanceID must be unique in the cluster.
+func NewClusteredSQLJobStore(db *sql.DB, dialect Dialect, instanceID string, checkinInterval time.Duration) *SQLJobStore {
+	s := NewSQLJobStore(db, dialect)
+
+	s.instanceID = instanceID
+	s.clustered = true
+	s.checkinInterval = checkinInterval
+
+	return s
+}
+
+// ClusterManager returns the ClusterManager started with the scheduler, or nil if the store is not clustered.
+func (s *SQLJobStore) ClusterManager() *ClusterManager { return s.manager }
+
+// checkIn records the instance is alive, the record is created again if another instance removed it.
+func (s *SQLJobStore) checkIn() error {
+	return s.inTx(func(tx *sql.Tx) error {
+		now := fireTimeColumn(s.clock.Now())
+
+		exists, err := s.exists(tx, "SELECT COUNT(*) FROM {scheduler_state} WHERE instance_name = ?", s.instanceID)
+
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			_, err = tx.Exec(s.sql("UPDATE {scheduler_state} SET last_checkin = ?, checkin_interval = ? WHERE instance_name = ?"),
+				now, int64(s.checkinInterval), s.instanceID)
+		} else {
+			_, err = tx.Exec(s.sql("INSERT INTO {scheduler_state} (instance_name, last_checkin, checkin_interval) VALUES (?, ?, ?)"),
+				s.instanceID, now, int64(s.checkinInterval))
+		}
+
+		return err
+	})
+}
+
+// recoverFailedInstances recovers the other instances which missed two check-ins, and returns them.
+//
+// The record of a failed instance is removed with its last check-in, so only one of the surviving instances
+// recovers it, and an instance checking in again meanwhile is not recovered.
+func (s *SQLJobStore) recoverFailedInstances() ([]string, error) {
+	type instanceState struct {
+		name            string
+		lastCheckin     int64
+		checkinInterval int64
+	}
+
+	var states []instanceState
+
+	err := s.inTx(func(tx *sql.Tx) error {
+		rows, err := tx.Query(s.sql("SELECT instance_name, last_checkin, checkin_interval FROM {scheduler_state} "+
+			"WHERE instance_name <> ? ORDER BY instance_name"), s.instanceID)
+
+		if err != nil {
+			return err
+		}
+
+		defer rows.Close()
+
+		for rows.Next() {
+			var state instanceState
+
+			if err := rows.Scan(&state.name, &state.lastCheckin, &state.checkinInterval); err != nil {
+				return err
+			}
+
+			states = append(states, state)
+		}
+
+		return rows.Err()
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	now := fireTimeColumn(s.clock.Now())
+
+	var recovered []string
+
+	for _, state := range states {
+		if now <= state.lastCheckin+2*state.checkinInterval {
+			continue
+		}
+
+		removed := false
+
+		err := s.inTx(func(tx *sql.Tx) error {
+			res, err := tx.Exec(s.sql("DELETE FROM {scheduler_state} WHERE instance_name = ? AND last_checkin = ?"),
+				state.name, state.lastCheckin)
+
+			if err != nil {
+				return err
+			}
+
+			if n, err := res.RowsAffected(); err != nil || n == 0 {
+				return err
+			}
+
+			removed = true
+
+			return s.recoverJobs(tx, state.name)
+		})
+
+		if err != nil {
+			return recovered, err
+		}
+
+		if removed {
+			recovered = append(recovered, state.name)
+		}
+	}
+
+	return recovered, nil
+}
+
+// ClusterManager checks the instance of a clustered SQLJobStore in every check-in interval,
+// and recovers the jobs fired by the instances which stopped checking in.
+type ClusterManager struct {
+	store *SQLJobStore
+	halt  chan struct{}
+	done  chan struct{}
+}
+
+func newClusterManager(store *SQLJobStore) *ClusterManager {
+	return &ClusterManager{store: store, halt: make(chan struct{}), done: make(chan struct{})}
+}
+
+// Manage checks the instance in, and returns the failed instances it recovered.
+func (m *ClusterManager) Manage() ([]string, error) {
+	if err := m.store.checkIn(); err != nil {
+		return nil, err
+	}
+
+	return m.store.recoverFailedInstances()
+}
+
+func (m *ClusterManager) start() {
+	go m.run()
+}
+
+// run manages the cluster until the manager is stopped, a failed check-in or recovery is retried in the next interval.
+func (m *ClusterManager) run() {
+	defer close(m.done)
+
+	for {
+		timer := m.store.clock.NewTimer(m.store.checkinInterval)
+
+		select {
+		case <-m.halt:
+			timer.Stop()
+
+			return
+
+		case <-timer.C():
+			m.Manage()
+		}
+	}
+}
+
+func (m *ClusterManager) stop() {
+	close(m.halt)
+
+	<-m.done
+}
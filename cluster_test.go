@@ -0,0 +1,186 @@
+//go:build sqlite
+
+// The clustering tests share the SQLite databases of the SQLJobStore tests, they need the cgo driver
+// github.com/mattn/go-sqlite3, run them with `go test -tags sqlite`.
+
+package quartz
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestClusteredSQLJobStore(t *testing.T) {
+	Convey("Given two clustered instances sharing a database", t, func() {
+		db, _ := openSQLiteJobStore()
+
+		defer db.Close()
+
+		startTime := time.Date(2016, time.March, 1, 8, 0, 0, 0, time.UTC)
+
+		clock1, clock2 := NewManualClock(startTime), NewManualClock(startTime)
+
+		node1 := NewClusteredSQLJobStore(db, SQLiteDialect, "node1", time.Second)
+		node1.SetClock(clock1)
+		node2 := NewClusteredSQLJobStore(db, SQLiteDialect, "node2", time.Second)
+		node2.SetClock(clock2)
+
+		So(node1.Clustered(), ShouldBeTrue)
+		So(node1.InstanceID(), ShouldEqual, "node1")
+		So(node1.SchedulerStarted(), ShouldBeNil)
+
+		defer node1.Shutdown()
+
+		So(node2.SchedulerStarted(), ShouldBeNil)
+
+		defer node2.Shutdown()
+
+		So(node1.ClusterManager(), ShouldNotBeNil)
+
+		job := (&JobBuilder{}).WithIdentity("job").StoreDurably(true).RequestRecovery(true).Build()
+
+		So(node1.StoreJob(job, false), ShouldBeNil)
+
+		newTrigger := func(name string) OperableTrigger {
+			trigger := (&TriggerBuilder{}).
+				WithIdentity(name).
+				ForJobDetail(job).
+				StartAt(startTime).
+				UsingJobData("name", name).
+				Build().(OperableTrigger)
+			trigger.ComputeFirstFireTime(nil)
+
+			return trigger
+		}
+
+		Convey("A trigger should be acquired by only one instance", func() {
+			for i := 0; i < 20; i++ {
+				So(node1.StoreTrigger(newTrigger("trigger"+string(rune('a'+i))), false), ShouldBeNil)
+			}
+
+			var lock sync.Mutex
+			var wg sync.WaitGroup
+
+			acquired := make(map[string]int)
+
+			for _, node := range []*SQLJobStore{node1, node2, node1, node2} {
+				wg.Add(1)
+
+				go func(node *SQLJobStore) {
+					defer wg.Done()
+
+					for i := 0; i < 10; i++ {
+						triggers, err := node.AcquireNextTriggers(startTime, 3, 0)
+
+						if err != nil {
+							continue
+						}
+
+						lock.Lock()
+						for _, trigger := range triggers {
+							acquired[trigger.Key().String()]++
+						}
+						lock.Unlock()
+					}
+				}(node)
+			}
+
+			wg.Wait()
+
+			So(acquired, ShouldHaveLength, 20)
+
+			for _, count := range acquired {
+				So(count, ShouldEqual, 1)
+			}
+		})
+
+		Convey("When the first instance fails while executing a job", func() {
+			fired, waiting := newTrigger("fired"), newTrigger("waiting")
+
+			So(node1.StoreTrigger(fired, false), ShouldBeNil)
+			So(node1.StoreTrigger(waiting, false), ShouldBeNil)
+
+			acquired, err := node1.AcquireNextTriggers(startTime, 10, 0)
+
+			So(err, ShouldBeNil)
+			So(acquired, ShouldHaveLength, 2)
+
+			bundles, err := node1.TriggersFired([]OperableTrigger{fired})
+
+			So(err, ShouldBeNil)
+			So(bundles, ShouldHaveLength, 1)
+
+			acquired, _ = node2.AcquireNextTriggers(startTime, 10, 0)
+
+			So(acquired, ShouldBeEmpty)
+
+			Convey("The instance should not be recovered while it checks in", func() {
+				clock1.Advance(time.Second)
+				clock2.Advance(time.Second)
+
+				recovered, err := node2.ClusterManager().Manage()
+
+				So(err, ShouldBeNil)
+				So(recovered, ShouldBeEmpty)
+
+				clock2.Advance(time.Second)
+
+				recovered, _ = node1.ClusterManager().Manage()
+
+				So(recovered, ShouldBeEmpty)
+			})
+
+			Convey("Another instance should recover its jobs once its check-ins are stale", func() {
+				clock2.Advance(3 * time.Second)
+
+				recovered, err := node2.ClusterManager().Manage()
+
+				So(err, ShouldBeNil)
+				So(recovered, ShouldResemble, []string{"node1"})
+
+				keys, err := node2.GetTriggerKeys(GroupEquals(RECOVERING_JOBS_GROUP))
+
+				So(err, ShouldBeNil)
+				So(keys, ShouldHaveLength, 1)
+
+				recovery, _ := node2.RetrieveTrigger(keys[0])
+
+				So(recovery.JobKey(), ShouldResemble, job.Key())
+				So(recovery.JobDataMap().Get("name"), ShouldEqual, "fired")
+
+				state, _ := node2.GetTriggerState(waiting.Key())
+
+				So(state, ShouldEqual, STATE_WAITING)
+
+				acquired, _ := node2.AcquireNextTriggers(clock2.Now(), 10, 0)
+
+				So(acquired, ShouldHaveLength, 2)
+
+				recovered, _ = node2.ClusterManager().Manage()
+
+				So(recovered, ShouldBeEmpty)
+			})
+
+			Convey("The ClusterManager should recover it in the background", func() {
+				node1.Shutdown()
+
+				So(node1.ClusterManager(), ShouldBeNil)
+
+				deadline := time.Now().Add(5 * time.Second)
+
+				for time.Now().Before(deadline) && len(node2.TriggersForJob(job.Key())) < 3 {
+					clock2.Advance(time.Second)
+
+					time.Sleep(10 * time.Millisecond)
+				}
+
+				keys, _ := node2.GetTriggerKeys(GroupEquals(RECOVERING_JOBS_GROUP))
+
+				So(keys, ShouldHaveLength, 1)
+			})
+		})
+	})
+}
@@ -0,0 +1,140 @@
+package quartz
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// ValueCodec serializes the values of a custom type in the JobDataMap for the persistent stores.
+type ValueCodec struct {
+	Encode func(value interface{}) ([]byte, error)
+
+	Decode func(data []byte) (interface{}, error)
+}
+
+var (
+	valueCodecsLock sync.RWMutex
+	valueCodecs     = make(map[string]*ValueCodec)
+)
+
+// RegisterValueCodec registers the codec of the values whose ValueTypeName is typeName, replacing the registered one.
+//
+// The persistent stores encode the values of the registered types with the codec,
+// the values of the other custom types are encoded by gob and must be registered with gob.Register.
+func RegisterValueCodec(typeName string, enc func(interface{}) ([]byte, error), dec func([]byte) (interface{}, error)) {
+	valueCodecsLock.Lock()
+	defer valueCodecsLock.Unlock()
+
+	valueCodecs[typeName] = &ValueCodec{enc, dec}
+}
+
+func lookupValueCodec(typeName string) *ValueCodec {
+	valueCodecsLock.RLock()
+	defer valueCodecsLock.RUnlock()
+
+	return valueCodecs[typeName]
+}
+
+// ValueTypeName returns the name of the value's type to register its ValueCodec, e.g. 'main.Point' or '*main.Point'.
+func ValueTypeName(value interface{}) string {
+	return reflect.TypeOf(value).String()
+}
+
+// persistedValue wraps a value of a custom type in the encoded entries of a JobDataMap,
+// it's encoded with the ValueCodec of the type, or by gob if no codec is registered.
+type persistedValue struct {
+	value interface{}
+}
+
+type persistedValueRecord struct {
+	Type  string
+	Codec bool
+	Data  []byte
+}
+
+type gobValue struct {
+	Value interface{}
+}
+
+func (v *persistedValue) GobEncode() ([]byte, error) {
+	r := persistedValueRecord{Type: ValueTypeName(v.value)}
+
+	var err error
+
+	if codec := lookupValueCodec(r.Type); codec != nil {
+		r.Codec = true
+
+		if r.Data, err = codec.Encode(v.value); err != nil {
+			return nil, fmt.Errorf("Unable to encode the value of type '%s': %w", r.Type, err)
+		}
+	} else if r.Data, err = gobEncode(gobValue{v.value}); err != nil {
+		return nil, fmt.Errorf("The value of type '%s' can't be persisted, register a ValueCodec or gob.Register the type: %w", r.Type, err)
+	}
+
+	return gobEncode(r)
+}
+
+func (v *persistedValue) GobDecode(data []byte) error {
+	var r persistedValueRecord
+
+	if err := gobDecode(data, &r); err != nil {
+		return err
+	}
+
+	if !r.Codec {
+		var gv gobValue
+
+		if err := gobDecode(r.Data, &gv); err != nil {
+			return fmt.Errorf("Unable to decode the value of type '%s': %w", r.Type, err)
+		}
+
+		v.value = gv.Value
+
+		return nil
+	}
+
+	codec := lookupValueCodec(r.Type)
+
+	if codec == nil {
+		return fmt.Errorf("No ValueCodec is registered for the type '%s'.", r.Type)
+	}
+
+	value, err := codec.Decode(r.Data)
+
+	if err != nil {
+		return fmt.Errorf("Unable to decode the value of type '%s': %w", r.Type, err)
+	}
+
+	v.value = value
+
+	return nil
+}
+
+// persistedEntries returns the entries of the map to be persisted, the values of the custom types are wrapped
+// to be encoded by their ValueCodec, or nil if the map is empty.
+func persistedEntries(m JobDataMap) map[string]interface{} {
+	entries := dataMapEntries(m)
+
+	for key, value := range entries {
+		if !isBuiltinValue(value) {
+			entries[key] = &persistedValue{value}
+		}
+	}
+
+	return entries
+}
+
+// isBuiltinValue returns whether gob encodes the value without a registration.
+func isBuiltinValue(value interface{}) bool {
+	switch value.(type) {
+	case nil, bool, string, []byte, time.Time,
+		int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, uintptr,
+		float32, float64, complex64, complex128:
+		return true
+
+	default:
+		return false
+	}
+}
@@ -0,0 +1,115 @@
+package quartz
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type codecPoint struct {
+	X, Y int
+}
+
+type unknownValue struct {
+	Value int
+}
+
+func TestValueCodec(t *testing.T) {
+	RegisterValueCodec(ValueTypeName(codecPoint{}), func(value interface{}) ([]byte, error) {
+		p := value.(codecPoint)
+
+		return []byte(fmt.Sprintf("%d,%d", p.X, p.Y)), nil
+	}, func(data []byte) (interface{}, error) {
+		var p codecPoint
+
+		if _, err := fmt.Sscanf(string(data), "%d,%d", &p.X, &p.Y); err != nil {
+			return nil, err
+		}
+
+		return p, nil
+	})
+
+	Convey("Given a FileJobStore in a temporary directory", t, func() {
+		So(ValueTypeName(codecPoint{}), ShouldEqual, "quartz.codecPoint")
+		So(ValueTypeName(&codecPoint{}), ShouldEqual, "*quartz.codecPoint")
+
+		dir, err := os.MkdirTemp("", "quartz")
+
+		So(err, ShouldBeNil)
+
+		defer os.RemoveAll(dir)
+
+		path := filepath.Join(dir, "jobs.gob")
+
+		store, err := NewFileJobStore(path)
+
+		So(err, ShouldBeNil)
+
+		Convey("When stores a job with the values of a registered type", func() {
+			job := (&JobBuilder{}).
+				WithIdentity("job").
+				StoreDurably(true).
+				UsingJobData("point", codecPoint{1, 2}).
+				UsingJobData("names", []string{"foo", "bar"}).
+				Build()
+
+			trigger := (&TriggerBuilder{}).
+				WithIdentity("trigger").
+				ForJobDetail(job).
+				UsingJobData("point", codecPoint{3, 4}).
+				StartNow().
+				Build().(OperableTrigger)
+
+			So(store.StoreJobAndTrigger(job, trigger), ShouldBeNil)
+
+			Convey("The values should be restored by the codec", func() {
+				reloaded, err := NewFileJobStore(path)
+
+				So(err, ShouldBeNil)
+
+				job, err := reloaded.RetrieveJob(job.Key())
+
+				So(err, ShouldBeNil)
+				So(job.JobDataMap().Get("point"), ShouldResemble, codecPoint{1, 2})
+				So(job.JobDataMap().Get("names"), ShouldResemble, []string{"foo", "bar"})
+
+				trigger, err := reloaded.RetrieveTrigger(trigger.Key())
+
+				So(err, ShouldBeNil)
+				So(trigger.JobDataMap().Get("point"), ShouldResemble, codecPoint{3, 4})
+			})
+		})
+
+		Convey("When stores a job with the value of an unknown type", func() {
+			job := (&JobBuilder{}).
+				WithIdentity("unknown").
+				StoreDurably(true).
+				UsingJobData("value", unknownValue{1}).
+				Build()
+
+			err := store.StoreJob(job, false)
+
+			Convey("It should fail with the type", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "quartz.unknownValue")
+				So(err.Error(), ShouldContainSubstring, "register a ValueCodec")
+			})
+		})
+	})
+
+	Convey("Given a value encoded by a codec which isn't registered", t, func() {
+		data, err := gobEncode(persistedValueRecord{Type: "quartz.missing", Codec: true})
+
+		So(err, ShouldBeNil)
+
+		Convey("It should fail to be decoded", func() {
+			err := (&persistedValue{}).GobDecode(data)
+
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "quartz.missing")
+		})
+	})
+}
@@ -0,0 +1,148 @@
+package quartz
+
+import (
+	"context"
+	"time"
+)
+
+// TriggerFiredBundle holds the information the scheduler needs to execute a Job when its Trigger fired.
+type TriggerFiredBundle struct {
+	JobDetail JobDetail
+
+	Trigger OperableTrigger
+
+	Calendar Calendar
+
+	Recovering bool
+
+	FireTime time.Time
+
+	ScheduledFireTime time.Time
+
+	PrevFireTime time.Time
+
+	NextFireTime time.Time
+}
+
+type jobExecutionContext struct {
+	scheduler   Scheduler
+	bundle      *TriggerFiredBundle
+	jobInstance Job
+	dataMap     JobDataMap
+	data        map[string]interface{}
+	result      interface{}
+	exception   *JobExecutionException
+	jobRunTime  time.Duration
+	ctx         context.Context
+	cancel      context.CancelFunc
+}
+
+func newJobExecutionContext(parent context.Context, scheduler Scheduler, bundle *TriggerFiredBundle, job Job) *jobExecutionContext {
+	if parent == nil {
+		parent = context.Background()
+	}
+
+	ctx, cancel := context.WithCancel(parent)
+
+	// the changes of the job which doesn't persist its data would be lost, so it's given a read-only view.
+	if d, ok := bundle.JobDetail.(*jobDetail); ok && !d.persistJobData && d.dataMap != nil {
+		if _, unmodifiable := d.dataMap.(*unmodifiableDataMap); !unmodifiable {
+			d.dataMap = UnmodifiableMap(d.dataMap).(JobDataMap)
+		}
+	}
+
+	dataMap := NewJobDataMap()
+
+	if m := bundle.JobDetail.JobDataMap(); m != nil {
+		dataMap.PutAll(m)
+	}
+
+	if m := bundle.Trigger.JobDataMap(); m != nil {
+		dataMap.PutAll(m)
+	}
+
+	dataMap.ClearDirtyFlag()
+
+	return &jobExecutionContext{
+		scheduler:   scheduler,
+		bundle:      bundle,
+		jobInstance: job,
+		dataMap:     dataMap,
+		data:        make(map[string]interface{}),
+		jobRunTime:  -1,
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+func (c *jobExecutionContext) Scheduler() Scheduler { return c.scheduler }
+
+func (c *jobExecutionContext) Context() context.Context { return c.ctx }
+
+func (c *jobExecutionContext) setContext(ctx context.Context) { c.ctx = ctx }
+
+// interrupt cancels the context of the execution, it is also called to release the context once the job completed.
+func (c *jobExecutionContext) interrupt() { c.cancel() }
+
+func (c *jobExecutionContext) Trigger() Trigger { return c.bundle.Trigger }
+
+func (c *jobExecutionContext) JobInstance() Job { return c.jobInstance }
+
+func (c *jobExecutionContext) JobDetail() JobDetail { return c.bundle.JobDetail }
+
+func (c *jobExecutionContext) FireTime() time.Time { return c.bundle.FireTime }
+
+func (c *jobExecutionContext) ScheduledFireTime() time.Time { return c.bundle.ScheduledFireTime }
+
+func (c *jobExecutionContext) PreviousFireTime() time.Time { return c.bundle.PrevFireTime }
+
+func (c *jobExecutionContext) NextFireTime() time.Time { return c.bundle.NextFireTime }
+
+// JobRunTime returns -1 until the job has completed.
+func (c *jobExecutionContext) JobRunTime() time.Duration { return c.jobRunTime }
+
+func (c *jobExecutionContext) setJobRunTime(d time.Duration) { c.jobRunTime = d }
+
+func (c *jobExecutionContext) Result() interface{} { return c.result }
+
+func (c *jobExecutionContext) SetResult(result interface{}) { c.result = result }
+
+func (c *jobExecutionContext) Exception() error {
+	if c.exception == nil {
+		return nil
+	}
+
+	return c.exception
+}
+
+func (c *jobExecutionContext) setException(e *JobExecutionException) { c.exception = e }
+
+// MergedJobDataMap returns the JobDataMap of the JobDetail overlaid with the one of the Trigger.
+//
+// The merged map is a copy, if it was changed by the job which persists its data after execution,
+// it replaces the JobDataMap of the JobDetail when the job completes.
+func (c *jobExecutionContext) MergedJobDataMap() JobDataMap { return c.dataMap }
+
+// persistMergedJobDataMap replaces the JobDataMap of the job which persists its data with the merged one,
+// if it was changed, so the JobStore stores it for the next execution.
+//
+// The attempt of a retried job belongs to its retry trigger, it is not persisted with the data of the job.
+func (c *jobExecutionContext) persistMergedJobDataMap() {
+	d, ok := c.bundle.JobDetail.(*jobDetail)
+
+	if !ok || !d.persistJobData || !c.dataMap.Dirty() {
+		return
+	}
+
+	dataMap := c.dataMap.CloneDataMap()
+
+	if data := c.bundle.Trigger.JobDataMap(); data != nil && data.Contains(RetryAttemptKey) {
+		dataMap.Remove(RetryAttemptKey)
+	}
+
+	d.dataMap = dataMap
+}
+
+func (c *jobExecutionContext) Put(key string, value interface{}) { c.data[key] = value }
+
+func (c *jobExecutionContext) Get(key string) interface{} { return c.data[key] }
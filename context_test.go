@@ -0,0 +1,115 @@
+package quartz
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestJobExecutionContext(t *testing.T) {
+	Convey("Given a fired trigger of a job", t, func() {
+		jobDetail := (&JobBuilder{}).
+			WithIdentity("job").
+			UsingJobData("job", "job").
+			UsingJobData("shared", "job").
+			Build()
+
+		trigger := (&TriggerBuilder{}).
+			WithIdentity("trigger").
+			ForJobDetail(jobDetail).
+			UsingJobData("trigger", "trigger").
+			UsingJobData("shared", "trigger").
+			Build().(OperableTrigger)
+
+		now := time.Now()
+
+		bundle := &TriggerFiredBundle{
+			JobDetail:         jobDetail,
+			Trigger:           trigger,
+			FireTime:          now,
+			ScheduledFireTime: now.Add(-time.Second),
+			PrevFireTime:      now.Add(-time.Minute),
+			NextFireTime:      now.Add(time.Minute),
+		}
+
+		job := JobFunc(func(context JobExecutionContext) error { return nil })
+
+		var context JobExecutionContext = newJobExecutionContext(nil, nil, bundle, job)
+
+		So(context.JobDetail(), ShouldEqual, jobDetail)
+		So(context.Trigger(), ShouldEqual, trigger)
+		So(context.JobInstance(), ShouldNotBeNil)
+		So(context.FireTime(), ShouldResemble, now)
+		So(context.ScheduledFireTime(), ShouldResemble, now.Add(-time.Second))
+		So(context.PreviousFireTime(), ShouldResemble, now.Add(-time.Minute))
+		So(context.NextFireTime(), ShouldResemble, now.Add(time.Minute))
+		So(context.JobRunTime(), ShouldEqual, -1)
+		So(context.Exception(), ShouldBeNil)
+
+		Convey("The trigger data should override the job data", func() {
+			m := context.MergedJobDataMap()
+
+			So(m.Get("job"), ShouldEqual, "job")
+			So(m.Get("trigger"), ShouldEqual, "trigger")
+			So(m.Get("shared"), ShouldEqual, "trigger")
+			So(m.Dirty(), ShouldBeFalse)
+
+			m.Put("shared", "context")
+			m.Put("added", "context")
+
+			So(jobDetail.JobDataMap().Get("shared"), ShouldEqual, "job")
+			So(trigger.JobDataMap().Get("shared"), ShouldEqual, "trigger")
+			So(jobDetail.JobDataMap().Contains("added"), ShouldBeFalse)
+			So(trigger.JobDataMap().Contains("added"), ShouldBeFalse)
+			So(context.MergedJobDataMap().Get("added"), ShouldEqual, "context")
+		})
+
+		Convey("The context should keep the values and the result", func() {
+			context.Put("key", "value")
+			context.SetResult(123)
+
+			So(context.Get("key"), ShouldEqual, "value")
+			So(context.Get("missing"), ShouldBeNil)
+			So(context.Result(), ShouldEqual, 123)
+		})
+
+		Convey("The context should keep the exception", func() {
+			err := errors.New("failed")
+
+			context.(*jobExecutionContext).setException(NewJobExecutionException(err))
+
+			So(errors.Is(context.Exception(), err), ShouldBeTrue)
+		})
+
+		Convey("The job data of the job which doesn't persist it should be read-only", func() {
+			dataMap := context.JobDetail().JobDataMap()
+
+			So(dataMap.Get("job"), ShouldEqual, "job")
+			So(func() { dataMap.Put("job", "changed") }, ShouldPanicWith, ErrUnmodifiable)
+		})
+	})
+
+	Convey("Given a fired trigger of a job persisting its data", t, func() {
+		jobDetail := (&JobBuilder{}).
+			WithIdentity("job").
+			UsingJobData("job", "job").
+			PersistJobDataAfterExecution(true).
+			Build()
+
+		bundle := &TriggerFiredBundle{
+			JobDetail: jobDetail,
+			Trigger:   (&TriggerBuilder{}).WithIdentity("trigger").ForJobDetail(jobDetail).Build().(OperableTrigger),
+			FireTime:  time.Now(),
+		}
+
+		context := newJobExecutionContext(nil, nil, bundle, nil)
+
+		Convey("The job data should be modifiable", func() {
+			context.JobDetail().JobDataMap().Put("job", "changed")
+
+			So(jobDetail.JobDataMap().Get("job"), ShouldEqual, "changed")
+		})
+	})
+}
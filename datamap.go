@@ -0,0 +1,395 @@
+package quartz
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// TypedDataMap provides typed access to the values of a Map,
+// the returned bool reports whether the key is present and the value has the expected type.
+type TypedDataMap interface {
+	GetString(key string) (string, bool)
+
+	GetInt(key string) (int, bool)
+
+	GetInt64(key string) (int64, bool)
+
+	GetFloat64(key string) (float64, bool)
+
+	GetBool(key string) (bool, bool)
+
+	GetTime(key string) (time.Time, bool)
+
+	GetStringOr(key string, def string) string
+
+	GetIntOr(key string, def int) int
+
+	GetInt64Or(key string, def int64) int64
+
+	GetFloat64Or(key string, def float64) float64
+
+	GetBoolOr(key string, def bool) bool
+
+	GetTimeOr(key string, def time.Time) time.Time
+
+	// GetIntValue returns the int value, parsing it if it was stored as a string.
+	GetIntValue(key string) (int, error)
+
+	// GetLongValue returns the int64 value, parsing it if it was stored as a string.
+	GetLongValue(key string) (int64, error)
+
+	// GetBoolValue returns the bool value, parsing it if it was stored as a string.
+	GetBoolValue(key string) (bool, error)
+
+	// GetFloatValue returns the float64 value, parsing it if it was stored as a string.
+	GetFloatValue(key string) (float64, error)
+}
+
+// ErrKeyNotFound is returned by the value getters of TypedDataMap when the key is missing.
+var ErrKeyNotFound = errors.New("key not found")
+
+func keyNotFoundError(key string) error {
+	return fmt.Errorf("Unable to get the value of key '%s', %w.", key, ErrKeyNotFound)
+}
+
+func invalidValueError(key string, value interface{}, kind string, err error) error {
+	if err != nil {
+		return fmt.Errorf("The value of key '%s' (%v) is not a valid %s: %w", key, value, kind, err)
+	}
+
+	return fmt.Errorf("The value of key '%s' (%T) is not a valid %s.", key, value, kind)
+}
+
+func (m *dirtyFlagMap) GetString(key string) (s string, ok bool) {
+	s, ok = m.entries[key].(string)
+
+	return
+}
+
+// GetInt accepts any integer value which fits in an int.
+func (m *dirtyFlagMap) GetInt(key string) (int, bool) {
+	if n, ok := toInt64(m.entries[key]); ok && math.MinInt <= n && n <= math.MaxInt {
+		return int(n), true
+	}
+
+	return 0, false
+}
+
+// GetInt64 accepts any integer value which fits in an int64.
+func (m *dirtyFlagMap) GetInt64(key string) (int64, bool) { return toInt64(m.entries[key]) }
+
+// GetFloat64 accepts a float32 or float64 value.
+func (m *dirtyFlagMap) GetFloat64(key string) (float64, bool) {
+	switch v := m.entries[key].(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	}
+
+	return 0, false
+}
+
+func (m *dirtyFlagMap) GetBool(key string) (b bool, ok bool) {
+	b, ok = m.entries[key].(bool)
+
+	return
+}
+
+func (m *dirtyFlagMap) GetTime(key string) (t time.Time, ok bool) {
+	t, ok = m.entries[key].(time.Time)
+
+	return
+}
+
+func (m *dirtyFlagMap) GetStringOr(key string, def string) string {
+	if s, ok := m.GetString(key); ok {
+		return s
+	}
+
+	return def
+}
+
+func (m *dirtyFlagMap) GetIntOr(key string, def int) int {
+	if n, ok := m.GetInt(key); ok {
+		return n
+	}
+
+	return def
+}
+
+func (m *dirtyFlagMap) GetInt64Or(key string, def int64) int64 {
+	if n, ok := m.GetInt64(key); ok {
+		return n
+	}
+
+	return def
+}
+
+func (m *dirtyFlagMap) GetFloat64Or(key string, def float64) float64 {
+	if f, ok := m.GetFloat64(key); ok {
+		return f
+	}
+
+	return def
+}
+
+func (m *dirtyFlagMap) GetBoolOr(key string, def bool) bool {
+	if b, ok := m.GetBool(key); ok {
+		return b
+	}
+
+	return def
+}
+
+func (m *dirtyFlagMap) GetTimeOr(key string, def time.Time) time.Time {
+	if t, ok := m.GetTime(key); ok {
+		return t
+	}
+
+	return def
+}
+
+func (m *dirtyFlagMap) GetIntValue(key string) (int, error) {
+	n, err := m.GetLongValue(key)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if n < math.MinInt || n > math.MaxInt {
+		return 0, invalidValueError(key, m.entries[key], "int", strconv.ErrRange)
+	}
+
+	return int(n), nil
+}
+
+func (m *dirtyFlagMap) GetLongValue(key string) (int64, error) {
+	value, exists := m.entries[key]
+
+	if !exists {
+		return 0, keyNotFoundError(key)
+	}
+
+	if n, ok := toInt64(value); ok {
+		return n, nil
+	}
+
+	s, ok := value.(string)
+
+	if !ok {
+		return 0, invalidValueError(key, value, "integer", nil)
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+
+	if err != nil {
+		return 0, invalidValueError(key, value, "integer", err)
+	}
+
+	return n, nil
+}
+
+func (m *dirtyFlagMap) GetBoolValue(key string) (bool, error) {
+	value, exists := m.entries[key]
+
+	if !exists {
+		return false, keyNotFoundError(key)
+	}
+
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+
+	case string:
+		b, err := strconv.ParseBool(v)
+
+		if err != nil {
+			return false, invalidValueError(key, value, "bool", err)
+		}
+
+		return b, nil
+	}
+
+	return false, invalidValueError(key, value, "bool", nil)
+}
+
+// GetFloatValue also accepts the integer values.
+func (m *dirtyFlagMap) GetFloatValue(key string) (float64, error) {
+	value, exists := m.entries[key]
+
+	if !exists {
+		return 0, keyNotFoundError(key)
+	}
+
+	if f, ok := m.GetFloat64(key); ok {
+		return f, nil
+	}
+
+	if n, ok := toInt64(value); ok {
+		return float64(n), nil
+	}
+
+	s, ok := value.(string)
+
+	if !ok {
+		return 0, invalidValueError(key, value, "float", nil)
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+
+	if err != nil {
+		return 0, invalidValueError(key, value, "float", err)
+	}
+
+	return f, nil
+}
+
+// MarshalJSON serializes the entries as a JSON object in the order of Keys.
+//
+// Only the JSON-compatible values survive the round trip, the integral numbers are decoded as int64,
+// the other numbers as float64, the times as strings and the nested maps as map[string]interface{}.
+func (m *dirtyFlagMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteByte('{')
+
+	for i, key := range m.Keys() {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		k, _ := json.Marshal(key)
+
+		buf.Write(k)
+		buf.WriteByte(':')
+
+		v, err := json.Marshal(m.entries[key])
+
+		if err != nil {
+			return nil, fmt.Errorf("Unable to marshal the value of key '%s' (%T) to JSON: %w", key, m.entries[key], err)
+		}
+
+		buf.Write(v)
+	}
+
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON replaces the entries with the JSON object and clears the dirty flag,
+// an ordered map keeps the keys in the order of the JSON object.
+func (m *dirtyFlagMap) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	entries := make(map[string]interface{})
+	order := make([]string, 0)
+
+	tok, err := dec.Token()
+
+	if err != nil {
+		return err
+	}
+
+	if tok != nil {
+		if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+			return fmt.Errorf("Unable to unmarshal %v into a JobDataMap, expected a JSON object.", tok)
+		}
+
+		for dec.More() {
+			if tok, err = dec.Token(); err != nil {
+				return err
+			}
+
+			key := tok.(string)
+
+			var value interface{}
+
+			if err := dec.Decode(&value); err != nil {
+				return err
+			}
+
+			if _, exists := entries[key]; !exists {
+				order = append(order, key)
+			}
+
+			entries[key] = fromJSONNumber(value)
+		}
+
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+	}
+
+	m.entries = entries
+	m.dirty = false
+
+	if m.ordered {
+		m.order = order
+	}
+
+	return nil
+}
+
+func fromJSONNumber(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+
+		f, _ := v.Float64()
+
+		return f
+
+	case []interface{}:
+		for i, item := range v {
+			v[i] = fromJSONNumber(item)
+		}
+
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = fromJSONNumber(item)
+		}
+	}
+
+	return value
+}
+
+func toInt64(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint8:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	case uint:
+		if uint64(v) <= math.MaxInt64 {
+			return int64(v), true
+		}
+	case uint64:
+		if v <= math.MaxInt64 {
+			return int64(v), true
+		}
+	}
+
+	return 0, false
+}
@@ -0,0 +1,271 @@
+package quartz
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strconv"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTypedDataMap(t *testing.T) {
+	Convey("Given a JobDataMap with some values", t, func() {
+		now := time.Now()
+
+		m := NewJobDataMap()
+		m.Put("string", "value")
+		m.Put("int", 123)
+		m.Put("int32", int32(-456))
+		m.Put("uint64", uint64(math.MaxUint64))
+		m.Put("float32", float32(1.5))
+		m.Put("float64", 3.14)
+		m.Put("bool", true)
+		m.Put("time", now)
+
+		Convey("The typed accessors should return the values", func() {
+			s, ok := m.GetString("string")
+			So(ok, ShouldBeTrue)
+			So(s, ShouldEqual, "value")
+
+			n, ok := m.GetInt("int")
+			So(ok, ShouldBeTrue)
+			So(n, ShouldEqual, 123)
+
+			n, ok = m.GetInt("int32")
+			So(ok, ShouldBeTrue)
+			So(n, ShouldEqual, -456)
+
+			l, ok := m.GetInt64("int")
+			So(ok, ShouldBeTrue)
+			So(l, ShouldEqual, 123)
+
+			f, ok := m.GetFloat64("float64")
+			So(ok, ShouldBeTrue)
+			So(f, ShouldEqual, 3.14)
+
+			f, ok = m.GetFloat64("float32")
+			So(ok, ShouldBeTrue)
+			So(f, ShouldEqual, 1.5)
+
+			b, ok := m.GetBool("bool")
+			So(ok, ShouldBeTrue)
+			So(b, ShouldBeTrue)
+
+			tm, ok := m.GetTime("time")
+			So(ok, ShouldBeTrue)
+			So(tm, ShouldResemble, now)
+		})
+
+		Convey("The missing keys should not be found", func() {
+			_, ok := m.GetString("missing")
+			So(ok, ShouldBeFalse)
+
+			_, ok = m.GetInt("missing")
+			So(ok, ShouldBeFalse)
+
+			_, ok = m.GetTime("missing")
+			So(ok, ShouldBeFalse)
+
+			So(m.GetStringOr("missing", "def"), ShouldEqual, "def")
+			So(m.GetIntOr("missing", 7), ShouldEqual, 7)
+			So(m.GetInt64Or("missing", 8), ShouldEqual, 8)
+			So(m.GetFloat64Or("missing", 0.5), ShouldEqual, 0.5)
+			So(m.GetBoolOr("missing", true), ShouldBeTrue)
+			So(m.GetTimeOr("missing", now), ShouldResemble, now)
+		})
+
+		Convey("The mismatched types should not be found", func() {
+			_, ok := m.GetString("int")
+			So(ok, ShouldBeFalse)
+
+			_, ok = m.GetInt("string")
+			So(ok, ShouldBeFalse)
+
+			_, ok = m.GetInt64("uint64")
+			So(ok, ShouldBeFalse)
+
+			_, ok = m.GetFloat64("int")
+			So(ok, ShouldBeFalse)
+
+			_, ok = m.GetBool("string")
+			So(ok, ShouldBeFalse)
+
+			_, ok = m.GetTime("string")
+			So(ok, ShouldBeFalse)
+
+			So(m.GetStringOr("int", "def"), ShouldEqual, "def")
+			So(m.GetIntOr("float64", 7), ShouldEqual, 7)
+			So(m.GetBoolOr("string", false), ShouldBeFalse)
+		})
+
+		Convey("The clone should keep the typed accessors", func() {
+			clone := m.Clone().(JobDataMap)
+
+			So(clone.GetStringOr("string", "def"), ShouldEqual, "value")
+		})
+
+		Convey("The typed clone should be independent and carry the dirty flag", func() {
+			clone := m.CloneDataMap()
+
+			So(clone.Dirty(), ShouldBeTrue)
+			So(clone.Entries(), ShouldResemble, m.Entries())
+
+			clone.Put("string", "another")
+			clone.Remove("int")
+
+			So(m.GetStringOr("string", "def"), ShouldEqual, "value")
+			So(m.Contains("int"), ShouldBeTrue)
+
+			m.ClearDirtyFlag()
+
+			clone = m.CloneDataMap()
+
+			So(clone.Dirty(), ShouldBeFalse)
+
+			clone.Put("added", true)
+
+			So(clone.Dirty(), ShouldBeTrue)
+			So(m.Dirty(), ShouldBeFalse)
+			So(m.Contains("added"), ShouldBeFalse)
+		})
+	})
+}
+
+func TestDataMapValues(t *testing.T) {
+	Convey("Given a JobDataMap with native and string values", t, func() {
+		m := NewJobDataMap()
+		m.Put("int", 123)
+		m.Put("int64", int64(math.MaxInt64))
+		m.Put("float", 1.5)
+		m.Put("bool", true)
+		m.Put("intString", "-456")
+		m.Put("floatString", "2.5")
+		m.Put("boolString", "false")
+		m.Put("bad", "abc")
+		m.Put("big", "99999999999999999999")
+		m.Put("slice", []int{1, 2, 3})
+
+		Convey("The native values should be returned", func() {
+			n, err := m.GetIntValue("int")
+			So(err, ShouldBeNil)
+			So(n, ShouldEqual, 123)
+
+			l, err := m.GetLongValue("int64")
+			So(err, ShouldBeNil)
+			So(l, ShouldEqual, int64(math.MaxInt64))
+
+			f, err := m.GetFloatValue("float")
+			So(err, ShouldBeNil)
+			So(f, ShouldEqual, 1.5)
+
+			f, err = m.GetFloatValue("int")
+			So(err, ShouldBeNil)
+			So(f, ShouldEqual, 123)
+
+			b, err := m.GetBoolValue("bool")
+			So(err, ShouldBeNil)
+			So(b, ShouldBeTrue)
+		})
+
+		Convey("The string values should be parsed", func() {
+			n, err := m.GetIntValue("intString")
+			So(err, ShouldBeNil)
+			So(n, ShouldEqual, -456)
+
+			l, err := m.GetLongValue("intString")
+			So(err, ShouldBeNil)
+			So(l, ShouldEqual, -456)
+
+			f, err := m.GetFloatValue("floatString")
+			So(err, ShouldBeNil)
+			So(f, ShouldEqual, 2.5)
+
+			b, err := m.GetBoolValue("boolString")
+			So(err, ShouldBeNil)
+			So(b, ShouldBeFalse)
+		})
+
+		Convey("The missing key should be distinguishable", func() {
+			_, err := m.GetIntValue("missing")
+			So(errors.Is(err, ErrKeyNotFound), ShouldBeTrue)
+
+			_, err = m.GetBoolValue("missing")
+			So(errors.Is(err, ErrKeyNotFound), ShouldBeTrue)
+
+			_, err = m.GetFloatValue("missing")
+			So(errors.Is(err, ErrKeyNotFound), ShouldBeTrue)
+		})
+
+		Convey("The invalid values should be reported", func() {
+			_, err := m.GetIntValue("bad")
+			So(err, ShouldNotBeNil)
+			So(errors.Is(err, ErrKeyNotFound), ShouldBeFalse)
+			So(errors.Is(err, strconv.ErrSyntax), ShouldBeTrue)
+			So(err.Error(), ShouldContainSubstring, "bad")
+
+			_, err = m.GetLongValue("big")
+			So(errors.Is(err, strconv.ErrRange), ShouldBeTrue)
+
+			_, err = m.GetBoolValue("bad")
+			So(errors.Is(err, strconv.ErrSyntax), ShouldBeTrue)
+
+			_, err = m.GetFloatValue("bad")
+			So(errors.Is(err, strconv.ErrSyntax), ShouldBeTrue)
+
+			_, err = m.GetIntValue("slice")
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "[]int")
+
+			_, err = m.GetBoolValue("int")
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestDataMapJSON(t *testing.T) {
+	Convey("Given a JobDataMap with mixed values", t, func() {
+		m := NewJobDataMap()
+		m.Put("string", "value")
+		m.Put("int", 123)
+		m.Put("float", 1.5)
+		m.Put("bool", true)
+		m.Put("list", []interface{}{"a", 1})
+
+		data, err := json.Marshal(m)
+
+		So(err, ShouldBeNil)
+		So(string(data), ShouldEqual, `{"bool":true,"float":1.5,"int":123,"list":["a",1],"string":"value"}`)
+
+		Convey("When unmarshal it to another map", func() {
+			other := NewJobDataMap()
+			other.Put("stale", "value")
+
+			So(json.Unmarshal(data, other), ShouldBeNil)
+
+			So(other.Dirty(), ShouldBeFalse)
+			So(other.Keys(), ShouldResemble, []string{"bool", "float", "int", "list", "string"})
+			So(other.Get("string"), ShouldEqual, "value")
+			So(other.Get("int"), ShouldEqual, int64(123))
+			So(other.GetIntOr("int", 0), ShouldEqual, 123)
+			So(other.Get("float"), ShouldEqual, 1.5)
+			So(other.Get("bool"), ShouldEqual, true)
+			So(other.Get("list"), ShouldResemble, []interface{}{"a", int64(1)})
+		})
+
+		Convey("When a value is not serializable", func() {
+			m.Put("func", func() {})
+
+			_, err := json.Marshal(m)
+
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "func")
+		})
+
+		Convey("When the JSON is not an object", func() {
+			So(json.Unmarshal([]byte(`[1, 2]`), NewJobDataMap()), ShouldNotBeNil)
+		})
+	})
+}
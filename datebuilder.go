@@ -0,0 +1,106 @@
+package quartz
+
+import (
+	"fmt"
+	"time"
+)
+
+// The DateBuilder-style helpers compute the times commonly given to TriggerBuilder.StartAt,
+// e.g. StartAt(TomorrowAt(10, 0, 0)) or StartAt(EvenHourDate(time.Time{})).
+//
+// They take an optional *time.Location, the local time zone is used by default for the times of the day,
+// and the location of the given time for the times after it.
+
+// TodayAt returns today's time at the hour, minute and second, it panics if they are out of range.
+func TodayAt(hour, minute, second int, loc ...*time.Location) time.Time {
+	return todayAt(time.Now(), hour, minute, second, locationOr(time.Local, loc))
+}
+
+// TomorrowAt returns tomorrow's time at the hour, minute and second, it panics if they are out of range.
+func TomorrowAt(hour, minute, second int, loc ...*time.Location) time.Time {
+	return tomorrowAt(time.Now(), hour, minute, second, locationOr(time.Local, loc))
+}
+
+// EvenHourDate returns the start of the next hour after the time, or after now if it is zero,
+// e.g. 09:00:00 for 08:13:54, and 10:00:00 for 09:00:00.
+func EvenHourDate(after time.Time, loc ...*time.Location) time.Time {
+	after = afterIn(after, loc)
+
+	return time.Date(after.Year(), after.Month(), after.Day(), after.Hour()+1, 0, 0, 0, after.Location())
+}
+
+// EvenMinuteDate returns the start of the next minute after the time, or after now if it is zero,
+// e.g. 08:14:00 for 08:13:54, and 08:15:00 for 08:14:00.
+func EvenMinuteDate(after time.Time, loc ...*time.Location) time.Time {
+	after = afterIn(after, loc)
+
+	return time.Date(after.Year(), after.Month(), after.Day(), after.Hour(), after.Minute()+1, 0, 0, after.Location())
+}
+
+// NextGivenMinuteDate returns the next minute after the time, or after now if it is zero, which is a multiple of
+// the minuteBase in the hour, e.g. 08:15:00 for 08:13:54 with a base of 15, and the start of the next hour
+// if there is none or the base is 0, it panics if the minuteBase is not between 0 and 59.
+func NextGivenMinuteDate(after time.Time, minuteBase int, loc ...*time.Location) time.Time {
+	if minuteBase < 0 || minuteBase > 59 {
+		panic(fmt.Sprintf("Invalid minuteBase %d (must be >= 0 and <= 59).", minuteBase))
+	}
+
+	after = afterIn(after, loc)
+
+	if minuteBase == 0 {
+		return EvenHourDate(after)
+	}
+
+	if next := (after.Minute()/minuteBase + 1) * minuteBase; next < 60 {
+		return time.Date(after.Year(), after.Month(), after.Day(), after.Hour(), next, 0, 0, after.Location())
+	}
+
+	return EvenHourDate(after)
+}
+
+func todayAt(now time.Time, hour, minute, second int, loc *time.Location) time.Time {
+	validateTimeOfDay(hour, minute, second)
+
+	now = now.In(loc)
+
+	return time.Date(now.Year(), now.Month(), now.Day(), hour, minute, second, 0, loc)
+}
+
+func tomorrowAt(now time.Time, hour, minute, second int, loc *time.Location) time.Time {
+	validateTimeOfDay(hour, minute, second)
+
+	now = now.In(loc)
+
+	return time.Date(now.Year(), now.Month(), now.Day()+1, hour, minute, second, 0, loc)
+}
+
+func validateTimeOfDay(hour, minute, second int) {
+	if hour < 0 || hour > 23 {
+		panic(fmt.Sprintf("Invalid hour %d (must be >= 0 and <= 23).", hour))
+	}
+
+	if minute < 0 || minute > 59 {
+		panic(fmt.Sprintf("Invalid minute %d (must be >= 0 and <= 59).", minute))
+	}
+
+	if second < 0 || second > 59 {
+		panic(fmt.Sprintf("Invalid second %d (must be >= 0 and <= 59).", second))
+	}
+}
+
+// afterIn returns the time, or now if it is zero, in the optional location.
+func afterIn(after time.Time, loc []*time.Location) time.Time {
+	if after.IsZero() {
+		after = time.Now()
+	}
+
+	return after.In(locationOr(after.Location(), loc))
+}
+
+func locationOr(def *time.Location, loc []*time.Location) *time.Location {
+	if len(loc) > 0 && loc[0] != nil {
+		return loc[0]
+	}
+
+	return def
+}
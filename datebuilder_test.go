@@ -0,0 +1,86 @@
+package quartz
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDateBuilder(t *testing.T) {
+	Convey("Given a time zone and a time in it", t, func() {
+		tokyo := time.FixedZone("Tokyo", 9*60*60)
+		now := time.Date(2020, time.March, 14, 8, 13, 54, 123, time.UTC)
+
+		Convey("The time of today should be in the time zone", func() {
+			So(todayAt(now, 10, 30, 0, time.UTC), ShouldEqual, time.Date(2020, time.March, 14, 10, 30, 0, 0, time.UTC))
+			So(todayAt(now, 10, 30, 0, tokyo), ShouldEqual, time.Date(2020, time.March, 14, 10, 30, 0, 0, tokyo))
+
+			So(todayAt(time.Date(2020, time.March, 14, 20, 0, 0, 0, time.UTC), 10, 30, 0, tokyo),
+				ShouldEqual, time.Date(2020, time.March, 15, 10, 30, 0, 0, tokyo))
+
+			So(TodayAt(10, 30, 0, tokyo).Location(), ShouldEqual, tokyo)
+			So(TodayAt(10, 30, 0).Location(), ShouldEqual, time.Local)
+		})
+
+		Convey("The time of tomorrow should roll over the month and year", func() {
+			So(tomorrowAt(now, 10, 30, 0, time.UTC), ShouldEqual, time.Date(2020, time.March, 15, 10, 30, 0, 0, time.UTC))
+			So(tomorrowAt(time.Date(2020, time.February, 29, 8, 0, 0, 0, time.UTC), 0, 0, 0, time.UTC),
+				ShouldEqual, time.Date(2020, time.March, 1, 0, 0, 0, 0, time.UTC))
+			So(tomorrowAt(time.Date(2020, time.December, 31, 23, 59, 59, 0, time.UTC), 0, 0, 0, time.UTC),
+				ShouldEqual, time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC))
+
+			So(TomorrowAt(10, 30, 0, tokyo).Location(), ShouldEqual, tokyo)
+			So(TomorrowAt(10, 30, 0, tokyo), ShouldHappenAfter, time.Now())
+		})
+
+		Convey("The invalid time of the day should panic", func() {
+			So(func() { TodayAt(24, 0, 0) }, ShouldPanic)
+			So(func() { TodayAt(0, 60, 0) }, ShouldPanic)
+			So(func() { TomorrowAt(0, 0, 60) }, ShouldPanic)
+			So(func() { TomorrowAt(-1, 0, 0) }, ShouldPanic)
+		})
+
+		Convey("The even hour should be the start of the next hour", func() {
+			So(EvenHourDate(now), ShouldEqual, time.Date(2020, time.March, 14, 9, 0, 0, 0, time.UTC))
+			So(EvenHourDate(time.Date(2020, time.March, 14, 9, 0, 0, 0, time.UTC)),
+				ShouldEqual, time.Date(2020, time.March, 14, 10, 0, 0, 0, time.UTC))
+			So(EvenHourDate(time.Date(2020, time.December, 31, 23, 30, 0, 0, time.UTC)),
+				ShouldEqual, time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC))
+
+			So(EvenHourDate(now, tokyo), ShouldEqual, time.Date(2020, time.March, 14, 18, 0, 0, 0, tokyo))
+			So(EvenHourDate(time.Time{}), ShouldHappenAfter, time.Now())
+		})
+
+		Convey("The even minute should be the start of the next minute", func() {
+			So(EvenMinuteDate(now), ShouldEqual, time.Date(2020, time.March, 14, 8, 14, 0, 0, time.UTC))
+			So(EvenMinuteDate(time.Date(2020, time.March, 14, 8, 14, 0, 0, time.UTC)),
+				ShouldEqual, time.Date(2020, time.March, 14, 8, 15, 0, 0, time.UTC))
+			So(EvenMinuteDate(time.Date(2020, time.March, 14, 8, 59, 30, 0, time.UTC)),
+				ShouldEqual, time.Date(2020, time.March, 14, 9, 0, 0, 0, time.UTC))
+			So(EvenMinuteDate(time.Date(2020, time.March, 14, 23, 59, 30, 0, time.UTC)),
+				ShouldEqual, time.Date(2020, time.March, 15, 0, 0, 0, 0, time.UTC))
+
+			So(EvenMinuteDate(now, tokyo).Location(), ShouldEqual, tokyo)
+			So(EvenMinuteDate(time.Time{}), ShouldHappenAfter, time.Now())
+		})
+
+		Convey("The next given minute should be the next multiple of the minute base", func() {
+			So(NextGivenMinuteDate(now, 15), ShouldEqual, time.Date(2020, time.March, 14, 8, 15, 0, 0, time.UTC))
+			So(NextGivenMinuteDate(time.Date(2020, time.March, 14, 8, 15, 0, 0, time.UTC), 15),
+				ShouldEqual, time.Date(2020, time.March, 14, 8, 30, 0, 0, time.UTC))
+			So(NextGivenMinuteDate(time.Date(2020, time.March, 14, 8, 45, 0, 0, time.UTC), 15),
+				ShouldEqual, time.Date(2020, time.March, 14, 9, 0, 0, 0, time.UTC))
+			So(NextGivenMinuteDate(time.Date(2020, time.March, 14, 8, 50, 0, 0, time.UTC), 25),
+				ShouldEqual, time.Date(2020, time.March, 14, 9, 0, 0, 0, time.UTC))
+			So(NextGivenMinuteDate(time.Date(2020, time.March, 14, 23, 55, 0, 0, time.UTC), 10),
+				ShouldEqual, time.Date(2020, time.March, 15, 0, 0, 0, 0, time.UTC))
+			So(NextGivenMinuteDate(now, 0), ShouldEqual, time.Date(2020, time.March, 14, 9, 0, 0, 0, time.UTC))
+
+			So(NextGivenMinuteDate(now, 20, tokyo), ShouldEqual, time.Date(2020, time.March, 14, 17, 20, 0, 0, tokyo))
+
+			So(func() { NextGivenMinuteDate(now, -1) }, ShouldPanic)
+			So(func() { NextGivenMinuteDate(now, 60) }, ShouldPanic)
+		})
+	})
+}
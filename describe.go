@@ -0,0 +1,236 @@
+package quartz
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DescribeSchedule renders the schedule of the trigger in English, e.g. "every 5 minutes, 100 times",
+// or "at 08:30 on weekdays" for a schedule printed as a common cron expression.
+//
+// It is best-effort, a schedule it doesn't understand falls back to its String() or type name.
+func DescribeSchedule(t Trigger) string {
+	return describeScheduleBuilder(t.ScheduleBuilder(), t)
+}
+
+func describeScheduleBuilder(sb ScheduleBuilder, t Trigger) string {
+	switch b := sb.(type) {
+	case *SimpleScheduleBuilder:
+		return describeSimpleSchedule(b, t.StartTime(), t.EndTime())
+
+	case *JitterScheduleBuilder:
+		return describeScheduleBuilder(b.base, t) + ", delayed up to " + describeInterval(b.maxJitter)
+
+	case fmt.Stringer:
+		if desc, ok := describeCronExpression(b.String()); ok {
+			return desc
+		}
+
+		return b.String()
+
+	default:
+		return fmt.Sprintf("%T", b)
+	}
+}
+
+func describeSimpleSchedule(b *SimpleScheduleBuilder, startTime, endTime time.Time) string {
+	if b.repeatCount == 0 || b.repeatInterval <= 0 {
+		if startTime.IsZero() {
+			return "once"
+		}
+
+		return "once at " + startTime.Format("2006-01-02 15:04:05")
+	}
+
+	desc := "every " + describeInterval(b.repeatInterval)
+
+	if b.repeatCount == REPEAT_INDEFINITELY {
+		desc += ", indefinitely"
+	} else {
+		desc += fmt.Sprintf(", %d times", b.repeatCount+1)
+	}
+
+	if !endTime.IsZero() {
+		desc += ", until " + endTime.Format("2006-01-02 15:04:05")
+	}
+
+	return desc
+}
+
+func describeInterval(d time.Duration) string {
+	units := []struct {
+		unit time.Duration
+		name string
+	}{
+		{24 * time.Hour, "day"},
+		{time.Hour, "hour"},
+		{time.Minute, "minute"},
+		{time.Second, "second"},
+	}
+
+	for _, u := range units {
+		if d%u.unit == 0 {
+			if n := d / u.unit; n > 1 {
+				return fmt.Sprintf("%d %ss", n, u.name)
+			}
+
+			return u.name
+		}
+	}
+
+	return d.String()
+}
+
+// describeCronExpression renders the common patterns of a Quartz cron expression,
+// e.g. "0 30 8 ? * MON-FRI" as "at 08:30 on weekdays", it returns false for the other expressions.
+func describeCronExpression(expr string) (string, bool) {
+	fields := strings.Fields(strings.ToUpper(expr))
+
+	if len(fields) == 7 && fields[6] == "*" {
+		fields = fields[:6]
+	}
+
+	if len(fields) != 6 || fields[4] != "*" {
+		return "", false
+	}
+
+	when, fixed, ok := describeCronTime(fields[0], fields[1], fields[2])
+
+	if !ok {
+		return "", false
+	}
+
+	days, ok := describeCronDays(fields[3], fields[5])
+
+	if !ok {
+		return "", false
+	}
+
+	if days == "" && fixed {
+		days = "every day"
+	}
+
+	if days == "" {
+		return when, true
+	}
+
+	return when + " " + days, true
+}
+
+// describeCronTime renders the second, minute and hour fields, fixed is true if they fire once a day.
+func describeCronTime(second, minute, hour string) (desc string, fixed bool, ok bool) {
+	if second != "0" {
+		return "", false, false
+	}
+
+	m, minuteFixed := cronNumber(minute, 59)
+	h, hourFixed := cronNumber(hour, 23)
+
+	switch {
+	case minuteFixed && hourFixed:
+		return fmt.Sprintf("at %02d:%02d", h, m), true, true
+
+	case minute == "*" && hour == "*":
+		return "every minute", false, true
+
+	case hour == "*" && minuteFixed:
+		if m == 0 {
+			return "every hour", false, true
+		}
+
+		return fmt.Sprintf("every hour at minute %d", m), false, true
+
+	case hour == "*":
+		if n, ok := cronStep(minute); ok {
+			return "every " + describeInterval(time.Duration(n)*time.Minute), false, true
+		}
+
+	case minuteFixed && m == 0:
+		if n, ok := cronStep(hour); ok {
+			return "every " + describeInterval(time.Duration(n)*time.Hour), false, true
+		}
+	}
+
+	return "", false, false
+}
+
+// describeCronDays renders the day of month and day of week fields, or empty for every day.
+func describeCronDays(dayOfMonth, dayOfWeek string) (string, bool) {
+	anyDay := func(s string) bool { return s == "*" || s == "?" }
+
+	switch {
+	case anyDay(dayOfMonth) && anyDay(dayOfWeek):
+		return "", true
+
+	case dayOfMonth == "?" && (dayOfWeek == "MON-FRI" || dayOfWeek == "2-6"):
+		return "on weekdays", true
+
+	case dayOfMonth == "?" && (dayOfWeek == "SAT,SUN" || dayOfWeek == "SUN,SAT" || dayOfWeek == "1,7" || dayOfWeek == "7,1"):
+		return "on weekends", true
+
+	case dayOfMonth == "?":
+		var names []string
+
+		for _, day := range strings.Split(dayOfWeek, ",") {
+			weekday, ok := cronWeekday(day)
+
+			if !ok {
+				return "", false
+			}
+
+			names = append(names, weekday.String()+"s")
+		}
+
+		if len(names) == 1 {
+			return "on " + names[0], true
+		}
+
+		return "on " + strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1], true
+
+	case dayOfWeek == "?" && dayOfMonth == "L":
+		return "on the last day of the month", true
+
+	case dayOfWeek == "?":
+		if day, ok := cronNumber(dayOfMonth, 31); ok && day > 0 {
+			return fmt.Sprintf("on day %d of the month", day), true
+		}
+	}
+
+	return "", false
+}
+
+func cronNumber(s string, max int) (int, bool) {
+	n, err := strconv.Atoi(s)
+
+	return n, err == nil && n >= 0 && n <= max
+}
+
+// cronStep parses the increments starting from zero, e.g. "*/5" or "0/5".
+func cronStep(s string) (int, bool) {
+	for _, prefix := range []string{"*/", "0/"} {
+		if strings.HasPrefix(s, prefix) {
+			n, err := strconv.Atoi(strings.TrimPrefix(s, prefix))
+
+			return n, err == nil && n > 0
+		}
+	}
+
+	return 0, false
+}
+
+// cronWeekday parses a day of week by its name, or by its number from 1 (Sunday) to 7 (Saturday).
+func cronWeekday(s string) (time.Weekday, bool) {
+	if n, ok := cronNumber(s, 7); ok && n > 0 {
+		return time.Weekday(n - 1), true
+	}
+
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.ToUpper(day.String()[:3]) == s {
+			return day, true
+		}
+	}
+
+	return 0, false
+}
@@ -0,0 +1,76 @@
+package quartz
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type dummyScheduleBuilder struct {
+	expr string
+}
+
+func (b *dummyScheduleBuilder) Build() MutableTrigger { return &dummyTrigger{expr: b.expr} }
+
+func (b *dummyScheduleBuilder) String() string { return b.expr }
+
+type dummyTrigger struct {
+	simpleTrigger
+
+	expr string
+}
+
+func (t *dummyTrigger) ScheduleBuilder() ScheduleBuilder { return &dummyScheduleBuilder{t.expr} }
+
+func TestDescribeSchedule(t *testing.T) {
+	Convey("Given some triggers", t, func() {
+		startTime := time.Date(2016, time.March, 1, 8, 0, 0, 0, time.UTC)
+
+		describe := func(sb ScheduleBuilder) string {
+			return DescribeSchedule((&TriggerBuilder{}).StartAt(startTime).WithSchedule(sb).Build())
+		}
+
+		So(describe(&SimpleScheduleBuilder{5 * time.Minute, 99}), ShouldEqual, "every 5 minutes, 100 times")
+		So(describe(&SimpleScheduleBuilder{time.Hour, REPEAT_INDEFINITELY}), ShouldEqual, "every hour, indefinitely")
+		So(describe(&SimpleScheduleBuilder{90 * time.Second, 1}), ShouldEqual, "every 90 seconds, 2 times")
+		So(describe(&SimpleScheduleBuilder{48 * time.Hour, REPEAT_INDEFINITELY}), ShouldEqual, "every 2 days, indefinitely")
+		So(describe(&SimpleScheduleBuilder{1500 * time.Millisecond, 2}), ShouldEqual, "every 1.5s, 3 times")
+		So(describe(&SimpleScheduleBuilder{time.Minute, 0}), ShouldEqual, "once at 2016-03-01 08:00:00")
+
+		Convey("With an end time", func() {
+			trigger := (&TriggerBuilder{}).
+				StartAt(startTime).
+				EndAt(startTime.Add(24 * time.Hour)).
+				WithSchedule(&SimpleScheduleBuilder{time.Minute, REPEAT_INDEFINITELY}).
+				Build()
+
+			So(DescribeSchedule(trigger), ShouldEqual, "every minute, indefinitely, until 2016-03-02 08:00:00")
+		})
+
+		Convey("The common cron expressions", func() {
+			describe := func(expr string) string { return DescribeSchedule(&dummyTrigger{expr: expr}) }
+
+			So(describe("0 0 8 ? * MON-FRI"), ShouldEqual, "at 08:00 on weekdays")
+			So(describe("0 30 8 ? * 2-6 *"), ShouldEqual, "at 08:30 on weekdays")
+			So(describe("0 0 10 ? * SAT,SUN"), ShouldEqual, "at 10:00 on weekends")
+			So(describe("0 0 9 ? * MON,WED,FRI"), ShouldEqual, "at 09:00 on Mondays, Wednesdays and Fridays")
+			So(describe("0 0 9 ? * 1"), ShouldEqual, "at 09:00 on Sundays")
+			So(describe("0 0 12 * * ?"), ShouldEqual, "at 12:00 every day")
+			So(describe("0 0 6 1 * ?"), ShouldEqual, "at 06:00 on day 1 of the month")
+			So(describe("0 0 0 L * ?"), ShouldEqual, "at 00:00 on the last day of the month")
+			So(describe("0 0/5 * * * ?"), ShouldEqual, "every 5 minutes")
+			So(describe("0 */15 * ? * MON-FRI"), ShouldEqual, "every 15 minutes on weekdays")
+			So(describe("0 * * * * ?"), ShouldEqual, "every minute")
+			So(describe("0 15 * * * ?"), ShouldEqual, "every hour at minute 15")
+			So(describe("0 0 */2 * * ?"), ShouldEqual, "every 2 hours")
+		})
+
+		Convey("Fallback to the raw expression", func() {
+			So(DescribeSchedule(&dummyTrigger{expr: "0 0 8-17 ? JAN-MAR MON#2"}), ShouldEqual, "0 0 8-17 ? JAN-MAR MON#2")
+			So(DescribeSchedule(&dummyTrigger{expr: "30 0 8 * * ?"}), ShouldEqual, "30 0 8 * * ?")
+			So(DescribeSchedule(&dummyTrigger{expr: "0 0 8 ? * MON-FRI 2020"}), ShouldEqual, "0 0 8 ? * MON-FRI 2020")
+			So(DescribeSchedule(&dummyTrigger{expr: "@daily"}), ShouldEqual, "@daily")
+		})
+	})
+}
@@ -0,0 +1,128 @@
+package quartz
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultEventBufferSize is the capacity of the channel returned by StdScheduler.Events().
+const DefaultEventBufferSize = 64
+
+// SchedulerEventType is the kind of a SchedulerEvent.
+type SchedulerEventType int
+
+const (
+	// EventJobScheduled is delivered when a trigger is scheduled, including when it replaces a rescheduled trigger.
+	EventJobScheduled SchedulerEventType = iota
+
+	// EventTriggerFired is delivered when a trigger has fired, and its job is about to be executed.
+	EventTriggerFired
+
+	// EventJobExecuted is delivered after a job has been executed, Err is the error returned by the job.
+	EventJobExecuted
+
+	// EventMisfire is delivered when a trigger has misfired.
+	EventMisfire
+
+	// EventShutdown is delivered when the scheduler has been shutdown, it is the last event of the channel.
+	EventShutdown
+)
+
+func (t SchedulerEventType) String() string {
+	switch t {
+	case EventJobScheduled:
+		return "JobScheduled"
+	case EventTriggerFired:
+		return "TriggerFired"
+	case EventJobExecuted:
+		return "JobExecuted"
+	case EventMisfire:
+		return "Misfire"
+	case EventShutdown:
+		return "Shutdown"
+	default:
+		return "Unknown"
+	}
+}
+
+// SchedulerEvent is delivered by the channel returned by StdScheduler.Events().
+type SchedulerEvent struct {
+	Type SchedulerEventType
+
+	// The time of the scheduler's Clock when the event occurred.
+	Time time.Time
+
+	// The key of the job, nil for EventShutdown.
+	JobKey JobKey
+
+	// The key of the trigger, nil for EventShutdown.
+	TriggerKey TriggerKey
+
+	// The fire time of the trigger, zero if the event isn't related to a firing.
+	FireTime time.Time
+
+	// The error returned by the job for EventJobExecuted.
+	Err error
+}
+
+// eventBus delivers the SchedulerEvents to a buffered channel without blocking the scheduler,
+// the events are dropped when the buffer is full.
+type eventBus struct {
+	events  chan SchedulerEvent
+	dropped int64
+
+	lock   sync.RWMutex
+	closed bool
+}
+
+func newEventBus(size int) *eventBus {
+	return &eventBus{events: make(chan SchedulerEvent, size)}
+}
+
+func (b *eventBus) publish(event SchedulerEvent) {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	if b.closed {
+		return
+	}
+
+	select {
+	case b.events <- event:
+	default:
+		atomic.AddInt64(&b.dropped, 1)
+	}
+}
+
+// close delivers the last event and closes the channel, the last event replaces the oldest one if the buffer is full.
+func (b *eventBus) close(last SchedulerEvent) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.closed {
+		return
+	}
+
+	b.closed = true
+
+	select {
+	case b.events <- last:
+	default:
+		select {
+		case <-b.events:
+			atomic.AddInt64(&b.dropped, 1)
+		default:
+		}
+
+		select {
+		case b.events <- last:
+		default:
+			atomic.AddInt64(&b.dropped, 1)
+		}
+	}
+
+	close(b.events)
+}
+
+func (b *eventBus) droppedEvents() int64 { return atomic.LoadInt64(&b.dropped) }
@@ -0,0 +1,103 @@
+package quartz
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestStdSchedulerEvents(t *testing.T) {
+	Convey("Given a StdScheduler with a RAMJobStore", t, func() {
+		scheduler := NewStdScheduler("scheduler", NewRAMJobStore(), NewSimpleThreadPool(1))
+
+		defer scheduler.Shutdown()
+
+		events := scheduler.Events()
+
+		job := NewJobDetailFromFunc("failing", func(context JobExecutionContext) error {
+			return errors.New("failed")
+		})
+
+		trigger := (&TriggerBuilder{}).WithIdentity("trigger").StartNow().Build()
+
+		Convey("When a job is scheduled and fired", func() {
+			_, err := scheduler.ScheduleJob(job, trigger)
+
+			So(err, ShouldBeNil)
+			So(scheduler.Start(), ShouldBeNil)
+
+			var received []SchedulerEvent
+
+			timeout := time.After(time.Second)
+
+		loop:
+			for len(received) < 3 {
+				select {
+				case event := <-events:
+					received = append(received, event)
+				case <-timeout:
+					break loop
+				}
+			}
+
+			Convey("The events should arrive in order with the keys", func() {
+				So(received, ShouldHaveLength, 3)
+
+				for i, typ := range []SchedulerEventType{EventJobScheduled, EventTriggerFired, EventJobExecuted} {
+					So(received[i].Type, ShouldEqual, typ)
+					So(received[i].JobKey, ShouldResemble, job.Key())
+					So(received[i].TriggerKey, ShouldResemble, trigger.Key())
+					So(received[i].Time.IsZero(), ShouldBeFalse)
+				}
+
+				So(received[0].FireTime.IsZero(), ShouldBeTrue)
+				So(received[1].FireTime.IsZero(), ShouldBeFalse)
+				So(received[2].FireTime, ShouldResemble, received[1].FireTime)
+				So(received[2].Err, ShouldNotBeNil)
+				So(received[2].Type.String(), ShouldEqual, "JobExecuted")
+			})
+
+			Convey("The channel should be closed after the shutdown event", func() {
+				So(scheduler.Shutdown(), ShouldBeNil)
+
+				event, ok := <-events
+
+				So(ok, ShouldBeTrue)
+				So(event.Type, ShouldEqual, EventShutdown)
+
+				_, ok = <-events
+
+				So(ok, ShouldBeFalse)
+			})
+		})
+
+		Convey("When the consumer is slow", func() {
+			for i := 0; i < DefaultEventBufferSize+3; i++ {
+				_, err := scheduler.ScheduleJob(
+					(&JobBuilder{}).WithIdentity(fmt.Sprintf("job%d", i)).Build(),
+					(&TriggerBuilder{}).WithIdentity(fmt.Sprintf("trigger%d", i)).StartAt(time.Now().Add(time.Hour)).Build())
+
+				So(err, ShouldBeNil)
+			}
+
+			Convey("The events should be dropped without blocking the scheduler", func() {
+				So(len(events), ShouldEqual, DefaultEventBufferSize)
+				So(scheduler.DroppedEvents(), ShouldEqual, 3)
+
+				So(scheduler.Shutdown(), ShouldBeNil)
+
+				var last SchedulerEvent
+
+				for event := range events {
+					last = event
+				}
+
+				So(last.Type, ShouldEqual, EventShutdown)
+				So(scheduler.DroppedEvents(), ShouldEqual, 4)
+			})
+		})
+	})
+}
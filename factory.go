@@ -0,0 +1,137 @@
+package quartz
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	DefaultSchedulerName    = "QuartzScheduler"
+	DefaultThreadCount      = 10
+	DefaultMisfireThreshold = 60 * time.Second
+	DefaultIdleWaitTime     = 30 * time.Second
+	DefaultMaxBatchSize     = 1
+)
+
+// SchedulerConfig configures the Scheduler created by StdSchedulerFactory.
+//
+// The zero values of Name, JobStore, MisfireThreshold, IdleWaitTime and MaxBatchSize are replaced with the defaults,
+// a nil MetricsCollector, Tracer or Logger does nothing.
+type SchedulerConfig struct {
+	Name        string
+	ThreadCount int
+	JobStore    JobStore
+
+	// The time a trigger may fire later than its fire time before it is misfired.
+	MisfireThreshold time.Duration
+
+	// The time the scheduling loop sleeps when no trigger is due, it is woken up early when the scheduling changed.
+	IdleWaitTime time.Duration
+
+	// The maximum number of triggers acquired and fired together, limited by the available threads.
+	MaxBatchSize int
+
+	// The time after the first acquired trigger in which the other triggers are acquired in the same batch.
+	BatchTimeWindow time.Duration
+
+	MetricsCollector MetricsCollector
+	Tracer           Tracer
+	Logger           Logger
+}
+
+// DefaultSchedulerConfig returns the configuration of the default Scheduler.
+func DefaultSchedulerConfig() SchedulerConfig {
+	return SchedulerConfig{
+		Name:             DefaultSchedulerName,
+		ThreadCount:      DefaultThreadCount,
+		MisfireThreshold: DefaultMisfireThreshold,
+		IdleWaitTime:     DefaultIdleWaitTime,
+		MaxBatchSize:     DefaultMaxBatchSize,
+	}
+}
+
+func invalidConfigError(name string, value interface{}) error {
+	return fmt.Errorf("Invalid scheduler config %s: %v.", name, value)
+}
+
+// Validate checks the configuration, it ignores the zero values which will be replaced with the defaults.
+func (cfg SchedulerConfig) Validate() error {
+	if cfg.ThreadCount < 1 {
+		return invalidConfigError("ThreadCount", cfg.ThreadCount)
+	}
+
+	if cfg.MisfireThreshold < 0 {
+		return invalidConfigError("MisfireThreshold", cfg.MisfireThreshold)
+	}
+
+	if cfg.IdleWaitTime < 0 {
+		return invalidConfigError("IdleWaitTime", cfg.IdleWaitTime)
+	}
+
+	if cfg.MaxBatchSize < 0 {
+		return invalidConfigError("MaxBatchSize", cfg.MaxBatchSize)
+	}
+
+	if cfg.BatchTimeWindow < 0 {
+		return invalidConfigError("BatchTimeWindow", cfg.BatchTimeWindow)
+	}
+
+	return nil
+}
+
+// StdSchedulerFactory creates StdScheduler with a SimpleThreadPool.
+type StdSchedulerFactory struct{}
+
+// NewScheduler creates a Scheduler with the configuration, it uses a RAMJobStore if JobStore is nil.
+//
+// The Scheduler is bound to the DefaultSchedulerRepository until it is shutdown.
+func (f *StdSchedulerFactory) NewScheduler(cfg SchedulerConfig) (Scheduler, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	if cfg.Name == "" {
+		cfg.Name = DefaultSchedulerName
+	}
+
+	if cfg.JobStore == nil {
+		cfg.JobStore = NewRAMJobStore()
+	}
+
+	if cfg.MisfireThreshold == 0 {
+		cfg.MisfireThreshold = DefaultMisfireThreshold
+	}
+
+	if cfg.IdleWaitTime == 0 {
+		cfg.IdleWaitTime = DefaultIdleWaitTime
+	}
+
+	if cfg.MaxBatchSize == 0 {
+		cfg.MaxBatchSize = DefaultMaxBatchSize
+	}
+
+	scheduler := NewStdScheduler(cfg.Name, cfg.JobStore, NewSimpleThreadPool(cfg.ThreadCount))
+
+	scheduler.misfireThreshold = cfg.MisfireThreshold
+	scheduler.idleWaitTime = cfg.IdleWaitTime
+	scheduler.maxBatchSize = cfg.MaxBatchSize
+	scheduler.batchTimeWindow = cfg.BatchTimeWindow
+	scheduler.SetMetricsCollector(cfg.MetricsCollector)
+	scheduler.SetTracer(cfg.Tracer)
+	scheduler.SetLogger(cfg.Logger)
+
+	if err := schedulerRepository.Bind(scheduler); err != nil {
+		return nil, err
+	}
+
+	return scheduler, nil
+}
+
+// DefaultScheduler returns the Scheduler with the default name, it's created with DefaultSchedulerConfig if not exists.
+func (f *StdSchedulerFactory) DefaultScheduler() (Scheduler, error) {
+	if scheduler := schedulerRepository.Lookup(DefaultSchedulerName); scheduler != nil {
+		return scheduler, nil
+	}
+
+	return f.NewScheduler(DefaultSchedulerConfig())
+}
@@ -0,0 +1,117 @@
+package quartz
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestStdSchedulerFactory(t *testing.T) {
+	Convey("Given a StdSchedulerFactory", t, func() {
+		factory := &StdSchedulerFactory{}
+
+		Convey("When create the default scheduler", func() {
+			scheduler, err := factory.DefaultScheduler()
+
+			So(err, ShouldBeNil)
+
+			defer scheduler.Shutdown()
+
+			s := scheduler.(*StdScheduler)
+
+			So(s.Name(), ShouldEqual, DefaultSchedulerName)
+			So(s.store, ShouldHaveSameTypeAs, &RAMJobStore{})
+			So(s.threadPool.PoolSize(), ShouldEqual, DefaultThreadCount)
+			So(s.misfireThreshold, ShouldEqual, DefaultMisfireThreshold)
+			So(s.idleWaitTime, ShouldEqual, DefaultIdleWaitTime)
+			So(s.InStandbyMode(), ShouldBeTrue)
+
+			Convey("The default scheduler should be shared until it is shutdown", func() {
+				So(DefaultSchedulerRepository().Lookup(DefaultSchedulerName), ShouldEqual, scheduler)
+
+				other, err := factory.DefaultScheduler()
+
+				So(err, ShouldBeNil)
+				So(other, ShouldEqual, scheduler)
+
+				_, err = factory.NewScheduler(SchedulerConfig{ThreadCount: 1})
+
+				So(err, ShouldNotBeNil)
+
+				So(scheduler.Shutdown(), ShouldBeNil)
+				So(DefaultSchedulerRepository().Lookup(DefaultSchedulerName), ShouldBeNil)
+
+				other, err = factory.DefaultScheduler()
+
+				So(err, ShouldBeNil)
+				So(other, ShouldNotEqual, scheduler)
+				So(other.Shutdown(), ShouldBeNil)
+			})
+		})
+
+		Convey("When create a scheduler from the config", func() {
+			store := NewRAMJobStore()
+
+			scheduler, err := factory.NewScheduler(SchedulerConfig{
+				Name:             "test",
+				ThreadCount:      3,
+				JobStore:         store,
+				MisfireThreshold: time.Second,
+				IdleWaitTime:     time.Minute,
+				MaxBatchSize:     5,
+				BatchTimeWindow:  time.Second,
+			})
+
+			So(err, ShouldBeNil)
+
+			defer scheduler.Shutdown()
+
+			s := scheduler.(*StdScheduler)
+
+			So(s.Name(), ShouldEqual, "test")
+			So(s.store, ShouldEqual, store)
+			So(s.threadPool.PoolSize(), ShouldEqual, 3)
+			So(s.misfireThreshold, ShouldEqual, time.Second)
+			So(s.idleWaitTime, ShouldEqual, time.Minute)
+			So(s.maxBatchSize, ShouldEqual, 5)
+			So(s.batchTimeWindow, ShouldEqual, time.Second)
+
+			So(scheduler.Start(), ShouldBeNil)
+			So(scheduler.Started(), ShouldBeTrue)
+		})
+
+		Convey("When create a scheduler with the zero values", func() {
+			scheduler, err := factory.NewScheduler(SchedulerConfig{ThreadCount: 1})
+
+			So(err, ShouldBeNil)
+
+			defer scheduler.Shutdown()
+
+			s := scheduler.(*StdScheduler)
+
+			So(s.Name(), ShouldEqual, DefaultSchedulerName)
+			So(s.store, ShouldNotBeNil)
+			So(s.misfireThreshold, ShouldEqual, DefaultMisfireThreshold)
+			So(s.idleWaitTime, ShouldEqual, DefaultIdleWaitTime)
+			So(s.maxBatchSize, ShouldEqual, DefaultMaxBatchSize)
+			So(s.batchTimeWindow, ShouldEqual, 0)
+		})
+
+		Convey("When the config is invalid", func() {
+			for _, cfg := range []SchedulerConfig{
+				{},
+				{ThreadCount: -1},
+				{ThreadCount: 1, MisfireThreshold: -time.Second},
+				{ThreadCount: 1, IdleWaitTime: -time.Second},
+				{ThreadCount: 1, MaxBatchSize: -1},
+				{ThreadCount: 1, BatchTimeWindow: -time.Second},
+			} {
+				scheduler, err := factory.NewScheduler(cfg)
+
+				So(err, ShouldNotBeNil)
+				So(scheduler, ShouldBeNil)
+			}
+		})
+	})
+}
@@ -0,0 +1,826 @@
+package quartz
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RECOVERING_JOBS_GROUP is the group of the triggers which re-execute the jobs interrupted by a shutdown.
+const RECOVERING_JOBS_GROUP = "RECOVERING_JOBS"
+
+// The keys of the JobDataMap of a recovery trigger, which describe the trigger whose execution was interrupted.
+const (
+	FAILED_JOB_ORIGINAL_TRIGGER_NAME               = "QRTZ_FAILED_JOB_ORIG_TRIGGER_NAME"
+	FAILED_JOB_ORIGINAL_TRIGGER_GROUP              = "QRTZ_FAILED_JOB_ORIG_TRIGGER_GROUP"
+	FAILED_JOB_ORIGINAL_TRIGGER_SCHEDULED_FIRETIME = "QRTZ_FAILED_JOB_ORIG_TRIGGER_SCHEDULED_FIRETIME"
+	FAILED_JOB_ORIGINAL_TRIGGER_FIRETIME           = "QRTZ_FAILED_JOB_ORIG_TRIGGER_FIRETIME"
+)
+
+func init() {
+	// the fire times of the interrupted trigger are stored in the JobDataMap of the recovery trigger.
+	gob.Register(time.Time{})
+}
+
+func unsupportedTriggerError(trigger Trigger) error {
+	return fmt.Errorf("The trigger (%s) of type %T can't be persisted.", trigger.Key(), trigger)
+}
+
+func unsupportedCalendarError(name string, calendar Calendar) error {
+	return fmt.Errorf("The calendar '%s' of type %T can't be persisted.", name, calendar)
+}
+
+func invalidCalendarRecordError(name string, err error) error {
+	return fmt.Errorf("The calendar '%s' couldn't be restored: %w", name, err)
+}
+
+// FileJobStore is a RAMJobStore which persists its jobs, triggers, calendars and paused groups to a file.
+//
+// The file is rewritten atomically after each change, and loaded when the store is created.
+// The JobFactory of the jobs is not persisted, the jobs should be created with JobBuilder.OfType,
+// and the custom types in the JobDataMap must have a ValueCodec or be registered with gob.Register.
+// Only the Daily, Annual, Monthly and Holiday calendars can be persisted.
+type FileJobStore struct {
+	*RAMJobStore
+
+	path string
+
+	lock   sync.Mutex
+	cipher DataMapCipher
+}
+
+type fileStoreData struct {
+	Jobs                []jobRecord
+	Triggers            []triggerRecord
+	Calendars           []calendarRecord
+	PausedTriggerGroups []string
+	PausedJobGroups     []string
+	Fired               []firedRecord
+}
+
+type jobRecord struct {
+	Key              string
+	Description      string
+	Durable          bool
+	RequestsRecovery bool
+	PersistJobData   bool
+	NoConcurrent     bool
+	JobData          map[string]interface{}
+	RetryPolicy      *RetryPolicy
+}
+
+// triggerRecord is the record of a trigger, Type is its type as in its JSON representation,
+// the records without Type are the simple triggers.
+type triggerRecord struct {
+	Type             string
+	Key              string
+	JobKey           string
+	Description      string
+	Priority         int
+	JobData          map[string]interface{}
+	StartTime        time.Time
+	EndTime          time.Time
+	NextFireTime     time.Time
+	PreviousFireTime time.Time
+	TimeZone         string
+	CalendarName     string
+	RepeatInterval   time.Duration
+	RepeatCount      int
+	TimesTriggered   int
+	Complete         bool
+	N                int
+	IntervalType     NthIncludedDayIntervalType
+	FireAtTime       TimeOfDay
+	State            TriggerState
+}
+
+// The types of the calendars in their records.
+const (
+	dailyCalendarType   = "daily"
+	annualCalendarType  = "annual"
+	monthlyCalendarType = "monthly"
+	holidayCalendarType = "holiday"
+)
+
+// calendarRecord is the record of a calendar and its base calendar, Name is the name of the stored calendar.
+//
+// The excluded days of an AnnualCalendar are formatted as "01-02",
+// and the excluded dates of a HolidayCalendar as "2006-01-02" in the location of the calendar.
+type calendarRecord struct {
+	Name            string
+	Type            string
+	Description     string
+	TimeZone        string
+	Base            *calendarRecord
+	RangeStart      TimeOfDay
+	RangeEnd        TimeOfDay
+	InvertTimeRange bool
+	ExcludedDays    []int
+	ExcludedDates   []string
+}
+
+// firedRecord is a trigger whose job was executing when the data was saved.
+type firedRecord struct {
+	TriggerKey        string
+	JobKey            string
+	ScheduledFireTime time.Time
+	FireTime          time.Time
+}
+
+// NewFileJobStore creates a FileJobStore with the data of the file, the file is created on the first change.
+func NewFileJobStore(path string) (*FileJobStore, error) {
+	return NewEncryptedFileJobStore(path, nil)
+}
+
+// NewEncryptedFileJobStore creates a FileJobStore whose JobDataMap values are encrypted by the cipher in the file.
+func NewEncryptedFileJobStore(path string, cipher DataMapCipher) (*FileJobStore, error) {
+	s := &FileJobStore{
+		RAMJobStore: NewRAMJobStore(),
+		path:        path,
+		cipher:      cipher,
+	}
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *FileJobStore) Path() string { return s.path }
+
+func (s *FileJobStore) SupportsPersistence() bool { return true }
+
+// SchedulerStarted releases the triggers left acquired, and schedules the jobs requesting recovery again,
+// if they were executing when the data was saved.
+func (s *FileJobStore) SchedulerStarted() error {
+	return s.persist(s.RAMJobStore.SchedulerStarted())
+}
+
+// newRecoveryTrigger creates a one-shot trigger in the RECOVERING_JOBS group firing the job at the time,
+// with a copy of the data of the trigger whose execution was interrupted, and its key and fire times if known.
+func newRecoveryTrigger(triggerKey TriggerKey, jobKey JobKey, scheduledFireTime, fireTime time.Time, dataMap JobDataMap, now time.Time) OperableTrigger {
+	trigger := &simpleTrigger{startTime: now, nextFireTime: now}
+	trigger.SetKey(NewUniqueTriggerKey(RECOVERING_JOBS_GROUP))
+	trigger.SetJobKey(jobKey)
+
+	recoveryDataMap := NewJobDataMap()
+
+	if dataMap != nil {
+		recoveryDataMap.PutAll(dataMap)
+	}
+
+	recoveryDataMap.Put(FAILED_JOB_ORIGINAL_TRIGGER_NAME, triggerKey.Name())
+	recoveryDataMap.Put(FAILED_JOB_ORIGINAL_TRIGGER_GROUP, triggerKey.Group())
+
+	if !scheduledFireTime.IsZero() {
+		recoveryDataMap.Put(FAILED_JOB_ORIGINAL_TRIGGER_SCHEDULED_FIRETIME, scheduledFireTime)
+	}
+
+	if !fireTime.IsZero() {
+		recoveryDataMap.Put(FAILED_JOB_ORIGINAL_TRIGGER_FIRETIME, fireTime)
+	}
+
+	trigger.SetJobDataMap(recoveryDataMap)
+
+	return trigger
+}
+
+func (s *FileJobStore) Shutdown() {
+	s.RAMJobStore.Shutdown()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.save()
+}
+
+// persist saves the data if the change succeeded, and returns the error of the change or the saving.
+//
+// The firing of the triggers only saves the data on a best-effort basis, the changes are saved again by the next change.
+func (s *FileJobStore) persist(err error) error {
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.save()
+}
+
+func (s *FileJobStore) StoreJobAndTrigger(job JobDetail, trigger OperableTrigger) error {
+	return s.persist(s.RAMJobStore.StoreJobAndTrigger(job, trigger))
+}
+
+func (s *FileJobStore) StoreJobsAndTriggers(triggersAndJobs map[JobDetail][]Trigger, replace bool) error {
+	return s.persist(s.RAMJobStore.StoreJobsAndTriggers(triggersAndJobs, replace))
+}
+
+func (s *FileJobStore) StoreJob(jobDetail JobDetail, replaceExisting bool) error {
+	return s.persist(s.RAMJobStore.StoreJob(jobDetail, replaceExisting))
+}
+
+func (s *FileJobStore) RemoveJob(key JobKey) (bool, error) {
+	found, err := s.RAMJobStore.RemoveJob(key)
+
+	return found, s.persist(err)
+}
+
+func (s *FileJobStore) RemoveJobs(keys []JobKey) (bool, error) {
+	found, err := s.RAMJobStore.RemoveJobs(keys)
+
+	return found, s.persist(err)
+}
+
+func (s *FileJobStore) StoreTrigger(trigger OperableTrigger, replaceExisting bool) error {
+	return s.persist(s.RAMJobStore.StoreTrigger(trigger, replaceExisting))
+}
+
+func (s *FileJobStore) StoreTriggers(triggers []OperableTrigger, replaceExisting bool) error {
+	return s.persist(s.RAMJobStore.StoreTriggers(triggers, replaceExisting))
+}
+
+func (s *FileJobStore) RemoveTrigger(key TriggerKey) (bool, error) {
+	found, err := s.RAMJobStore.RemoveTrigger(key)
+
+	return found, s.persist(err)
+}
+
+func (s *FileJobStore) RemoveTriggers(keys []TriggerKey) (bool, error) {
+	found, err := s.RAMJobStore.RemoveTriggers(keys)
+
+	return found, s.persist(err)
+}
+
+func (s *FileJobStore) ReplaceTrigger(key TriggerKey, trigger OperableTrigger) error {
+	return s.persist(s.RAMJobStore.ReplaceTrigger(key, trigger))
+}
+
+func (s *FileJobStore) ResetTriggerFromErrorState(key TriggerKey) error {
+	return s.persist(s.RAMJobStore.ResetTriggerFromErrorState(key))
+}
+
+// StoreCalendar stores and saves the calendar, it is rejected if the calendar can't be persisted.
+func (s *FileJobStore) StoreCalendar(name string, calendar Calendar, replaceExisting bool) error {
+	if _, err := newCalendarRecord(name, calendar); err != nil {
+		return err
+	}
+
+	return s.persist(s.RAMJobStore.StoreCalendar(name, calendar, replaceExisting))
+}
+
+func (s *FileJobStore) RemoveCalendar(name string) (bool, error) {
+	found, err := s.RAMJobStore.RemoveCalendar(name)
+
+	return found, s.persist(err)
+}
+
+func (s *FileJobStore) PauseJob(key JobKey) error {
+	return s.persist(s.RAMJobStore.PauseJob(key))
+}
+
+func (s *FileJobStore) PauseTrigger(key TriggerKey) error {
+	return s.persist(s.RAMJobStore.PauseTrigger(key))
+}
+
+func (s *FileJobStore) ResumeJob(key JobKey) error {
+	return s.persist(s.RAMJobStore.ResumeJob(key))
+}
+
+func (s *FileJobStore) ResumeTrigger(key TriggerKey) error {
+	return s.persist(s.RAMJobStore.ResumeTrigger(key))
+}
+
+func (s *FileJobStore) PauseTriggers(matcher GroupMatcher) ([]string, error) {
+	groups, err := s.RAMJobStore.PauseTriggers(matcher)
+
+	return groups, s.persist(err)
+}
+
+func (s *FileJobStore) ResumeTriggers(matcher GroupMatcher) ([]string, error) {
+	groups, err := s.RAMJobStore.ResumeTriggers(matcher)
+
+	return groups, s.persist(err)
+}
+
+func (s *FileJobStore) PauseJobs(matcher GroupMatcher) ([]string, error) {
+	groups, err := s.RAMJobStore.PauseJobs(matcher)
+
+	return groups, s.persist(err)
+}
+
+func (s *FileJobStore) ResumeJobs(matcher GroupMatcher) ([]string, error) {
+	groups, err := s.RAMJobStore.ResumeJobs(matcher)
+
+	return groups, s.persist(err)
+}
+
+func (s *FileJobStore) PauseAll() error {
+	return s.persist(s.RAMJobStore.PauseAll())
+}
+
+func (s *FileJobStore) ResumeAll() error {
+	return s.persist(s.RAMJobStore.ResumeAll())
+}
+
+func (s *FileJobStore) ClearAllSchedulingData() error {
+	return s.persist(s.RAMJobStore.ClearAllSchedulingData())
+}
+
+func (s *FileJobStore) RestoreSnapshot(b []byte) error {
+	return s.persist(s.RAMJobStore.RestoreSnapshot(b))
+}
+
+func (s *FileJobStore) AcquireNextTriggers(noLaterThan time.Time, maxCount int, timeWindow time.Duration) ([]OperableTrigger, error) {
+	triggers, err := s.RAMJobStore.AcquireNextTriggers(noLaterThan, maxCount, timeWindow)
+
+	if err == nil && len(triggers) > 0 {
+		s.persist(nil)
+	}
+
+	return triggers, err
+}
+
+func (s *FileJobStore) ReleaseAcquiredTrigger(trigger OperableTrigger) {
+	s.RAMJobStore.ReleaseAcquiredTrigger(trigger)
+
+	s.persist(nil)
+}
+
+func (s *FileJobStore) TriggersFired(triggers []OperableTrigger) ([]*TriggerFiredBundle, error) {
+	bundles, err := s.RAMJobStore.TriggersFired(triggers)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// the executing jobs are saved to be recovered after a crash.
+	s.persist(nil)
+
+	return bundles, nil
+}
+
+func (s *FileJobStore) TriggeredJobComplete(trigger OperableTrigger, job JobDetail, instruction CompletedExecutionInstruction) {
+	s.RAMJobStore.TriggeredJobComplete(trigger, job, instruction)
+
+	s.persist(nil)
+}
+
+// snapshot copies the data to be saved, the caller must hold the lock of the FileJobStore.
+func (s *FileJobStore) snapshot() (*fileStoreData, error) {
+	s.RAMJobStore.lock.Lock()
+	defer s.RAMJobStore.lock.Unlock()
+
+	data := &fileStoreData{
+		PausedTriggerGroups: stringKeys(s.pausedTriggerGroups),
+		PausedJobGroups:     stringKeys(s.pausedJobGroups),
+	}
+
+	var err error
+
+	if data.Jobs, data.Triggers, err = s.records(); err != nil {
+		return nil, err
+	}
+
+	if data.Calendars, err = s.calendars.records(); err != nil {
+		return nil, err
+	}
+
+	for _, r := range data.Jobs {
+		if err := encryptEntries(s.cipher, r.JobData); err != nil {
+			return nil, fmt.Errorf("Couldn't encrypt the job (%s): %w", r.Key, err)
+		}
+	}
+
+	for _, r := range data.Triggers {
+		if err := encryptEntries(s.cipher, r.JobData); err != nil {
+			return nil, fmt.Errorf("Couldn't encrypt the trigger (%s): %w", r.Key, err)
+		}
+	}
+
+	data.Fired = s.firedRecords()
+
+	return data, nil
+}
+
+// save writes the data to a temporary file and renames it to the path, the caller must hold the lock.
+func (s *FileJobStore) save() error {
+	data, err := s.snapshot()
+
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+
+	if err := gob.NewEncoder(&buf).Encode(data); err != nil {
+		return fmt.Errorf("Couldn't encode the scheduling data: %w", err)
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+
+		return err
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), s.path)
+}
+
+// load restores the data of the file, the triggers acquired by the previous scheduler are waiting again.
+func (s *FileJobStore) load() error {
+	content, err := os.ReadFile(s.path)
+
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	var data fileStoreData
+
+	if err := gob.NewDecoder(bytes.NewReader(content)).Decode(&data); err != nil {
+		return fmt.Errorf("Couldn't decode the scheduling data of %s: %w", s.path, err)
+	}
+
+	for _, r := range data.Jobs {
+		if err := decryptEntries(s.cipher, r.JobData); err != nil {
+			return fmt.Errorf("Couldn't decrypt the job (%s) of %s: %w", r.Key, s.path, err)
+		}
+	}
+
+	for _, r := range data.Triggers {
+		if err := decryptEntries(s.cipher, r.JobData); err != nil {
+			return fmt.Errorf("Couldn't decrypt the trigger (%s) of %s: %w", r.Key, s.path, err)
+		}
+	}
+
+	calendars, err := restoreCalendars(data.Calendars)
+
+	if err != nil {
+		return fmt.Errorf("Couldn't restore the calendars of %s: %w", s.path, err)
+	}
+
+	s.calendars.replace(calendars)
+
+	s.RAMJobStore.lock.Lock()
+	defer s.RAMJobStore.lock.Unlock()
+
+	for _, group := range data.PausedTriggerGroups {
+		s.pausedTriggerGroups.Add(group)
+	}
+
+	for _, group := range data.PausedJobGroups {
+		s.pausedJobGroups.Add(group)
+	}
+
+	if err := s.restoreRecords(data.Jobs, data.Triggers); err != nil {
+		return err
+	}
+
+	s.restoreFiredRecords(data.Fired)
+
+	return nil
+}
+
+func newJobRecord(job JobDetail) jobRecord {
+	return jobRecord{
+		Key:              job.Key().String(),
+		Description:      job.Description(),
+		Durable:          job.Durable(),
+		RequestsRecovery: job.RequestsRecovery(),
+		PersistJobData:   job.PersistJobDataAfterExecution(),
+		NoConcurrent:     job.ConcurrentExecutionDisallowed(),
+		JobData:          persistedEntries(job.JobDataMap()),
+		RetryPolicy:      job.RetryPolicy(),
+	}
+}
+
+func (r *jobRecord) jobDetail() JobDetail {
+	b := (&JobBuilder{}).
+		WithJobKey(JobKey(r.Key)).
+		WithDescription(r.Description).
+		StoreDurably(r.Durable).
+		RequestRecovery(r.RequestsRecovery).
+		PersistJobDataAfterExecution(r.PersistJobData).
+		DisallowConcurrentExecution(r.NoConcurrent).
+		UsingJobDataMap(newDataMap(r.JobData))
+
+	if r.RetryPolicy != nil {
+		b.WithRetryPolicy(*r.RetryPolicy)
+	}
+
+	return b.Build()
+}
+
+// newTriggerRecord returns the record of the trigger without its state,
+// only the simple and NthIncludedDay triggers can be persisted.
+func newTriggerRecord(trigger OperableTrigger) (triggerRecord, error) {
+	switch t := trigger.(type) {
+	case *simpleTrigger:
+		r := newAbstractTriggerRecord(SimpleTriggerType, &t.abstractTrigger)
+
+		r.StartTime = t.startTime
+		r.EndTime = t.endTime
+		r.NextFireTime = t.nextFireTime
+		r.PreviousFireTime = t.previousFireTime
+		r.RepeatInterval = t.repeatInterval
+		r.RepeatCount = t.repeatCount
+		r.TimesTriggered = t.timesTriggered
+		r.Complete = t.complete
+
+		return r, nil
+
+	case *nthIncludedDayTrigger:
+		// the calendar is not recorded, the trigger uses the stored Calendar of its CalendarName when it fires.
+		r := newAbstractTriggerRecord(NthIncludedDayTriggerType, &t.abstractTrigger)
+
+		r.StartTime = t.startTime
+		r.EndTime = t.endTime
+		r.NextFireTime = t.nextFireTime
+		r.PreviousFireTime = t.previousFireTime
+		r.N = t.n
+		r.IntervalType = t.intervalType
+		r.FireAtTime = t.fireAtTime
+
+		return r, nil
+
+	default:
+		return triggerRecord{}, unsupportedTriggerError(trigger)
+	}
+}
+
+func newAbstractTriggerRecord(typ string, t *abstractTrigger) triggerRecord {
+	return triggerRecord{
+		Type:         typ,
+		Key:          t.Key().String(),
+		JobKey:       t.JobKey().String(),
+		Description:  t.Description(),
+		Priority:     t.Priority(),
+		JobData:      persistedEntries(t.dataMap),
+		TimeZone:     timeZoneName(t.timeZone),
+		CalendarName: t.calName,
+	}
+}
+
+func (r *triggerRecord) trigger() OperableTrigger {
+	switch r.Type {
+	case NthIncludedDayTriggerType:
+		trigger := &nthIncludedDayTrigger{
+			startTime:        r.StartTime,
+			endTime:          r.EndTime,
+			nextFireTime:     r.NextFireTime,
+			previousFireTime: r.PreviousFireTime,
+			n:                r.N,
+			intervalType:     r.IntervalType,
+			fireAtTime:       r.FireAtTime,
+		}
+
+		r.restoreAbstractTrigger(&trigger.abstractTrigger)
+
+		return trigger
+
+	default:
+		trigger := &simpleTrigger{
+			startTime:        r.StartTime,
+			endTime:          r.EndTime,
+			nextFireTime:     r.NextFireTime,
+			previousFireTime: r.PreviousFireTime,
+			repeatInterval:   r.RepeatInterval,
+			repeatCount:      r.RepeatCount,
+			timesTriggered:   r.TimesTriggered,
+			complete:         r.Complete,
+		}
+
+		r.restoreAbstractTrigger(&trigger.abstractTrigger)
+
+		return trigger
+	}
+}
+
+func (r *triggerRecord) restoreAbstractTrigger(trigger *abstractTrigger) {
+	trigger.SetKey(TriggerKey(r.Key))
+	trigger.SetJobKey(JobKey(r.JobKey))
+	trigger.SetDescription(r.Description)
+	trigger.SetPriority(r.Priority)
+	trigger.SetCalendarName(r.CalendarName)
+
+	if r.TimeZone != "" {
+		// the trigger falls back to time.Local if its location is not known here.
+		if loc, err := time.LoadLocation(r.TimeZone); err == nil {
+			trigger.SetTimeZone(loc)
+		}
+	}
+
+	if r.JobData != nil {
+		trigger.SetJobDataMap(newDataMap(r.JobData))
+	}
+}
+
+// newCalendarRecord returns the record of the calendar and its base calendars without Name,
+// only the Daily, Annual, Monthly and Holiday calendars can be persisted.
+func newCalendarRecord(name string, calendar Calendar) (*calendarRecord, error) {
+	var r *calendarRecord
+
+	switch c := calendar.(type) {
+	case *DailyCalendar:
+		r = newBaseCalendarRecord(dailyCalendarType, &c.baseCalendar)
+
+		r.RangeStart = c.rangeStart
+		r.RangeEnd = c.rangeEnd
+		r.InvertTimeRange = c.invertTimeRange
+
+	case *AnnualCalendar:
+		r = newBaseCalendarRecord(annualCalendarType, &c.baseCalendar)
+
+		for _, v := range c.excludeDays.Keys() {
+			day := v.(monthDay)
+
+			r.ExcludedDates = append(r.ExcludedDates, fmt.Sprintf("%02d-%02d", day.month, day.day))
+		}
+
+		sort.Strings(r.ExcludedDates)
+
+	case *MonthlyCalendar:
+		r = newBaseCalendarRecord(monthlyCalendarType, &c.baseCalendar)
+
+		r.ExcludedDays = c.DaysExcluded()
+
+	case *HolidayCalendar:
+		r = newBaseCalendarRecord(holidayCalendarType, &c.baseCalendar)
+
+		for _, date := range c.ExcludedDates() {
+			r.ExcludedDates = append(r.ExcludedDates, date.Format("2006-01-02"))
+		}
+
+	default:
+		return nil, unsupportedCalendarError(name, calendar)
+	}
+
+	if base := calendar.BaseCalendar(); base != nil {
+		var err error
+
+		if r.Base, err = newCalendarRecord(name, base); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+func newBaseCalendarRecord(typ string, c *baseCalendar) *calendarRecord {
+	return &calendarRecord{
+		Type:        typ,
+		Description: c.desc,
+		TimeZone:    timeZoneName(c.location),
+	}
+}
+
+// calendar restores the calendar and its base calendars, name is the name of the stored calendar.
+func (r *calendarRecord) calendar(name string) (Calendar, error) {
+	var base Calendar
+
+	if r.Base != nil {
+		var err error
+
+		if base, err = r.Base.calendar(name); err != nil {
+			return nil, err
+		}
+	}
+
+	loc := time.Local
+
+	if r.TimeZone != "" {
+		// the calendar falls back to time.Local if its location is not known here, like the triggers.
+		if l, err := time.LoadLocation(r.TimeZone); err == nil {
+			loc = l
+		}
+	}
+
+	var calendar Calendar
+
+	switch r.Type {
+	case dailyCalendarType:
+		c := NewDailyCalendar(base, r.RangeStart, r.RangeEnd)
+		c.SetInvertTimeRange(r.InvertTimeRange)
+		c.SetLocation(loc)
+
+		calendar = c
+
+	case annualCalendarType:
+		c := NewAnnualCalendar(base)
+		c.SetLocation(loc)
+
+		for _, s := range r.ExcludedDates {
+			var month time.Month
+			var day int
+
+			if _, err := fmt.Sscanf(s, "%d-%d", &month, &day); err != nil {
+				return nil, invalidCalendarRecordError(name, err)
+			}
+
+			c.SetDayExcluded(month, day, true)
+		}
+
+		calendar = c
+
+	case monthlyCalendarType:
+		c := NewMonthlyCalendar(base)
+		c.SetLocation(loc)
+
+		for _, day := range r.ExcludedDays {
+			c.SetDayExcluded(day, true)
+		}
+
+		calendar = c
+
+	case holidayCalendarType:
+		c := NewHolidayCalendar(base)
+		c.SetLocation(loc)
+
+		for _, s := range r.ExcludedDates {
+			date, err := time.ParseInLocation("2006-01-02", s, loc)
+
+			if err != nil {
+				return nil, invalidCalendarRecordError(name, err)
+			}
+
+			c.AddExcludedDate(date)
+		}
+
+		calendar = c
+
+	default:
+		return nil, invalidCalendarRecordError(name, fmt.Errorf("unknown type '%s'", r.Type))
+	}
+
+	calendar.SetDescription(r.Description)
+
+	return calendar, nil
+}
+
+// timeZoneName returns the name of the location to be loaded again, or empty for the default time.Local.
+func timeZoneName(loc *time.Location) string {
+	if loc == nil {
+		return ""
+	}
+
+	return loc.String()
+}
+
+// dataMapEntries returns the entries of the map to be encoded, or nil if the map is empty.
+func dataMapEntries(m JobDataMap) map[string]interface{} {
+	if m == nil || m.Empty() {
+		return nil
+	}
+
+	entries := make(map[string]interface{}, m.Len())
+
+	m.ForEach(func(key string, value interface{}) bool {
+		entries[key] = value
+
+		return true
+	})
+
+	return entries
+}
+
+func newDataMap(entries map[string]interface{}) JobDataMap {
+	m := NewJobDataMap()
+
+	for key, value := range entries {
+		if v, ok := value.(*persistedValue); ok {
+			value = v.value
+		}
+
+		m.Put(key, value)
+	}
+
+	m.ClearDirtyFlag()
+
+	return m
+}
@@ -0,0 +1,264 @@
+package quartz
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFileJobStore(t *testing.T) {
+	Convey("Given a FileJobStore in a temporary directory", t, func() {
+		dir, err := os.MkdirTemp("", "quartz")
+
+		So(err, ShouldBeNil)
+
+		defer os.RemoveAll(dir)
+
+		path := filepath.Join(dir, "jobs.gob")
+
+		store, err := NewFileJobStore(path)
+
+		So(err, ShouldBeNil)
+		So(store.SupportsPersistence(), ShouldBeTrue)
+		So(store.NumberOfJobs(), ShouldEqual, 0)
+
+		job := (&JobBuilder{}).
+			WithGroupIdentity("job", "reports").
+			WithDescription("daily report").
+			RequestRecovery(true).
+			UsingJobData("count", 1).
+			UsingJobData("name", "daily").
+			Build()
+
+		startTime := time.Date(2016, time.March, 1, 8, 0, 0, 0, time.UTC)
+
+		trigger := (&TriggerBuilder{}).
+			WithGroupIdentity("trigger", "reports").
+			ForJobDetail(job).
+			StartAt(startTime).
+			WithPriority(7).
+			ModifiedByCalendar("holidays").
+			UsingJobData("trigger", true).
+			WithSchedule(&SimpleScheduleBuilder{time.Minute, REPEAT_INDEFINITELY}).
+			Build().(OperableTrigger)
+		trigger.ComputeFirstFireTime(nil)
+
+		So(store.StoreJobAndTrigger(job, trigger), ShouldBeNil)
+
+		durable := (&JobBuilder{}).WithIdentity("durable").StoreDurably(true).Build()
+
+		So(store.StoreJob(durable, false), ShouldBeNil)
+
+		Convey("The data should survive a round trip across the store instances", func() {
+			_, err := store.PauseJobs(GroupEquals("backup"))
+
+			So(err, ShouldBeNil)
+
+			reloaded, err := NewFileJobStore(path)
+
+			So(err, ShouldBeNil)
+			So(reloaded.NumberOfJobs(), ShouldEqual, 2)
+			So(reloaded.NumberOfTriggers(), ShouldEqual, 1)
+			So(reloaded.pausedJobGroups.Contains("backup"), ShouldBeTrue)
+
+			stored, err := reloaded.RetrieveJob(job.Key())
+
+			So(err, ShouldBeNil)
+			So(stored.Description(), ShouldEqual, "daily report")
+			So(stored.RequestsRecovery(), ShouldBeTrue)
+			So(stored.JobDataMap().Get("count"), ShouldEqual, 1)
+			So(stored.JobDataMap().Get("name"), ShouldEqual, "daily")
+
+			stored, _ = reloaded.RetrieveJob(durable.Key())
+
+			So(stored.Durable(), ShouldBeTrue)
+
+			retrieved, state, fireTime, err := reloaded.RetrieveTriggerWithState(trigger.Key())
+
+			So(err, ShouldBeNil)
+			So(state, ShouldEqual, STATE_WAITING)
+			So(fireTime.Equal(startTime), ShouldBeTrue)
+			So(retrieved.JobKey(), ShouldResemble, job.Key())
+			So(retrieved.Priority(), ShouldEqual, 7)
+			So(retrieved.CalendarName(), ShouldEqual, "holidays")
+			So(retrieved.JobDataMap().Get("trigger"), ShouldEqual, true)
+			So(retrieved.FireTimeAfter(startTime).Equal(startTime.Add(time.Minute)), ShouldBeTrue)
+		})
+
+		Convey("The calendars should survive a round trip across the store instances", func() {
+			shanghai, _ := time.LoadLocation("Asia/Shanghai")
+
+			daily := NewDailyCalendar(nil, TimeOfDay{9, 0, 0}, TimeOfDay{17, 0, 0})
+			daily.SetInvertTimeRange(true)
+			daily.SetLocation(shanghai)
+
+			annual := NewAnnualCalendar(daily)
+			annual.SetLocation(time.UTC)
+			annual.SetDayExcluded(time.December, 25, true)
+			annual.SetDayExcluded(time.February, 29, true)
+
+			monthly := NewMonthlyCalendar(annual)
+			monthly.SetLocation(time.UTC)
+			monthly.SetDayExcluded(1, true)
+			monthly.SetDayExcluded(31, true)
+
+			holidays := NewHolidayCalendar(monthly)
+			holidays.SetDescription("holidays")
+			holidays.SetLocation(shanghai)
+			holidays.AddExcludedDate(time.Date(2016, time.March, 8, 0, 0, 0, 0, shanghai))
+
+			So(store.StoreCalendar("holidays", holidays, false), ShouldBeNil)
+			So(store.StoreCalendar("weekends", &weekendCalendar{}, false), ShouldNotBeNil)
+
+			reloaded, err := NewFileJobStore(path)
+
+			So(err, ShouldBeNil)
+
+			names, _ := reloaded.GetCalendarNames()
+
+			So(names, ShouldResemble, []string{"holidays"})
+
+			cal, err := reloaded.RetrieveCalendar("holidays")
+
+			So(err, ShouldBeNil)
+			So(cal.Description(), ShouldEqual, "holidays")
+
+			restored := cal.(*HolidayCalendar)
+
+			So(restored.Location().String(), ShouldEqual, "Asia/Shanghai")
+			So(restored.ExcludedDates(), ShouldResemble, holidays.ExcludedDates())
+
+			restoredMonthly := restored.BaseCalendar().(*MonthlyCalendar)
+
+			So(restoredMonthly.DaysExcluded(), ShouldResemble, []int{1, 31})
+
+			restoredAnnual := restoredMonthly.BaseCalendar().(*AnnualCalendar)
+
+			So(restoredAnnual.IsDayExcluded(time.December, 25), ShouldBeTrue)
+			So(restoredAnnual.IsDayExcluded(time.February, 29), ShouldBeTrue)
+
+			restoredDaily := restoredAnnual.BaseCalendar().(*DailyCalendar)
+
+			So(restoredDaily.RangeStart(), ShouldResemble, TimeOfDay{9, 0, 0})
+			So(restoredDaily.RangeEnd(), ShouldResemble, TimeOfDay{17, 0, 0})
+			So(restoredDaily.InvertTimeRange(), ShouldBeTrue)
+			So(restoredDaily.Location().String(), ShouldEqual, "Asia/Shanghai")
+
+			for _, t := range []time.Time{
+				time.Date(2016, time.March, 7, 2, 0, 0, 0, time.UTC),
+				time.Date(2016, time.March, 8, 2, 0, 0, 0, time.UTC),
+				time.Date(2016, time.March, 1, 2, 0, 0, 0, time.UTC),
+				time.Date(2016, time.December, 25, 2, 0, 0, 0, time.UTC),
+				time.Date(2016, time.March, 7, 12, 0, 0, 0, time.UTC),
+			} {
+				So(cal.IsTimeIncluded(t), ShouldEqual, holidays.IsTimeIncluded(t))
+				So(cal.NextIncludedTime(t).Equal(holidays.NextIncludedTime(t)), ShouldBeTrue)
+			}
+		})
+
+		Convey("The paused trigger should stay paused", func() {
+			So(store.PauseTrigger(trigger.Key()), ShouldBeNil)
+
+			reloaded, err := NewFileJobStore(path)
+
+			So(err, ShouldBeNil)
+
+			state, _ := reloaded.GetTriggerState(trigger.Key())
+
+			So(state, ShouldEqual, STATE_PAUSED)
+		})
+
+		Convey("The acquired trigger should be waiting again", func() {
+			acquired, err := store.AcquireNextTriggers(startTime, 1, 0)
+
+			So(err, ShouldBeNil)
+			So(acquired, ShouldHaveLength, 1)
+
+			reloaded, err := NewFileJobStore(path)
+
+			So(err, ShouldBeNil)
+
+			state, _ := reloaded.GetTriggerState(trigger.Key())
+
+			So(state, ShouldEqual, STATE_WAITING)
+		})
+
+		Convey("The job executing when the data was saved should be recovered", func() {
+			acquired, _ := store.AcquireNextTriggers(startTime, 1, 0)
+			bundles, err := store.TriggersFired(acquired)
+
+			So(err, ShouldBeNil)
+			So(bundles, ShouldHaveLength, 1)
+
+			reloaded, err := NewFileJobStore(path)
+
+			So(err, ShouldBeNil)
+			So(reloaded.SchedulerStarted(), ShouldBeNil)
+
+			keys, err := reloaded.GetTriggerKeys(GroupEquals(RECOVERING_JOBS_GROUP))
+
+			So(err, ShouldBeNil)
+			So(keys, ShouldHaveLength, 1)
+
+			recovering, _ := reloaded.RetrieveTrigger(keys[0])
+
+			So(recovering.JobKey(), ShouldResemble, job.Key())
+			So(recovering.JobDataMap().Get("trigger"), ShouldEqual, true)
+			So(recovering.JobDataMap().Get(FAILED_JOB_ORIGINAL_TRIGGER_NAME), ShouldEqual, trigger.Key().Name())
+			So(recovering.JobDataMap().Get(FAILED_JOB_ORIGINAL_TRIGGER_GROUP), ShouldEqual, trigger.Key().Group())
+
+			scheduledFireTime, ok := recovering.JobDataMap().GetTime(FAILED_JOB_ORIGINAL_TRIGGER_SCHEDULED_FIRETIME)
+
+			So(ok, ShouldBeTrue)
+			So(scheduledFireTime.Equal(bundles[0].ScheduledFireTime), ShouldBeTrue)
+
+			Convey("The recovery should not be repeated", func() {
+				again, err := NewFileJobStore(path)
+
+				So(err, ShouldBeNil)
+				So(again.SchedulerStarted(), ShouldBeNil)
+
+				keys, _ := again.GetTriggerKeys(GroupEquals(RECOVERING_JOBS_GROUP))
+
+				So(keys, ShouldHaveLength, 1)
+			})
+		})
+
+		Convey("The completed job should not be recovered", func() {
+			acquired, _ := store.AcquireNextTriggers(startTime, 1, 0)
+			bundles, _ := store.TriggersFired(acquired)
+
+			store.TriggeredJobComplete(bundles[0].Trigger, bundles[0].JobDetail, NOOP)
+
+			reloaded, err := NewFileJobStore(path)
+
+			So(err, ShouldBeNil)
+			So(reloaded.SchedulerStarted(), ShouldBeNil)
+
+			keys, _ := reloaded.GetTriggerKeys(GroupEquals(RECOVERING_JOBS_GROUP))
+
+			So(keys, ShouldBeEmpty)
+		})
+
+		Convey("The cleared data should be saved", func() {
+			So(store.ClearAllSchedulingData(), ShouldBeNil)
+
+			reloaded, err := NewFileJobStore(path)
+
+			So(err, ShouldBeNil)
+			So(reloaded.NumberOfJobs(), ShouldEqual, 0)
+			So(reloaded.NumberOfTriggers(), ShouldEqual, 0)
+		})
+
+		Convey("The corrupted file should be rejected", func() {
+			So(os.WriteFile(path, []byte("corrupted"), 0644), ShouldBeNil)
+
+			_, err := NewFileJobStore(path)
+
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
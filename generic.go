@@ -0,0 +1,123 @@
+package quartz
+
+// SetG is the type-safe variant of Set.
+type SetG[T any] interface {
+	Empty() bool
+
+	Len() int
+
+	Keys() []T
+
+	Contains(item T) bool
+
+	Add(item T)
+
+	Remove(item T) bool
+}
+
+// SortedSetG is the type-safe variant of SortedSet,
+// the lookup methods return false instead of nil when there is no such item.
+type SortedSetG[T any] interface {
+	SetG[T]
+
+	First() (T, bool)
+
+	Last() (T, bool)
+
+	PollFirst() (T, bool)
+
+	Ceiling(item T) (T, bool)
+
+	Floor(item T) (T, bool)
+
+	Higher(item T) (T, bool)
+
+	Lower(item T) (T, bool)
+}
+
+type hashSetG[T comparable] map[T]struct{}
+
+func NewHashSetG[T comparable]() SetG[T] { return make(hashSetG[T]) }
+
+func (s hashSetG[T]) Empty() bool { return len(s) == 0 }
+
+func (s hashSetG[T]) Len() int { return len(s) }
+
+func (s hashSetG[T]) Keys() (keys []T) {
+	for key := range s {
+		keys = append(keys, key)
+	}
+
+	return
+}
+
+func (s hashSetG[T]) Contains(key T) bool {
+	_, exists := s[key]
+
+	return exists
+}
+
+func (s hashSetG[T]) Add(key T) { s[key] = struct{}{} }
+
+func (s hashSetG[T]) Remove(key T) bool {
+	_, exists := s[key]
+
+	delete(s, key)
+
+	return exists
+}
+
+// treeSetG wraps a treeSet, the type assertions are confined to the compare function and the accessors.
+type treeSetG[T any] struct {
+	set *treeSet
+}
+
+func NewTreeSetG[T any](compare func(lhs, rhs T) int) SortedSetG[T] {
+	return &treeSetG[T]{&treeSet{
+		compare: func(lhs, rhs interface{}) int {
+			return compare(lhs.(T), rhs.(T))
+		},
+	}}
+}
+
+func (s *treeSetG[T]) Empty() bool { return s.set.Empty() }
+
+func (s *treeSetG[T]) Len() int { return s.set.Len() }
+
+func (s *treeSetG[T]) Keys() (keys []T) {
+	for _, key := range s.set.Keys() {
+		keys = append(keys, key.(T))
+	}
+
+	return
+}
+
+func (s *treeSetG[T]) Contains(item T) bool { return s.set.Contains(item) }
+
+func (s *treeSetG[T]) Add(item T) { s.set.Add(item) }
+
+func (s *treeSetG[T]) Remove(item T) bool { return s.set.Remove(item) }
+
+func (s *treeSetG[T]) First() (T, bool) { return typed[T](s.set.First()) }
+
+func (s *treeSetG[T]) Last() (T, bool) { return typed[T](s.set.Last()) }
+
+func (s *treeSetG[T]) PollFirst() (T, bool) { return typed[T](s.set.PollFirst()) }
+
+func (s *treeSetG[T]) Ceiling(item T) (T, bool) { return typed[T](s.set.Ceiling(item)) }
+
+func (s *treeSetG[T]) Floor(item T) (T, bool) { return typed[T](s.set.Floor(item)) }
+
+func (s *treeSetG[T]) Higher(item T) (T, bool) { return typed[T](s.set.Higher(item)) }
+
+func (s *treeSetG[T]) Lower(item T) (T, bool) { return typed[T](s.set.Lower(item)) }
+
+func typed[T any](item interface{}) (T, bool) {
+	if item == nil {
+		var empty T
+
+		return empty, false
+	}
+
+	return item.(T), true
+}
@@ -0,0 +1,93 @@
+package quartz
+
+import (
+	"bytes"
+	"sort"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestHashSetG(t *testing.T) {
+	Convey("Given a HashSetG of strings", t, func() {
+		s := NewHashSetG[string]()
+
+		So(s.Empty(), ShouldBeTrue)
+
+		s.Add("key")
+		s.Add("foo")
+		s.Add("key")
+
+		So(s.Len(), ShouldEqual, 2)
+		So(s.Contains("foo"), ShouldBeTrue)
+		So(s.Contains("bar"), ShouldBeFalse)
+
+		keys := s.Keys()
+
+		sort.Strings(keys)
+
+		So(keys, ShouldResemble, []string{"foo", "key"})
+
+		So(s.Remove("foo"), ShouldBeTrue)
+		So(s.Remove("foo"), ShouldBeFalse)
+		So(s.Len(), ShouldEqual, 1)
+	})
+}
+
+func TestTreeSetG(t *testing.T) {
+	Convey("Given a TreeSetG of TriggerKey", t, func() {
+		s := NewTreeSetG(func(lhs, rhs TriggerKey) int {
+			return bytes.Compare(lhs, rhs)
+		})
+
+		So(s.Empty(), ShouldBeTrue)
+
+		first, ok := s.First()
+
+		So(ok, ShouldBeFalse)
+		So(first, ShouldBeNil)
+
+		s.Add(NewGroupTriggerKey("b", "group"))
+		s.Add(NewGroupTriggerKey("a", "group"))
+		s.Add(NewTriggerKey("c"))
+		s.Add(NewGroupTriggerKey("a", "group"))
+
+		So(s.Len(), ShouldEqual, 3)
+		So(s.Contains(NewTriggerKey("c")), ShouldBeTrue)
+		So(s.Contains(NewTriggerKey("d")), ShouldBeFalse)
+
+		var keys []string
+
+		for _, key := range s.Keys() {
+			keys = append(keys, key.Name())
+		}
+
+		So(keys, ShouldResemble, []string{"c", "a", "b"})
+
+		first, ok = s.First()
+
+		So(ok, ShouldBeTrue)
+		So(first.String(), ShouldEqual, "DEFAULT.c")
+
+		last, ok := s.Last()
+
+		So(ok, ShouldBeTrue)
+		So(last.String(), ShouldEqual, "group.b")
+
+		next, ok := s.Higher(NewGroupTriggerKey("a", "group"))
+
+		So(ok, ShouldBeTrue)
+		So(next.Name(), ShouldEqual, "b")
+
+		_, ok = s.Higher(last)
+
+		So(ok, ShouldBeFalse)
+
+		polled, ok := s.PollFirst()
+
+		So(ok, ShouldBeTrue)
+		So(polled.Equals(NewTriggerKey("c")), ShouldBeTrue)
+		So(s.Remove(NewGroupTriggerKey("a", "group")), ShouldBeTrue)
+		So(s.Len(), ShouldEqual, 1)
+	})
+}
@@ -0,0 +1,187 @@
+package quartz
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"time"
+)
+
+// The concrete types are registered to be encoded as the Trigger, JobDetail and JobDataMap interfaces,
+// the custom types in the JobDataMap must have a ValueCodec or be registered with gob.Register too.
+func init() {
+	gob.Register(&simpleTrigger{})
+	gob.Register(&jitterTrigger{})
+	gob.Register(&nthIncludedDayTrigger{})
+	gob.Register(&jobDetail{})
+	gob.Register(&dirtyFlagMap{})
+	gob.Register(&persistedValue{})
+	gob.Register(&encryptedValue{})
+}
+
+func gobEncode(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func gobDecode(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// dataMapRecord is the gob encoding of a dirtyFlagMap.
+type dataMapRecord struct {
+	Entries map[string]interface{}
+	Dirty   bool
+	Ordered bool
+	Order   []string
+}
+
+func (m *dirtyFlagMap) GobEncode() ([]byte, error) {
+	return gobEncode(dataMapRecord{persistedEntries(m), m.dirty, m.ordered, m.order})
+}
+
+func (m *dirtyFlagMap) GobDecode(data []byte) error {
+	var r dataMapRecord
+
+	if err := gobDecode(data, &r); err != nil {
+		return err
+	}
+
+	if r.Entries == nil {
+		r.Entries = make(map[string]interface{})
+	}
+
+	for key, value := range r.Entries {
+		if v, ok := value.(*persistedValue); ok {
+			r.Entries[key] = v.value
+		}
+	}
+
+	*m = dirtyFlagMap{entries: r.Entries, dirty: r.Dirty, ordered: r.Ordered, order: r.Order}
+
+	return nil
+}
+
+// GobEncode encodes the job as its jobRecord, the JobFactory is not encoded.
+func (d *jobDetail) GobEncode() ([]byte, error) {
+	return gobEncode(newJobRecord(d))
+}
+
+func (d *jobDetail) GobDecode(data []byte) error {
+	var r jobRecord
+
+	if err := gobDecode(data, &r); err != nil {
+		return err
+	}
+
+	*d = *r.jobDetail().(*jobDetail)
+
+	return nil
+}
+
+// GobEncode encodes the trigger as its triggerRecord, without the state kept by the JobStore.
+func (t *simpleTrigger) GobEncode() ([]byte, error) {
+	r, err := newTriggerRecord(t)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return gobEncode(r)
+}
+
+func (t *simpleTrigger) GobDecode(data []byte) error {
+	var r triggerRecord
+
+	if err := gobDecode(data, &r); err != nil {
+		return err
+	}
+
+	st, ok := r.trigger().(*simpleTrigger)
+
+	if !ok {
+		return unexpectedTriggerTypeError(r.Type, SimpleTriggerType)
+	}
+
+	*t = *st
+
+	return nil
+}
+
+// GobEncode encodes the trigger as its triggerRecord, without its calendar and the state kept by the JobStore.
+func (t *nthIncludedDayTrigger) GobEncode() ([]byte, error) {
+	r, err := newTriggerRecord(t)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return gobEncode(r)
+}
+
+func (t *nthIncludedDayTrigger) GobDecode(data []byte) error {
+	var r triggerRecord
+
+	if err := gobDecode(data, &r); err != nil {
+		return err
+	}
+
+	nt, ok := r.trigger().(*nthIncludedDayTrigger)
+
+	if !ok {
+		return unexpectedTriggerTypeError(r.Type, NthIncludedDayTriggerType)
+	}
+
+	*t = *nt
+
+	return nil
+}
+
+// jitterRecord is the gob encoding of a jitterTrigger, the base trigger must be registered.
+type jitterRecord struct {
+	Trigger   OperableTrigger
+	MaxJitter time.Duration
+	Seed      int64
+}
+
+func (t *jitterTrigger) GobEncode() ([]byte, error) {
+	return gobEncode(jitterRecord{t.OperableTrigger, t.maxJitter, t.seed})
+}
+
+func (t *jitterTrigger) GobDecode(data []byte) error {
+	var r jitterRecord
+
+	if err := gobDecode(data, &r); err != nil {
+		return err
+	}
+
+	*t = jitterTrigger{r.Trigger, r.MaxJitter, r.Seed}
+
+	return nil
+}
+
+// encodeCalendar encodes the record of the calendar and its base calendars.
+func encodeCalendar(name string, calendar Calendar) ([]byte, error) {
+	r, err := newCalendarRecord(name, calendar)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return gobEncode(r)
+}
+
+func decodeCalendar(name string, data []byte) (Calendar, error) {
+	var r calendarRecord
+
+	if err := gobDecode(data, &r); err != nil {
+		return nil, fmt.Errorf("Couldn't decode the calendar '%s': %w", name, err)
+	}
+
+	return r.calendar(name)
+}
@@ -0,0 +1,120 @@
+package quartz
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGobEncoding(t *testing.T) {
+	Convey("Given the concrete triggers", t, func() {
+		startTime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		newTrigger := func(scheduleBuilder ScheduleBuilder) OperableTrigger {
+			trigger := (&TriggerBuilder{}).
+				WithGroupIdentity("trigger", "group").
+				WithDescription("desc").
+				WithPriority(7).
+				ForGroupJob("job", "group").
+				StartAt(startTime).
+				ModifiedByCalendar("holidays").
+				UsingJobData("key", "value").
+				WithSchedule(scheduleBuilder).
+				Build().(OperableTrigger)
+
+			trigger.ComputeFirstFireTime(nil)
+
+			return trigger
+		}
+
+		for name, trigger := range map[string]OperableTrigger{
+			"simple":         newTrigger(&SimpleScheduleBuilder{time.Minute, 9}),
+			"jitter":         newTrigger(WithJitter(&SimpleScheduleBuilder{time.Minute, 9}, 10*time.Second, 42)),
+			"nthIncludedDay": newTrigger(NthIncludedDay(3, INTERVAL_TYPE_MONTHLY).AtTimeOfDay(TimeOfDay{Hour: 9})),
+		} {
+			Convey("When encodes the "+name+" trigger as a Trigger", func() {
+				data, err := gobEncode(struct{ Trigger Trigger }{trigger})
+
+				So(err, ShouldBeNil)
+
+				var decoded struct{ Trigger Trigger }
+
+				So(gobDecode(data, &decoded), ShouldBeNil)
+
+				Convey("The decoded trigger should be the same", func() {
+					other := decoded.Trigger.(OperableTrigger)
+
+					So(other, ShouldHaveSameTypeAs, trigger)
+					So(other.Key(), ShouldResemble, trigger.Key())
+					So(other.JobKey(), ShouldResemble, trigger.JobKey())
+					So(other.Description(), ShouldEqual, "desc")
+					So(other.Priority(), ShouldEqual, 7)
+					So(other.CalendarName(), ShouldEqual, "holidays")
+					So(other.JobDataMap().Get("key"), ShouldEqual, "value")
+					So(other.StartTime().Equal(startTime), ShouldBeTrue)
+					So(other.NextFireTime().Equal(trigger.NextFireTime()), ShouldBeTrue)
+					So(other.FireTimeAfter(startTime).Equal(trigger.FireTimeAfter(startTime)), ShouldBeTrue)
+				})
+			})
+		}
+	})
+
+	Convey("Given a job", t, func() {
+		job := (&JobBuilder{}).
+			WithGroupIdentity("job", "group").
+			WithDescription("desc").
+			StoreDurably(true).
+			RequestRecovery(true).
+			PersistJobDataAfterExecution(true).
+			DisallowConcurrentExecution(true).
+			WithRetryPolicy(RetryPolicy{MaxAttempts: 3, InitialDelay: time.Second}).
+			UsingJobData("key", "value").
+			Build()
+
+		Convey("When encodes it as a JobDetail", func() {
+			data, err := gobEncode(struct{ Job JobDetail }{job})
+
+			So(err, ShouldBeNil)
+
+			var decoded struct{ Job JobDetail }
+
+			So(gobDecode(data, &decoded), ShouldBeNil)
+
+			Convey("The decoded job should be the same", func() {
+				other := decoded.Job
+
+				So(other.Key(), ShouldResemble, job.Key())
+				So(other.Description(), ShouldEqual, "desc")
+				So(other.Durable(), ShouldBeTrue)
+				So(other.RequestsRecovery(), ShouldBeTrue)
+				So(other.PersistJobDataAfterExecution(), ShouldBeTrue)
+				So(other.ConcurrentExecutionDisallowed(), ShouldBeTrue)
+				So(other.RetryPolicy(), ShouldResemble, job.RetryPolicy())
+				So(other.JobDataMap().Get("key"), ShouldEqual, "value")
+			})
+		})
+	})
+
+	Convey("Given a dirty ordered data map", t, func() {
+		m := NewOrderedDataMap()
+		m.Put("foo", "bar")
+		m.Put("abc", int64(123))
+
+		Convey("When encodes it as a JobDataMap", func() {
+			data, err := gobEncode(struct{ Map JobDataMap }{m})
+
+			So(err, ShouldBeNil)
+
+			var decoded struct{ Map JobDataMap }
+
+			So(gobDecode(data, &decoded), ShouldBeNil)
+
+			Convey("The decoded map should keep the entries, the order and the dirty flag", func() {
+				So(decoded.Map.Keys(), ShouldResemble, []string{"foo", "abc"})
+				So(decoded.Map.Values(), ShouldResemble, []interface{}{"bar", int64(123)})
+				So(decoded.Map.Dirty(), ShouldBeTrue)
+			})
+		})
+	})
+}
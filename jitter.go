@@ -0,0 +1,130 @@
+package quartz
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// JitterScheduleBuilder delays each fire time of the base schedule by a random offset up to maxJitter,
+// so the triggers sharing a schedule don't fire all at once.
+//
+// The offset of a fire time is derived from the seed and the scheduled time, so the fire times can be reproduced,
+// and it never delays a fire time past the end time of the trigger.
+// The maxJitter should be shorter than the repeat interval, or a delayed fire time may skip the next one.
+type JitterScheduleBuilder struct {
+	base      ScheduleBuilder
+	maxJitter time.Duration
+	seed      int64
+}
+
+func WithJitter(base ScheduleBuilder, maxJitter time.Duration, seed int64) *JitterScheduleBuilder {
+	return &JitterScheduleBuilder{base, maxJitter, seed}
+}
+
+// Build the trigger, panics if the schedule is invalid, use BuildE to check the error.
+func (b *JitterScheduleBuilder) Build() MutableTrigger {
+	trigger, err := b.BuildE()
+
+	if err != nil {
+		panic(err)
+	}
+
+	return trigger
+}
+
+func (b *JitterScheduleBuilder) BuildE() (MutableTrigger, error) {
+	if b.maxJitter < 0 {
+		return nil, errors.New("Max jitter must be >= 0.")
+	}
+
+	var base MutableTrigger
+
+	if sb, ok := b.base.(interface {
+		BuildE() (MutableTrigger, error)
+	}); ok {
+		var err error
+
+		if base, err = sb.BuildE(); err != nil {
+			return nil, err
+		}
+	} else {
+		base = b.base.Build()
+	}
+
+	ot, ok := base.(OperableTrigger)
+
+	if !ok {
+		return nil, errors.New("The jitter requires a schedule building an OperableTrigger.")
+	}
+
+	return &jitterTrigger{ot, b.maxJitter, b.seed}, nil
+}
+
+// jitterTrigger delays the fire times computed by the base trigger, which computes the next fire time
+// after the delayed one.
+type jitterTrigger struct {
+	OperableTrigger
+
+	maxJitter time.Duration
+	seed      int64
+}
+
+func (t *jitterTrigger) Clone() interface{} {
+	return &jitterTrigger{t.OperableTrigger.Clone().(OperableTrigger), t.maxJitter, t.seed}
+}
+
+func (t *jitterTrigger) jitter(fireTime time.Time) time.Time {
+	if fireTime.IsZero() || t.maxJitter <= 0 {
+		return fireTime
+	}
+
+	offset := time.Duration(rand.New(rand.NewSource(t.seed ^ fireTime.UnixNano())).Int63n(int64(t.maxJitter) + 1))
+
+	if jittered := fireTime.Add(offset); t.EndTime().IsZero() || !jittered.After(t.EndTime()) {
+		return jittered
+	}
+
+	return t.EndTime()
+}
+
+func (t *jitterTrigger) FireTimeAfter(afterTime time.Time) time.Time {
+	return t.jitter(t.OperableTrigger.FireTimeAfter(afterTime))
+}
+
+func (t *jitterTrigger) Triggered(cal Calendar) {
+	t.OperableTrigger.Triggered(cal)
+	t.OperableTrigger.SetNextFireTime(t.jitter(t.OperableTrigger.NextFireTime()))
+}
+
+func (t *jitterTrigger) ComputeFirstFireTime(cal Calendar) time.Time {
+	fireTime := t.jitter(t.OperableTrigger.ComputeFirstFireTime(cal))
+
+	t.OperableTrigger.SetNextFireTime(fireTime)
+
+	return fireTime
+}
+
+func (t *jitterTrigger) UpdateAfterMisfire(cal Calendar, now time.Time) {
+	t.OperableTrigger.UpdateAfterMisfire(cal, now)
+	t.OperableTrigger.SetNextFireTime(t.jitter(t.OperableTrigger.NextFireTime()))
+}
+
+func (t *jitterTrigger) Validate() error {
+	if t.maxJitter < 0 {
+		return errors.New("Max jitter must be >= 0.")
+	}
+
+	return t.OperableTrigger.Validate()
+}
+
+func (t *jitterTrigger) TriggerBuilder() *TriggerBuilder {
+	b := t.OperableTrigger.TriggerBuilder()
+	b.ScheduleBuilder = t.ScheduleBuilder()
+
+	return b
+}
+
+func (t *jitterTrigger) ScheduleBuilder() ScheduleBuilder {
+	return &JitterScheduleBuilder{t.OperableTrigger.ScheduleBuilder(), t.maxJitter, t.seed}
+}
@@ -0,0 +1,78 @@
+package quartz
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestJitterScheduleBuilder(t *testing.T) {
+	Convey("Given a trigger repeating every minute with a jitter", t, func() {
+		startTime := time.Date(2016, time.March, 1, 8, 0, 0, 0, time.UTC)
+
+		newTrigger := func(seed int64, endTime time.Time) OperableTrigger {
+			return (&TriggerBuilder{}).
+				WithIdentity("trigger").
+				ForJob("job").
+				StartAt(startTime).
+				EndAt(endTime).
+				WithSchedule(WithJitter(&SimpleScheduleBuilder{time.Minute, 9}, 10*time.Second, seed)).
+				Build().(OperableTrigger)
+		}
+
+		fireTimes := func(trigger OperableTrigger) (times []time.Time) {
+			for fireTime := trigger.ComputeFirstFireTime(nil); !fireTime.IsZero(); fireTime = trigger.NextFireTime() {
+				times = append(times, fireTime)
+
+				trigger.Triggered(nil)
+			}
+
+			return
+		}
+
+		trigger := newTrigger(42, zero)
+
+		So(trigger.Validate(), ShouldBeNil)
+
+		times := fireTimes(trigger)
+
+		Convey("Each fire time should be delayed by at most the jitter", func() {
+			So(times, ShouldHaveLength, 10)
+
+			for i, fireTime := range times {
+				scheduled := startTime.Add(time.Duration(i) * time.Minute)
+
+				So(fireTime, ShouldHappenOnOrBetween, scheduled, scheduled.Add(10*time.Second))
+			}
+		})
+
+		Convey("The fire times should be reproduced with the same seed", func() {
+			So(fireTimes(newTrigger(42, zero)), ShouldResemble, times)
+			So(fireTimes(newTrigger(42, zero).Clone().(OperableTrigger)), ShouldResemble, times)
+			So(fireTimes(newTrigger(7, zero)), ShouldNotResemble, times)
+		})
+
+		Convey("The fire times should not be delayed past the end time", func() {
+			endTime := startTime.Add(2 * time.Minute)
+
+			times := fireTimes(newTrigger(42, endTime))
+
+			So(times, ShouldHaveLength, 3)
+
+			for _, fireTime := range times {
+				So(fireTime.After(endTime), ShouldBeFalse)
+			}
+		})
+
+		Convey("The schedule should be described with its jitter", func() {
+			So(DescribeSchedule(trigger), ShouldEqual, "every minute, 10 times, delayed up to 10 seconds")
+		})
+
+		Convey("A negative jitter should be invalid", func() {
+			_, err := WithJitter(&SimpleScheduleBuilder{time.Minute, 9}, -time.Second, 42).BuildE()
+
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -2,6 +2,8 @@ package quartz
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -12,7 +14,56 @@ import (
 //
 type Job interface {
 	// Called by the Scheduler when a Trigger fires that is associated with the Job.
-	Execute(context JobExecutionContext)
+	//
+	// The returned error is reported as a JobExecutionException, return one directly to control the refire and unschedule behavior.
+	Execute(context JobExecutionContext) error
+}
+
+// JobFunc is an adapter to allow the use of ordinary functions as Job.
+type JobFunc func(context JobExecutionContext) error
+
+func (f JobFunc) Execute(context JobExecutionContext) error { return f(context) }
+
+// NewJobDetailFromFunc returns a JobDetail whose JobFactory always returns the function.
+func NewJobDetailFromFunc(name string, fn JobFunc) JobDetail {
+	return (&JobBuilder{}).WithIdentity(name).UsingJobFactory(&singletonJobFactory{fn}).Build()
+}
+
+// JobExecutionException is the failure of a Job execution,
+// it tells the Scheduler whether to refire the job immediately or to unschedule its triggers.
+type JobExecutionException struct {
+	Err error
+
+	RefireImmediately bool
+
+	UnscheduleFiringTrigger bool
+
+	UnscheduleAllTriggers bool
+}
+
+func NewJobExecutionException(err error) *JobExecutionException {
+	return &JobExecutionException{Err: err}
+}
+
+func (e *JobExecutionException) Error() string {
+	return fmt.Sprintf("Job threw an exception: %v", e.Err)
+}
+
+func (e *JobExecutionException) Unwrap() error { return e.Err }
+
+// asJobExecutionException converts the error returned by Job.Execute, it returns nil if the job succeeded.
+func asJobExecutionException(err error) *JobExecutionException {
+	if err == nil {
+		return nil
+	}
+
+	var jee *JobExecutionException
+
+	if errors.As(err, &jee) {
+		return jee
+	}
+
+	return NewJobExecutionException(err)
 }
 
 //
@@ -23,6 +74,10 @@ type Job interface {
 type JobExecutionContext interface {
 	Scheduler() Scheduler
 
+	// The context canceled when the scheduler is shutdown or the job is interrupted,
+	// the long running jobs should honor it and return as soon as it's done.
+	Context() context.Context
+
 	Trigger() Trigger
 
 	JobInstance() Job
@@ -43,6 +98,14 @@ type JobExecutionContext interface {
 
 	SetResult(interface{})
 
+	// The JobExecutionException of the execution, nil if the job didn't fail.
+	Exception() error
+
+	// The JobDataMap of the JobDetail overlaid with the one of the Trigger, the Trigger wins on the same key.
+	//
+	// The merged map is a copy for this execution. When the job persists its data after execution, the changed
+	// merged map replaces the JobDataMap of the JobDetail. Otherwise its changes are discarded, and the JobDataMap
+	// of the JobDetail is read-only.
 	MergedJobDataMap() JobDataMap
 
 	Put(key string, value interface{})
@@ -63,17 +126,46 @@ type JobDetail interface {
 
 	Durable() bool
 
+	// Whether the job should be re-executed if a 'recovery' or 'fail-over' situation is encountered.
+	RequestsRecovery() bool
+
+	// Whether the JobDataMap should be stored again after the job completed.
+	PersistJobDataAfterExecution() bool
+
+	// Whether the job must not be executed concurrently, its other triggers are blocked while it is executing.
+	ConcurrentExecutionDisallowed() bool
+
 	JobDataMap() JobDataMap
 
+	// The JobFactory to create the Job instance, nil if the Scheduler's JobFactory should be used.
+	JobFactory() JobFactory
+
+	// The RetryPolicy to retry the job when it failed, nil if the failed job shouldn't be retried.
+	RetryPolicy() *RetryPolicy
+
 	JobBuilder() *JobBuilder
 }
 
 type JobDataMap interface {
 	DirtyFlagMap
+
+	TypedDataMap
+
+	// CloneDataMap is Clone returning the JobDataMap.
+	CloneDataMap() JobDataMap
 }
 
+// JobFactory is responsible for producing the Job instance when a Trigger fired.
 type JobFactory interface {
-	NewJob(scheduler Scheduler) (Job, error)
+	NewJob(bundle *TriggerFiredBundle, scheduler Scheduler) (Job, error)
+}
+
+type singletonJobFactory struct {
+	job Job
+}
+
+func (f *singletonJobFactory) NewJob(bundle *TriggerFiredBundle, scheduler Scheduler) (Job, error) {
+	return f.job, nil
 }
 
 type JobKey []byte
@@ -86,7 +178,8 @@ func NewGroupJobKey(name, group string) JobKey {
 	return JobKey(fmt.Sprintf("%s.%s", group, name))
 }
 
-func NewUniqueKey(group string) JobKey {
+// NewUniqueJobKey creates a JobKey with a unique name in the group, or in the DEFAULT_GROUP if the group is empty.
+func NewUniqueJobKey(group string) JobKey {
 	if len(group) == 0 {
 		group = DEFAULT_GROUP
 	}
@@ -94,17 +187,29 @@ func NewUniqueKey(group string) JobKey {
 	return NewGroupJobKey(newUniqueName(group), group)
 }
 
+// NewUniqueKey creates a JobKey with a unique name in the group.
+//
+// Deprecated: the name doesn't tell the key is a JobKey, use NewUniqueJobKey, or NewUniqueTriggerKey for a trigger.
+func NewUniqueKey(group string) JobKey {
+	return NewUniqueJobKey(group)
+}
+
 func (key JobKey) Name() string             { return strings.Split(string(key), ".")[1] }
 func (key JobKey) Group() string            { return strings.Split(string(key), ".")[0] }
 func (key JobKey) String() string           { return string(key) }
 func (key JobKey) Equals(other JobKey) bool { return bytes.Equal(key, other) }
 
 type jobDetail struct {
-	key     JobKey
-	desc    string
-	durable bool
-	dataMap JobDataMap
-	builder *JobBuilder
+	key              JobKey
+	desc             string
+	durable          bool
+	requestsRecovery bool
+	persistJobData   bool
+	noConcurrent     bool
+	dataMap          JobDataMap
+	factory          JobFactory
+	retryPolicy      *RetryPolicy
+	builder          *JobBuilder
 }
 
 func (d *jobDetail) Key() JobKey { return d.key }
@@ -113,31 +218,47 @@ func (d *jobDetail) Description() string { return d.desc }
 
 func (d *jobDetail) Durable() bool { return d.durable }
 
+func (d *jobDetail) RequestsRecovery() bool { return d.requestsRecovery }
+
+func (d *jobDetail) PersistJobDataAfterExecution() bool { return d.persistJobData }
+
+func (d *jobDetail) ConcurrentExecutionDisallowed() bool { return d.noConcurrent }
+
 func (d *jobDetail) JobDataMap() JobDataMap { return d.dataMap }
 
+func (d *jobDetail) JobFactory() JobFactory { return d.factory }
+
+func (d *jobDetail) RetryPolicy() *RetryPolicy { return d.retryPolicy }
+
 func (d *jobDetail) JobBuilder() *JobBuilder { return d.builder }
 
 func (d *jobDetail) Clone() interface{} {
 	clone := *d
 
 	if d.dataMap != nil {
-		clone.dataMap = d.dataMap.Clone().(JobDataMap)
+		clone.dataMap = d.dataMap.CloneDataMap()
 	}
 
 	return &clone
 }
 
 func NewJobDataMap() JobDataMap {
-	return JobDataMap(NewDirtyFlagMap())
+	return &dirtyFlagMap{entries: make(map[string]interface{})}
 }
 
 //
 // JobBuilder is used to instantiate JobDetails.
 //
 type JobBuilder struct {
-	Key         JobKey
-	Description string
-	DataMap     JobDataMap
+	Key              JobKey
+	Description      string
+	Durable          bool
+	RequestsRecovery bool
+	PersistJobData   bool
+	NoConcurrent     bool
+	DataMap          JobDataMap
+	Factory          JobFactory
+	RetryPolicy      *RetryPolicy
 }
 
 func (b *JobBuilder) WithIdentity(name string) *JobBuilder {
@@ -164,6 +285,52 @@ func (b *JobBuilder) WithDescription(desc string) *JobBuilder {
 	return b
 }
 
+// StoreDurably sets whether the job should remain stored after it is orphaned (no triggers point to it).
+func (b *JobBuilder) StoreDurably(durable bool) *JobBuilder {
+	b.Durable = durable
+
+	return b
+}
+
+// RequestRecovery sets whether the job should be re-executed if a 'recovery' or 'fail-over' situation is encountered.
+func (b *JobBuilder) RequestRecovery(shouldRecover bool) *JobBuilder {
+	b.RequestsRecovery = shouldRecover
+
+	return b
+}
+
+// PersistJobDataAfterExecution sets whether the JobDataMap should be stored again after the job completed.
+func (b *JobBuilder) PersistJobDataAfterExecution(persist bool) *JobBuilder {
+	b.PersistJobData = persist
+
+	return b
+}
+
+// DisallowConcurrentExecution sets whether the job must not be executed concurrently.
+func (b *JobBuilder) DisallowConcurrentExecution(disallow bool) *JobBuilder {
+	b.NoConcurrent = disallow
+
+	return b
+}
+
+func (b *JobBuilder) UsingJobFactory(factory JobFactory) *JobBuilder {
+	b.Factory = factory
+
+	return b
+}
+
+// WithRetryPolicy sets the RetryPolicy to retry the job when it failed.
+func (b *JobBuilder) WithRetryPolicy(policy RetryPolicy) *JobBuilder {
+	b.RetryPolicy = &policy
+
+	return b
+}
+
+// OfType sets the registered job type which the DefaultJobFactory will instantiate.
+func (b *JobBuilder) OfType(name string) *JobBuilder {
+	return b.UsingJobData(JobClassKey, name)
+}
+
 func (b *JobBuilder) UsingJobData(key string, value interface{}) *JobBuilder {
 	if b.DataMap == nil {
 		b.DataMap = NewJobDataMap()
@@ -192,14 +359,20 @@ func (b *JobBuilder) SetJobDataMap(dataMap JobDataMap) *JobBuilder {
 
 func (b *JobBuilder) Build() JobDetail {
 	job := &jobDetail{
-		key:     b.Key,
-		desc:    b.Description,
-		dataMap: b.DataMap,
-		builder: b,
+		key:              b.Key,
+		desc:             b.Description,
+		durable:          b.Durable,
+		requestsRecovery: b.RequestsRecovery,
+		persistJobData:   b.PersistJobData,
+		noConcurrent:     b.NoConcurrent,
+		dataMap:          b.DataMap,
+		factory:          b.Factory,
+		retryPolicy:      b.RetryPolicy,
+		builder:          b,
 	}
 
 	if job.key == nil {
-		job.key = NewUniqueKey("")
+		job.key = NewUniqueJobKey("")
 	}
 
 	return job
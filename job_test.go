@@ -1,6 +1,7 @@
 package quartz
 
 import (
+	"errors"
 	"testing"
 
 	. "github.com/smartystreets/goconvey/convey"
@@ -24,12 +25,14 @@ func TestJobKey(t *testing.T) {
 	})
 
 	Convey("Given a uniqued JobKey", t, func(c C) {
-		key := NewUniqueKey("")
+		key := NewUniqueJobKey("")
 
 		c.Printf("unique key: %s", key)
 
+		So(key, ShouldHaveSameTypeAs, JobKey(nil))
 		So(len(key.Name()), ShouldEqual, 41)
 		So(key.Group(), ShouldEqual, DEFAULT_GROUP)
+		So(NewUniqueKey("group"), ShouldHaveSameTypeAs, JobKey(nil))
 	})
 }
 
@@ -115,6 +118,24 @@ func TestJobBuilder(t *testing.T) {
 			So(b.Build().Description(), ShouldEqual, "desc")
 		})
 
+		Convey("StoreDurably -> JobDetail.Durable()", func() {
+			So(b.Build().Durable(), ShouldBeFalse)
+
+			job := b.StoreDurably(true).Build()
+
+			So(job.Durable(), ShouldBeTrue)
+			So(job.Clone().(JobDetail).Durable(), ShouldBeTrue)
+		})
+
+		Convey("RequestRecovery -> JobDetail.RequestsRecovery()", func() {
+			So(b.Build().RequestsRecovery(), ShouldBeFalse)
+
+			job := b.RequestRecovery(true).Build()
+
+			So(job.RequestsRecovery(), ShouldBeTrue)
+			So(job.Clone().(JobDetail).RequestsRecovery(), ShouldBeTrue)
+		})
+
 		Convey("UsingJobData -> JobDetail.JobDataMap()", func() {
 			b.UsingJobData("key", "value")
 
@@ -144,3 +165,72 @@ func TestJobBuilder(t *testing.T) {
 		})
 	})
 }
+
+type errorJob struct {
+	err error
+}
+
+func (j *errorJob) Execute(context JobExecutionContext) error { return j.err }
+
+func TestJobExecutionException(t *testing.T) {
+	Convey("Given a job returns nil", t, func() {
+		var job Job = &errorJob{}
+
+		So(asJobExecutionException(job.Execute(nil)), ShouldBeNil)
+	})
+
+	Convey("Given a job returns an error", t, func() {
+		err := errors.New("failed")
+
+		var job Job = &errorJob{err}
+
+		jee := asJobExecutionException(job.Execute(nil))
+
+		So(jee, ShouldNotBeNil)
+		So(errors.Is(jee, err), ShouldBeTrue)
+		So(jee.Error(), ShouldEqual, "Job threw an exception: failed")
+		So(jee.RefireImmediately, ShouldBeFalse)
+	})
+
+	Convey("Given a job returns a JobExecutionException", t, func() {
+		err := &JobExecutionException{Err: errors.New("failed"), UnscheduleAllTriggers: true}
+
+		var job Job = &errorJob{err}
+
+		jee := asJobExecutionException(job.Execute(nil))
+
+		So(jee, ShouldEqual, err)
+		So(jee.UnscheduleAllTriggers, ShouldBeTrue)
+	})
+}
+
+func TestJobFunc(t *testing.T) {
+	Convey("Given a JobDetail from a function", t, func() {
+		var executed int
+
+		jobDetail := NewJobDetailFromFunc("cleanup", func(context JobExecutionContext) error {
+			executed++
+
+			return nil
+		})
+
+		So(jobDetail.Key().String(), ShouldEqual, "DEFAULT.cleanup")
+		So(jobDetail.JobFactory(), ShouldNotBeNil)
+
+		Convey("The job created by its factory should execute the function", func() {
+			job, err := jobDetail.JobFactory().NewJob(nil, nil)
+
+			So(err, ShouldBeNil)
+			So(job.Execute(nil), ShouldBeNil)
+			So(executed, ShouldEqual, 1)
+		})
+
+		Convey("The factory should survive the clone", func() {
+			job, err := jobDetail.Clone().(JobDetail).JobFactory().NewJob(nil, nil)
+
+			So(err, ShouldBeNil)
+			So(job.Execute(nil), ShouldBeNil)
+			So(executed, ShouldEqual, 1)
+		})
+	})
+}
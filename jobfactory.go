@@ -0,0 +1,66 @@
+package quartz
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// JobClassKey is the key of the JobDataMap which holds the registered job type of a JobDetail.
+const JobClassKey = "jobClass"
+
+// ErrUnknownJobType is returned when the job type of a JobDetail is not registered.
+var ErrUnknownJobType = errors.New("unknown job type")
+
+func unknownJobTypeError(key JobKey, name string) error {
+	return fmt.Errorf("The job (%s) has the %w '%s'.", key.String(), ErrUnknownJobType, name)
+}
+
+func missingJobTypeError(key JobKey) error {
+	return fmt.Errorf("The job (%s) has no '%s' in its JobDataMap.", key.String(), JobClassKey)
+}
+
+var (
+	jobTypesLock sync.RWMutex
+	jobTypes     = make(map[string]func() Job)
+)
+
+// RegisterJobType registers the constructor of a job type, which replaces the previous one with the same name.
+func RegisterJobType(name string, ctor func() Job) {
+	jobTypesLock.Lock()
+	defer jobTypesLock.Unlock()
+
+	jobTypes[name] = ctor
+}
+
+func lookupJobType(name string) func() Job {
+	jobTypesLock.RLock()
+	defer jobTypesLock.RUnlock()
+
+	return jobTypes[name]
+}
+
+// DefaultJobFactory creates a new Job instance of the type registered with the JobClassKey of the JobDataMap.
+type DefaultJobFactory struct{}
+
+func (f *DefaultJobFactory) NewJob(bundle *TriggerFiredBundle, scheduler Scheduler) (Job, error) {
+	jobDetail := bundle.JobDetail
+
+	var name string
+
+	if dataMap := jobDetail.JobDataMap(); dataMap != nil {
+		name, _ = dataMap.GetString(JobClassKey)
+	}
+
+	if name == "" {
+		return nil, missingJobTypeError(jobDetail.Key())
+	}
+
+	ctor := lookupJobType(name)
+
+	if ctor == nil {
+		return nil, unknownJobTypeError(jobDetail.Key(), name)
+	}
+
+	return ctor(), nil
+}
@@ -0,0 +1,94 @@
+package quartz
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type countingJob struct {
+	counter *int32
+}
+
+func (j *countingJob) Execute(context JobExecutionContext) error {
+	atomic.AddInt32(j.counter, 1)
+
+	return nil
+}
+
+func TestDefaultJobFactory(t *testing.T) {
+	Convey("Given a registered job type", t, func() {
+		var counter int32
+
+		RegisterJobType("counting", func() Job { return &countingJob{&counter} })
+
+		factory := &DefaultJobFactory{}
+
+		Convey("The factory should create a new job of the type", func() {
+			bundle := &TriggerFiredBundle{JobDetail: (&JobBuilder{}).WithIdentity("job").OfType("counting").Build()}
+
+			job, err := factory.NewJob(bundle, nil)
+
+			So(err, ShouldBeNil)
+			So(job, ShouldHaveSameTypeAs, &countingJob{})
+
+			other, err := factory.NewJob(bundle, nil)
+
+			So(err, ShouldBeNil)
+			So(other, ShouldNotPointTo, job)
+		})
+
+		Convey("The factory should fail with an unknown job type", func() {
+			bundle := &TriggerFiredBundle{JobDetail: (&JobBuilder{}).WithIdentity("job").OfType("unknown").Build()}
+
+			_, err := factory.NewJob(bundle, nil)
+
+			So(errors.Is(err, ErrUnknownJobType), ShouldBeTrue)
+			So(err.Error(), ShouldContainSubstring, "unknown")
+		})
+
+		Convey("The factory should fail without a job type", func() {
+			bundle := &TriggerFiredBundle{JobDetail: (&JobBuilder{}).WithIdentity("job").Build()}
+
+			_, err := factory.NewJob(bundle, nil)
+
+			So(err, ShouldNotBeNil)
+			So(errors.Is(err, ErrUnknownJobType), ShouldBeFalse)
+		})
+
+		Convey("The scheduler should fire the job of the type", func() {
+			scheduler := NewStdScheduler("scheduler", NewRAMJobStore(), NewSimpleThreadPool(1))
+
+			defer scheduler.Shutdown()
+
+			job := (&JobBuilder{}).WithIdentity("job").OfType("counting").Build()
+
+			_, err := scheduler.ScheduleJob(job, (&TriggerBuilder{}).StartNow().Build())
+
+			So(err, ShouldBeNil)
+			So(scheduler.Start(), ShouldBeNil)
+
+			So(waitFor(time.Second, func() bool { return atomic.LoadInt32(&counter) == 1 }), ShouldBeTrue)
+		})
+
+		Convey("The scheduler should use its JobFactory", func() {
+			scheduler := NewStdScheduler("scheduler", NewRAMJobStore(), NewSimpleThreadPool(1))
+
+			defer scheduler.Shutdown()
+
+			scheduler.SetJobFactory(&singletonJobFactory{&countingJob{&counter}})
+
+			job := (&JobBuilder{}).WithIdentity("job").Build()
+
+			_, err := scheduler.ScheduleJob(job, (&TriggerBuilder{}).StartNow().Build())
+
+			So(err, ShouldBeNil)
+			So(scheduler.Start(), ShouldBeNil)
+
+			So(waitFor(time.Second, func() bool { return atomic.LoadInt32(&counter) == 1 }), ShouldBeTrue)
+		})
+	})
+}
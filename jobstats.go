@@ -0,0 +1,84 @@
+package quartz
+
+import (
+	"sync"
+	"time"
+)
+
+func jobNotFoundError(key JobKey) error {
+	return &ErrJobNotFound{key}
+}
+
+// JobStats are the execution statistics of a job collected by the scheduler since it was created.
+type JobStats struct {
+	// The number of executions, including the failed ones.
+	Executions int
+
+	// The number of executions which returned an error.
+	Failures int
+
+	TotalRunTime time.Duration
+
+	AverageRunTime time.Duration
+
+	// The fire time of the last execution, zero if the job was never executed.
+	LastFireTime time.Time
+}
+
+// jobStatsCollector collects the JobStats of the jobs when they were executed.
+type jobStatsCollector struct {
+	lock  sync.Mutex
+	stats map[string]*JobStats
+}
+
+func newJobStatsCollector() *jobStatsCollector {
+	return &jobStatsCollector{stats: make(map[string]*JobStats)}
+}
+
+func (c *jobStatsCollector) JobWasExecuted(context JobExecutionContext, err error) {
+	key := context.JobDetail().Key().String()
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	stats, exists := c.stats[key]
+
+	if !exists {
+		stats = &JobStats{}
+		c.stats[key] = stats
+	}
+
+	stats.Executions++
+
+	if err != nil {
+		stats.Failures++
+	}
+
+	stats.TotalRunTime += context.JobRunTime()
+	stats.AverageRunTime = stats.TotalRunTime / time.Duration(stats.Executions)
+
+	if context.FireTime().After(stats.LastFireTime) {
+		stats.LastFireTime = context.FireTime()
+	}
+}
+
+// get returns a copy of the JobStats of the job, false if it was never executed.
+func (c *jobStatsCollector) get(key JobKey) (JobStats, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	stats, exists := c.stats[key.String()]
+
+	if !exists {
+		return JobStats{}, false
+	}
+
+	return *stats, true
+}
+
+func (c *jobStatsCollector) clear() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.stats = make(map[string]*JobStats)
+}
@@ -0,0 +1,86 @@
+package quartz
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestStdSchedulerGetJobStats(t *testing.T) {
+	Convey("Given a started StdScheduler with a durable job failing at its second execution", t, func() {
+		scheduler := NewStdScheduler("scheduler", NewRAMJobStore(), NewSimpleThreadPool(1))
+
+		defer scheduler.Shutdown()
+
+		So(scheduler.Start(), ShouldBeNil)
+
+		var counter int32
+
+		job := (&JobBuilder{}).
+			WithIdentity("job").
+			StoreDurably(true).
+			UsingJobFactory(&singletonJobFactory{JobFunc(func(context JobExecutionContext) error {
+				time.Sleep(10 * time.Millisecond)
+
+				if atomic.AddInt32(&counter, 1) == 2 {
+					return errors.New("failed")
+				}
+
+				return nil
+			})}).
+			Build()
+
+		So(scheduler.AddJob(job, false), ShouldBeNil)
+
+		Convey("The job never executed should have empty stats", func() {
+			stats, err := scheduler.GetJobStats(job.Key())
+
+			So(err, ShouldBeNil)
+			So(stats, ShouldResemble, JobStats{})
+		})
+
+		Convey("The stats should count the executions and their run time", func() {
+			startTime := time.Now()
+
+			for i := 0; i < 3; i++ {
+				So(scheduler.TriggerJob(job.Key()), ShouldBeNil)
+			}
+
+			So(waitFor(time.Second, func() bool {
+				stats, _ := scheduler.GetJobStats(job.Key())
+
+				return stats.Executions == 3
+			}), ShouldBeTrue)
+
+			stats, err := scheduler.GetJobStats(job.Key())
+
+			So(err, ShouldBeNil)
+			So(stats.Executions, ShouldEqual, 3)
+			So(stats.Failures, ShouldEqual, 1)
+			So(stats.TotalRunTime, ShouldBeGreaterThanOrEqualTo, 30*time.Millisecond)
+			So(stats.AverageRunTime, ShouldEqual, stats.TotalRunTime/3)
+			So(stats.AverageRunTime, ShouldBeGreaterThanOrEqualTo, 10*time.Millisecond)
+			So(stats.LastFireTime, ShouldHappenOnOrBetween, startTime, time.Now())
+
+			Convey("The stats should be reset when the scheduling data is cleared", func() {
+				So(scheduler.Clear(), ShouldBeNil)
+
+				_, err := scheduler.GetJobStats(job.Key())
+
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("The stats of a nonexistent job should not be found", func() {
+			_, err := scheduler.GetJobStats(NewJobKey("nonexists"))
+
+			var notFound *ErrJobNotFound
+
+			So(errors.As(err, &notFound), ShouldBeTrue)
+			So(notFound.Key, ShouldResemble, NewJobKey("nonexists"))
+		})
+	})
+}
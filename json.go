@@ -0,0 +1,364 @@
+package quartz
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// The types of the triggers in their JSON representation.
+const (
+	SimpleTriggerType         = "simple"
+	JitterTriggerType         = "jitter"
+	NthIncludedDayTriggerType = "nthIncludedDay"
+)
+
+func unknownTriggerTypeError(typ string) error {
+	return fmt.Errorf("The trigger type '%s' is unknown.", typ)
+}
+
+func unexpectedTriggerTypeError(typ, expected string) error {
+	return fmt.Errorf("The trigger type '%s' is not the expected '%s'.", typ, expected)
+}
+
+// TriggerDTO is the JSON representation of a Trigger, Type tells which concrete trigger to reconstruct.
+//
+// The jitter trigger only has MaxJitter and Seed, the trigger it delays is Base.
+// The NthIncludedDay trigger has N, IntervalType and FireAtTime, its calendar is the one of CalendarName.
+type TriggerDTO struct {
+	Type             string                     `json:"type"`
+	Key              string                     `json:"key,omitempty"`
+	JobKey           string                     `json:"jobKey,omitempty"`
+	Description      string                     `json:"description,omitempty"`
+	Priority         int                        `json:"priority,omitempty"`
+	JobData          map[string]interface{}     `json:"jobData,omitempty"`
+	StartTime        *time.Time                 `json:"startTime,omitempty"`
+	EndTime          *time.Time                 `json:"endTime,omitempty"`
+	NextFireTime     *time.Time                 `json:"nextFireTime,omitempty"`
+	PreviousFireTime *time.Time                 `json:"previousFireTime,omitempty"`
+	TimeZone         string                     `json:"timeZone,omitempty"`
+	CalendarName     string                     `json:"calendarName,omitempty"`
+	RepeatInterval   time.Duration              `json:"repeatInterval,omitempty"`
+	RepeatCount      int                        `json:"repeatCount,omitempty"`
+	TimesTriggered   int                        `json:"timesTriggered,omitempty"`
+	Complete         bool                       `json:"complete,omitempty"`
+	N                int                        `json:"n,omitempty"`
+	IntervalType     NthIncludedDayIntervalType `json:"intervalType,omitempty"`
+	FireAtTime       *TimeOfDay                 `json:"fireAtTime,omitempty"`
+	MaxJitter        time.Duration              `json:"maxJitter,omitempty"`
+	Seed             int64                      `json:"seed,omitempty"`
+	Base             *TriggerDTO                `json:"base,omitempty"`
+}
+
+// NewTriggerDTO returns the JSON representation of the trigger,
+// only the simple, jitter and NthIncludedDay triggers are supported.
+func NewTriggerDTO(trigger Trigger) (*TriggerDTO, error) {
+	switch t := trigger.(type) {
+	case *simpleTrigger, *nthIncludedDayTrigger:
+		r, err := newTriggerRecord(t.(OperableTrigger))
+
+		if err != nil {
+			return nil, err
+		}
+
+		dto := &TriggerDTO{
+			Type:             r.Type,
+			Key:              r.Key,
+			JobKey:           r.JobKey,
+			Description:      r.Description,
+			Priority:         r.Priority,
+			JobData:          dataMapEntries(t.(OperableTrigger).JobDataMap()),
+			StartTime:        jsonTime(r.StartTime),
+			EndTime:          jsonTime(r.EndTime),
+			NextFireTime:     jsonTime(r.NextFireTime),
+			PreviousFireTime: jsonTime(r.PreviousFireTime),
+			TimeZone:         r.TimeZone,
+			CalendarName:     r.CalendarName,
+			RepeatInterval:   r.RepeatInterval,
+			RepeatCount:      r.RepeatCount,
+			TimesTriggered:   r.TimesTriggered,
+			Complete:         r.Complete,
+		}
+
+		if r.Type == NthIncludedDayTriggerType {
+			dto.N = r.N
+			dto.IntervalType = r.IntervalType
+			dto.FireAtTime = &r.FireAtTime
+		}
+
+		return dto, nil
+
+	case *jitterTrigger:
+		base, err := NewTriggerDTO(t.OperableTrigger)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &TriggerDTO{Type: JitterTriggerType, MaxJitter: t.maxJitter, Seed: t.seed, Base: base}, nil
+
+	default:
+		return nil, unsupportedTriggerError(trigger)
+	}
+}
+
+// Trigger reconstructs the concrete trigger of the Type.
+func (dto *TriggerDTO) Trigger() (OperableTrigger, error) {
+	switch dto.Type {
+	case SimpleTriggerType, NthIncludedDayTriggerType:
+		if dto.Key == "" {
+			return nil, errors.New("Trigger's name cannot be null")
+		}
+
+		if dto.JobKey == "" {
+			return nil, errors.New("Trigger's related Job's name cannot be null")
+		}
+
+		r := &triggerRecord{
+			Type:             dto.Type,
+			Key:              dto.Key,
+			JobKey:           dto.JobKey,
+			Description:      dto.Description,
+			Priority:         dto.Priority,
+			StartTime:        timeOf(dto.StartTime),
+			EndTime:          timeOf(dto.EndTime),
+			NextFireTime:     timeOf(dto.NextFireTime),
+			PreviousFireTime: timeOf(dto.PreviousFireTime),
+			TimeZone:         dto.TimeZone,
+			CalendarName:     dto.CalendarName,
+			RepeatInterval:   dto.RepeatInterval,
+			RepeatCount:      dto.RepeatCount,
+			TimesTriggered:   dto.TimesTriggered,
+			Complete:         dto.Complete,
+		}
+
+		if dto.Type == NthIncludedDayTriggerType {
+			r.N = dto.N
+			r.IntervalType = dto.IntervalType
+			r.FireAtTime = DefaultNthIncludedDayFireAtTime
+
+			if dto.FireAtTime != nil {
+				r.FireAtTime = *dto.FireAtTime
+			}
+
+			if err := validateNthIncludedDay(r.N, r.IntervalType); err != nil {
+				return nil, err
+			}
+		}
+
+		if dto.JobData != nil {
+			r.JobData = fromJSONNumber(dto.JobData).(map[string]interface{})
+		}
+
+		return r.trigger(), nil
+
+	case JitterTriggerType:
+		if dto.Base == nil {
+			return nil, errors.New("The jitter trigger has no base trigger.")
+		}
+
+		base, err := dto.Base.Trigger()
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &jitterTrigger{base, dto.MaxJitter, dto.Seed}, nil
+
+	default:
+		return nil, unknownTriggerTypeError(dto.Type)
+	}
+}
+
+// MarshalTrigger serializes the trigger to JSON with a "type" field telling its concrete type.
+func MarshalTrigger(trigger Trigger) ([]byte, error) {
+	dto, err := NewTriggerDTO(trigger)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(dto)
+}
+
+// UnmarshalTrigger reconstructs the trigger serialized by MarshalTrigger.
+//
+// The integral numbers of the JobDataMap are decoded as int64, the other numbers as float64.
+func UnmarshalTrigger(data []byte) (OperableTrigger, error) {
+	var dto TriggerDTO
+
+	if err := decodeJSON(data, &dto); err != nil {
+		return nil, err
+	}
+
+	return dto.Trigger()
+}
+
+func (t *simpleTrigger) MarshalJSON() ([]byte, error) { return MarshalTrigger(t) }
+
+func (t *simpleTrigger) UnmarshalJSON(data []byte) error {
+	trigger, err := UnmarshalTrigger(data)
+
+	if err != nil {
+		return err
+	}
+
+	st, ok := trigger.(*simpleTrigger)
+
+	if !ok {
+		return unexpectedTriggerTypeError(jsonTriggerType(trigger), SimpleTriggerType)
+	}
+
+	*t = *st
+
+	return nil
+}
+
+func (t *jitterTrigger) MarshalJSON() ([]byte, error) { return MarshalTrigger(t) }
+
+func (t *jitterTrigger) UnmarshalJSON(data []byte) error {
+	trigger, err := UnmarshalTrigger(data)
+
+	if err != nil {
+		return err
+	}
+
+	jt, ok := trigger.(*jitterTrigger)
+
+	if !ok {
+		return unexpectedTriggerTypeError(jsonTriggerType(trigger), JitterTriggerType)
+	}
+
+	*t = *jt
+
+	return nil
+}
+
+func (t *nthIncludedDayTrigger) MarshalJSON() ([]byte, error) { return MarshalTrigger(t) }
+
+func (t *nthIncludedDayTrigger) UnmarshalJSON(data []byte) error {
+	trigger, err := UnmarshalTrigger(data)
+
+	if err != nil {
+		return err
+	}
+
+	nt, ok := trigger.(*nthIncludedDayTrigger)
+
+	if !ok {
+		return unexpectedTriggerTypeError(jsonTriggerType(trigger), NthIncludedDayTriggerType)
+	}
+
+	*t = *nt
+
+	return nil
+}
+
+func jsonTriggerType(trigger Trigger) string {
+	switch trigger.(type) {
+	case *jitterTrigger:
+		return JitterTriggerType
+	case *nthIncludedDayTrigger:
+		return NthIncludedDayTriggerType
+	default:
+		return SimpleTriggerType
+	}
+}
+
+// JobDetailDTO is the JSON representation of a JobDetail, the JobFactory is not serialized.
+type JobDetailDTO struct {
+	Key              string                 `json:"key"`
+	Description      string                 `json:"description,omitempty"`
+	Durable          bool                   `json:"durable,omitempty"`
+	RequestsRecovery bool                   `json:"requestsRecovery,omitempty"`
+	PersistJobData   bool                   `json:"persistJobData,omitempty"`
+	NoConcurrent     bool                   `json:"noConcurrent,omitempty"`
+	JobData          map[string]interface{} `json:"jobData,omitempty"`
+	RetryPolicy      *RetryPolicy           `json:"retryPolicy,omitempty"`
+}
+
+// NewJobDetailDTO returns the JSON representation of the job.
+func NewJobDetailDTO(job JobDetail) *JobDetailDTO {
+	r := newJobRecord(job)
+
+	return &JobDetailDTO{
+		Key:              r.Key,
+		Description:      r.Description,
+		Durable:          r.Durable,
+		RequestsRecovery: r.RequestsRecovery,
+		PersistJobData:   r.PersistJobData,
+		NoConcurrent:     r.NoConcurrent,
+		JobData:          dataMapEntries(job.JobDataMap()),
+		RetryPolicy:      r.RetryPolicy,
+	}
+}
+
+// JobDetail reconstructs the job, which should be created by the DefaultJobFactory from its registered type.
+func (dto *JobDetailDTO) JobDetail() (JobDetail, error) {
+	if dto.Key == "" {
+		return nil, errors.New("Job's name cannot be null")
+	}
+
+	r := &jobRecord{
+		Key:              dto.Key,
+		Description:      dto.Description,
+		Durable:          dto.Durable,
+		RequestsRecovery: dto.RequestsRecovery,
+		PersistJobData:   dto.PersistJobData,
+		NoConcurrent:     dto.NoConcurrent,
+		RetryPolicy:      dto.RetryPolicy,
+	}
+
+	if dto.JobData != nil {
+		r.JobData = fromJSONNumber(dto.JobData).(map[string]interface{})
+	}
+
+	return r.jobDetail(), nil
+}
+
+func (d *jobDetail) MarshalJSON() ([]byte, error) { return json.Marshal(NewJobDetailDTO(d)) }
+
+func (d *jobDetail) UnmarshalJSON(data []byte) error {
+	var dto JobDetailDTO
+
+	if err := decodeJSON(data, &dto); err != nil {
+		return err
+	}
+
+	job, err := dto.JobDetail()
+
+	if err != nil {
+		return err
+	}
+
+	*d = *job.(*jobDetail)
+
+	return nil
+}
+
+// decodeJSON decodes the numbers as json.Number, to be converted by fromJSONNumber.
+func decodeJSON(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	return dec.Decode(v)
+}
+
+// jsonTime returns nil for the zero time to omit it.
+func jsonTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
+
+func timeOf(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+
+	return *t
+}
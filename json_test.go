@@ -0,0 +1,152 @@
+package quartz
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTriggerJSON(t *testing.T) {
+	Convey("Given the triggers of each kind", t, func() {
+		startTime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		loc, _ := time.LoadLocation("Asia/Shanghai")
+
+		newTrigger := func(scheduleBuilder ScheduleBuilder) OperableTrigger {
+			trigger := (&TriggerBuilder{}).
+				WithGroupIdentity("trigger", "group").
+				WithDescription("desc").
+				WithPriority(7).
+				ForGroupJob("job", "group").
+				StartAt(startTime).
+				InTimeZone(loc).
+				ModifiedByCalendar("holidays").
+				UsingJobData("str", "value").
+				UsingJobData("int", 123).
+				WithSchedule(scheduleBuilder).
+				Build().(OperableTrigger)
+
+			trigger.ComputeFirstFireTime(nil)
+			trigger.Triggered(nil)
+
+			return trigger
+		}
+
+		for typ, trigger := range map[string]OperableTrigger{
+			SimpleTriggerType:         newTrigger(&SimpleScheduleBuilder{time.Minute, 9}),
+			JitterTriggerType:         newTrigger(WithJitter(&SimpleScheduleBuilder{time.Minute, 9}, 10*time.Second, 42)),
+			NthIncludedDayTriggerType: newTrigger(NthIncludedDay(3, INTERVAL_TYPE_MONTHLY).AtTimeOfDay(TimeOfDay{Hour: 9})),
+		} {
+			Convey("When marshals the "+typ+" trigger", func() {
+				data, err := MarshalTrigger(trigger)
+
+				So(err, ShouldBeNil)
+
+				var fields map[string]interface{}
+
+				So(json.Unmarshal(data, &fields), ShouldBeNil)
+				So(fields["type"], ShouldEqual, typ)
+
+				Convey("The unmarshaled trigger should be the same", func() {
+					other, err := UnmarshalTrigger(data)
+
+					So(err, ShouldBeNil)
+					So(other, ShouldHaveSameTypeAs, trigger)
+					So(other.Key(), ShouldResemble, trigger.Key())
+					So(other.JobKey(), ShouldResemble, trigger.JobKey())
+					So(other.Description(), ShouldEqual, "desc")
+					So(other.Priority(), ShouldEqual, 7)
+					So(other.CalendarName(), ShouldEqual, "holidays")
+					So(other.TimeZone().String(), ShouldEqual, loc.String())
+					So(other.JobDataMap().Get("str"), ShouldEqual, "value")
+					So(other.JobDataMap().GetIntOr("int", 0), ShouldEqual, 123)
+					So(other.StartTime().Equal(startTime), ShouldBeTrue)
+					So(other.EndTime().IsZero(), ShouldBeTrue)
+					So(other.NextFireTime().Equal(trigger.NextFireTime()), ShouldBeTrue)
+					So(other.PreviousFireTime().Equal(trigger.PreviousFireTime()), ShouldBeTrue)
+					So(other.FireTimeAfter(startTime).Equal(trigger.FireTimeAfter(startTime)), ShouldBeTrue)
+				})
+
+				Convey("The trigger should be unmarshaled by encoding/json", func() {
+					other := trigger.Clone().(OperableTrigger)
+
+					So(json.Unmarshal(data, other), ShouldBeNil)
+					So(other.NextFireTime().Equal(trigger.NextFireTime()), ShouldBeTrue)
+				})
+			})
+		}
+
+		Convey("When unmarshals a trigger of another kind", func() {
+			data, err := json.Marshal(newTrigger(&SimpleScheduleBuilder{time.Minute, 9}))
+
+			So(err, ShouldBeNil)
+
+			err = json.Unmarshal(data, &jitterTrigger{})
+
+			Convey("It should fail", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+
+	Convey("Given the invalid JSON of triggers", t, func() {
+		for _, data := range []string{
+			`{"type":"cron","key":"group.trigger","jobKey":"group.job"}`,
+			`{"type":"simple","jobKey":"group.job"}`,
+			`{"type":"simple","key":"group.trigger"}`,
+			`{"type":"jitter"}`,
+			`{"type":"nthIncludedDay","key":"group.trigger","jobKey":"group.job","intervalType":2}`,
+			`{"type":"simple"`,
+		} {
+			_, err := UnmarshalTrigger([]byte(data))
+
+			So(err, ShouldNotBeNil)
+		}
+	})
+}
+
+func TestJobDetailJSON(t *testing.T) {
+	Convey("Given a job", t, func() {
+		job := (&JobBuilder{}).
+			WithGroupIdentity("job", "group").
+			WithDescription("desc").
+			StoreDurably(true).
+			RequestRecovery(true).
+			PersistJobDataAfterExecution(true).
+			DisallowConcurrentExecution(true).
+			WithRetryPolicy(RetryPolicy{MaxAttempts: 3, InitialDelay: time.Second}).
+			OfType("test").
+			UsingJobData("int", 123).
+			Build()
+
+		Convey("When marshals it to JSON", func() {
+			data, err := json.Marshal(job)
+
+			So(err, ShouldBeNil)
+
+			Convey("The unmarshaled job should be the same", func() {
+				other := &jobDetail{}
+
+				So(json.Unmarshal(data, other), ShouldBeNil)
+				So(other.Key(), ShouldResemble, job.Key())
+				So(other.Description(), ShouldEqual, "desc")
+				So(other.Durable(), ShouldBeTrue)
+				So(other.RequestsRecovery(), ShouldBeTrue)
+				So(other.PersistJobDataAfterExecution(), ShouldBeTrue)
+				So(other.ConcurrentExecutionDisallowed(), ShouldBeTrue)
+				So(other.RetryPolicy(), ShouldResemble, job.RetryPolicy())
+				So(other.JobDataMap().GetStringOr(JobClassKey, ""), ShouldEqual, "test")
+				So(other.JobDataMap().Get("int"), ShouldEqual, int64(123))
+			})
+		})
+
+		Convey("When unmarshals a job without key", func() {
+			err := json.Unmarshal([]byte(`{"description":"desc"}`), &jobDetail{})
+
+			Convey("It should fail", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
@@ -0,0 +1,430 @@
+package quartz
+
+import (
+	"fmt"
+	"sync"
+)
+
+// JobListener is notified when a Job is executed.
+type JobListener interface {
+	Name() string
+
+	// Called when a JobDetail is about to be executed.
+	JobToBeExecuted(context JobExecutionContext)
+
+	// Called when a JobDetail was about to be executed but a TriggerListener vetoed its execution.
+	JobExecutionVetoed(context JobExecutionContext)
+
+	// Called after a JobDetail has been executed, err is the error returned by the Job.
+	JobWasExecuted(context JobExecutionContext, err error)
+}
+
+// TriggerListener is notified when a Trigger fires.
+type TriggerListener interface {
+	Name() string
+
+	// Called when a Trigger has fired, and its Job is about to be executed.
+	TriggerFired(trigger Trigger, context JobExecutionContext)
+
+	// Called right after TriggerFired, the job execution is skipped if any listener returns true.
+	VetoJobExecution(trigger Trigger, context JobExecutionContext) bool
+
+	// Called when a Trigger has misfired.
+	TriggerMisfired(trigger Trigger)
+
+	// Called when a Trigger has fired, its Job has been executed, and the instruction has been decided.
+	TriggerComplete(trigger Trigger, context JobExecutionContext, instruction CompletedExecutionInstruction)
+}
+
+// SchedulerListener is notified of the major events of a Scheduler.
+type SchedulerListener interface {
+	JobScheduled(trigger Trigger)
+
+	JobUnscheduled(key TriggerKey)
+
+	// Called when a Trigger has reached the condition in which it will never fire again.
+	TriggerFinalized(trigger Trigger)
+
+	TriggerPaused(key TriggerKey)
+
+	// Called when a group of Trigger has been paused, group is empty if all the groups are paused.
+	TriggersPaused(group string)
+
+	TriggerResumed(key TriggerKey)
+
+	// Called when a group of Trigger has been resumed, group is empty if all the groups are resumed.
+	TriggersResumed(group string)
+
+	JobAdded(jobDetail JobDetail)
+
+	JobDeleted(key JobKey)
+
+	JobPaused(key JobKey)
+
+	// Called when a group of Job has been paused.
+	JobsPaused(group string)
+
+	JobResumed(key JobKey)
+
+	// Called when a group of Job has been resumed.
+	JobsResumed(group string)
+
+	// Called when a serious error has occurred within the Scheduler.
+	SchedulerError(msg string, err error)
+
+	SchedulerInStandbyMode()
+
+	SchedulerStarting()
+
+	SchedulerStarted()
+
+	SchedulerShuttingdown()
+
+	SchedulerShutdown()
+
+	SchedulingDataCleared()
+}
+
+// JobListenerSupport is a no-op JobListener to be embedded, which only overrides the interesting methods.
+type JobListenerSupport struct{}
+
+func (JobListenerSupport) JobToBeExecuted(context JobExecutionContext) {}
+
+func (JobListenerSupport) JobExecutionVetoed(context JobExecutionContext) {}
+
+func (JobListenerSupport) JobWasExecuted(context JobExecutionContext, err error) {}
+
+// TriggerListenerSupport is a no-op TriggerListener to be embedded, which only overrides the interesting methods.
+type TriggerListenerSupport struct{}
+
+func (TriggerListenerSupport) TriggerFired(trigger Trigger, context JobExecutionContext) {}
+
+func (TriggerListenerSupport) VetoJobExecution(trigger Trigger, context JobExecutionContext) bool {
+	return false
+}
+
+func (TriggerListenerSupport) TriggerMisfired(trigger Trigger) {}
+
+func (TriggerListenerSupport) TriggerComplete(trigger Trigger, context JobExecutionContext, instruction CompletedExecutionInstruction) {
+}
+
+// SchedulerListenerSupport is a no-op SchedulerListener to be embedded, which only overrides the interesting methods.
+type SchedulerListenerSupport struct{}
+
+func (SchedulerListenerSupport) JobScheduled(trigger Trigger) {}
+
+func (SchedulerListenerSupport) JobUnscheduled(key TriggerKey) {}
+
+func (SchedulerListenerSupport) TriggerFinalized(trigger Trigger) {}
+
+func (SchedulerListenerSupport) TriggerPaused(key TriggerKey) {}
+
+func (SchedulerListenerSupport) TriggersPaused(group string) {}
+
+func (SchedulerListenerSupport) TriggerResumed(key TriggerKey) {}
+
+func (SchedulerListenerSupport) TriggersResumed(group string) {}
+
+func (SchedulerListenerSupport) JobAdded(jobDetail JobDetail) {}
+
+func (SchedulerListenerSupport) JobDeleted(key JobKey) {}
+
+func (SchedulerListenerSupport) JobPaused(key JobKey) {}
+
+func (SchedulerListenerSupport) JobsPaused(group string) {}
+
+func (SchedulerListenerSupport) JobResumed(key JobKey) {}
+
+func (SchedulerListenerSupport) JobsResumed(group string) {}
+
+func (SchedulerListenerSupport) SchedulerError(msg string, err error) {}
+
+func (SchedulerListenerSupport) SchedulerInStandbyMode() {}
+
+func (SchedulerListenerSupport) SchedulerStarting() {}
+
+func (SchedulerListenerSupport) SchedulerStarted() {}
+
+func (SchedulerListenerSupport) SchedulerShuttingdown() {}
+
+func (SchedulerListenerSupport) SchedulerShutdown() {}
+
+func (SchedulerListenerSupport) SchedulingDataCleared() {}
+
+// ListenerManager manages the listeners of a Scheduler, the listeners are notified in the order they were added.
+type ListenerManager interface {
+	// Add the JobListener interested in the jobs matched by any of the matchers, or all the jobs without matcher.
+	// It replaces the listener with the same name.
+	AddJobListener(listener JobListener, matchers ...GroupMatcher)
+
+	JobListener(name string) JobListener
+
+	JobListenerMatchers(name string) []GroupMatcher
+
+	JobListeners() []JobListener
+
+	RemoveJobListener(name string) bool
+
+	// Add the TriggerListener interested in the triggers matched by any of the matchers, or all the triggers without matcher.
+	// It replaces the listener with the same name.
+	AddTriggerListener(listener TriggerListener, matchers ...GroupMatcher)
+
+	TriggerListener(name string) TriggerListener
+
+	TriggerListenerMatchers(name string) []GroupMatcher
+
+	TriggerListeners() []TriggerListener
+
+	RemoveTriggerListener(name string) bool
+
+	AddSchedulerListener(listener SchedulerListener)
+
+	SchedulerListeners() []SchedulerListener
+
+	RemoveSchedulerListener(listener SchedulerListener) bool
+}
+
+type listenerManager struct {
+	lock                    sync.RWMutex
+	jobListeners            []JobListener
+	jobListenerMatchers     map[string][]GroupMatcher
+	triggerListeners        []TriggerListener
+	triggerListenerMatchers map[string][]GroupMatcher
+	schedulerListeners      []SchedulerListener
+	logger                  Logger
+}
+
+func newListenerManager() *listenerManager {
+	return &listenerManager{
+		jobListenerMatchers:     make(map[string][]GroupMatcher),
+		triggerListenerMatchers: make(map[string][]GroupMatcher),
+		logger:                  NoopLogger{},
+	}
+}
+
+func (m *listenerManager) setLogger(logger Logger) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.logger = logger
+}
+
+func (m *listenerManager) AddJobListener(listener JobListener, matchers ...GroupMatcher) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.jobListenerMatchers[listener.Name()] = append([]GroupMatcher(nil), matchers...)
+
+	for i, l := range m.jobListeners {
+		if l.Name() == listener.Name() {
+			m.jobListeners[i] = listener
+
+			return
+		}
+	}
+
+	m.jobListeners = append(m.jobListeners, listener)
+}
+
+func (m *listenerManager) JobListener(name string) JobListener {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	for _, l := range m.jobListeners {
+		if l.Name() == name {
+			return l
+		}
+	}
+
+	return nil
+}
+
+func (m *listenerManager) JobListenerMatchers(name string) []GroupMatcher {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	return append([]GroupMatcher(nil), m.jobListenerMatchers[name]...)
+}
+
+func (m *listenerManager) JobListeners() []JobListener {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	return append([]JobListener(nil), m.jobListeners...)
+}
+
+func (m *listenerManager) RemoveJobListener(name string) bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for i, l := range m.jobListeners {
+		if l.Name() == name {
+			m.jobListeners = append(m.jobListeners[:i:i], m.jobListeners[i+1:]...)
+
+			delete(m.jobListenerMatchers, name)
+
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *listenerManager) AddTriggerListener(listener TriggerListener, matchers ...GroupMatcher) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.triggerListenerMatchers[listener.Name()] = append([]GroupMatcher(nil), matchers...)
+
+	for i, l := range m.triggerListeners {
+		if l.Name() == listener.Name() {
+			m.triggerListeners[i] = listener
+
+			return
+		}
+	}
+
+	m.triggerListeners = append(m.triggerListeners, listener)
+}
+
+func (m *listenerManager) TriggerListener(name string) TriggerListener {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	for _, l := range m.triggerListeners {
+		if l.Name() == name {
+			return l
+		}
+	}
+
+	return nil
+}
+
+func (m *listenerManager) TriggerListenerMatchers(name string) []GroupMatcher {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	return append([]GroupMatcher(nil), m.triggerListenerMatchers[name]...)
+}
+
+func (m *listenerManager) TriggerListeners() []TriggerListener {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	return append([]TriggerListener(nil), m.triggerListeners...)
+}
+
+func (m *listenerManager) RemoveTriggerListener(name string) bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for i, l := range m.triggerListeners {
+		if l.Name() == name {
+			m.triggerListeners = append(m.triggerListeners[:i:i], m.triggerListeners[i+1:]...)
+
+			delete(m.triggerListenerMatchers, name)
+
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *listenerManager) AddSchedulerListener(listener SchedulerListener) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.schedulerListeners = append(m.schedulerListeners, listener)
+}
+
+func (m *listenerManager) SchedulerListeners() []SchedulerListener {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	return append([]SchedulerListener(nil), m.schedulerListeners...)
+}
+
+func (m *listenerManager) RemoveSchedulerListener(listener SchedulerListener) bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for i, l := range m.schedulerListeners {
+		if l == listener {
+			m.schedulerListeners = append(m.schedulerListeners[:i:i], m.schedulerListeners[i+1:]...)
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// The listeners are notified without holding the lock, so they may add or remove listeners.
+//
+// A panicking listener is logged and doesn't stop the other listeners nor the caller,
+// so the execution of a job always completes in the JobStore.
+
+func (m *listenerManager) notifyJobListeners(key JobKey, notify func(l JobListener)) {
+	m.lock.RLock()
+
+	logger := m.logger
+
+	var listeners []JobListener
+
+	for _, l := range m.jobListeners {
+		if matchesAny(m.jobListenerMatchers[l.Name()], key) {
+			listeners = append(listeners, l)
+		}
+	}
+
+	m.lock.RUnlock()
+
+	for _, l := range listeners {
+		notifyListener(logger, l.Name(), func() { notify(l) })
+	}
+}
+
+func (m *listenerManager) notifyTriggerListeners(key TriggerKey, notify func(l TriggerListener)) {
+	m.lock.RLock()
+
+	logger := m.logger
+
+	var listeners []TriggerListener
+
+	for _, l := range m.triggerListeners {
+		if matchesAny(m.triggerListenerMatchers[l.Name()], key) {
+			listeners = append(listeners, l)
+		}
+	}
+
+	m.lock.RUnlock()
+
+	for _, l := range listeners {
+		notifyListener(logger, l.Name(), func() { notify(l) })
+	}
+}
+
+func (m *listenerManager) notifySchedulerListeners(notify func(l SchedulerListener)) {
+	m.lock.RLock()
+
+	logger := m.logger
+	listeners := append([]SchedulerListener(nil), m.schedulerListeners...)
+
+	m.lock.RUnlock()
+
+	for _, l := range listeners {
+		notifyListener(logger, fmt.Sprintf("%T", l), func() { notify(l) })
+	}
+}
+
+// notifyListener notifies the listener, a panic is logged as an error.
+func notifyListener(logger Logger, name string, notify func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("Listener panicked.", "listener", name, "panic", r)
+		}
+	}()
+
+	notify()
+}
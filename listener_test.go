@@ -0,0 +1,422 @@
+package quartz
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type recordingListener struct {
+	JobListenerSupport
+	TriggerListenerSupport
+	SchedulerListenerSupport
+
+	name   string
+	lock   sync.Mutex
+	events []string
+}
+
+func (l *recordingListener) Name() string { return l.name }
+
+func (l *recordingListener) record(event string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.events = append(l.events, event)
+}
+
+func (l *recordingListener) Events() []string {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	return append([]string(nil), l.events...)
+}
+
+func (l *recordingListener) JobToBeExecuted(context JobExecutionContext) {
+	l.record("JobToBeExecuted")
+}
+
+func (l *recordingListener) JobExecutionVetoed(context JobExecutionContext) {
+	l.record("JobExecutionVetoed")
+}
+
+func (l *recordingListener) JobWasExecuted(context JobExecutionContext, err error) {
+	if err != nil {
+		l.record("JobWasExecuted: " + err.Error())
+	} else {
+		l.record("JobWasExecuted")
+	}
+}
+
+func (l *recordingListener) TriggerFired(trigger Trigger, context JobExecutionContext) {
+	l.record("TriggerFired")
+}
+
+func (l *recordingListener) TriggerComplete(trigger Trigger, context JobExecutionContext, instruction CompletedExecutionInstruction) {
+	l.record("TriggerComplete")
+}
+
+func (l *recordingListener) TriggerMisfired(trigger Trigger) {
+	l.record("TriggerMisfired")
+}
+
+func (l *recordingListener) JobAdded(jobDetail JobDetail) { l.record("JobAdded") }
+
+func (l *recordingListener) JobScheduled(trigger Trigger) { l.record("JobScheduled") }
+
+func (l *recordingListener) JobUnscheduled(key TriggerKey) { l.record("JobUnscheduled") }
+
+func (l *recordingListener) JobDeleted(key JobKey) { l.record("JobDeleted") }
+
+func (l *recordingListener) TriggerFinalized(trigger Trigger) { l.record("TriggerFinalized") }
+
+func (l *recordingListener) TriggerPaused(key TriggerKey) { l.record("TriggerPaused") }
+
+func (l *recordingListener) TriggerResumed(key TriggerKey) { l.record("TriggerResumed") }
+
+func (l *recordingListener) SchedulerError(msg string, err error) { l.record("SchedulerError") }
+
+func (l *recordingListener) SchedulerInStandbyMode() { l.record("SchedulerInStandbyMode") }
+
+func (l *recordingListener) SchedulerStarting() { l.record("SchedulerStarting") }
+
+func (l *recordingListener) SchedulerStarted() { l.record("SchedulerStarted") }
+
+func (l *recordingListener) SchedulerShuttingdown() { l.record("SchedulerShuttingdown") }
+
+func (l *recordingListener) SchedulerShutdown() { l.record("SchedulerShutdown") }
+
+func (l *recordingListener) SchedulingDataCleared() { l.record("SchedulingDataCleared") }
+
+func TestListenerManager(t *testing.T) {
+	Convey("Given a ListenerManager", t, func() {
+		var m ListenerManager = newListenerManager()
+
+		first := &recordingListener{name: "first"}
+		second := &recordingListener{name: "second"}
+
+		m.AddJobListener(first)
+		m.AddJobListener(second)
+		m.AddTriggerListener(first)
+		m.AddSchedulerListener(first)
+		m.AddSchedulerListener(second)
+
+		So(m.JobListeners(), ShouldResemble, []JobListener{first, second})
+		So(m.JobListener("second"), ShouldEqual, second)
+		So(m.JobListener("missing"), ShouldBeNil)
+		So(m.TriggerListener("first"), ShouldEqual, first)
+		So(m.SchedulerListeners(), ShouldResemble, []SchedulerListener{first, second})
+
+		Convey("The listener with the same name should be replaced in place", func() {
+			other := &recordingListener{name: "first"}
+
+			m.AddJobListener(other)
+
+			So(m.JobListeners(), ShouldResemble, []JobListener{other, second})
+		})
+
+		Convey("The listeners should keep their matchers", func() {
+			m.AddJobListener(first, GroupEquals("reports"), GroupStartsWith("daily"))
+
+			So(m.JobListenerMatchers("first"), ShouldResemble, []GroupMatcher{GroupEquals("reports"), GroupStartsWith("daily")})
+			So(m.JobListenerMatchers("second"), ShouldBeEmpty)
+
+			var notified []string
+
+			manager := m.(*listenerManager)
+
+			manager.notifyJobListeners(NewGroupJobKey("job", "daily-backup"), func(l JobListener) { notified = append(notified, l.Name()) })
+			manager.notifyJobListeners(NewGroupJobKey("job", "others"), func(l JobListener) { notified = append(notified, l.Name()) })
+
+			So(notified, ShouldResemble, []string{"first", "second", "second"})
+
+			So(m.RemoveJobListener("first"), ShouldBeTrue)
+			So(m.JobListenerMatchers("first"), ShouldBeEmpty)
+		})
+
+		Convey("The listeners could be removed", func() {
+			So(m.RemoveJobListener("first"), ShouldBeTrue)
+			So(m.RemoveJobListener("first"), ShouldBeFalse)
+			So(m.JobListeners(), ShouldResemble, []JobListener{second})
+
+			So(m.RemoveTriggerListener("first"), ShouldBeTrue)
+			So(m.TriggerListeners(), ShouldBeEmpty)
+
+			So(m.RemoveSchedulerListener(first), ShouldBeTrue)
+			So(m.RemoveSchedulerListener(first), ShouldBeFalse)
+			So(m.SchedulerListeners(), ShouldResemble, []SchedulerListener{second})
+		})
+	})
+}
+
+func TestSchedulerListeners(t *testing.T) {
+	Convey("Given a StdScheduler with a listener", t, func() {
+		scheduler := NewStdScheduler("scheduler", NewRAMJobStore(), NewSimpleThreadPool(1))
+
+		listener := &recordingListener{name: "recorder"}
+
+		scheduler.ListenerManager().AddJobListener(listener)
+		scheduler.ListenerManager().AddTriggerListener(listener)
+		scheduler.ListenerManager().AddSchedulerListener(listener)
+
+		Convey("When a job is executed", func() {
+			job := NewJobDetailFromFunc("job", func(context JobExecutionContext) error {
+				return errors.New("failed")
+			})
+
+			So(scheduler.Start(), ShouldBeNil)
+
+			_, err := scheduler.ScheduleJob(job, (&TriggerBuilder{}).StartNow().Build())
+
+			So(err, ShouldBeNil)
+			So(waitFor(time.Second, func() bool { return len(listener.Events()) == 9 }), ShouldBeTrue)
+
+			So(scheduler.Shutdown(), ShouldBeNil)
+
+			So(listener.Events(), ShouldResemble, []string{
+				"SchedulerStarting",
+				"SchedulerStarted",
+				"JobAdded",
+				"JobScheduled",
+				"TriggerFired",
+				"JobToBeExecuted",
+				"JobWasExecuted: Job threw an exception: failed",
+				"TriggerComplete",
+				"TriggerFinalized",
+				"SchedulerShuttingdown",
+				"SchedulerShutdown",
+			})
+		})
+
+		Convey("When the triggers are managed", func() {
+			defer scheduler.Shutdown()
+
+			job := NewJobDetailFromFunc("job", func(context JobExecutionContext) error { return nil })
+			trigger := (&TriggerBuilder{}).WithIdentity("trigger").StartAt(time.Now().Add(time.Hour)).Build()
+
+			_, err := scheduler.ScheduleJob(job, trigger)
+
+			So(err, ShouldBeNil)
+			So(scheduler.PauseTrigger(trigger.Key()), ShouldBeNil)
+			So(scheduler.ResumeTrigger(trigger.Key()), ShouldBeNil)
+
+			found, err := scheduler.UnscheduleJob(trigger.Key())
+
+			So(err, ShouldBeNil)
+			So(found, ShouldBeTrue)
+
+			So(scheduler.Start(), ShouldBeNil)
+			So(scheduler.Standby(), ShouldBeNil)
+			So(scheduler.Standby(), ShouldBeNil)
+
+			So(listener.Events(), ShouldResemble, []string{
+				"JobAdded",
+				"JobScheduled",
+				"TriggerPaused",
+				"TriggerResumed",
+				"JobUnscheduled",
+				"SchedulerStarting",
+				"SchedulerStarted",
+				"SchedulerInStandbyMode",
+			})
+		})
+
+		Convey("When the jobs and the triggers are removed with a missing key", func() {
+			defer scheduler.Shutdown()
+
+			job := NewJobDetailFromFunc("job", func(context JobExecutionContext) error { return nil })
+			trigger := (&TriggerBuilder{}).WithIdentity("trigger").StartAt(time.Now().Add(time.Hour)).Build()
+
+			_, err := scheduler.ScheduleJob(job, trigger)
+
+			So(err, ShouldBeNil)
+
+			other := (&JobBuilder{}).WithIdentity("other").StoreDurably(true).Build()
+
+			So(scheduler.AddJob(other, false), ShouldBeNil)
+
+			found, err := scheduler.UnscheduleJobs([]TriggerKey{trigger.Key(), NewTriggerKey("missing")})
+
+			So(err, ShouldBeNil)
+			So(found, ShouldBeFalse)
+
+			found, err = scheduler.DeleteJobs([]JobKey{other.Key(), NewJobKey("missing")})
+
+			So(err, ShouldBeNil)
+			So(found, ShouldBeFalse)
+
+			Convey("Only the removed ones should be notified", func() {
+				So(listener.Events(), ShouldResemble, []string{
+					"JobAdded",
+					"JobScheduled",
+					"JobAdded",
+					"JobUnscheduled",
+					"JobDeleted",
+				})
+			})
+		})
+
+		Convey("When the job could not be instantiated", func() {
+			defer scheduler.Shutdown()
+
+			job := (&JobBuilder{}).WithIdentity("job").OfType("unregistered").Build()
+
+			So(scheduler.Start(), ShouldBeNil)
+
+			_, err := scheduler.ScheduleJob(job, (&TriggerBuilder{}).StartNow().Build())
+
+			So(err, ShouldBeNil)
+			So(waitFor(time.Second, func() bool {
+				events := listener.Events()
+
+				return len(events) > 0 && events[len(events)-1] == "SchedulerError"
+			}), ShouldBeTrue)
+		})
+	})
+}
+
+type vetoingListener struct {
+	TriggerListenerSupport
+
+	lock         sync.Mutex
+	instructions []CompletedExecutionInstruction
+	fireTimes    []time.Time
+}
+
+func (l *vetoingListener) Name() string { return "veto" }
+
+func (l *vetoingListener) VetoJobExecution(trigger Trigger, context JobExecutionContext) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.fireTimes = append(l.fireTimes, context.ScheduledFireTime())
+
+	return true
+}
+
+func (l *vetoingListener) TriggerComplete(trigger Trigger, context JobExecutionContext, instruction CompletedExecutionInstruction) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.instructions = append(l.instructions, instruction)
+}
+
+func (l *vetoingListener) Instructions() []CompletedExecutionInstruction {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	return append([]CompletedExecutionInstruction(nil), l.instructions...)
+}
+
+func TestVetoJobExecution(t *testing.T) {
+	Convey("Given a StdScheduler with a vetoing trigger listener", t, func() {
+		scheduler := NewStdScheduler("scheduler", NewRAMJobStore(), NewSimpleThreadPool(1))
+
+		defer scheduler.Shutdown()
+
+		veto := &vetoingListener{}
+		recorder := &recordingListener{name: "recorder"}
+
+		scheduler.ListenerManager().AddTriggerListener(veto)
+		scheduler.ListenerManager().AddJobListener(recorder)
+		scheduler.ListenerManager().AddSchedulerListener(recorder)
+
+		executed := false
+
+		job := NewJobDetailFromFunc("job", func(context JobExecutionContext) error {
+			executed = true
+
+			return nil
+		})
+
+		trigger := (&TriggerBuilder{}).
+			WithIdentity("trigger").
+			StartNow().
+			WithSchedule(&SimpleScheduleBuilder{10 * time.Millisecond, 2}).
+			Build()
+
+		Convey("When the trigger fires", func() {
+			_, err := scheduler.ScheduleJob(job, trigger)
+
+			So(err, ShouldBeNil)
+			So(scheduler.Start(), ShouldBeNil)
+
+			So(waitFor(time.Second, func() bool { return len(veto.Instructions()) == 3 }), ShouldBeTrue)
+			So(waitFor(time.Second, func() bool { return !scheduler.CheckTriggerExists(trigger.Key()) }), ShouldBeTrue)
+
+			Convey("The job should not be executed but the trigger should be rescheduled", func() {
+				So(executed, ShouldBeFalse)
+				So(veto.Instructions(), ShouldResemble, []CompletedExecutionInstruction{JOB_VETOED, JOB_VETOED, JOB_VETOED})
+
+				veto.lock.Lock()
+				fireTimes := veto.fireTimes
+				veto.lock.Unlock()
+
+				So(fireTimes[1], ShouldHappenAfter, fireTimes[0])
+				So(fireTimes[2], ShouldHappenAfter, fireTimes[1])
+
+				So(recorder.Events(), ShouldContain, "JobExecutionVetoed")
+				So(recorder.Events(), ShouldNotContain, "JobToBeExecuted")
+				So(recorder.Events(), ShouldContain, "TriggerFinalized")
+			})
+		})
+	})
+}
+
+type panickingListener struct {
+	JobListenerSupport
+}
+
+func (l *panickingListener) Name() string { return "panicking" }
+
+func (l *panickingListener) JobWasExecuted(context JobExecutionContext, err error) { panic("boom") }
+
+func TestPanickingListener(t *testing.T) {
+	Convey("Given a StdScheduler with a panicking job listener", t, func() {
+		scheduler := NewStdScheduler("scheduler", NewRAMJobStore(), NewSimpleThreadPool(1))
+
+		defer scheduler.Shutdown()
+
+		logger := &capturingLogger{}
+
+		scheduler.SetLogger(logger)
+
+		recorder := &recordingListener{name: "recorder"}
+
+		scheduler.ListenerManager().AddJobListener(&panickingListener{})
+		scheduler.ListenerManager().AddJobListener(recorder)
+
+		var executed int32
+
+		job := NewJobDetailFromFunc("job", func(context JobExecutionContext) error {
+			atomic.AddInt32(&executed, 1)
+
+			return nil
+		})
+
+		trigger := (&TriggerBuilder{}).
+			WithIdentity("trigger").
+			StartNow().
+			WithSchedule(&SimpleScheduleBuilder{10 * time.Millisecond, 2}).
+			Build()
+
+		Convey("When the trigger fires", func() {
+			_, err := scheduler.ScheduleJob(job, trigger)
+
+			So(err, ShouldBeNil)
+			So(scheduler.Start(), ShouldBeNil)
+
+			Convey("The trigger should complete and the panic should be logged", func() {
+				So(waitFor(time.Second, func() bool { return !scheduler.CheckTriggerExists(trigger.Key()) }), ShouldBeTrue)
+				So(atomic.LoadInt32(&executed), ShouldEqual, 3)
+				So(recorder.Events(), ShouldContain, "JobWasExecuted")
+
+				So(logger.Messages(), ShouldContain, "ERROR Listener panicked. listener panicking panic boom")
+			})
+		})
+	})
+}
@@ -0,0 +1,57 @@
+package quartz
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Logger logs the messages of the scheduler with the fields given as alternating keys and values.
+type Logger interface {
+	Debug(msg string, keysAndValues ...interface{})
+
+	Info(msg string, keysAndValues ...interface{})
+
+	Warn(msg string, keysAndValues ...interface{})
+
+	Error(msg string, keysAndValues ...interface{})
+}
+
+// NoopLogger is the default Logger discarding the messages.
+type NoopLogger struct{}
+
+func (NoopLogger) Debug(msg string, keysAndValues ...interface{}) {}
+
+func (NoopLogger) Info(msg string, keysAndValues ...interface{}) {}
+
+func (NoopLogger) Warn(msg string, keysAndValues ...interface{}) {}
+
+func (NoopLogger) Error(msg string, keysAndValues ...interface{}) {}
+
+// NewSlogLogger adapts the slog.Logger, or the default one if nil, to the Logger.
+func NewSlogLogger(logger *slog.Logger) Logger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &slogLogger{logger}
+}
+
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+func (l *slogLogger) Debug(msg string, keysAndValues ...interface{}) {
+	l.logger.Log(context.Background(), slog.LevelDebug, msg, keysAndValues...)
+}
+
+func (l *slogLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.logger.Log(context.Background(), slog.LevelInfo, msg, keysAndValues...)
+}
+
+func (l *slogLogger) Warn(msg string, keysAndValues ...interface{}) {
+	l.logger.Log(context.Background(), slog.LevelWarn, msg, keysAndValues...)
+}
+
+func (l *slogLogger) Error(msg string, keysAndValues ...interface{}) {
+	l.logger.Log(context.Background(), slog.LevelError, msg, keysAndValues...)
+}
@@ -0,0 +1,127 @@
+package quartz
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type capturingLogger struct {
+	lock     sync.Mutex
+	messages []string
+}
+
+func (l *capturingLogger) log(level, msg string, keysAndValues ...interface{}) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.messages = append(l.messages, strings.TrimSpace(fmt.Sprintln(append([]interface{}{level, msg}, keysAndValues...)...)))
+}
+
+func (l *capturingLogger) Messages() []string {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	return append([]string(nil), l.messages...)
+}
+
+func (l *capturingLogger) Debug(msg string, keysAndValues ...interface{}) {
+	l.log("DEBUG", msg, keysAndValues...)
+}
+
+func (l *capturingLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.log("INFO", msg, keysAndValues...)
+}
+
+func (l *capturingLogger) Warn(msg string, keysAndValues ...interface{}) {
+	l.log("WARN", msg, keysAndValues...)
+}
+
+func (l *capturingLogger) Error(msg string, keysAndValues ...interface{}) {
+	l.log("ERROR", msg, keysAndValues...)
+}
+
+func TestStdSchedulerLogger(t *testing.T) {
+	Convey("Given a started StdScheduler with a capturing Logger", t, func() {
+		scheduler := NewStdScheduler("scheduler", NewRAMJobStore(), NewSimpleThreadPool(2))
+
+		defer scheduler.Shutdown()
+
+		logger := &capturingLogger{}
+
+		scheduler.SetLogger(logger)
+		scheduler.misfireThreshold = time.Second
+
+		So(scheduler.Start(), ShouldBeNil)
+
+		executed := make(chan struct{})
+
+		job := NewJobDetailFromFunc("job", func(context JobExecutionContext) error {
+			close(executed)
+
+			return nil
+		})
+
+		Convey("The misfired trigger should be logged", func() {
+			fireTime := time.Now().Add(-time.Minute).Round(0)
+
+			_, err := scheduler.ScheduleJob(job, (&TriggerBuilder{}).WithIdentity("trigger").StartAt(fireTime).Build())
+
+			So(err, ShouldBeNil)
+
+			<-executed
+
+			var misfired []string
+
+			for _, msg := range logger.Messages() {
+				if strings.HasPrefix(msg, "WARN Trigger misfired.") {
+					misfired = append(misfired, msg)
+				}
+			}
+
+			So(misfired, ShouldHaveLength, 1)
+			So(misfired[0], ShouldStartWith, "WARN Trigger misfired. trigger DEFAULT.trigger fireTime "+fireTime.Format("2006-01-02 15:04:05"))
+			So(logger.Messages(), ShouldContain, "DEBUG Acquired the next triggers. count 1")
+			So(logger.Messages()[0], ShouldEqual, "INFO Scheduler started. scheduler scheduler")
+
+			Convey("The shutdown should be logged", func() {
+				So(scheduler.Shutdown(), ShouldBeNil)
+
+				So(logger.Messages(), ShouldContain, "INFO Scheduler shutting down. scheduler scheduler waitForJobsToComplete false")
+				So(logger.Messages(), ShouldContain, "INFO Scheduler shutdown complete. scheduler scheduler")
+			})
+		})
+	})
+}
+
+func TestSlogLogger(t *testing.T) {
+	Convey("Given a Logger adapting a slog.Logger", t, func() {
+		var buf bytes.Buffer
+
+		logger := NewSlogLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+			Level: slog.LevelWarn,
+			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+				if a.Key == slog.TimeKey {
+					return slog.Attr{}
+				}
+
+				return a
+			},
+		})))
+
+		Convey("The messages should be logged at their level with the fields", func() {
+			logger.Debug("debug", "key", "value")
+			logger.Info("info", "key", "value")
+			logger.Warn("warn", "key", "value")
+			logger.Error("error", "key", 42)
+
+			So(buf.String(), ShouldEqual, "level=WARN msg=warn key=value\nlevel=ERROR msg=error key=42\n")
+		})
+	})
+}
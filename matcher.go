@@ -0,0 +1,115 @@
+package quartz
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GroupedKey is the key of a Job or a Trigger, both JobKey and TriggerKey implement it.
+type GroupedKey interface {
+	Name() string
+
+	Group() string
+}
+
+// groupedKeyLess orders the keys by their groups and then their names.
+func groupedKeyLess(lhs, rhs GroupedKey) bool {
+	return groupedKeyCompare(lhs, rhs) < 0
+}
+
+// groupedKeyCompare compares the keys by their groups and then their names.
+func groupedKeyCompare(lhs, rhs GroupedKey) int {
+	if lhs.Group() != rhs.Group() {
+		return strings.Compare(lhs.Group(), rhs.Group())
+	}
+
+	return strings.Compare(lhs.Name(), rhs.Name())
+}
+
+type StringOperator int
+
+const (
+	EQUALS StringOperator = iota
+	STARTS_WITH
+	ENDS_WITH
+	CONTAINS
+	ANYTHING
+)
+
+func (op StringOperator) String() string {
+	switch op {
+	case EQUALS:
+		return "EQUALS"
+	case STARTS_WITH:
+		return "STARTS_WITH"
+	case ENDS_WITH:
+		return "ENDS_WITH"
+	case CONTAINS:
+		return "CONTAINS"
+	case ANYTHING:
+		return "ANYTHING"
+	default:
+		return fmt.Sprintf("StringOperator(%d)", int(op))
+	}
+}
+
+// Evaluate checks the value against the compareTo string with the operator.
+func (op StringOperator) Evaluate(value, compareTo string) bool {
+	switch op {
+	case EQUALS:
+		return value == compareTo
+	case STARTS_WITH:
+		return strings.HasPrefix(value, compareTo)
+	case ENDS_WITH:
+		return strings.HasSuffix(value, compareTo)
+	case CONTAINS:
+		return strings.Contains(value, compareTo)
+	case ANYTHING:
+		return true
+	default:
+		return false
+	}
+}
+
+// GroupMatcher matches the group of a JobKey or TriggerKey.
+type GroupMatcher struct {
+	Operator  StringOperator
+	CompareTo string
+}
+
+func GroupEquals(group string) GroupMatcher { return GroupMatcher{EQUALS, group} }
+
+func GroupStartsWith(prefix string) GroupMatcher { return GroupMatcher{STARTS_WITH, prefix} }
+
+func GroupEndsWith(suffix string) GroupMatcher { return GroupMatcher{ENDS_WITH, suffix} }
+
+func GroupContains(sub string) GroupMatcher { return GroupMatcher{CONTAINS, sub} }
+
+func AnyGroup() GroupMatcher { return GroupMatcher{Operator: ANYTHING} }
+
+func (m GroupMatcher) Matches(key GroupedKey) bool { return m.MatchesGroup(key.Group()) }
+
+func (m GroupMatcher) MatchesGroup(group string) bool { return m.Operator.Evaluate(group, m.CompareTo) }
+
+func (m GroupMatcher) String() string {
+	if m.Operator == ANYTHING {
+		return "GroupMatcher(ANYTHING)"
+	}
+
+	return fmt.Sprintf("GroupMatcher(%s '%s')", m.Operator, m.CompareTo)
+}
+
+// matchesAny checks the key against the matchers, no matcher matches everything.
+func matchesAny(matchers []GroupMatcher, key GroupedKey) bool {
+	if len(matchers) == 0 {
+		return true
+	}
+
+	for _, m := range matchers {
+		if m.Matches(key) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,45 @@
+package quartz
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGroupMatcher(t *testing.T) {
+	Convey("Given some keys in the groups", t, func() {
+		keys := []GroupedKey{
+			NewGroupJobKey("job", "reports"),
+			NewGroupJobKey("job", "reports-daily"),
+			NewGroupJobKey("job", "daily-backup"),
+			NewGroupTriggerKey("trigger", "reports"),
+			NewGroupTriggerKey("trigger", DEFAULT_GROUP),
+		}
+
+		matched := func(m GroupMatcher) (groups []string) {
+			for _, key := range keys {
+				if m.Matches(key) {
+					groups = append(groups, key.Group())
+				}
+			}
+
+			return
+		}
+
+		So(matched(GroupEquals("reports")), ShouldResemble, []string{"reports", "reports"})
+		So(matched(GroupStartsWith("reports")), ShouldResemble, []string{"reports", "reports-daily", "reports"})
+		So(matched(GroupEndsWith("daily")), ShouldResemble, []string{"reports-daily"})
+		So(matched(GroupContains("daily")), ShouldResemble, []string{"reports-daily", "daily-backup"})
+		So(matched(AnyGroup()), ShouldHaveLength, len(keys))
+		So(matched(GroupEquals("missing")), ShouldBeEmpty)
+
+		So(GroupEquals("reports").String(), ShouldEqual, "GroupMatcher(EQUALS 'reports')")
+		So(AnyGroup().String(), ShouldEqual, "GroupMatcher(ANYTHING)")
+	})
+
+	Convey("Given no matcher", t, func() {
+		So(matchesAny(nil, NewJobKey("job")), ShouldBeTrue)
+		So(matchesAny([]GroupMatcher{GroupEquals("other"), GroupEquals(DEFAULT_GROUP)}, NewJobKey("job")), ShouldBeTrue)
+		So(matchesAny([]GroupMatcher{GroupEquals("other")}, NewJobKey("job")), ShouldBeFalse)
+	})
+}
@@ -0,0 +1,28 @@
+package quartz
+
+import "time"
+
+// MetricsCollector is notified by the scheduler at its lifecycle points, it can be implemented to feed a metrics system,
+// e.g. Prometheus counters and histograms.
+//
+// The methods are called from the scheduling loop and the worker threads, they must be concurrency-safe and fast.
+type MetricsCollector interface {
+	// JobExecuted is called after each execution of the job, with the error returned by the job.
+	JobExecuted(key JobKey, runTime time.Duration, err error)
+
+	// TriggerMisfired is called when the trigger fires later than its fire time by more than the misfire threshold.
+	TriggerMisfired(key TriggerKey)
+
+	// TriggersAcquired is called with the number of the triggers acquired by the scheduling loop.
+	TriggersAcquired(n int)
+}
+
+// NoopMetricsCollector is the default MetricsCollector doing nothing,
+// it can be embedded to implement only the interesting methods.
+type NoopMetricsCollector struct{}
+
+func (NoopMetricsCollector) JobExecuted(key JobKey, runTime time.Duration, err error) {}
+
+func (NoopMetricsCollector) TriggerMisfired(key TriggerKey) {}
+
+func (NoopMetricsCollector) TriggersAcquired(n int) {}
@@ -0,0 +1,121 @@
+package quartz
+
+import (
+	"errors"
+	"expvar"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type recordingCollector struct {
+	lock   sync.Mutex
+	events []string
+}
+
+func (c *recordingCollector) record(event string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.events = append(c.events, event)
+}
+
+func (c *recordingCollector) Events() []string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return append([]string(nil), c.events...)
+}
+
+func (c *recordingCollector) JobExecuted(key JobKey, runTime time.Duration, err error) {
+	c.record(fmt.Sprintf("JobExecuted: %s %v", key, err))
+}
+
+func (c *recordingCollector) TriggerMisfired(key TriggerKey) {
+	c.record("TriggerMisfired: " + key.String())
+}
+
+func (c *recordingCollector) TriggersAcquired(n int) {
+	if n > 0 {
+		c.record(fmt.Sprintf("TriggersAcquired: %d", n))
+	}
+}
+
+func TestMetricsCollector(t *testing.T) {
+	Convey("Given a started StdScheduler with a recording MetricsCollector", t, func() {
+		scheduler := NewStdScheduler("scheduler", NewRAMJobStore(), NewSimpleThreadPool(2))
+
+		defer scheduler.Shutdown()
+
+		collector := &recordingCollector{}
+
+		scheduler.SetMetricsCollector(collector)
+		scheduler.misfireThreshold = time.Second
+
+		So(scheduler.Start(), ShouldBeNil)
+
+		Convey("The collector should be notified of the acquired trigger and the executed job", func() {
+			job := NewJobDetailFromFunc("job", func(context JobExecutionContext) error { return errors.New("failed") })
+			trigger := (&TriggerBuilder{}).WithIdentity("trigger").StartNow().Build()
+
+			_, err := scheduler.ScheduleJob(job, trigger)
+
+			So(err, ShouldBeNil)
+
+			So(waitFor(time.Second, func() bool { return len(collector.Events()) == 2 }), ShouldBeTrue)
+			So(collector.Events(), ShouldResemble, []string{
+				"TriggersAcquired: 1",
+				"JobExecuted: DEFAULT.job Job threw an exception: failed",
+			})
+		})
+
+		Convey("The collector should be notified of the misfired trigger", func() {
+			job := NewJobDetailFromFunc("job", func(context JobExecutionContext) error { return nil })
+			trigger := (&TriggerBuilder{}).WithIdentity("trigger").StartAt(time.Now().Add(-time.Minute)).Build()
+
+			_, err := scheduler.ScheduleJob(job, trigger)
+
+			So(err, ShouldBeNil)
+
+			So(waitFor(time.Second, func() bool { return len(collector.Events()) == 4 }), ShouldBeTrue)
+			So(collector.Events(), ShouldResemble, []string{
+				"TriggersAcquired: 1",
+				"TriggerMisfired: DEFAULT.trigger",
+				"TriggersAcquired: 1",
+				"JobExecuted: DEFAULT.job <nil>",
+			})
+		})
+	})
+}
+
+// expvarCollector is an example adapter publishing the metrics with the expvar package,
+// an adapter for Prometheus would update its counters and histograms the same way.
+type expvarCollector struct {
+	NoopMetricsCollector
+
+	executions *expvar.Map
+	failures   *expvar.Map
+}
+
+func (c *expvarCollector) JobExecuted(key JobKey, runTime time.Duration, err error) {
+	c.executions.Add(key.String(), 1)
+
+	if err != nil {
+		c.failures.Add(key.String(), 1)
+	}
+}
+
+func ExampleMetricsCollector() {
+	scheduler := NewStdScheduler("scheduler", NewRAMJobStore(), NewSimpleThreadPool(2))
+
+	scheduler.SetMetricsCollector(&expvarCollector{
+		executions: expvar.NewMap("quartz.executions"),
+		failures:   expvar.NewMap("quartz.failures"),
+	})
+
+	scheduler.Start()
+	defer scheduler.Shutdown()
+}
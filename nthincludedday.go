@@ -0,0 +1,372 @@
+package quartz
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// NthIncludedDayIntervalType is the interval in which a NthIncludedDay trigger fires once.
+type NthIncludedDayIntervalType int
+
+const (
+	// INTERVAL_TYPE_WEEKLY fires on the Nth included day of each week, the weeks start on Sunday.
+	INTERVAL_TYPE_WEEKLY NthIncludedDayIntervalType = iota + 1
+	INTERVAL_TYPE_MONTHLY
+	INTERVAL_TYPE_YEARLY
+)
+
+func (t NthIncludedDayIntervalType) String() string {
+	switch t {
+	case INTERVAL_TYPE_WEEKLY:
+		return "week"
+	case INTERVAL_TYPE_MONTHLY:
+		return "month"
+	case INTERVAL_TYPE_YEARLY:
+		return "year"
+	default:
+		return fmt.Sprintf("NthIncludedDayIntervalType(%d)", int(t))
+	}
+}
+
+// maxDays returns the most days of an interval, or 0 if the interval type is unknown.
+func (t NthIncludedDayIntervalType) maxDays() int {
+	switch t {
+	case INTERVAL_TYPE_WEEKLY:
+		return 7
+	case INTERVAL_TYPE_MONTHLY:
+		return 31
+	case INTERVAL_TYPE_YEARLY:
+		return 366
+	default:
+		return 0
+	}
+}
+
+func validateNthIncludedDay(n int, intervalType NthIncludedDayIntervalType) error {
+	maxDays := intervalType.maxDays()
+
+	if maxDays == 0 {
+		return fmt.Errorf("Invalid interval type: %d", int(intervalType))
+	}
+
+	if n < 1 || n > maxDays {
+		return fmt.Errorf("N must be from 1 to %d for the interval of a %s, got %d", maxDays, intervalType, n)
+	}
+
+	return nil
+}
+
+// DefaultNthIncludedDayFireAtTime is the time of day when the NthIncludedDay triggers fire by default.
+var DefaultNthIncludedDayFireAtTime = TimeOfDay{Hour: 12}
+
+// NthIncludedDayScheduleBuilder fires on the Nth day of each interval that is included by a Calendar,
+// e.g. the 3rd business day of each month, with a calendar excluding the weekends and the holidays.
+//
+// The calendar is the stored Calendar of the trigger's CalendarName when the trigger is scheduled,
+// or the one given by WithCalendar, and all the days are included without calendar.
+// The interval without N included days is skipped.
+type NthIncludedDayScheduleBuilder struct {
+	n            int
+	intervalType NthIncludedDayIntervalType
+	fireAtTime   TimeOfDay
+	calendar     Calendar
+}
+
+// NthIncludedDay fires on the Nth included day of each interval at DefaultNthIncludedDayFireAtTime.
+func NthIncludedDay(n int, intervalType NthIncludedDayIntervalType) *NthIncludedDayScheduleBuilder {
+	return &NthIncludedDayScheduleBuilder{n, intervalType, DefaultNthIncludedDayFireAtTime, nil}
+}
+
+// AtTimeOfDay sets the time of day of the fire times, in the time zone of the trigger.
+func (b *NthIncludedDayScheduleBuilder) AtTimeOfDay(fireAtTime TimeOfDay) *NthIncludedDayScheduleBuilder {
+	b.fireAtTime = fireAtTime
+
+	return b
+}
+
+// WithCalendar sets the Calendar including the days, it is replaced by the stored Calendar of the trigger's
+// CalendarName when the trigger is scheduled.
+//
+// The calendar is not serialized, the trigger restored by a persistent JobStore only uses its CalendarName.
+func (b *NthIncludedDayScheduleBuilder) WithCalendar(cal Calendar) *NthIncludedDayScheduleBuilder {
+	b.calendar = cal
+
+	return b
+}
+
+func (b *NthIncludedDayScheduleBuilder) String() string {
+	return fmt.Sprintf("on the %s included day of each %s at %s", ordinal(b.n), b.intervalType, b.fireAtTime)
+}
+
+// Build the trigger, panics if the schedule is invalid, use BuildE to check the error.
+func (b *NthIncludedDayScheduleBuilder) Build() MutableTrigger {
+	trigger, err := b.BuildE()
+
+	if err != nil {
+		panic(err)
+	}
+
+	return trigger
+}
+
+func (b *NthIncludedDayScheduleBuilder) BuildE() (MutableTrigger, error) {
+	if err := validateNthIncludedDay(b.n, b.intervalType); err != nil {
+		return nil, err
+	}
+
+	return &nthIncludedDayTrigger{
+		n:            b.n,
+		intervalType: b.intervalType,
+		fireAtTime:   b.fireAtTime,
+		calendar:     cloneCalendar(b.calendar),
+	}, nil
+}
+
+func ordinal(n int) string {
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+		return fmt.Sprintf("%dth", n)
+	case n%10 == 1:
+		return fmt.Sprintf("%dst", n)
+	case n%10 == 2:
+		return fmt.Sprintf("%dnd", n)
+	case n%10 == 3:
+		return fmt.Sprintf("%drd", n)
+	default:
+		return fmt.Sprintf("%dth", n)
+	}
+}
+
+// nthIncludedDayTrigger fires on the Nth day included by its calendar in each interval,
+// the calendar is replaced by the one given when the trigger is scheduled, fired or misfired.
+type nthIncludedDayTrigger struct {
+	abstractTrigger
+
+	startTime        time.Time
+	endTime          time.Time
+	nextFireTime     time.Time
+	previousFireTime time.Time
+	n                int
+	intervalType     NthIncludedDayIntervalType
+	fireAtTime       TimeOfDay
+	calendar         Calendar
+}
+
+func (t *nthIncludedDayTrigger) Clone() interface{} {
+	clone := *t
+
+	if t.dataMap != nil {
+		clone.dataMap = t.dataMap.CloneDataMap()
+	}
+
+	clone.calendar = cloneCalendar(t.calendar)
+
+	return &clone
+}
+
+func (t *nthIncludedDayTrigger) StartTime() time.Time { return t.startTime }
+
+func (t *nthIncludedDayTrigger) TimeZone() *time.Location { return t.timeZoneOr(t.startTime) }
+
+func (t *nthIncludedDayTrigger) SetStartTime(startTime time.Time) error {
+	if startTime.IsZero() {
+		return errors.New("Start time cannot be null")
+	}
+
+	if !t.endTime.IsZero() && t.endTime.Before(startTime) {
+		return errors.New("End time cannot be before start time")
+	}
+
+	t.startTime = startTime
+
+	return nil
+}
+
+func (t *nthIncludedDayTrigger) EndTime() time.Time { return t.endTime }
+
+func (t *nthIncludedDayTrigger) SetEndTime(endTime time.Time) error {
+	if !t.startTime.IsZero() && !endTime.IsZero() && t.startTime.After(endTime) {
+		return errors.New("End time cannot be before start time")
+	}
+
+	t.endTime = endTime
+
+	return nil
+}
+
+func (t *nthIncludedDayTrigger) NextFireTime() time.Time { return t.nextFireTime }
+
+func (t *nthIncludedDayTrigger) SetNextFireTime(nextFireTime time.Time) {
+	t.nextFireTime = nextFireTime
+}
+
+func (t *nthIncludedDayTrigger) PreviousFireTime() time.Time { return t.previousFireTime }
+
+func (t *nthIncludedDayTrigger) SetPreviousFireTime(previousFireTime time.Time) {
+	t.previousFireTime = previousFireTime
+}
+
+func (t *nthIncludedDayTrigger) CompareTo(other Trigger) int { return compareTriggers(t, other) }
+
+func (t *nthIncludedDayTrigger) MayFireAgain() bool { return !t.NextFireTime().IsZero() }
+
+// intervalStart returns the first day of the interval containing the time, in the time zone of the trigger.
+func (t *nthIncludedDayTrigger) intervalStart(tm time.Time) time.Time {
+	year, month, day := tm.In(t.TimeZone()).Date()
+
+	switch t.intervalType {
+	case INTERVAL_TYPE_WEEKLY:
+		day -= int(tm.In(t.TimeZone()).Weekday())
+	case INTERVAL_TYPE_MONTHLY:
+		day = 1
+	case INTERVAL_TYPE_YEARLY:
+		month, day = time.January, 1
+	}
+
+	return time.Date(year, month, day, 0, 0, 0, 0, t.TimeZone())
+}
+
+// nextIntervalStart returns the first day of the interval after the one starting at the given day.
+func (t *nthIncludedDayTrigger) nextIntervalStart(start time.Time) time.Time {
+	switch t.intervalType {
+	case INTERVAL_TYPE_WEEKLY:
+		return start.AddDate(0, 0, 7)
+	case INTERVAL_TYPE_MONTHLY:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(1, 0, 0)
+	}
+}
+
+// nthIncludedDay walks the days of the interval starting at the given day, and returns the fire time
+// on its Nth included day, or the zero time if the interval has less than N included days.
+func (t *nthIncludedDayTrigger) nthIncludedDay(start time.Time) time.Time {
+	end := t.nextIntervalStart(start)
+	included := 0
+
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		fireTime := t.fireAtTime.On(day)
+
+		if t.calendar == nil || t.calendar.IsTimeIncluded(fireTime) {
+			if included++; included == t.n {
+				return fireTime
+			}
+		}
+	}
+
+	return zero
+}
+
+// FireTimeAfter returns the fire time on the Nth included day of the interval containing the given time,
+// or of the following intervals, in the time zone of the trigger.
+func (t *nthIncludedDayTrigger) FireTimeAfter(afterTime time.Time) time.Time {
+	if afterTime.IsZero() {
+		afterTime = time.Now()
+	}
+
+	from := afterTime
+
+	if from.Before(t.startTime) {
+		from = t.startTime
+	}
+
+	for start := t.intervalStart(from); start.Year() <= yearToGiveUpSchedulingAt; start = t.nextIntervalStart(start) {
+		if !t.endTime.IsZero() && start.After(t.endTime) {
+			return zero
+		}
+
+		fireTime := t.nthIncludedDay(start)
+
+		if fireTime.IsZero() || !fireTime.After(afterTime) || fireTime.Before(t.startTime) {
+			continue
+		}
+
+		if !t.endTime.IsZero() && fireTime.After(t.endTime) {
+			return zero
+		}
+
+		return fireTime
+	}
+
+	return zero
+}
+
+// FinalFireTime returns the last fire time before the end time, or the zero time if the trigger never ends.
+func (t *nthIncludedDayTrigger) FinalFireTime() time.Time {
+	if t.endTime.IsZero() {
+		return zero
+	}
+
+	var final time.Time
+
+	for fireTime := t.FireTimeAfter(t.startTime.Add(-time.Nanosecond)); !fireTime.IsZero(); fireTime = t.FireTimeAfter(fireTime) {
+		final = fireTime
+	}
+
+	return final
+}
+
+// useCalendar replaces the calendar of the trigger with the given one, if any.
+func (t *nthIncludedDayTrigger) useCalendar(cal Calendar) {
+	if cal != nil {
+		t.calendar = cal
+	}
+}
+
+func (t *nthIncludedDayTrigger) Triggered(cal Calendar) {
+	t.useCalendar(cal)
+
+	t.previousFireTime = t.nextFireTime
+	t.nextFireTime = t.FireTimeAfter(t.nextFireTime)
+}
+
+func (t *nthIncludedDayTrigger) ComputeFirstFireTime(cal Calendar) time.Time {
+	t.useCalendar(cal)
+
+	t.nextFireTime = t.FireTimeAfter(t.startTime.Add(-time.Nanosecond))
+
+	return t.nextFireTime
+}
+
+// UpdateAfterMisfire reschedules the trigger to its next fire time after now, the missed fire times are skipped.
+func (t *nthIncludedDayTrigger) UpdateAfterMisfire(cal Calendar, now time.Time) {
+	t.useCalendar(cal)
+
+	t.nextFireTime = t.FireTimeAfter(now)
+}
+
+func (t *nthIncludedDayTrigger) Validate() error {
+	if err := t.abstractTrigger.validate(); err != nil {
+		return err
+	}
+
+	if err := validateNthIncludedDay(t.n, t.intervalType); err != nil {
+		return err
+	}
+
+	if !t.endTime.IsZero() && t.endTime.Before(t.startTime) {
+		return errors.New("End time cannot be before start time")
+	}
+
+	return nil
+}
+
+func (t *nthIncludedDayTrigger) TriggerBuilder() *TriggerBuilder {
+	return &TriggerBuilder{
+		Key:             t.Key(),
+		Description:     t.desc,
+		StartTime:       t.startTime,
+		EndTime:         t.endTime,
+		Priority:        t.priority,
+		TimeZone:        t.timeZone,
+		CalendarName:    t.calName,
+		JobKey:          t.JobKey(),
+		DataMap:         t.dataMap,
+		ScheduleBuilder: t.ScheduleBuilder(),
+	}
+}
+
+func (t *nthIncludedDayTrigger) ScheduleBuilder() ScheduleBuilder {
+	return &NthIncludedDayScheduleBuilder{t.n, t.intervalType, t.fireAtTime, cloneCalendar(t.calendar)}
+}
@@ -0,0 +1,209 @@
+package quartz
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// weekendCalendar excludes the Saturdays and the Sundays.
+type weekendCalendar struct {
+	baseCalendar
+}
+
+func (c *weekendCalendar) excluded(t time.Time) bool {
+	weekday := t.In(c.Location()).Weekday()
+
+	return weekday == time.Saturday || weekday == time.Sunday
+}
+
+func (c *weekendCalendar) IsTimeIncluded(t time.Time) bool {
+	return c.baseCalendar.IsTimeIncluded(t) && !c.excluded(t)
+}
+
+func (c *weekendCalendar) NextIncludedTime(t time.Time) time.Time {
+	return c.nextIncludedTime(t, c.excluded, func(t time.Time) time.Time { return nextDay(t, c.Location()) })
+}
+
+func (c *weekendCalendar) Clone() interface{} { return &weekendCalendar{c.baseCalendar.clone()} }
+
+func TestNthIncludedDayTrigger(t *testing.T) {
+	Convey("Given a calendar of the business days with a holiday", t, func() {
+		weekdays := &weekendCalendar{}
+		weekdays.SetLocation(time.UTC)
+
+		holidays := NewHolidayCalendar(weekdays)
+		holidays.SetLocation(time.UTC)
+		holidays.AddExcludedDate(time.Date(2024, time.March, 4, 0, 0, 0, 0, time.UTC))
+
+		nineAM := TimeOfDay{Hour: 9}
+
+		newTrigger := func(sb *NthIncludedDayScheduleBuilder, startTime time.Time) OperableTrigger {
+			return (&TriggerBuilder{}).
+				WithIdentity("trigger").
+				ForJob("job").
+				StartAt(startTime).
+				InTimeZone(time.UTC).
+				WithSchedule(sb).
+				Build().(OperableTrigger)
+		}
+
+		march := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+		Convey("When fires on the 3rd business day of each month", func() {
+			trigger := newTrigger(NthIncludedDay(3, INTERVAL_TYPE_MONTHLY).AtTimeOfDay(nineAM).WithCalendar(holidays), march)
+
+			So(trigger.Validate(), ShouldBeNil)
+
+			Convey("The holiday should shift the 3rd business day", func() {
+				So(trigger.ComputeFirstFireTime(nil), ShouldResemble, time.Date(2024, time.March, 6, 9, 0, 0, 0, time.UTC))
+
+				trigger.Triggered(nil)
+
+				So(trigger.PreviousFireTime(), ShouldResemble, time.Date(2024, time.March, 6, 9, 0, 0, 0, time.UTC))
+				So(trigger.NextFireTime(), ShouldResemble, time.Date(2024, time.April, 3, 9, 0, 0, 0, time.UTC))
+			})
+
+			Convey("The calendar given when scheduled should replace the trigger's one", func() {
+				So(trigger.ComputeFirstFireTime(weekdays), ShouldResemble, time.Date(2024, time.March, 5, 9, 0, 0, 0, time.UTC))
+			})
+
+			Convey("The fire time on the Nth day should only be returned after the given time", func() {
+				So(trigger.FireTimeAfter(time.Date(2024, time.March, 6, 8, 0, 0, 0, time.UTC)), ShouldResemble, time.Date(2024, time.March, 6, 9, 0, 0, 0, time.UTC))
+				So(trigger.FireTimeAfter(time.Date(2024, time.March, 6, 9, 0, 0, 0, time.UTC)), ShouldResemble, time.Date(2024, time.April, 3, 9, 0, 0, 0, time.UTC))
+			})
+
+			Convey("The missed fire times should be skipped after a misfire", func() {
+				trigger.ComputeFirstFireTime(nil)
+				trigger.UpdateAfterMisfire(nil, time.Date(2024, time.May, 15, 0, 0, 0, 0, time.UTC))
+
+				So(trigger.NextFireTime(), ShouldResemble, time.Date(2024, time.June, 5, 9, 0, 0, 0, time.UTC))
+			})
+
+			Convey("The copy should keep the schedule", func() {
+				clone := trigger.Clone().(OperableTrigger)
+
+				So(clone.ComputeFirstFireTime(nil), ShouldResemble, time.Date(2024, time.March, 6, 9, 0, 0, 0, time.UTC))
+				So(DescribeSchedule(clone), ShouldEqual, "on the 3rd included day of each month at 09:00:00")
+			})
+		})
+
+		Convey("When the intervals have less than N business days", func() {
+			trigger := newTrigger(NthIncludedDay(23, INTERVAL_TYPE_MONTHLY).WithCalendar(weekdays), time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC))
+
+			Convey("They should be skipped", func() {
+				So(trigger.ComputeFirstFireTime(nil), ShouldResemble, time.Date(2024, time.May, 31, 12, 0, 0, 0, time.UTC))
+			})
+		})
+
+		Convey("When fires on the Nth business day of each week or year", func() {
+			weekly := newTrigger(NthIncludedDay(1, INTERVAL_TYPE_WEEKLY).AtTimeOfDay(nineAM).WithCalendar(holidays), march)
+			yearly := newTrigger(NthIncludedDay(3, INTERVAL_TYPE_YEARLY).AtTimeOfDay(nineAM).WithCalendar(holidays), march)
+
+			Convey("The first business day of the weeks after the start time should be returned", func() {
+				So(weekly.ComputeFirstFireTime(nil), ShouldResemble, time.Date(2024, time.March, 5, 9, 0, 0, 0, time.UTC))
+
+				weekly.Triggered(nil)
+
+				So(weekly.NextFireTime(), ShouldResemble, time.Date(2024, time.March, 11, 9, 0, 0, 0, time.UTC))
+			})
+
+			Convey("The 3rd business day of the next year should be returned", func() {
+				So(yearly.ComputeFirstFireTime(nil), ShouldResemble, time.Date(2025, time.January, 3, 9, 0, 0, 0, time.UTC))
+			})
+		})
+
+		Convey("When the trigger has an end time", func() {
+			trigger := (&TriggerBuilder{}).
+				WithIdentity("trigger").
+				ForJob("job").
+				StartAt(march).
+				EndAt(time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)).
+				InTimeZone(time.UTC).
+				WithSchedule(NthIncludedDay(3, INTERVAL_TYPE_MONTHLY).AtTimeOfDay(nineAM).WithCalendar(holidays)).
+				Build().(OperableTrigger)
+
+			Convey("It should not fire after the end time", func() {
+				So(trigger.FinalFireTime(), ShouldResemble, time.Date(2024, time.April, 3, 9, 0, 0, 0, time.UTC))
+				So(trigger.FireTimeAfter(time.Date(2024, time.April, 3, 9, 0, 0, 0, time.UTC)).IsZero(), ShouldBeTrue)
+			})
+		})
+
+		Convey("When the trigger is stored in a snapshot", func() {
+			store := NewRAMJobStore()
+
+			// the weekendCalendar can't be serialized, the snapshot only keeps the holidays.
+			stored := NewHolidayCalendar(nil)
+			stored.SetLocation(time.UTC)
+			stored.AddExcludedDate(time.Date(2024, time.March, 4, 0, 0, 0, 0, time.UTC))
+
+			So(store.StoreCalendar("business-days", stored, false), ShouldBeNil)
+
+			job := (&JobBuilder{}).WithIdentity("job").StoreDurably(true).Build()
+
+			So(store.StoreJob(job, false), ShouldBeNil)
+
+			shanghai, _ := time.LoadLocation("Asia/Shanghai")
+
+			trigger := (&TriggerBuilder{}).
+				WithIdentity("trigger").
+				ForJob("job").
+				StartAt(march).
+				InTimeZone(shanghai).
+				ModifiedByCalendar("business-days").
+				WithSchedule(NthIncludedDay(3, INTERVAL_TYPE_MONTHLY).AtTimeOfDay(nineAM)).
+				Build().(OperableTrigger)
+			trigger.ComputeFirstFireTime(holidays)
+
+			So(store.StoreTrigger(trigger, false), ShouldBeNil)
+
+			snapshot, err := store.Snapshot()
+
+			So(err, ShouldBeNil)
+
+			restored := NewRAMJobStore()
+
+			So(restored.RestoreSnapshot(snapshot), ShouldBeNil)
+
+			Convey("The restored trigger should keep the schedule", func() {
+				other, err := restored.RetrieveTrigger(trigger.Key())
+
+				So(err, ShouldBeNil)
+				So(other, ShouldHaveSameTypeAs, trigger)
+				So(DescribeSchedule(other), ShouldEqual, "on the 3rd included day of each month at 09:00:00")
+				So(other.TimeZone().String(), ShouldEqual, "Asia/Shanghai")
+				So(other.CalendarName(), ShouldEqual, "business-days")
+				So(other.NextFireTime().Equal(time.Date(2024, time.March, 6, 9, 0, 0, 0, shanghai)), ShouldBeTrue)
+
+				Convey("It should fire on the days of the stored calendar", func() {
+					other.Triggered(holidays)
+
+					So(other.NextFireTime(), ShouldResemble, time.Date(2024, time.April, 3, 9, 0, 0, 0, shanghai))
+				})
+			})
+		})
+
+		Convey("When fires on the Nth day without calendar", func() {
+			trigger := newTrigger(NthIncludedDay(3, INTERVAL_TYPE_MONTHLY), march)
+
+			Convey("All the days should be included", func() {
+				So(trigger.ComputeFirstFireTime(nil), ShouldResemble, time.Date(2024, time.March, 3, 12, 0, 0, 0, time.UTC))
+			})
+		})
+	})
+
+	Convey("Given the invalid schedules", t, func() {
+		for _, sb := range []*NthIncludedDayScheduleBuilder{
+			NthIncludedDay(0, INTERVAL_TYPE_MONTHLY),
+			NthIncludedDay(8, INTERVAL_TYPE_WEEKLY),
+			NthIncludedDay(32, INTERVAL_TYPE_MONTHLY),
+			NthIncludedDay(367, INTERVAL_TYPE_YEARLY),
+			NthIncludedDay(1, NthIncludedDayIntervalType(0)),
+		} {
+			_, err := sb.BuildE()
+
+			So(err, ShouldNotBeNil)
+		}
+	})
+}
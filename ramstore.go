@@ -1,9 +1,11 @@
 package quartz
 
 import (
+	"errors"
 	"fmt"
-	"strings"
+	"sort"
 	"sync"
+	"time"
 )
 
 type TriggerState int
@@ -17,19 +19,39 @@ const (
 	STATE_BLOCKED
 	STATE_PAUSED_BLOCKED
 	STATE_ERROR
+	// STATE_NONE is returned for the trigger which does not exist.
+	STATE_NONE
 )
 
 func jobAlreadyExistsError(job JobDetail) error {
-	return fmt.Errorf("Unable to store Job : '%s', because one already exists with this identification.", job.Key())
+	return &ErrJobAlreadyExists{job.Key()}
 }
 
 func triggerAlreadyExistsError(trigger Trigger) error {
-	return fmt.Errorf("Unable to store Trigger with name: '%s' and group: '%s', "+
-		"because one already exists with this identification.", trigger.Key().Name, trigger.Key().Group)
+	return &ErrTriggerAlreadyExists{trigger.Key()}
 }
 
-func jobPersistenceError(key JobKey) error {
-	return fmt.Errorf("The job (%s) referenced by the trigger does not exist.", key.String())
+// ErrMissingJobKey is returned when a trigger built without ForJob is stored.
+var ErrMissingJobKey = errors.New("the trigger has no job key")
+
+func missingJobKeyError(trigger Trigger) error {
+	return fmt.Errorf("Unable to store Trigger : '%s', %w.", trigger.Key(), ErrMissingJobKey)
+}
+
+func triggerNotFoundError(key TriggerKey) error {
+	return &ErrTriggerNotFound{key}
+}
+
+func jobPersistenceError(trigger Trigger) error {
+	return &ErrUnableToResolveJob{trigger.JobKey(), trigger.Key()}
+}
+
+func calendarAlreadyExistsError(name string) error {
+	return fmt.Errorf("Unable to store Calendar : '%s', because one already exists with this identification.", name)
+}
+
+func calendarReferencedError(name string) error {
+	return fmt.Errorf("The calendar (%s) cannot be removed since it is referenced by a trigger.", name)
 }
 
 type jobWrapper struct {
@@ -42,6 +64,9 @@ type triggerWrapper struct {
 	trigger OperableTrigger
 
 	state TriggerState
+
+	// index is the position of the trigger in the timeTriggerQueue.
+	index int
 }
 
 func (w *triggerWrapper) Key() TriggerKey { return w.trigger.Key() }
@@ -53,34 +78,156 @@ type JobMap map[string]*jobWrapper
 type TriggerMap map[string]*triggerWrapper
 
 type RAMJobStore struct {
-	lock                sync.Mutex
+	lock                sync.RWMutex
 	jobsByKey           JobMap
 	triggersByKey       TriggerMap
 	jobsByGroup         map[string]JobMap
 	triggersByGroup     map[string]TriggerMap
-	timeTriggers        Set
-	triggers            []*triggerWrapper
+	timeTriggers        *timeTriggerQueue
+	triggersByJob       map[string]TriggerMap
 	pausedTriggerGroups Set
 	pausedJobGroups     Set
 	blockedJobs         Set
+	fired               map[string]firedRecord
+	calendars           *calendarMap
+	normalizeToUTC      bool
+	displayLocation     *time.Location
+	clock               Clock
 }
 
 func NewRAMJobStore() *RAMJobStore {
 	return &RAMJobStore{
-		jobsByKey:       make(JobMap),
-		triggersByKey:   make(TriggerMap),
-		jobsByGroup:     make(map[string]JobMap),
-		triggersByGroup: make(map[string]TriggerMap),
-		timeTriggers: NewTreeSet(func(lhs, rhs interface{}) int {
-			return strings.Compare(lhs.(Trigger).Key().String(), rhs.(Trigger).Key().String())
-		}),
-		pausedTriggerGroups: NewHashSet(),
-		pausedJobGroups:     NewHashSet(),
+		jobsByKey:           make(JobMap),
+		triggersByKey:       make(TriggerMap),
+		jobsByGroup:         make(map[string]JobMap),
+		triggersByGroup:     make(map[string]TriggerMap),
+		triggersByJob:       make(map[string]TriggerMap),
+		timeTriggers:        newTimeTriggerQueue(),
+		pausedTriggerGroups: NewSortedHashSet(StringLess),
+		pausedJobGroups:     NewSortedHashSet(StringLess),
 		blockedJobs:         NewHashSet(),
+		fired:               make(map[string]firedRecord),
+		calendars:           newCalendarMap(),
+		clock:               SystemClock,
+	}
+}
+
+// SetClock sets the clock of the fire times and the batches, it must be called before the scheduler is started.
+func (s *RAMJobStore) SetClock(clock Clock) {
+	s.clock = clock
+}
+
+// NormalizeTimesToUTC converts the start, end and fire times of the stored triggers to UTC,
+// and converts them back to the display location when the triggers are retrieved.
+func (s *RAMJobStore) NormalizeTimesToUTC(normalize bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.normalizeToUTC = normalize
+}
+
+// SetDisplayLocation sets the location of the retrieved trigger times, defaults to time.Local.
+func (s *RAMJobStore) SetDisplayLocation(loc *time.Location) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.displayLocation = loc
+}
+
+func (s *RAMJobStore) displayTrigger(trigger OperableTrigger) OperableTrigger {
+	trigger = trigger.Clone().(OperableTrigger)
+
+	if s.normalizeToUTC {
+		loc := s.displayLocation
+
+		if loc == nil {
+			loc = time.Local
+		}
+
+		triggerTimesIn(trigger, loc)
+	}
+
+	return trigger
+}
+
+// triggerTimesIn converts the start, end and fire times of the trigger to the location.
+func triggerTimesIn(trigger OperableTrigger, loc *time.Location) {
+	if endTime := trigger.EndTime(); !endTime.IsZero() {
+		trigger.SetEndTime(endTime.In(loc))
+	}
+
+	if startTime := trigger.StartTime(); !startTime.IsZero() {
+		trigger.SetStartTime(startTime.In(loc))
+	}
+
+	if nextFireTime := trigger.NextFireTime(); !nextFireTime.IsZero() {
+		trigger.SetNextFireTime(nextFireTime.In(loc))
+	}
+
+	if previousFireTime := trigger.PreviousFireTime(); !previousFireTime.IsZero() {
+		trigger.SetPreviousFireTime(previousFireTime.In(loc))
+	}
+}
+
+// SchedulerStarted releases the triggers left acquired by a previous scheduler, so they are fired again,
+// and schedules the jobs requesting recovery again, if they were left executing.
+func (s *RAMJobStore) SchedulerStarted() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, tw := range s.triggersByKey {
+		if tw.state == STATE_ACQUIRED {
+			tw.state = STATE_WAITING
+
+			s.timeTriggers.push(tw)
+		}
 	}
+
+	return s.recoverJobs(s.clock.Now())
 }
 
-func (s *RAMJobStore) SchedulerStarted() error { return nil }
+// recoverJobs stores a one-shot trigger firing now for each executing job requesting recovery,
+// and unblocks the jobs left executing, the caller must hold the lock.
+func (s *RAMJobStore) recoverJobs(now time.Time) error {
+	keys := make([]string, 0, len(s.fired))
+
+	for key := range s.fired {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		r := s.fired[key]
+
+		jw, exists := s.jobsByKey[r.JobKey]
+
+		if !exists || !jw.jobDetail.RequestsRecovery() {
+			continue
+		}
+
+		var dataMap JobDataMap
+
+		if tw, exists := s.triggersByKey[key]; exists {
+			dataMap = tw.trigger.JobDataMap()
+		}
+
+		trigger := newRecoveryTrigger(TriggerKey(key), JobKey(r.JobKey), r.ScheduledFireTime, r.FireTime, dataMap, now)
+
+		if err := s.storeTrigger(trigger, false); err != nil {
+			return err
+		}
+	}
+
+	s.fired = make(map[string]firedRecord)
+
+	// no job is executing before the scheduler is started.
+	for _, key := range stringKeys(s.blockedJobs) {
+		s.unblockJob(JobKey(key))
+	}
+
+	return nil
+}
 
 func (s *RAMJobStore) SchedulerPaused() {}
 
@@ -90,12 +237,20 @@ func (s *RAMJobStore) SupportsPersistence() bool { return false }
 
 func (s *RAMJobStore) Clustered() bool { return false }
 
+func (s *RAMJobStore) Shutdown() {}
+
 func (s *RAMJobStore) StoreJobAndTrigger(job JobDetail, trigger OperableTrigger) error {
-	if err := s.StoreJob(job, false); err != nil {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.storeJob(job, false); err != nil {
 		return err
 	}
 
-	if err := s.StoreTrigger(trigger, false); err != nil {
+	// the job didn't exist, it's removed again to not be left behind without the trigger.
+	if err := s.storeTrigger(trigger, false); err != nil {
+		s.removeJob(job.Key())
+
 		return err
 	}
 
@@ -108,12 +263,12 @@ func (s *RAMJobStore) StoreJobsAndTriggers(triggersAndJobs map[JobDetail][]Trigg
 
 	if !replace {
 		for job, triggers := range triggersAndJobs {
-			if s.CheckJobExists(job.Key()) {
+			if s.checkJobExists(job.Key()) {
 				return jobAlreadyExistsError(job)
 			}
 
 			for _, trigger := range triggers {
-				if s.CheckTriggerExists(trigger.Key()) {
+				if s.checkTriggerExists(trigger.Key()) {
 					return triggerAlreadyExistsError(trigger)
 				}
 			}
@@ -121,12 +276,12 @@ func (s *RAMJobStore) StoreJobsAndTriggers(triggersAndJobs map[JobDetail][]Trigg
 	}
 
 	for job, triggers := range triggersAndJobs {
-		if err := s.StoreJob(job, true); err != nil {
+		if err := s.storeJob(job, true); err != nil {
 			return err
 		}
 
 		for _, trigger := range triggers {
-			if err := s.StoreTrigger(trigger.(OperableTrigger), true); err != nil {
+			if err := s.storeTrigger(trigger.(OperableTrigger), true); err != nil {
 				return err
 			}
 		}
@@ -139,6 +294,10 @@ func (s *RAMJobStore) StoreJob(jobDetail JobDetail, replaceExisting bool) error
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
+	return s.storeJob(jobDetail, replaceExisting)
+}
+
+func (s *RAMJobStore) storeJob(jobDetail JobDetail, replaceExisting bool) error {
 	jw, exists := s.jobsByKey[jobDetail.Key().String()]
 
 	if exists {
@@ -171,13 +330,73 @@ func (s *RAMJobStore) RemoveJob(key JobKey) (bool, error) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
-	return false, nil
+	return s.removeJob(key), nil
+}
+
+// removeJob removes the job and its triggers, the caller must hold the lock.
+func (s *RAMJobStore) removeJob(key JobKey) bool {
+	for _, tw := range s.triggersForJob(key) {
+		s.removeTrigger(tw.Key(), false)
+	}
+
+	jw, exists := s.jobsByKey[key.String()]
+
+	if exists {
+		delete(s.jobsByKey, key.String())
+
+		if jobs := s.jobsByGroup[key.Group()]; jobs != nil {
+			delete(jobs, key.String())
+
+			if len(jobs) == 0 {
+				delete(s.jobsByGroup, key.Group())
+			}
+		}
+
+		s.blockedJobs.Remove(key.String())
+	}
+
+	return exists && jw != nil
 }
 
 func (s *RAMJobStore) StoreTrigger(trigger OperableTrigger, replaceExisting bool) error {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
+	return s.storeTrigger(trigger, replaceExisting)
+}
+
+func (s *RAMJobStore) StoreTriggers(triggers []OperableTrigger, replaceExisting bool) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, trigger := range triggers {
+		if trigger.JobKey() == nil {
+			return missingJobKeyError(trigger)
+		}
+
+		if !replaceExisting && s.checkTriggerExists(trigger.Key()) {
+			return triggerAlreadyExistsError(trigger)
+		}
+
+		if !s.checkJobExists(trigger.JobKey()) {
+			return jobPersistenceError(trigger)
+		}
+	}
+
+	for _, trigger := range triggers {
+		if err := s.storeTrigger(trigger, true); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *RAMJobStore) storeTrigger(trigger OperableTrigger, replaceExisting bool) error {
+	if trigger.JobKey() == nil {
+		return missingJobKeyError(trigger)
+	}
+
 	_, exists := s.triggersByKey[trigger.Key().String()]
 
 	if exists {
@@ -188,13 +407,18 @@ func (s *RAMJobStore) StoreTrigger(trigger OperableTrigger, replaceExisting bool
 		s.removeTrigger(trigger.Key(), false)
 	}
 
-	if job := s.RetrieveJob(trigger.JobKey()); job == nil {
-		return jobPersistenceError(trigger.JobKey())
+	if _, exists := s.jobsByKey[trigger.JobKey().String()]; !exists {
+		return jobPersistenceError(trigger)
 	}
 
-	tw := &triggerWrapper{trigger: trigger}
+	// the trigger is copied, the caller changing its fire times would corrupt the order of the queued triggers.
+	trigger = trigger.Clone().(OperableTrigger)
 
-	s.triggers = append(s.triggers, tw)
+	if s.normalizeToUTC {
+		triggerTimesIn(trigger, time.UTC)
+	}
+
+	tw := &triggerWrapper{trigger: trigger}
 
 	grpMap, exists := s.triggersByGroup[trigger.Key().Group()]
 
@@ -206,6 +430,11 @@ func (s *RAMJobStore) StoreTrigger(trigger OperableTrigger, replaceExisting bool
 
 	grpMap[trigger.Key().String()] = tw
 
+	if s.triggersByJob[trigger.JobKey().String()] == nil {
+		s.triggersByJob[trigger.JobKey().String()] = make(TriggerMap)
+	}
+
+	s.triggersByJob[trigger.JobKey().String()][trigger.Key().String()] = tw
 	s.triggersByKey[trigger.Key().String()] = tw
 
 	if s.pausedTriggerGroups.Contains(trigger.Key().Group()) || s.pausedJobGroups.Contains(trigger.JobKey().Group()) {
@@ -217,50 +446,48 @@ func (s *RAMJobStore) StoreTrigger(trigger OperableTrigger, replaceExisting bool
 	} else if s.blockedJobs.Contains(trigger.JobKey().String()) {
 		tw.state = STATE_BLOCKED
 	} else {
-		s.timeTriggers.Add(tw)
+		s.timeTriggers.push(tw)
 	}
 
 	return nil
 }
 
-func (s *RAMJobStore) RemoveTrigger(key TriggerKey) bool {
-	return s.removeTrigger(key, true)
-}
-
-func (s *RAMJobStore) removeTrigger(key TriggerKey, removeOrphanedJob bool) bool {
+func (s *RAMJobStore) RemoveTrigger(key TriggerKey) (bool, error) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
-	_, exists := s.triggersByKey[key.String()]
+	return s.removeTrigger(key, true), nil
+}
+
+func (s *RAMJobStore) removeTrigger(key TriggerKey, removeOrphanedJob bool) bool {
+	tw, exists := s.triggersByKey[key.String()]
 
 	if exists {
 		delete(s.triggersByKey, key.String())
 
-		if triggers, exists := s.triggersByGroup[key.Group()]; exists && len(triggers) > 0 {
+		if triggers := s.triggersByGroup[key.Group()]; triggers != nil {
 			delete(triggers, key.String())
+		}
 
-			if len(triggers) == 0 {
-				delete(s.triggersByGroup, key.Group())
-			}
+		if len(s.triggersByGroup[key.Group()]) == 0 {
+			delete(s.triggersByGroup, key.Group())
 		}
 
-		var tw *triggerWrapper
+		if triggers := s.triggersByJob[tw.JobKey().String()]; triggers != nil {
+			delete(triggers, key.String())
 
-		for i, trigger := range s.triggers {
-			if !trigger.Key().Equals(key) {
-				tw = trigger
-				s.triggers = append(s.triggers[:i], s.triggers[i+1:]...)
+			if len(triggers) == 0 {
+				delete(s.triggersByJob, tw.JobKey().String())
 			}
 		}
 
-		s.timeTriggers.Remove(tw)
+		s.timeTriggers.remove(tw.Key())
 
 		if removeOrphanedJob {
 			jw, exists := s.jobsByKey[tw.JobKey().String()]
-			triggers := s.TriggersForJob(tw.JobKey())
 
-			if len(triggers) == 0 && exists && jw.jobDetail.Durable() {
-				s.RemoveJob(jw.Key())
+			if exists && len(s.triggersByJob[tw.JobKey().String()]) == 0 && !jw.jobDetail.Durable() {
+				s.removeJob(jw.Key())
 			}
 		}
 	}
@@ -275,11 +502,7 @@ func (s *RAMJobStore) RemoveJobs(keys []JobKey) (bool, error) {
 	allFound := true
 
 	for _, key := range keys {
-		if found, err := s.RemoveJob(key); err != nil {
-			return false, err
-		} else {
-			allFound = found && allFound
-		}
+		allFound = s.removeJob(key) && allFound
 	}
 
 	return allFound, nil
@@ -292,59 +515,870 @@ func (s *RAMJobStore) RemoveTriggers(keys []TriggerKey) (bool, error) {
 	allFound := true
 
 	for _, key := range keys {
-		allFound = s.RemoveTrigger(key) && allFound
+		allFound = s.removeTrigger(key, true) && allFound
 	}
 
 	return allFound, nil
 }
 
-func (s *RAMJobStore) RetrieveJob(key JobKey) JobDetail {
+func (s *RAMJobStore) ReplaceTrigger(key TriggerKey, trigger OperableTrigger) error {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
+	tw, exists := s.triggersByKey[key.String()]
+
+	if !exists {
+		return triggerNotFoundError(key)
+	}
+
+	if !tw.JobKey().Equals(trigger.JobKey()) {
+		return errors.New("New trigger is not related to the same job as the old trigger.")
+	}
+
+	s.removeTrigger(key, false)
+
+	return s.storeTrigger(trigger, false)
+}
+
+// RetrieveJob returns a copy of the job, or nil if it doesn't exist.
+func (s *RAMJobStore) RetrieveJob(key JobKey) (JobDetail, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
 	if jw, exists := s.jobsByKey[key.String()]; exists {
-		return jw.jobDetail.Clone().(JobDetail)
+		return jw.jobDetail.Clone().(JobDetail), nil
 	}
 
-	return nil
+	return nil, nil
+}
+
+// RetrieveTrigger returns a copy of the trigger, or nil if it doesn't exist.
+func (s *RAMJobStore) RetrieveTrigger(key TriggerKey) (OperableTrigger, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if tw, exists := s.triggersByKey[key.String()]; exists {
+		return s.displayTrigger(tw.trigger), nil
+	}
+
+	return nil, nil
+}
+
+func (s *RAMJobStore) RetrieveTriggerWithState(key TriggerKey) (OperableTrigger, TriggerState, time.Time, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	tw, exists := s.triggersByKey[key.String()]
+
+	if !exists {
+		return nil, STATE_NONE, zero, triggerNotFoundError(key)
+	}
+
+	trigger := s.displayTrigger(tw.trigger)
+
+	return trigger, tw.state, trigger.NextFireTime(), nil
+}
+
+// GetTriggerState returns the current state of the trigger, or STATE_NONE with ErrTriggerNotFound if it does not exist.
+func (s *RAMJobStore) GetTriggerState(key TriggerKey) (TriggerState, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	tw, exists := s.triggersByKey[key.String()]
+
+	if !exists {
+		return STATE_NONE, triggerNotFoundError(key)
+	}
+
+	return tw.state, nil
 }
 
-func (s *RAMJobStore) RetrieveTrigger(key TriggerKey) OperableTrigger {
+// ResetTriggerFromErrorState moves the trigger out of STATE_ERROR, the trigger in other states is left untouched.
+func (s *RAMJobStore) ResetTriggerFromErrorState(key TriggerKey) error {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
-	if tw, exists := s.triggersByKey[key.String()]; exists {
-		return tw.trigger.Clone().(OperableTrigger)
+	tw, exists := s.triggersByKey[key.String()]
+
+	if !exists {
+		return triggerNotFoundError(key)
+	}
+
+	if tw.state != STATE_ERROR {
+		return nil
+	}
+
+	paused := s.pausedTriggerGroups.Contains(key.Group()) || s.pausedJobGroups.Contains(tw.JobKey().Group())
+	blocked := s.blockedJobs.Contains(tw.JobKey().String())
+
+	switch {
+	case paused && blocked:
+		tw.state = STATE_PAUSED_BLOCKED
+	case paused:
+		tw.state = STATE_PAUSED
+	case blocked:
+		tw.state = STATE_BLOCKED
+	default:
+		tw.state = STATE_WAITING
+
+		s.timeTriggers.push(tw)
 	}
 
 	return nil
 }
 
 func (s *RAMJobStore) TriggersForJob(key JobKey) (triggers []OperableTrigger) {
-	s.lock.Lock()
-	defer s.lock.Unlock()
+	s.lock.RLock()
+	defer s.lock.RUnlock()
 
-	for _, tw := range s.triggers {
-		if tw.JobKey().Equals(key) {
-			triggers = append(triggers, tw.trigger.Clone().(OperableTrigger))
-		}
+	for _, tw := range s.triggersForJob(key) {
+		triggers = append(triggers, s.displayTrigger(tw.trigger))
+	}
+
+	return
+}
+
+// triggersForJob returns the triggers of the job ordered by their keys.
+func (s *RAMJobStore) triggersForJob(key JobKey) (triggers []*triggerWrapper) {
+	for _, tw := range s.triggersByJob[key.String()] {
+		triggers = append(triggers, tw)
 	}
 
+	sort.Slice(triggers, func(i, j int) bool { return groupedKeyLess(triggers[i].Key(), triggers[j].Key()) })
+
 	return
 }
 
+func (s *RAMJobStore) GetJobKeys(matcher GroupMatcher) ([]JobKey, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	var keys []JobKey
+
+	for group, jobs := range s.jobsByGroup {
+		if matcher.MatchesGroup(group) {
+			for _, jw := range jobs {
+				keys = append(keys, jw.Key())
+			}
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool { return groupedKeyLess(keys[i], keys[j]) })
+
+	return keys, nil
+}
+
+func (s *RAMJobStore) GetTriggerKeys(matcher GroupMatcher) ([]TriggerKey, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	var keys []TriggerKey
+
+	for group, triggers := range s.triggersByGroup {
+		if matcher.MatchesGroup(group) {
+			for _, tw := range triggers {
+				keys = append(keys, tw.Key())
+			}
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool { return groupedKeyLess(keys[i], keys[j]) })
+
+	return keys, nil
+}
+
+func (s *RAMJobStore) NumberOfJobs() int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return len(s.jobsByKey)
+}
+
+func (s *RAMJobStore) NumberOfTriggers() int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return len(s.triggersByKey)
+}
+
 func (s *RAMJobStore) CheckJobExists(key JobKey) bool {
-	s.lock.Lock()
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.checkJobExists(key)
+}
+
+func (s *RAMJobStore) checkJobExists(key JobKey) bool {
 	jw, exists := s.jobsByKey[key.String()]
-	s.lock.Unlock()
 
 	return exists && jw != nil
 }
 
 func (s *RAMJobStore) CheckTriggerExists(key TriggerKey) bool {
-	s.lock.Lock()
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.checkTriggerExists(key)
+}
+
+func (s *RAMJobStore) checkTriggerExists(key TriggerKey) bool {
 	tw, exists := s.triggersByKey[key.String()]
-	s.lock.Unlock()
 
 	return exists && tw != nil
 }
+
+// StoreCalendar stores a copy of the calendar, the fire times of the triggers using a replaced calendar are not updated.
+func (s *RAMJobStore) StoreCalendar(name string, calendar Calendar, replaceExisting bool) error {
+	return s.calendars.store(name, calendar, replaceExisting)
+}
+
+func (s *RAMJobStore) RemoveCalendar(name string) (bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, tw := range s.triggersByKey {
+		if tw.trigger.CalendarName() == name {
+			return false, calendarReferencedError(name)
+		}
+	}
+
+	return s.calendars.remove(name), nil
+}
+
+func (s *RAMJobStore) RetrieveCalendar(name string) (Calendar, error) {
+	return cloneCalendar(s.calendars.retrieve(name)), nil
+}
+
+func (s *RAMJobStore) GetCalendarNames() ([]string, error) {
+	return s.calendars.names(), nil
+}
+
+func (s *RAMJobStore) PauseTrigger(key TriggerKey) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if tw, exists := s.triggersByKey[key.String()]; exists {
+		s.pauseTrigger(tw)
+	}
+
+	return nil
+}
+
+func (s *RAMJobStore) pauseTrigger(tw *triggerWrapper) {
+	switch tw.state {
+	case STATE_COMPLETE, STATE_PAUSED, STATE_PAUSED_BLOCKED:
+		return
+
+	case STATE_BLOCKED:
+		tw.state = STATE_PAUSED_BLOCKED
+
+	default:
+		tw.state = STATE_PAUSED
+	}
+
+	s.timeTriggers.remove(tw.Key())
+}
+
+func (s *RAMJobStore) ResumeTrigger(key TriggerKey) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if tw, exists := s.triggersByKey[key.String()]; exists {
+		s.resumeTrigger(tw)
+	}
+
+	return nil
+}
+
+func (s *RAMJobStore) resumeTrigger(tw *triggerWrapper) {
+	if tw.state != STATE_PAUSED && tw.state != STATE_PAUSED_BLOCKED {
+		return
+	}
+
+	if s.blockedJobs.Contains(tw.JobKey().String()) {
+		tw.state = STATE_BLOCKED
+	} else {
+		tw.state = STATE_WAITING
+
+		s.timeTriggers.push(tw)
+	}
+}
+
+func (s *RAMJobStore) PauseJob(key JobKey) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, tw := range s.triggersForJob(key) {
+		s.pauseTrigger(tw)
+	}
+
+	return nil
+}
+
+func (s *RAMJobStore) ResumeJob(key JobKey) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, tw := range s.triggersForJob(key) {
+		s.resumeTrigger(tw)
+	}
+
+	return nil
+}
+
+// matchedGroups returns the sorted groups matched by the matcher, the group of an EQUALS matcher is always returned.
+func matchedGroups(matcher GroupMatcher, candidates ...[]string) []string {
+	if matcher.Operator == EQUALS {
+		return []string{matcher.CompareTo}
+	}
+
+	matched := make(map[string]struct{})
+
+	for _, groups := range candidates {
+		for _, group := range groups {
+			if matcher.MatchesGroup(group) {
+				matched[group] = struct{}{}
+			}
+		}
+	}
+
+	groups := make([]string, 0, len(matched))
+
+	for group := range matched {
+		groups = append(groups, group)
+	}
+
+	sort.Strings(groups)
+
+	return groups
+}
+
+func (s *RAMJobStore) triggerGroups() (groups []string) {
+	for group := range s.triggersByGroup {
+		groups = append(groups, group)
+	}
+
+	return
+}
+
+func (s *RAMJobStore) jobGroups() (groups []string) {
+	for group := range s.jobsByGroup {
+		groups = append(groups, group)
+	}
+
+	return
+}
+
+func stringKeys(set Set) (keys []string) {
+	for _, key := range set.Keys() {
+		keys = append(keys, key.(string))
+	}
+
+	return
+}
+
+// PauseTriggers pauses the triggers in the groups matched by the matcher, and returns the paused groups.
+//
+// The triggers stored into a paused group later will be paused too.
+func (s *RAMJobStore) PauseTriggers(matcher GroupMatcher) ([]string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	groups := matchedGroups(matcher, s.triggerGroups())
+
+	for _, group := range groups {
+		s.pausedTriggerGroups.Add(group)
+
+		for _, tw := range s.triggersByGroup[group] {
+			s.pauseTrigger(tw)
+		}
+	}
+
+	return groups, nil
+}
+
+// ResumeTriggers resumes the triggers in the groups matched by the matcher, and returns the resumed groups.
+//
+// The triggers of the jobs in a paused job group are kept paused.
+func (s *RAMJobStore) ResumeTriggers(matcher GroupMatcher) ([]string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	groups := matchedGroups(matcher, s.triggerGroups(), stringKeys(s.pausedTriggerGroups))
+
+	for _, group := range groups {
+		s.pausedTriggerGroups.Remove(group)
+
+		for _, tw := range s.triggersByGroup[group] {
+			if !s.pausedJobGroups.Contains(tw.JobKey().Group()) {
+				s.resumeTrigger(tw)
+			}
+		}
+	}
+
+	return groups, nil
+}
+
+// PauseJobs pauses the triggers of the jobs in the groups matched by the matcher, and returns the paused groups.
+//
+// The triggers stored for the jobs in a paused group later will be paused too.
+func (s *RAMJobStore) PauseJobs(matcher GroupMatcher) ([]string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	groups := matchedGroups(matcher, s.jobGroups())
+
+	for _, group := range groups {
+		s.pausedJobGroups.Add(group)
+
+		for _, jw := range s.jobsByGroup[group] {
+			for _, tw := range s.triggersForJob(jw.Key()) {
+				s.pauseTrigger(tw)
+			}
+		}
+	}
+
+	return groups, nil
+}
+
+// ResumeJobs resumes the triggers of the jobs in the groups matched by the matcher, and returns the resumed groups.
+func (s *RAMJobStore) ResumeJobs(matcher GroupMatcher) ([]string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	groups := matchedGroups(matcher, s.jobGroups(), stringKeys(s.pausedJobGroups))
+
+	for _, group := range groups {
+		s.pausedJobGroups.Remove(group)
+
+		for _, jw := range s.jobsByGroup[group] {
+			for _, tw := range s.triggersForJob(jw.Key()) {
+				s.resumeTrigger(tw)
+			}
+		}
+	}
+
+	return groups, nil
+}
+
+// PauseAll pauses all the trigger groups, the triggers stored into them later will be paused too.
+func (s *RAMJobStore) PauseAll() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for group, triggers := range s.triggersByGroup {
+		s.pausedTriggerGroups.Add(group)
+
+		for _, tw := range triggers {
+			s.pauseTrigger(tw)
+		}
+	}
+
+	return nil
+}
+
+// ResumeAll resumes all the triggers and forgets the paused groups.
+func (s *RAMJobStore) ResumeAll() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.pausedJobGroups.RemoveAll(s.pausedJobGroups.Keys()...)
+	s.pausedTriggerGroups.RemoveAll(s.pausedTriggerGroups.Keys()...)
+
+	for _, tw := range s.triggersByKey {
+		s.resumeTrigger(tw)
+	}
+
+	return nil
+}
+
+func (s *RAMJobStore) ClearAllSchedulingData() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.jobsByKey = make(JobMap)
+	s.triggersByKey = make(TriggerMap)
+	s.jobsByGroup = make(map[string]JobMap)
+	s.triggersByGroup = make(map[string]TriggerMap)
+	s.timeTriggers.clear()
+	s.triggersByJob = make(map[string]TriggerMap)
+	s.blockedJobs = NewHashSet()
+	s.fired = make(map[string]firedRecord)
+	s.calendars.clear()
+
+	return nil
+}
+
+// calendarMap holds the calendars of a JobStore by their names.
+type calendarMap struct {
+	lock      sync.RWMutex
+	calendars map[string]Calendar
+}
+
+func newCalendarMap() *calendarMap {
+	return &calendarMap{calendars: make(map[string]Calendar)}
+}
+
+func (m *calendarMap) store(name string, calendar Calendar, replaceExisting bool) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if _, exists := m.calendars[name]; exists && !replaceExisting {
+		return calendarAlreadyExistsError(name)
+	}
+
+	m.calendars[name] = calendar.Clone().(Calendar)
+
+	return nil
+}
+
+func (m *calendarMap) remove(name string) bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	_, exists := m.calendars[name]
+
+	delete(m.calendars, name)
+
+	return exists
+}
+
+// retrieve returns the stored calendar, or nil if there is none, it must not be changed.
+func (m *calendarMap) retrieve(name string) Calendar {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	return m.calendars[name]
+}
+
+func (m *calendarMap) names() []string {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	var names []string
+
+	for name := range m.calendars {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func (m *calendarMap) clear() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.calendars = make(map[string]Calendar)
+}
+
+// records returns the records of the calendars ordered by their names.
+func (m *calendarMap) records() ([]calendarRecord, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	var records []calendarRecord
+
+	for name, calendar := range m.calendars {
+		r, err := newCalendarRecord(name, calendar)
+
+		if err != nil {
+			return nil, err
+		}
+
+		r.Name = name
+
+		records = append(records, *r)
+	}
+
+	sort.Slice(records, func(i, j int) bool { return records[i].Name < records[j].Name })
+
+	return records, nil
+}
+
+// restoreCalendars restores the recorded calendars by their names.
+func restoreCalendars(records []calendarRecord) (map[string]Calendar, error) {
+	calendars := make(map[string]Calendar, len(records))
+
+	for _, r := range records {
+		calendar, err := r.calendar(r.Name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		calendars[r.Name] = calendar
+	}
+
+	return calendars, nil
+}
+
+// replace replaces all the calendars, the given ones must not be changed anymore.
+func (m *calendarMap) replace(calendars map[string]Calendar) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.calendars = calendars
+}
+
+// calendarReferenced checks whether any trigger of the store uses the calendar.
+func calendarReferenced(store JobStore, name string) (bool, error) {
+	keys, err := store.GetTriggerKeys(AnyGroup())
+
+	if err != nil {
+		return false, err
+	}
+
+	for _, key := range keys {
+		trigger, err := store.RetrieveTrigger(key)
+
+		if err != nil {
+			return false, err
+		}
+
+		if trigger != nil && trigger.CalendarName() == name {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// batchEndAfter returns the latest fire time of the triggers acquired with the first one,
+// the overdue triggers are acquired together since they all fire now.
+func batchEndAfter(firstFireTime, now time.Time, timeWindow time.Duration) time.Time {
+	if firstFireTime.Before(now) {
+		firstFireTime = now
+	}
+
+	return firstFireTime.Add(timeWindow)
+}
+
+// AcquireNextTriggers acquires at most maxCount waiting triggers ordered by their next fire time and then by priority,
+// the first one fires no later than noLaterThan, and the others no later than the timeWindow after the first one,
+// or after now if the first one is overdue.
+func (s *RAMJobStore) AcquireNextTriggers(noLaterThan time.Time, maxCount int, timeWindow time.Duration) ([]OperableTrigger, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var triggers []OperableTrigger
+
+	// only one trigger of a job disallowing concurrent execution is acquired in a batch, the others are pushed back.
+	var excluded []*triggerWrapper
+
+	acquiredJobs := make(map[string]bool)
+
+	batchEnd := noLaterThan
+
+	for len(triggers) < maxCount {
+		tw := s.timeTriggers.peek()
+
+		if tw == nil {
+			break
+		}
+
+		fireTime := tw.trigger.NextFireTime()
+
+		if fireTime.IsZero() || fireTime.After(batchEnd) {
+			break
+		}
+
+		s.timeTriggers.pop()
+
+		if tw.state != STATE_WAITING {
+			continue
+		}
+
+		if jw, exists := s.jobsByKey[tw.JobKey().String()]; exists && jw.jobDetail.ConcurrentExecutionDisallowed() {
+			if acquiredJobs[jw.Key().String()] {
+				excluded = append(excluded, tw)
+
+				continue
+			}
+
+			acquiredJobs[jw.Key().String()] = true
+		}
+
+		tw.state = STATE_ACQUIRED
+
+		if len(triggers) == 0 {
+			batchEnd = batchEndAfter(fireTime, s.clock.Now(), timeWindow)
+		}
+
+		triggers = append(triggers, s.displayTrigger(tw.trigger))
+	}
+
+	for _, tw := range excluded {
+		s.timeTriggers.push(tw)
+	}
+
+	return triggers, nil
+}
+
+func (s *RAMJobStore) ReleaseAcquiredTrigger(trigger OperableTrigger) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if tw, exists := s.triggersByKey[trigger.Key().String()]; exists && tw.state == STATE_ACQUIRED {
+		tw.state = STATE_WAITING
+
+		s.timeTriggers.push(tw)
+	}
+}
+
+// TriggersFired updates the acquired triggers for their next fire time,
+// and returns the bundles to execute their jobs, the triggers no longer acquired are skipped.
+func (s *RAMJobStore) TriggersFired(triggers []OperableTrigger) ([]*TriggerFiredBundle, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var bundles []*TriggerFiredBundle
+
+	for _, trigger := range triggers {
+		tw, exists := s.triggersByKey[trigger.Key().String()]
+
+		if !exists || tw.state != STATE_ACQUIRED {
+			continue
+		}
+
+		jw, exists := s.jobsByKey[tw.JobKey().String()]
+
+		if !exists {
+			continue
+		}
+
+		noConcurrent := jw.jobDetail.ConcurrentExecutionDisallowed()
+
+		// the trigger acquired before its job was blocked waits for the job to complete.
+		if noConcurrent && s.blockedJobs.Contains(jw.Key().String()) {
+			tw.state = STATE_BLOCKED
+
+			continue
+		}
+
+		before := s.displayTrigger(tw.trigger)
+
+		cal := s.calendars.retrieve(tw.trigger.CalendarName())
+
+		tw.trigger.Triggered(cal)
+		tw.state = STATE_WAITING
+
+		if !tw.trigger.NextFireTime().IsZero() {
+			s.timeTriggers.push(tw)
+		}
+
+		if noConcurrent {
+			s.blockJob(jw.Key())
+		}
+
+		after := s.displayTrigger(tw.trigger)
+
+		bundle := &TriggerFiredBundle{
+			JobDetail:         jw.jobDetail.Clone().(JobDetail),
+			Trigger:           after,
+			Calendar:          cloneCalendar(cal),
+			FireTime:          s.clock.Now(),
+			ScheduledFireTime: before.NextFireTime(),
+			PrevFireTime:      before.PreviousFireTime(),
+			NextFireTime:      after.NextFireTime(),
+		}
+
+		s.fired[tw.Key().String()] = firedRecord{tw.Key().String(), jw.Key().String(), bundle.ScheduledFireTime, bundle.FireTime}
+
+		bundles = append(bundles, bundle)
+	}
+
+	return bundles, nil
+}
+
+// blockJob blocks the waiting and paused triggers of the job executing, the caller must hold the lock.
+func (s *RAMJobStore) blockJob(key JobKey) {
+	s.blockedJobs.Add(key.String())
+
+	for _, tw := range s.triggersForJob(key) {
+		switch tw.state {
+		case STATE_WAITING:
+			tw.state = STATE_BLOCKED
+		case STATE_PAUSED:
+			tw.state = STATE_PAUSED_BLOCKED
+		default:
+			continue
+		}
+
+		s.timeTriggers.remove(tw.Key())
+	}
+}
+
+// unblockJob restores the blocked triggers of the job completed, the caller must hold the lock.
+func (s *RAMJobStore) unblockJob(key JobKey) {
+	s.blockedJobs.Remove(key.String())
+
+	for _, tw := range s.triggersForJob(key) {
+		switch tw.state {
+		case STATE_BLOCKED:
+			tw.state = STATE_WAITING
+
+			if !tw.trigger.NextFireTime().IsZero() {
+				s.timeTriggers.push(tw)
+			}
+		case STATE_PAUSED_BLOCKED:
+			tw.state = STATE_PAUSED
+		}
+	}
+}
+
+func (s *RAMJobStore) TriggeredJobComplete(trigger OperableTrigger, job JobDetail, instruction CompletedExecutionInstruction) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if jw, exists := s.jobsByKey[job.Key().String()]; exists && job.PersistJobDataAfterExecution() {
+		stored := jw.jobDetail.Clone().(JobDetail)
+
+		if d, ok := stored.(*jobDetail); ok && job.JobDataMap() != nil {
+			d.dataMap = job.JobDataMap().CloneDataMap()
+			d.dataMap.ClearDirtyFlag()
+		}
+
+		jw.jobDetail = stored
+	}
+
+	if job.ConcurrentExecutionDisallowed() {
+		s.unblockJob(job.Key())
+	}
+
+	delete(s.fired, trigger.Key().String())
+
+	tw, exists := s.triggersByKey[trigger.Key().String()]
+
+	if !exists {
+		return
+	}
+
+	switch instruction {
+	case DELETE_TRIGGER:
+		// the trigger may have been rescheduled while its job was executing.
+		if tw.trigger.NextFireTime().Equal(trigger.NextFireTime()) {
+			s.removeTrigger(trigger.Key(), true)
+		}
+
+	case SET_TRIGGER_COMPLETE:
+		tw.state = STATE_COMPLETE
+		s.timeTriggers.remove(tw.Key())
+
+	case SET_TRIGGER_ERROR:
+		tw.state = STATE_ERROR
+		s.timeTriggers.remove(tw.Key())
+
+	case SET_ALL_JOB_TRIGGERS_COMPLETE, SET_ALL_JOB_TRIGGERS_ERROR:
+		for _, tw := range s.triggersForJob(job.Key()) {
+			if instruction == SET_ALL_JOB_TRIGGERS_COMPLETE {
+				tw.state = STATE_COMPLETE
+			} else {
+				tw.state = STATE_ERROR
+			}
+
+			s.timeTriggers.remove(tw.Key())
+		}
+	}
+}
@@ -0,0 +1,1238 @@
+package quartz
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRAMJobStoreNormalizeTimesToUTC(t *testing.T) {
+	Convey("Given a RAMJobStore normalizing times to UTC", t, func() {
+		store := NewRAMJobStore()
+		store.NormalizeTimesToUTC(true)
+
+		loc := time.FixedZone("UTC+8", 8*3600)
+
+		store.SetDisplayLocation(loc)
+
+		job := (&JobBuilder{}).WithIdentity("job").Build()
+
+		So(store.StoreJob(job, false), ShouldBeNil)
+
+		startTime := time.Date(2016, time.March, 1, 8, 0, 0, 0, time.FixedZone("UTC-5", -5*3600))
+		endTime := startTime.Add(time.Hour)
+
+		trigger := (&TriggerBuilder{}).WithIdentity("trigger").ForJobDetail(job).StartAt(startTime).EndAt(endTime).Build().(OperableTrigger)
+		trigger.SetNextFireTime(startTime)
+
+		So(store.StoreTrigger(trigger, false), ShouldBeNil)
+
+		Convey("The stored trigger times should be in UTC", func() {
+			stored := store.triggersByKey[trigger.Key().String()].trigger
+
+			So(stored.StartTime().Location(), ShouldEqual, time.UTC)
+			So(stored.EndTime().Location(), ShouldEqual, time.UTC)
+			So(stored.NextFireTime().Location(), ShouldEqual, time.UTC)
+			So(stored.StartTime().Equal(startTime), ShouldBeTrue)
+
+			So(trigger.StartTime().Location(), ShouldNotEqual, time.UTC)
+		})
+
+		Convey("The retrieved trigger times should be in the display location", func() {
+			retrieved, err := store.RetrieveTrigger(trigger.Key())
+
+			So(err, ShouldBeNil)
+
+			So(retrieved.StartTime().Location(), ShouldEqual, loc)
+			So(retrieved.EndTime().Location(), ShouldEqual, loc)
+			So(retrieved.NextFireTime().Location(), ShouldEqual, loc)
+			So(retrieved.StartTime().Equal(startTime), ShouldBeTrue)
+			So(retrieved.EndTime().Equal(endTime), ShouldBeTrue)
+
+			triggers := store.TriggersForJob(job.Key())
+
+			So(len(triggers), ShouldEqual, 1)
+			So(triggers[0].StartTime().Location(), ShouldEqual, loc)
+		})
+	})
+}
+
+func TestRAMJobStoreConcurrentTriggers(t *testing.T) {
+	Convey("Given a RAMJobStore with a durable job", t, func() {
+		store := NewRAMJobStore()
+
+		job := (&JobBuilder{}).WithIdentity("job").StoreDurably(true).Build()
+
+		So(store.StoreJob(job, false), ShouldBeNil)
+
+		Convey("When storing and removing the last trigger of a group concurrently", func() {
+			var wg sync.WaitGroup
+			var failed int32
+
+			for i := 0; i < 8; i++ {
+				wg.Add(1)
+
+				go func(i int) {
+					defer wg.Done()
+
+					for j := 0; j < 200; j++ {
+						trigger := (&TriggerBuilder{}).
+							WithGroupIdentity(fmt.Sprintf("trigger%d", i), "group").
+							ForJobDetail(job).
+							StartNow().
+							Build().(OperableTrigger)
+
+						if err := store.StoreTrigger(trigger, true); err != nil {
+							atomic.AddInt32(&failed, 1)
+						}
+
+						if removed, err := store.RemoveTrigger(trigger.Key()); !removed || err != nil {
+							atomic.AddInt32(&failed, 1)
+						}
+					}
+				}(i)
+			}
+
+			wg.Wait()
+
+			Convey("All the triggers should be stored and removed", func() {
+				So(atomic.LoadInt32(&failed), ShouldEqual, 0)
+				So(store.triggersByKey, ShouldBeEmpty)
+				So(store.triggersByGroup, ShouldBeEmpty)
+				So(store.triggersByJob, ShouldBeEmpty)
+				So(store.timeTriggers.Len(), ShouldEqual, 0)
+			})
+		})
+	})
+}
+
+func TestRAMJobStoreRetrieveTriggerWithState(t *testing.T) {
+	Convey("Given a RAMJobStore with a job", t, func() {
+		store := NewRAMJobStore()
+
+		job := (&JobBuilder{}).WithIdentity("job").Build()
+
+		So(store.StoreJob(job, false), ShouldBeNil)
+
+		nextFireTime := time.Now().Add(time.Minute)
+
+		Convey("Retrieve a waiting trigger", func() {
+			trigger := (&TriggerBuilder{}).WithIdentity("trigger").ForJobDetail(job).StartNow().Build().(OperableTrigger)
+			trigger.SetNextFireTime(nextFireTime)
+
+			So(store.StoreTrigger(trigger, false), ShouldBeNil)
+
+			retrieved, state, fireTime, err := store.RetrieveTriggerWithState(trigger.Key())
+
+			So(err, ShouldBeNil)
+			So(retrieved.Key(), ShouldResemble, trigger.Key())
+			So(state, ShouldEqual, STATE_WAITING)
+			So(fireTime, ShouldResemble, nextFireTime)
+		})
+
+		Convey("Retrieve a paused trigger", func() {
+			store.pausedTriggerGroups.Add("paused")
+
+			trigger := (&TriggerBuilder{}).WithGroupIdentity("trigger", "paused").ForJobDetail(job).StartNow().Build().(OperableTrigger)
+			trigger.SetNextFireTime(nextFireTime)
+
+			So(store.StoreTrigger(trigger, false), ShouldBeNil)
+
+			retrieved, state, fireTime, err := store.RetrieveTriggerWithState(trigger.Key())
+
+			So(err, ShouldBeNil)
+			So(retrieved.Key(), ShouldResemble, trigger.Key())
+			So(state, ShouldEqual, STATE_PAUSED)
+			So(fireTime, ShouldResemble, nextFireTime)
+		})
+
+		Convey("Retrieve a nonexistent trigger", func() {
+			retrieved, _, _, err := store.RetrieveTriggerWithState(NewTriggerKey("nonexists"))
+
+			So(retrieved, ShouldBeNil)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestRAMJobStoreTriggeredJobComplete(t *testing.T) {
+	Convey("Given a RAMJobStore with a job persisting its data", t, func() {
+		store := NewRAMJobStore()
+
+		job := (&JobBuilder{}).WithIdentity("job").UsingJobData("count", 1).PersistJobDataAfterExecution(true).Build()
+
+		So(store.StoreJob(job, false), ShouldBeNil)
+
+		trigger := (&TriggerBuilder{}).WithIdentity("trigger").ForJobDetail(job).StartNow().Build().(OperableTrigger)
+		trigger.SetNextFireTime(time.Now().Add(time.Minute))
+
+		So(store.StoreTrigger(trigger, false), ShouldBeNil)
+
+		execute := func() {
+			fired, _ := store.RetrieveJob(job.Key())
+			fired.JobDataMap().Put("count", fired.JobDataMap().GetIntOr("count", 0)+1)
+
+			store.TriggeredJobComplete(trigger, fired, NOOP)
+		}
+
+		Convey("The mutated data should be seen by the next execution", func() {
+			execute()
+
+			stored, _ := store.RetrieveJob(job.Key())
+
+			So(stored.JobDataMap().Get("count"), ShouldEqual, 2)
+
+			execute()
+
+			stored, _ = store.RetrieveJob(job.Key())
+
+			So(stored.JobDataMap().Get("count"), ShouldEqual, 3)
+			So(job.JobDataMap().Get("count"), ShouldEqual, 1)
+		})
+
+		Convey("The data of a job not persisting it should not be stored", func() {
+			other := (&JobBuilder{}).WithIdentity("other").UsingJobData("count", 1).Build()
+
+			So(store.StoreJob(other, false), ShouldBeNil)
+
+			fired, _ := store.RetrieveJob(other.Key())
+			fired.JobDataMap().Put("count", 2)
+
+			store.TriggeredJobComplete(trigger, fired, NOOP)
+
+			stored, _ := store.RetrieveJob(other.Key())
+
+			So(stored.JobDataMap().Get("count"), ShouldEqual, 1)
+		})
+
+		Convey("The trigger state should follow the instruction", func() {
+			store.TriggeredJobComplete(trigger, job, SET_TRIGGER_ERROR)
+
+			_, state, _, err := store.RetrieveTriggerWithState(trigger.Key())
+
+			So(err, ShouldBeNil)
+			So(state, ShouldEqual, STATE_ERROR)
+
+			store.TriggeredJobComplete(trigger, job, SET_ALL_JOB_TRIGGERS_COMPLETE)
+
+			_, state, _, _ = store.RetrieveTriggerWithState(trigger.Key())
+
+			So(state, ShouldEqual, STATE_COMPLETE)
+			So(store.timeTriggers.Len(), ShouldEqual, 0)
+
+			store.TriggeredJobComplete(trigger, job, DELETE_TRIGGER)
+
+			So(store.CheckTriggerExists(trigger.Key()), ShouldBeFalse)
+		})
+	})
+}
+
+func TestRAMJobStoreResetTriggerFromErrorState(t *testing.T) {
+	Convey("Given a RAMJobStore with a trigger in the error state", t, func() {
+		store := NewRAMJobStore()
+
+		job := (&JobBuilder{}).WithIdentity("job").Build()
+
+		So(store.StoreJob(job, false), ShouldBeNil)
+
+		trigger := (&TriggerBuilder{}).WithIdentity("trigger").ForJobDetail(job).StartNow().Build().(OperableTrigger)
+		trigger.SetNextFireTime(time.Now())
+
+		So(store.StoreTrigger(trigger, false), ShouldBeNil)
+
+		store.TriggeredJobComplete(trigger, job, SET_TRIGGER_ERROR)
+
+		state, err := store.GetTriggerState(trigger.Key())
+
+		So(err, ShouldBeNil)
+		So(state, ShouldEqual, STATE_ERROR)
+
+		acquired, _ := store.AcquireNextTriggers(time.Now(), 1, 0)
+
+		So(acquired, ShouldBeEmpty)
+
+		Convey("The reset trigger should be acquired again", func() {
+			So(store.ResetTriggerFromErrorState(trigger.Key()), ShouldBeNil)
+
+			state, _ := store.GetTriggerState(trigger.Key())
+
+			So(state, ShouldEqual, STATE_WAITING)
+
+			acquired, _ := store.AcquireNextTriggers(time.Now(), 1, 0)
+
+			So(acquired, ShouldHaveLength, 1)
+			So(acquired[0].Key(), ShouldResemble, trigger.Key())
+		})
+
+		Convey("The reset trigger should stay paused in a paused group", func() {
+			_, err := store.PauseJobs(GroupEquals(job.Key().Group()))
+
+			So(err, ShouldBeNil)
+			So(store.ResetTriggerFromErrorState(trigger.Key()), ShouldBeNil)
+
+			state, _ := store.GetTriggerState(trigger.Key())
+
+			So(state, ShouldEqual, STATE_PAUSED)
+		})
+
+		Convey("The trigger not in the error state should be left untouched", func() {
+			store.TriggeredJobComplete(trigger, job, SET_TRIGGER_COMPLETE)
+
+			So(store.ResetTriggerFromErrorState(trigger.Key()), ShouldBeNil)
+
+			state, _ := store.GetTriggerState(trigger.Key())
+
+			So(state, ShouldEqual, STATE_COMPLETE)
+		})
+
+		Convey("The nonexistent trigger should fail", func() {
+			state, err := store.GetTriggerState(NewTriggerKey("nonexists"))
+
+			So(state, ShouldEqual, STATE_NONE)
+			So(errors.Is(err, &ErrTriggerNotFound{}), ShouldBeTrue)
+
+			_, state, _, err = store.RetrieveTriggerWithState(NewTriggerKey("nonexists"))
+
+			So(state, ShouldEqual, STATE_NONE)
+			So(errors.Is(err, &ErrTriggerNotFound{Key: NewTriggerKey("nonexists")}), ShouldBeTrue)
+			So(store.ResetTriggerFromErrorState(NewTriggerKey("nonexists")), ShouldNotBeNil)
+		})
+	})
+}
+
+func TestRAMJobStoreGetKeys(t *testing.T) {
+	Convey("Given a RAMJobStore with jobs and triggers in several groups", t, func() {
+		store := NewRAMJobStore()
+
+		for _, group := range []string{"reports", "reports-daily", "backup", DEFAULT_GROUP} {
+			for _, name := range []string{"b", "a"} {
+				job := (&JobBuilder{}).WithGroupIdentity(name, group).Build()
+				trigger := (&TriggerBuilder{}).WithGroupIdentity(name, group).ForJobDetail(job).StartNow().Build().(OperableTrigger)
+
+				So(store.StoreJobAndTrigger(job, trigger), ShouldBeNil)
+			}
+		}
+
+		Convey("The job keys should be filtered by the matcher and sorted", func() {
+			keys, err := store.GetJobKeys(GroupStartsWith("reports"))
+
+			So(err, ShouldBeNil)
+			So(keys, ShouldResemble, []JobKey{
+				NewGroupJobKey("a", "reports"),
+				NewGroupJobKey("b", "reports"),
+				NewGroupJobKey("a", "reports-daily"),
+				NewGroupJobKey("b", "reports-daily"),
+			})
+
+			keys, err = store.GetJobKeys(AnyGroup())
+
+			So(err, ShouldBeNil)
+			So(keys, ShouldHaveLength, 8)
+
+			keys, err = store.GetJobKeys(GroupEquals("missing"))
+
+			So(err, ShouldBeNil)
+			So(keys, ShouldBeEmpty)
+		})
+
+		Convey("The trigger keys should be filtered by the matcher and sorted", func() {
+			keys, err := store.GetTriggerKeys(GroupStartsWith("reports"))
+
+			So(err, ShouldBeNil)
+			So(keys, ShouldResemble, []TriggerKey{
+				NewGroupTriggerKey("a", "reports"),
+				NewGroupTriggerKey("b", "reports"),
+				NewGroupTriggerKey("a", "reports-daily"),
+				NewGroupTriggerKey("b", "reports-daily"),
+			})
+
+			keys, err = store.GetTriggerKeys(GroupEquals(DEFAULT_GROUP))
+
+			So(err, ShouldBeNil)
+			So(keys, ShouldResemble, []TriggerKey{NewTriggerKey("a"), NewTriggerKey("b")})
+		})
+
+		Convey("The removed job should not be listed", func() {
+			found, err := store.RemoveJob(NewGroupJobKey("a", "backup"))
+
+			So(err, ShouldBeNil)
+			So(found, ShouldBeTrue)
+
+			jobKeys, _ := store.GetJobKeys(GroupEquals("backup"))
+			triggerKeys, _ := store.GetTriggerKeys(GroupEquals("backup"))
+
+			So(jobKeys, ShouldResemble, []JobKey{NewGroupJobKey("b", "backup")})
+			So(triggerKeys, ShouldResemble, []TriggerKey{NewGroupTriggerKey("b", "backup")})
+		})
+	})
+}
+
+func TestRAMJobStoreAcquireNextTriggersBatch(t *testing.T) {
+	Convey("Given a RAMJobStore with triggers firing in an hour", t, func() {
+		store := NewRAMJobStore()
+
+		job := (&JobBuilder{}).WithIdentity("job").StoreDurably(true).Build()
+
+		So(store.StoreJob(job, false), ShouldBeNil)
+
+		startTime := time.Now().Add(time.Hour)
+
+		for i, delay := range []time.Duration{0, 500 * time.Millisecond, 800 * time.Millisecond, 2 * time.Second} {
+			trigger := (&TriggerBuilder{}).
+				WithIdentity(fmt.Sprintf("trigger-%d", i)).
+				ForJobDetail(job).
+				StartAt(startTime.Add(delay)).
+				Build().(OperableTrigger)
+			trigger.ComputeFirstFireTime(nil)
+
+			So(store.StoreTrigger(trigger, false), ShouldBeNil)
+		}
+
+		keys := func(triggers []OperableTrigger) (names []string) {
+			for _, trigger := range triggers {
+				names = append(names, trigger.Key().Name())
+			}
+
+			return
+		}
+
+		Convey("The batch should be limited by the max count", func() {
+			acquired, err := store.AcquireNextTriggers(startTime.Add(time.Hour), 2, time.Second)
+
+			So(err, ShouldBeNil)
+			So(keys(acquired), ShouldResemble, []string{"trigger-0", "trigger-1"})
+		})
+
+		Convey("The triggers outside the time window should not be acquired", func() {
+			acquired, err := store.AcquireNextTriggers(startTime.Add(time.Hour), 10, time.Second)
+
+			So(err, ShouldBeNil)
+			So(keys(acquired), ShouldResemble, []string{"trigger-0", "trigger-1", "trigger-2"})
+
+			acquired, _ = store.AcquireNextTriggers(startTime.Add(time.Hour), 10, time.Second)
+
+			So(keys(acquired), ShouldResemble, []string{"trigger-3"})
+		})
+
+		Convey("The first trigger should fire no later than noLaterThan", func() {
+			acquired, err := store.AcquireNextTriggers(startTime.Add(-time.Second), 10, time.Hour)
+
+			So(err, ShouldBeNil)
+			So(acquired, ShouldBeEmpty)
+		})
+	})
+
+	Convey("Given a RAMJobStore with a ManualClock in the past", t, func() {
+		clock := NewManualClock(time.Date(2016, time.March, 1, 8, 0, 0, 0, time.UTC))
+
+		store := NewRAMJobStore()
+		store.SetClock(clock)
+
+		job := (&JobBuilder{}).WithIdentity("job").StoreDurably(true).Build()
+
+		So(store.StoreJob(job, false), ShouldBeNil)
+
+		for i, delay := range []time.Duration{-time.Minute, time.Hour} {
+			trigger := (&TriggerBuilder{}).
+				WithIdentity(fmt.Sprintf("trigger-%d", i)).
+				ForJobDetail(job).
+				StartAt(clock.Now().Add(delay)).
+				Build().(OperableTrigger)
+			trigger.ComputeFirstFireTime(nil)
+
+			So(store.StoreTrigger(trigger, false), ShouldBeNil)
+		}
+
+		Convey("The time window of the overdue trigger should start at the time of the clock", func() {
+			acquired, err := store.AcquireNextTriggers(clock.Now().Add(2*time.Hour), 10, time.Second)
+
+			So(err, ShouldBeNil)
+			So(acquired, ShouldHaveLength, 1)
+			So(acquired[0].Key().Name(), ShouldEqual, "trigger-0")
+
+			Convey("The trigger should be fired at the time of the clock", func() {
+				bundles, err := store.TriggersFired(acquired)
+
+				So(err, ShouldBeNil)
+				So(bundles, ShouldHaveLength, 1)
+				So(bundles[0].FireTime, ShouldResemble, clock.Now())
+			})
+		})
+	})
+}
+
+func TestRAMJobStoreErrors(t *testing.T) {
+	Convey("Given a RAMJobStore with a job and its trigger", t, func() {
+		store := NewRAMJobStore()
+
+		job := (&JobBuilder{}).WithIdentity("job").Build()
+		trigger := (&TriggerBuilder{}).WithIdentity("trigger").ForJobDetail(job).StartNow().Build().(OperableTrigger)
+
+		So(store.StoreJobAndTrigger(job, trigger), ShouldBeNil)
+
+		Convey("Storing the job again should return the key of the existing job", func() {
+			err := store.StoreJob(job, false)
+
+			var exists *ErrJobAlreadyExists
+
+			So(errors.As(err, &exists), ShouldBeTrue)
+			So(exists.Key, ShouldResemble, job.Key())
+			So(errors.Is(err, &ErrJobAlreadyExists{}), ShouldBeTrue)
+			So(errors.Is(err, &ErrJobAlreadyExists{job.Key()}), ShouldBeTrue)
+			So(errors.Is(err, &ErrJobAlreadyExists{NewJobKey("other")}), ShouldBeFalse)
+			So(errors.Is(err, &ErrTriggerAlreadyExists{}), ShouldBeFalse)
+		})
+
+		Convey("Storing the trigger again should return the key of the existing trigger", func() {
+			err := store.StoreTrigger(trigger, false)
+
+			var exists *ErrTriggerAlreadyExists
+
+			So(errors.As(err, &exists), ShouldBeTrue)
+			So(exists.Key, ShouldResemble, trigger.Key())
+			So(errors.Is(err, &ErrTriggerAlreadyExists{}), ShouldBeTrue)
+		})
+
+		Convey("Storing a trigger of a nonexistent job should return the keys of the job and the trigger", func() {
+			orphan := (&TriggerBuilder{}).WithIdentity("orphan").ForJob("missing").StartNow().Build().(OperableTrigger)
+
+			err := store.StoreTrigger(orphan, false)
+
+			var unresolved *ErrUnableToResolveJob
+
+			So(errors.As(err, &unresolved), ShouldBeTrue)
+			So(unresolved.Key, ShouldResemble, NewJobKey("missing"))
+			So(unresolved.TriggerKey, ShouldResemble, orphan.Key())
+			So(errors.Is(err, &ErrUnableToResolveJob{}), ShouldBeTrue)
+			So(err.Error(), ShouldEqual, "The job (DEFAULT.missing) referenced by the trigger (DEFAULT.orphan) does not exist.")
+		})
+
+		Convey("Storing the triggers with a trigger of a nonexistent job should store none of them", func() {
+			other := (&TriggerBuilder{}).WithIdentity("other").ForJobDetail(job).StartNow().Build().(OperableTrigger)
+			orphan := (&TriggerBuilder{}).WithIdentity("orphan").ForJob("missing").StartNow().Build().(OperableTrigger)
+
+			err := store.StoreTriggers([]OperableTrigger{other, orphan}, false)
+
+			So(errors.Is(err, &ErrUnableToResolveJob{}), ShouldBeTrue)
+			So(store.CheckTriggerExists(other.Key()), ShouldBeFalse)
+
+			So(store.StoreTriggers([]OperableTrigger{other}, false), ShouldBeNil)
+			So(store.CheckTriggerExists(other.Key()), ShouldBeTrue)
+		})
+
+		Convey("Storing a trigger without job key should fail", func() {
+			noJob := &simpleTrigger{}
+			noJob.SetKey(NewTriggerKey("nojob"))
+
+			err := store.StoreTrigger(noJob, false)
+
+			So(errors.Is(err, ErrMissingJobKey), ShouldBeTrue)
+			So(err.Error(), ShouldEqual, "Unable to store Trigger : 'DEFAULT.nojob', the trigger has no job key.")
+			So(store.CheckTriggerExists(noJob.Key()), ShouldBeFalse)
+
+			So(errors.Is(store.StoreTrigger(noJob, true), ErrMissingJobKey), ShouldBeTrue)
+		})
+
+		Convey("Storing a job with a trigger of another job should not leave the job behind", func() {
+			other := (&JobBuilder{}).WithIdentity("other").Build()
+			mismatched := (&TriggerBuilder{}).WithIdentity("mismatched").ForJob("missing").StartNow().Build().(OperableTrigger)
+
+			err := store.StoreJobAndTrigger(other, mismatched)
+
+			So(errors.Is(err, &ErrUnableToResolveJob{NewJobKey("missing"), mismatched.Key()}), ShouldBeTrue)
+			So(store.CheckJobExists(other.Key()), ShouldBeFalse)
+			So(store.CheckTriggerExists(mismatched.Key()), ShouldBeFalse)
+			So(store.NumberOfJobs(), ShouldEqual, 1)
+
+			Convey("The job should be stored again with its own trigger", func() {
+				trigger := (&TriggerBuilder{}).WithIdentity("other").ForJobDetail(other).StartNow().Build().(OperableTrigger)
+
+				So(store.StoreJobAndTrigger(other, trigger), ShouldBeNil)
+				So(store.NumberOfJobs(), ShouldEqual, 2)
+			})
+		})
+
+		Convey("Storing an existing job with a new trigger should keep the stored job", func() {
+			other := (&TriggerBuilder{}).WithIdentity("other").ForJobDetail(job).StartNow().Build().(OperableTrigger)
+
+			err := store.StoreJobAndTrigger(job, other)
+
+			So(errors.Is(err, &ErrJobAlreadyExists{}), ShouldBeTrue)
+			So(store.CheckJobExists(job.Key()), ShouldBeTrue)
+			So(store.CheckTriggerExists(trigger.Key()), ShouldBeTrue)
+			So(store.CheckTriggerExists(other.Key()), ShouldBeFalse)
+		})
+	})
+}
+
+func TestRAMJobStoreOrphanedJobs(t *testing.T) {
+	Convey("Given a RAMJobStore with a durable and a non-durable job", t, func() {
+		store := NewRAMJobStore()
+
+		durable := (&JobBuilder{}).WithIdentity("durable").StoreDurably(true).Build()
+		volatile := (&JobBuilder{}).WithIdentity("volatile").Build()
+
+		newTrigger := func(name string, job JobDetail) OperableTrigger {
+			return (&TriggerBuilder{}).WithIdentity(name).ForJobDetail(job).StartNow().Build().(OperableTrigger)
+		}
+
+		So(store.StoreJobAndTrigger(durable, newTrigger("durable", durable)), ShouldBeNil)
+		So(store.StoreJobAndTrigger(volatile, newTrigger("volatile", volatile)), ShouldBeNil)
+
+		Convey("Removing the last trigger of the non-durable job should remove the job", func() {
+			found, err := store.RemoveTrigger(NewTriggerKey("volatile"))
+
+			So(err, ShouldBeNil)
+			So(found, ShouldBeTrue)
+			So(store.CheckJobExists(volatile.Key()), ShouldBeFalse)
+			So(store.NumberOfJobs(), ShouldEqual, 1)
+		})
+
+		Convey("Removing the last trigger of the durable job should keep the job", func() {
+			found, err := store.RemoveTrigger(NewTriggerKey("durable"))
+
+			So(err, ShouldBeNil)
+			So(found, ShouldBeTrue)
+			So(store.CheckJobExists(durable.Key()), ShouldBeTrue)
+			So(store.TriggersForJob(durable.Key()), ShouldBeEmpty)
+		})
+
+		Convey("Removing the triggers in a batch should apply the same rules", func() {
+			allFound, err := store.RemoveTriggers([]TriggerKey{NewTriggerKey("durable"), NewTriggerKey("volatile")})
+
+			So(err, ShouldBeNil)
+			So(allFound, ShouldBeTrue)
+			So(store.CheckJobExists(durable.Key()), ShouldBeTrue)
+			So(store.CheckJobExists(volatile.Key()), ShouldBeFalse)
+		})
+
+		Convey("When the non-durable job has another trigger", func() {
+			So(store.StoreTrigger(newTrigger("another", volatile), false), ShouldBeNil)
+
+			found, err := store.RemoveTrigger(NewTriggerKey("volatile"))
+
+			So(err, ShouldBeNil)
+			So(found, ShouldBeTrue)
+
+			Convey("The job should be kept until its last trigger is removed", func() {
+				So(store.CheckJobExists(volatile.Key()), ShouldBeTrue)
+
+				_, err := store.RemoveTrigger(NewTriggerKey("another"))
+
+				So(err, ShouldBeNil)
+				So(store.CheckJobExists(volatile.Key()), ShouldBeFalse)
+			})
+		})
+
+		Convey("Replacing the only trigger of the non-durable job should keep the job", func() {
+			So(store.ReplaceTrigger(NewTriggerKey("volatile"), newTrigger("replaced", volatile)), ShouldBeNil)
+			So(store.CheckJobExists(volatile.Key()), ShouldBeTrue)
+			So(store.CheckTriggerExists(NewTriggerKey("replaced")), ShouldBeTrue)
+		})
+	})
+}
+
+func TestRAMJobStoreBlockedJobs(t *testing.T) {
+	Convey("Given a RAMJobStore with two triggers of a job disallowing concurrent execution", t, func() {
+		store := NewRAMJobStore()
+
+		job := (&JobBuilder{}).WithIdentity("job").DisallowConcurrentExecution(true).Build()
+
+		So(job.ConcurrentExecutionDisallowed(), ShouldBeTrue)
+
+		startTime := time.Now().Add(-time.Second)
+
+		newTrigger := func(name string) OperableTrigger {
+			trigger := (&TriggerBuilder{}).
+				WithIdentity(name).
+				ForJobDetail(job).
+				StartAt(startTime).
+				WithSchedule(&SimpleScheduleBuilder{time.Minute, 1}).
+				Build().(OperableTrigger)
+			trigger.ComputeFirstFireTime(nil)
+
+			return trigger
+		}
+
+		first, second := newTrigger("first"), newTrigger("second")
+
+		So(store.StoreJobAndTrigger(job, first), ShouldBeNil)
+		So(store.StoreTrigger(second, false), ShouldBeNil)
+
+		Convey("Only one trigger of the job should be acquired in a batch", func() {
+			acquired, err := store.AcquireNextTriggers(time.Now(), 10, time.Second)
+
+			So(err, ShouldBeNil)
+			So(acquired, ShouldHaveLength, 1)
+			So(acquired[0].Key(), ShouldResemble, first.Key())
+
+			state, _ := store.GetTriggerState(second.Key())
+
+			So(state, ShouldEqual, STATE_WAITING)
+
+			Convey("When the trigger fired", func() {
+				bundles, err := store.TriggersFired(acquired)
+
+				So(err, ShouldBeNil)
+				So(bundles, ShouldHaveLength, 1)
+
+				Convey("The other trigger should be blocked until the job completed", func() {
+					state, _ := store.GetTriggerState(second.Key())
+
+					So(state, ShouldEqual, STATE_BLOCKED)
+					So(store.blockedJobs.Contains(job.Key().String()), ShouldBeTrue)
+
+					acquired, err := store.AcquireNextTriggers(time.Now(), 10, time.Second)
+
+					So(err, ShouldBeNil)
+					So(acquired, ShouldBeEmpty)
+
+					store.TriggeredJobComplete(bundles[0].Trigger.(OperableTrigger), bundles[0].JobDetail, NOOP)
+
+					state, _ = store.GetTriggerState(second.Key())
+
+					So(state, ShouldEqual, STATE_WAITING)
+					So(store.blockedJobs.Contains(job.Key().String()), ShouldBeFalse)
+
+					acquired, err = store.AcquireNextTriggers(time.Now(), 10, time.Second)
+
+					So(err, ShouldBeNil)
+					So(acquired, ShouldHaveLength, 1)
+					So(acquired[0].Key(), ShouldResemble, second.Key())
+				})
+
+				Convey("The other trigger paused while blocked should be paused after the job completed", func() {
+					So(store.PauseTrigger(second.Key()), ShouldBeNil)
+
+					state, _ := store.GetTriggerState(second.Key())
+
+					So(state, ShouldEqual, STATE_PAUSED_BLOCKED)
+
+					store.TriggeredJobComplete(bundles[0].Trigger.(OperableTrigger), bundles[0].JobDetail, NOOP)
+
+					state, _ = store.GetTriggerState(second.Key())
+
+					So(state, ShouldEqual, STATE_PAUSED)
+				})
+			})
+		})
+	})
+}
+
+func TestRAMJobStoreCalendars(t *testing.T) {
+	Convey("Given a RAMJobStore with a HolidayCalendar", t, func() {
+		store := NewRAMJobStore()
+
+		holidays := NewHolidayCalendar(nil)
+		holidays.SetLocation(time.UTC)
+		holidays.AddExcludedDate(time.Date(2015, time.December, 25, 0, 0, 0, 0, time.UTC))
+
+		So(store.StoreCalendar("holidays", holidays, false), ShouldBeNil)
+
+		Convey("The calendar should be stored as a copy", func() {
+			holidays.AddExcludedDate(time.Date(2015, time.December, 26, 0, 0, 0, 0, time.UTC))
+
+			cal, err := store.RetrieveCalendar("holidays")
+
+			So(err, ShouldBeNil)
+			So(cal.(*HolidayCalendar).ExcludedDates(), ShouldHaveLength, 1)
+
+			cal, err = store.RetrieveCalendar("nonexists")
+
+			So(err, ShouldBeNil)
+			So(cal, ShouldBeNil)
+
+			names, err := store.GetCalendarNames()
+
+			So(err, ShouldBeNil)
+			So(names, ShouldResemble, []string{"holidays"})
+		})
+
+		Convey("The existing calendar should only be replaced on demand", func() {
+			So(store.StoreCalendar("holidays", NewHolidayCalendar(nil), false), ShouldNotBeNil)
+			So(store.StoreCalendar("holidays", NewHolidayCalendar(nil), true), ShouldBeNil)
+
+			cal, _ := store.RetrieveCalendar("holidays")
+
+			So(cal.(*HolidayCalendar).ExcludedDates(), ShouldBeEmpty)
+		})
+
+		Convey("When a daily trigger uses the calendar", func() {
+			job := (&JobBuilder{}).WithIdentity("job").StoreDurably(true).Build()
+
+			So(store.StoreJob(job, false), ShouldBeNil)
+
+			startTime := time.Date(2015, time.December, 24, 8, 0, 0, 0, time.UTC)
+
+			trigger := (&TriggerBuilder{}).
+				WithIdentity("trigger").
+				ForJobDetail(job).
+				StartAt(startTime).
+				ModifiedByCalendar("holidays").
+				WithSchedule(&SimpleScheduleBuilder{24 * time.Hour, REPEAT_INDEFINITELY}).
+				Build().(OperableTrigger)
+			trigger.ComputeFirstFireTime(holidays)
+
+			So(store.StoreTrigger(trigger, false), ShouldBeNil)
+
+			Convey("The fired trigger should skip the excluded date", func() {
+				acquired, err := store.AcquireNextTriggers(startTime, 1, 0)
+
+				So(err, ShouldBeNil)
+				So(acquired, ShouldHaveLength, 1)
+
+				bundles, err := store.TriggersFired(acquired)
+
+				So(err, ShouldBeNil)
+				So(bundles, ShouldHaveLength, 1)
+				So(bundles[0].Calendar, ShouldNotBeNil)
+				So(bundles[0].ScheduledFireTime, ShouldEqual, startTime)
+				So(bundles[0].NextFireTime, ShouldEqual, startTime.Add(48*time.Hour))
+			})
+
+			Convey("The calendar should not be removed while it is used", func() {
+				removed, err := store.RemoveCalendar("holidays")
+
+				So(err, ShouldNotBeNil)
+				So(removed, ShouldBeFalse)
+
+				_, err = store.RemoveTrigger(trigger.Key())
+
+				So(err, ShouldBeNil)
+
+				removed, err = store.RemoveCalendar("holidays")
+
+				So(err, ShouldBeNil)
+				So(removed, ShouldBeTrue)
+
+				removed, err = store.RemoveCalendar("holidays")
+
+				So(err, ShouldBeNil)
+				So(removed, ShouldBeFalse)
+			})
+		})
+
+		Convey("The calendars should be cleared with the scheduling data", func() {
+			So(store.ClearAllSchedulingData(), ShouldBeNil)
+
+			names, err := store.GetCalendarNames()
+
+			So(err, ShouldBeNil)
+			So(names, ShouldBeEmpty)
+		})
+	})
+}
+
+func TestRAMJobStoreSchedulerStarted(t *testing.T) {
+	Convey("Given a RAMJobStore with a trigger left acquired by a stopped scheduler", t, func() {
+		store := NewRAMJobStore()
+
+		job := (&JobBuilder{}).WithIdentity("job").Build()
+		trigger := (&TriggerBuilder{}).WithIdentity("trigger").ForJobDetail(job).StartNow().Build().(OperableTrigger)
+		trigger.ComputeFirstFireTime(nil)
+
+		So(store.StoreJobAndTrigger(job, trigger), ShouldBeNil)
+
+		acquired, _ := store.AcquireNextTriggers(time.Now(), 1, 0)
+
+		So(acquired, ShouldHaveLength, 1)
+
+		Convey("The trigger should be waiting again once the next scheduler started", func() {
+			So(store.SchedulerStarted(), ShouldBeNil)
+
+			state, _ := store.GetTriggerState(trigger.Key())
+
+			So(state, ShouldEqual, STATE_WAITING)
+
+			acquired, _ := store.AcquireNextTriggers(time.Now(), 1, 0)
+
+			So(acquired, ShouldHaveLength, 1)
+			So(acquired[0].Key(), ShouldResemble, trigger.Key())
+		})
+	})
+
+	Convey("Given a RAMJobStore with jobs left executing by a crashed scheduler", t, func() {
+		store := NewRAMJobStore()
+
+		recoverable := (&JobBuilder{}).WithIdentity("recoverable").RequestRecovery(true).DisallowConcurrentExecution(true).Build()
+		other := (&JobBuilder{}).WithIdentity("other").Build()
+
+		for _, job := range []JobDetail{recoverable, other} {
+			trigger := (&TriggerBuilder{}).
+				WithIdentity(job.Key().Name()).
+				ForJobDetail(job).
+				UsingJobData("trigger", job.Key().Name()).
+				StartNow().
+				WithSchedule(&SimpleScheduleBuilder{time.Minute, REPEAT_INDEFINITELY}).
+				Build().(OperableTrigger)
+			trigger.ComputeFirstFireTime(nil)
+
+			So(store.StoreJobAndTrigger(job, trigger), ShouldBeNil)
+		}
+
+		acquired, _ := store.AcquireNextTriggers(time.Now(), 2, 0)
+
+		So(acquired, ShouldHaveLength, 2)
+
+		bundles, err := store.TriggersFired(acquired)
+
+		So(err, ShouldBeNil)
+		So(bundles, ShouldHaveLength, 2)
+
+		var fired *TriggerFiredBundle
+
+		for _, bundle := range bundles {
+			if bundle.JobDetail.Key().String() == recoverable.Key().String() {
+				fired = bundle
+			}
+		}
+
+		assertRecovered := func(store *RAMJobStore) {
+			So(store.SchedulerStarted(), ShouldBeNil)
+
+			keys, err := store.GetTriggerKeys(GroupEquals(RECOVERING_JOBS_GROUP))
+
+			So(err, ShouldBeNil)
+			So(keys, ShouldHaveLength, 1)
+
+			recovering, _ := store.RetrieveTrigger(keys[0])
+
+			So(recovering.JobKey(), ShouldResemble, recoverable.Key())
+			So(recovering.JobDataMap().Get("trigger"), ShouldEqual, "recoverable")
+			So(recovering.JobDataMap().Get(FAILED_JOB_ORIGINAL_TRIGGER_NAME), ShouldEqual, "recoverable")
+			So(recovering.JobDataMap().Get(FAILED_JOB_ORIGINAL_TRIGGER_GROUP), ShouldEqual, DEFAULT_GROUP)
+
+			scheduledFireTime, ok := recovering.JobDataMap().GetTime(FAILED_JOB_ORIGINAL_TRIGGER_SCHEDULED_FIRETIME)
+
+			So(ok, ShouldBeTrue)
+			So(scheduledFireTime.Equal(fired.ScheduledFireTime), ShouldBeTrue)
+
+			fireTime, ok := recovering.JobDataMap().GetTime(FAILED_JOB_ORIGINAL_TRIGGER_FIRETIME)
+
+			So(ok, ShouldBeTrue)
+			So(fireTime.Equal(fired.FireTime), ShouldBeTrue)
+
+			Convey("The blocked trigger should be waiting again", func() {
+				state, _ := store.GetTriggerState(NewTriggerKey("recoverable"))
+
+				So(state, ShouldEqual, STATE_WAITING)
+			})
+
+			Convey("The recovery should not be repeated", func() {
+				So(store.SchedulerStarted(), ShouldBeNil)
+
+				keys, _ := store.GetTriggerKeys(GroupEquals(RECOVERING_JOBS_GROUP))
+
+				So(keys, ShouldHaveLength, 1)
+			})
+		}
+
+		Convey("The store reused by the next scheduler should recover the job requesting recovery", func() {
+			assertRecovered(store)
+		})
+
+		Convey("The restored snapshot should recover the job requesting recovery", func() {
+			snapshot, err := store.Snapshot()
+
+			So(err, ShouldBeNil)
+
+			restored := NewRAMJobStore()
+
+			So(restored.RestoreSnapshot(snapshot), ShouldBeNil)
+
+			assertRecovered(restored)
+		})
+
+		Convey("The completed job should not be recovered", func() {
+			store.TriggeredJobComplete(fired.Trigger, fired.JobDetail, NOOP)
+
+			So(store.SchedulerStarted(), ShouldBeNil)
+
+			keys, _ := store.GetTriggerKeys(GroupEquals(RECOVERING_JOBS_GROUP))
+
+			So(keys, ShouldBeEmpty)
+		})
+	})
+}
+
+func TestRAMJobStorePauseGroups(t *testing.T) {
+	Convey("Given a RAMJobStore with due triggers in several groups", t, func() {
+		store := NewRAMJobStore()
+
+		for _, group := range []string{"reports", "reports-daily", "backup"} {
+			job := (&JobBuilder{}).WithGroupIdentity("job", group).Build()
+			trigger := (&TriggerBuilder{}).WithGroupIdentity("trigger", group).ForJobDetail(job).StartNow().Build().(OperableTrigger)
+			trigger.SetNextFireTime(trigger.StartTime())
+
+			So(store.StoreJobAndTrigger(job, trigger), ShouldBeNil)
+		}
+
+		acquired := func() (groups []string) {
+			triggers, err := store.AcquireNextTriggers(time.Now().Add(time.Minute), 10, 0)
+
+			So(err, ShouldBeNil)
+
+			for _, trigger := range triggers {
+				groups = append(groups, trigger.Key().Group())
+
+				store.ReleaseAcquiredTrigger(trigger)
+			}
+
+			sort.Strings(groups)
+
+			return
+		}
+
+		So(acquired(), ShouldResemble, []string{"backup", "reports", "reports-daily"})
+
+		Convey("When pause the trigger groups", func() {
+			groups, err := store.PauseTriggers(GroupStartsWith("reports"))
+
+			So(err, ShouldBeNil)
+			So(groups, ShouldResemble, []string{"reports", "reports-daily"})
+			So(acquired(), ShouldResemble, []string{"backup"})
+
+			_, state, _, _ := store.RetrieveTriggerWithState(NewGroupTriggerKey("trigger", "reports"))
+
+			So(state, ShouldEqual, STATE_PAUSED)
+
+			Convey("The trigger stored into a paused group should be paused", func() {
+				job := (&JobBuilder{}).WithGroupIdentity("other", "backup").Build()
+				trigger := (&TriggerBuilder{}).WithGroupIdentity("other", "reports").ForJobDetail(job).StartNow().Build().(OperableTrigger)
+				trigger.SetNextFireTime(trigger.StartTime())
+
+				So(store.StoreJobAndTrigger(job, trigger), ShouldBeNil)
+				So(acquired(), ShouldResemble, []string{"backup"})
+			})
+
+			Convey("The resumed groups should be acquired again", func() {
+				groups, err := store.ResumeTriggers(GroupEquals("reports-daily"))
+
+				So(err, ShouldBeNil)
+				So(groups, ShouldResemble, []string{"reports-daily"})
+				So(acquired(), ShouldResemble, []string{"backup", "reports-daily"})
+
+				groups, err = store.ResumeTriggers(AnyGroup())
+
+				So(err, ShouldBeNil)
+				So(groups, ShouldResemble, []string{"backup", "reports", "reports-daily"})
+				So(acquired(), ShouldResemble, []string{"backup", "reports", "reports-daily"})
+			})
+		})
+
+		Convey("When pause a job group which has no job yet", func() {
+			groups, err := store.PauseJobs(GroupEquals("later"))
+
+			So(err, ShouldBeNil)
+			So(groups, ShouldResemble, []string{"later"})
+
+			job := (&JobBuilder{}).WithGroupIdentity("job", "later").Build()
+			trigger := (&TriggerBuilder{}).WithGroupIdentity("trigger", "later").ForJobDetail(job).StartNow().Build().(OperableTrigger)
+			trigger.SetNextFireTime(trigger.StartTime())
+
+			So(store.StoreJobAndTrigger(job, trigger), ShouldBeNil)
+			So(acquired(), ShouldResemble, []string{"backup", "reports", "reports-daily"})
+
+			Convey("The trigger group resume should not resume the paused jobs", func() {
+				_, err := store.ResumeTriggers(GroupEquals("later"))
+
+				So(err, ShouldBeNil)
+				So(acquired(), ShouldResemble, []string{"backup", "reports", "reports-daily"})
+			})
+
+			Convey("The resumed job group should requeue its triggers", func() {
+				groups, err := store.ResumeJobs(GroupEquals("later"))
+
+				So(err, ShouldBeNil)
+				So(groups, ShouldResemble, []string{"later"})
+				So(acquired(), ShouldResemble, []string{"backup", "later", "reports", "reports-daily"})
+			})
+		})
+	})
+}
+
+func TestRAMJobStoreStoreJobsAndTriggers(t *testing.T) {
+	Convey("Given a RAMJobStore with a job", t, func() {
+		store := NewRAMJobStore()
+
+		job := (&JobBuilder{}).WithGroupIdentity("job", "reports").Build()
+		other := (&JobBuilder{}).WithGroupIdentity("other", "reports").Build()
+
+		So(store.StoreJob(job, false), ShouldBeNil)
+
+		newTrigger := func(name string, job JobDetail) Trigger {
+			return (&TriggerBuilder{}).WithGroupIdentity(name, "reports").ForJobDetail(job).StartNow().Build()
+		}
+
+		Convey("The existing job should not be replaced", func() {
+			err := store.StoreJobsAndTriggers(map[JobDetail][]Trigger{
+				job:   {newTrigger("a", job)},
+				other: {newTrigger("b", other)},
+			}, false)
+
+			So(err, ShouldNotBeNil)
+			So(store.NumberOfJobs(), ShouldEqual, 1)
+			So(store.NumberOfTriggers(), ShouldEqual, 0)
+		})
+
+		Convey("The jobs and triggers should be stored when replacing", func() {
+			err := store.StoreJobsAndTriggers(map[JobDetail][]Trigger{
+				job:   {newTrigger("a", job)},
+				other: {newTrigger("b", other), newTrigger("c", other)},
+			}, true)
+
+			So(err, ShouldBeNil)
+			So(store.NumberOfJobs(), ShouldEqual, 2)
+			So(store.NumberOfTriggers(), ShouldEqual, 3)
+			So(store.CheckTriggerExists(NewGroupTriggerKey("c", "reports")), ShouldBeTrue)
+		})
+
+		Convey("The existing trigger should be reported by its name and group", func() {
+			trigger := newTrigger("a", job)
+
+			So(store.StoreTrigger(trigger.(OperableTrigger), false), ShouldBeNil)
+
+			err := store.StoreTrigger(trigger.(OperableTrigger), false)
+
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "name: 'a' and group: 'reports'")
+		})
+	})
+}
+
+func BenchmarkRAMJobStoreRetrieveTrigger(b *testing.B) {
+	store := NewRAMJobStore()
+
+	var keys []TriggerKey
+
+	for i := 0; i < 1000; i++ {
+		job := (&JobBuilder{}).WithIdentity(fmt.Sprintf("job%d", i)).Build()
+		trigger := (&TriggerBuilder{}).WithIdentity(fmt.Sprintf("trigger%d", i)).ForJobDetail(job).StartNow().Build().(OperableTrigger)
+
+		if err := store.StoreJobAndTrigger(job, trigger); err != nil {
+			b.Fatal(err)
+		}
+
+		keys = append(keys, trigger.Key())
+	}
+
+	// Exclusive retrieves the triggers holding the write lock, like the store did before it used a RWMutex.
+	b.Run("Exclusive", func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {
+			for i := 0; pb.Next(); i++ {
+				store.lock.Lock()
+				store.displayTrigger(store.triggersByKey[keys[i%len(keys)].String()].trigger)
+				store.lock.Unlock()
+			}
+		})
+	})
+
+	b.Run("Shared", func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {
+			for i := 0; pb.Next(); i++ {
+				store.RetrieveTrigger(keys[i%len(keys)])
+			}
+		})
+	})
+}
+
+func BenchmarkRAMJobStoreRemoveTrigger(b *testing.B) {
+	job := (&JobBuilder{}).WithIdentity("job").StoreDurably(true).Build()
+
+	var triggers []OperableTrigger
+
+	for i := 0; i < 10000; i++ {
+		triggers = append(triggers, (&TriggerBuilder{}).WithIdentity(fmt.Sprintf("trigger%d", i)).ForJobDetail(job).StartNow().Build().(OperableTrigger))
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+
+		store := NewRAMJobStore()
+
+		if err := store.StoreJob(job, false); err != nil {
+			b.Fatal(err)
+		}
+
+		for _, trigger := range triggers {
+			if err := store.StoreTrigger(trigger, false); err != nil {
+				b.Fatal(err)
+			}
+		}
+
+		b.StartTimer()
+
+		for _, trigger := range triggers {
+			store.RemoveTrigger(trigger.Key())
+		}
+	}
+}
+
+// countingTrigger counts the fire times computed by the trigger and its clones.
+type countingTrigger struct {
+	OperableTrigger
+
+	computed *int64
+}
+
+func (t *countingTrigger) Clone() interface{} {
+	return &countingTrigger{t.OperableTrigger.Clone().(OperableTrigger), t.computed}
+}
+
+func (t *countingTrigger) FireTimeAfter(afterTime time.Time) time.Time {
+	atomic.AddInt64(t.computed, 1)
+
+	return t.OperableTrigger.FireTimeAfter(afterTime)
+}
+
+// BenchmarkRAMJobStoreAcquireNextTriggers shows the acquisition reads the stored next fire time of the triggers,
+// none of them is computed while the queued triggers are scanned.
+func BenchmarkRAMJobStoreAcquireNextTriggers(b *testing.B) {
+	store := NewRAMJobStore()
+
+	var computed int64
+
+	for i := 0; i < 1000; i++ {
+		job := (&JobBuilder{}).WithIdentity(fmt.Sprintf("job%d", i)).Build()
+		trigger := (&TriggerBuilder{}).WithIdentity(fmt.Sprintf("trigger%d", i)).ForJobDetail(job).
+			StartAt(time.Now().Add(-time.Duration(i) * time.Second)).
+			WithSchedule(&SimpleScheduleBuilder{10 * time.Second, REPEAT_INDEFINITELY}).Build().(OperableTrigger)
+		trigger.ComputeFirstFireTime(nil)
+
+		if err := store.StoreJobAndTrigger(job, &countingTrigger{trigger, &computed}); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	atomic.StoreInt64(&computed, 0)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		triggers, err := store.AcquireNextTriggers(time.Now(), 100, time.Second)
+
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		for _, trigger := range triggers {
+			store.ReleaseAcquiredTrigger(trigger)
+		}
+	}
+
+	b.StopTimer()
+
+	if n := atomic.LoadInt64(&computed); n != 0 {
+		b.Fatalf("%d fire times computed while acquiring the triggers", n)
+	}
+
+	b.ReportMetric(float64(atomic.LoadInt64(&computed))/float64(b.N), "computed/op")
+}
@@ -0,0 +1,1266 @@
+package quartz
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// RedisClient is the subset of the Redis commands used by the RedisJobStore,
+// it should be implemented over the Redis client of the application.
+type RedisClient interface {
+	// HGet returns the value of the field, and whether the field exists.
+	HGet(key, field string) (string, bool, error)
+
+	HSet(key, field, value string) error
+
+	HDel(key string, fields ...string) error
+
+	HKeys(key string) ([]string, error)
+
+	HLen(key string) (int, error)
+
+	SAdd(key string, members ...string) error
+
+	SRem(key string, members ...string) error
+
+	SMembers(key string) ([]string, error)
+
+	SIsMember(key, member string) (bool, error)
+
+	ZAdd(key string, score float64, member string) error
+
+	ZRem(key string, members ...string) error
+
+	// ZRangeByScore returns the members with a score between min and max, which may be "-inf" or "+inf".
+	ZRangeByScore(key, min, max string) ([]string, error)
+
+	Del(keys ...string) error
+
+	// Eval runs the Lua script, the scripts of the RedisJobStore return an integer.
+	Eval(script string, keys []string, args ...string) (int64, error)
+}
+
+// acquireTriggerScript moves the trigger out of the waiting set and marks it acquired,
+// it returns 0 if another scheduler acquired the trigger first, or if its job is blocked.
+const acquireTriggerScript = `
+if redis.call('SISMEMBER', KEYS[3], ARGV[3]) == 1 then
+	return 0
+end
+if redis.call('ZREM', KEYS[1], ARGV[1]) == 1 then
+	redis.call('HSET', KEYS[2], ARGV[1], ARGV[2])
+	return 1
+end
+return 0
+`
+
+// blockJobScript marks the job executing, and moves its waiting triggers to the blocked set
+// and its paused triggers to STATE_PAUSED_BLOCKED, it returns 0 if the job was already executing.
+const blockJobScript = `
+if redis.call('SADD', KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+for _, trigger in ipairs(redis.call('SMEMBERS', KEYS[2])) do
+	local state = redis.call('HGET', KEYS[3], trigger)
+	if state == ARGV[2] then
+		local score = redis.call('ZSCORE', KEYS[4], trigger)
+		redis.call('ZREM', KEYS[4], trigger)
+		if score then
+			redis.call('ZADD', KEYS[5], score, trigger)
+		end
+		redis.call('HSET', KEYS[3], trigger, ARGV[3])
+	elseif state == ARGV[4] then
+		redis.call('HSET', KEYS[3], trigger, ARGV[5])
+	end
+end
+return 1
+`
+
+// unblockJobScript marks the job completed, and moves its blocked triggers back to the waiting set
+// and its STATE_PAUSED_BLOCKED triggers to STATE_PAUSED.
+const unblockJobScript = `
+redis.call('SREM', KEYS[1], ARGV[1])
+for _, trigger in ipairs(redis.call('SMEMBERS', KEYS[2])) do
+	local state = redis.call('HGET', KEYS[3], trigger)
+	if state == ARGV[3] then
+		local score = redis.call('ZSCORE', KEYS[5], trigger)
+		redis.call('ZREM', KEYS[5], trigger)
+		if score then
+			redis.call('ZADD', KEYS[4], score, trigger)
+		end
+		redis.call('HSET', KEYS[3], trigger, ARGV[2])
+	elseif state == ARGV[5] then
+		redis.call('HSET', KEYS[3], trigger, ARGV[4])
+	end
+end
+return 1
+`
+
+// RedisJobStore is a JobStore which keeps its jobs, triggers, calendars and paused groups in Redis,
+// so the schedule can be shared by the schedulers of several processes.
+//
+// The jobs, triggers and calendars are serialized like the FileJobStore does, in hashes keyed by their keys,
+// and the waiting, paused and blocked triggers are kept in sorted sets scored by their next fire time.
+// The triggers are acquired, and the triggers of the jobs disallowing concurrent execution are blocked and unblocked,
+// atomically with Lua scripts, the other changes are not atomic across the processes.
+type RedisJobStore struct {
+	client RedisClient
+	prefix string
+	cipher DataMapCipher
+	clock  Clock
+}
+
+// NewRedisJobStore creates a RedisJobStore whose Redis keys start with the prefix, defaults to "quartz:".
+func NewRedisJobStore(client RedisClient, prefix string) *RedisJobStore {
+	if prefix == "" {
+		prefix = "quartz:"
+	}
+
+	return &RedisJobStore{client: client, prefix: prefix, clock: SystemClock}
+}
+
+// SetClock sets the clock of the fire times and the batches, it must be called before the scheduler is started.
+func (s *RedisJobStore) SetClock(clock Clock) {
+	s.clock = clock
+}
+
+// SetDataMapCipher sets the cipher encrypting the JobDataMap values, it must be called before the store is used.
+func (s *RedisJobStore) SetDataMapCipher(cipher DataMapCipher) {
+	s.cipher = cipher
+}
+
+func (s *RedisJobStore) jobsKey() string { return s.prefix + "jobs" }
+
+func (s *RedisJobStore) triggersKey() string { return s.prefix + "triggers" }
+
+func (s *RedisJobStore) statesKey() string { return s.prefix + "trigger_states" }
+
+func (s *RedisJobStore) waitingKey() string { return s.prefix + "waiting_triggers" }
+
+func (s *RedisJobStore) pausedKey() string { return s.prefix + "paused_triggers" }
+
+func (s *RedisJobStore) blockedKey() string { return s.prefix + "blocked_triggers" }
+
+func (s *RedisJobStore) blockedJobsKey() string { return s.prefix + "blocked_jobs" }
+
+func (s *RedisJobStore) calendarsKey() string { return s.prefix + "calendars" }
+
+func (s *RedisJobStore) jobTriggersKey(key JobKey) string {
+	return s.prefix + "job_triggers:" + key.String()
+}
+
+func (s *RedisJobStore) pausedGroupsKey(groupType string) string {
+	if groupType == pausedJobGroup {
+		return s.prefix + "paused_job_groups"
+	}
+
+	return s.prefix + "paused_trigger_groups"
+}
+
+// fireTimeScore returns the fire time in milliseconds since the epoch, the precision of a sorted set score.
+func fireTimeScore(t time.Time) float64 {
+	return float64(t.UnixNano() / int64(time.Millisecond))
+}
+
+func (s *RedisJobStore) SchedulerStarted() error { return nil }
+
+func (s *RedisJobStore) SchedulerPaused() {}
+
+func (s *RedisJobStore) SchedulerResumed() {}
+
+func (s *RedisJobStore) Shutdown() {}
+
+func (s *RedisJobStore) SupportsPersistence() bool { return true }
+
+func (s *RedisJobStore) Clustered() bool { return false }
+
+func (s *RedisJobStore) StoreJobAndTrigger(job JobDetail, trigger OperableTrigger) error {
+	if err := s.StoreJob(job, false); err != nil {
+		return err
+	}
+
+	return s.StoreTrigger(trigger, false)
+}
+
+func (s *RedisJobStore) StoreJobsAndTriggers(triggersAndJobs map[JobDetail][]Trigger, replace bool) error {
+	if !replace {
+		for job, triggers := range triggersAndJobs {
+			if s.CheckJobExists(job.Key()) {
+				return jobAlreadyExistsError(job)
+			}
+
+			for _, trigger := range triggers {
+				if s.CheckTriggerExists(trigger.Key()) {
+					return triggerAlreadyExistsError(trigger)
+				}
+			}
+		}
+	}
+
+	for job, triggers := range triggersAndJobs {
+		if err := s.StoreJob(job, true); err != nil {
+			return err
+		}
+
+		for _, trigger := range triggers {
+			if err := s.StoreTrigger(trigger.(OperableTrigger), true); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *RedisJobStore) StoreJob(job JobDetail, replaceExisting bool) error {
+	_, exists, err := s.client.HGet(s.jobsKey(), job.Key().String())
+
+	if err != nil {
+		return err
+	}
+
+	if exists && !replaceExisting {
+		return jobAlreadyExistsError(job)
+	}
+
+	data, err := encodeJob(job, s.cipher)
+
+	if err != nil {
+		return err
+	}
+
+	return s.client.HSet(s.jobsKey(), job.Key().String(), string(data))
+}
+
+// StoreTrigger stores the trigger as waiting, or as paused if its group or the group of its job is paused.
+func (s *RedisJobStore) StoreTrigger(trigger OperableTrigger, replaceExisting bool) error {
+	if trigger.JobKey() == nil {
+		return missingJobKeyError(trigger)
+	}
+
+	if s.CheckTriggerExists(trigger.Key()) {
+		if !replaceExisting {
+			return triggerAlreadyExistsError(trigger)
+		}
+
+		if _, err := s.removeTrigger(trigger.Key(), false); err != nil {
+			return err
+		}
+	}
+
+	if !s.CheckJobExists(trigger.JobKey()) {
+		return jobPersistenceError(trigger)
+	}
+
+	if err := s.saveTrigger(trigger); err != nil {
+		return err
+	}
+
+	if err := s.client.SAdd(s.jobTriggersKey(trigger.JobKey()), trigger.Key().String()); err != nil {
+		return err
+	}
+
+	paused, err := s.isPaused(trigger.Key().Group(), trigger.JobKey().Group())
+
+	if err != nil {
+		return err
+	}
+
+	if paused {
+		return s.setState(trigger, STATE_PAUSED)
+	}
+
+	return s.setState(trigger, STATE_WAITING)
+}
+
+// StoreTriggers checks the triggers before storing them, the triggers are not stored atomically.
+func (s *RedisJobStore) StoreTriggers(triggers []OperableTrigger, replaceExisting bool) error {
+	for _, trigger := range triggers {
+		if trigger.JobKey() == nil {
+			return missingJobKeyError(trigger)
+		}
+
+		if !replaceExisting && s.CheckTriggerExists(trigger.Key()) {
+			return triggerAlreadyExistsError(trigger)
+		}
+
+		if !s.CheckJobExists(trigger.JobKey()) {
+			return jobPersistenceError(trigger)
+		}
+	}
+
+	for _, trigger := range triggers {
+		if err := s.StoreTrigger(trigger, true); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *RedisJobStore) saveTrigger(trigger OperableTrigger) error {
+	data, err := encodeTrigger(trigger, s.cipher)
+
+	if err != nil {
+		return err
+	}
+
+	return s.client.HSet(s.triggersKey(), trigger.Key().String(), string(data))
+}
+
+// setState records the state of the trigger, and moves it to the sorted set of the state.
+func (s *RedisJobStore) setState(trigger OperableTrigger, state TriggerState) error {
+	key := trigger.Key().String()
+
+	if err := s.client.HSet(s.statesKey(), key, strconv.Itoa(int(state))); err != nil {
+		return err
+	}
+
+	if err := s.client.ZRem(s.waitingKey(), key); err != nil {
+		return err
+	}
+
+	if err := s.client.ZRem(s.pausedKey(), key); err != nil {
+		return err
+	}
+
+	if err := s.client.ZRem(s.blockedKey(), key); err != nil {
+		return err
+	}
+
+	if fireTime := trigger.NextFireTime(); !fireTime.IsZero() {
+		switch state {
+		case STATE_WAITING:
+			return s.client.ZAdd(s.waitingKey(), fireTimeScore(fireTime), key)
+
+		case STATE_PAUSED, STATE_PAUSED_BLOCKED:
+			return s.client.ZAdd(s.pausedKey(), fireTimeScore(fireTime), key)
+
+		case STATE_BLOCKED:
+			return s.client.ZAdd(s.blockedKey(), fireTimeScore(fireTime), key)
+		}
+	}
+
+	return nil
+}
+
+func (s *RedisJobStore) isPaused(triggerGroup, jobGroup string) (bool, error) {
+	if paused, err := s.client.SIsMember(s.pausedGroupsKey(pausedTriggerGroup), triggerGroup); err != nil || paused {
+		return paused, err
+	}
+
+	return s.client.SIsMember(s.pausedGroupsKey(pausedJobGroup), jobGroup)
+}
+
+func (s *RedisJobStore) RemoveJob(key JobKey) (bool, error) {
+	return s.removeJob(key)
+}
+
+func (s *RedisJobStore) RemoveJobs(keys []JobKey) (bool, error) {
+	allFound := true
+
+	for _, key := range keys {
+		found, err := s.removeJob(key)
+
+		if err != nil {
+			return false, err
+		}
+
+		allFound = found && allFound
+	}
+
+	return allFound, nil
+}
+
+// removeJob removes the job and its triggers.
+func (s *RedisJobStore) removeJob(key JobKey) (bool, error) {
+	triggerKeys, err := s.client.SMembers(s.jobTriggersKey(key))
+
+	if err != nil {
+		return false, err
+	}
+
+	for _, triggerKey := range triggerKeys {
+		if _, err := s.removeTrigger(TriggerKey(triggerKey), false); err != nil {
+			return false, err
+		}
+	}
+
+	exists := s.CheckJobExists(key)
+
+	if err := s.client.HDel(s.jobsKey(), key.String()); err != nil {
+		return false, err
+	}
+
+	return exists, s.client.Del(s.jobTriggersKey(key))
+}
+
+// RetrieveJob returns the job, or nil if it doesn't exist.
+func (s *RedisJobStore) RetrieveJob(key JobKey) (JobDetail, error) {
+	data, exists, err := s.client.HGet(s.jobsKey(), key.String())
+
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	return decodeJob(key.String(), []byte(data), s.cipher)
+}
+
+func (s *RedisJobStore) RemoveTrigger(key TriggerKey) (bool, error) {
+	return s.removeTrigger(key, true)
+}
+
+func (s *RedisJobStore) RemoveTriggers(keys []TriggerKey) (bool, error) {
+	allFound := true
+
+	for _, key := range keys {
+		found, err := s.removeTrigger(key, true)
+
+		if err != nil {
+			return false, err
+		}
+
+		allFound = found && allFound
+	}
+
+	return allFound, nil
+}
+
+// removeTrigger removes the trigger, and its job if the job is not durable and has no trigger left.
+func (s *RedisJobStore) removeTrigger(key TriggerKey, removeOrphanedJob bool) (bool, error) {
+	trigger, err := s.RetrieveTrigger(key)
+
+	if err != nil || trigger == nil {
+		return false, err
+	}
+
+	if err := s.client.HDel(s.triggersKey(), key.String()); err != nil {
+		return false, err
+	}
+
+	if err := s.client.HDel(s.statesKey(), key.String()); err != nil {
+		return false, err
+	}
+
+	if err := s.client.ZRem(s.waitingKey(), key.String()); err != nil {
+		return false, err
+	}
+
+	if err := s.client.ZRem(s.pausedKey(), key.String()); err != nil {
+		return false, err
+	}
+
+	if err := s.client.ZRem(s.blockedKey(), key.String()); err != nil {
+		return false, err
+	}
+
+	if err := s.client.SRem(s.jobTriggersKey(trigger.JobKey()), key.String()); err != nil {
+		return false, err
+	}
+
+	if removeOrphanedJob {
+		job, err := s.RetrieveJob(trigger.JobKey())
+
+		if err != nil {
+			return true, err
+		}
+
+		if job != nil && !job.Durable() {
+			triggerKeys, err := s.client.SMembers(s.jobTriggersKey(job.Key()))
+
+			if err != nil {
+				return true, err
+			}
+
+			if len(triggerKeys) == 0 {
+				if _, err := s.removeJob(job.Key()); err != nil {
+					return true, err
+				}
+			}
+		}
+	}
+
+	return true, nil
+}
+
+func (s *RedisJobStore) ReplaceTrigger(key TriggerKey, trigger OperableTrigger) error {
+	old, err := s.RetrieveTrigger(key)
+
+	if err != nil {
+		return err
+	}
+
+	if old == nil {
+		return triggerNotFoundError(key)
+	}
+
+	if !old.JobKey().Equals(trigger.JobKey()) {
+		return errors.New("New trigger is not related to the same job as the old trigger.")
+	}
+
+	if _, err := s.removeTrigger(key, false); err != nil {
+		return err
+	}
+
+	return s.StoreTrigger(trigger, false)
+}
+
+// RetrieveTrigger returns the trigger, or nil if it doesn't exist.
+func (s *RedisJobStore) RetrieveTrigger(key TriggerKey) (OperableTrigger, error) {
+	data, exists, err := s.client.HGet(s.triggersKey(), key.String())
+
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	return decodeTrigger(key.String(), []byte(data), s.cipher)
+}
+
+func (s *RedisJobStore) RetrieveTriggerWithState(key TriggerKey) (OperableTrigger, TriggerState, time.Time, error) {
+	trigger, err := s.RetrieveTrigger(key)
+
+	if err != nil {
+		return nil, STATE_ERROR, zero, err
+	}
+
+	if trigger == nil {
+		return nil, STATE_NONE, zero, triggerNotFoundError(key)
+	}
+
+	state, err := s.GetTriggerState(key)
+
+	if err != nil {
+		return nil, STATE_ERROR, zero, err
+	}
+
+	return trigger, state, trigger.NextFireTime(), nil
+}
+
+// GetTriggerState returns the current state of the trigger, or STATE_NONE with ErrTriggerNotFound if it does not exist.
+func (s *RedisJobStore) GetTriggerState(key TriggerKey) (TriggerState, error) {
+	value, exists, err := s.client.HGet(s.statesKey(), key.String())
+
+	if err != nil {
+		return STATE_ERROR, err
+	}
+
+	if !exists {
+		return STATE_NONE, triggerNotFoundError(key)
+	}
+
+	state, err := strconv.Atoi(value)
+
+	if err != nil {
+		return STATE_ERROR, fmt.Errorf("Couldn't decode the state of the trigger (%s): %w", key, err)
+	}
+
+	return TriggerState(state), nil
+}
+
+// ResetTriggerFromErrorState moves the trigger out of STATE_ERROR, the trigger in other states is left untouched.
+func (s *RedisJobStore) ResetTriggerFromErrorState(key TriggerKey) error {
+	state, err := s.GetTriggerState(key)
+
+	if err != nil || state != STATE_ERROR {
+		return err
+	}
+
+	trigger, err := s.RetrieveTrigger(key)
+
+	if err != nil || trigger == nil {
+		return err
+	}
+
+	paused, err := s.isPaused(key.Group(), trigger.JobKey().Group())
+
+	if err != nil {
+		return err
+	}
+
+	if paused {
+		return s.setState(trigger, STATE_PAUSED)
+	}
+
+	return s.setState(trigger, STATE_WAITING)
+}
+
+func (s *RedisJobStore) CheckJobExists(key JobKey) bool {
+	_, exists, err := s.client.HGet(s.jobsKey(), key.String())
+
+	return err == nil && exists
+}
+
+func (s *RedisJobStore) CheckTriggerExists(key TriggerKey) bool {
+	_, exists, err := s.client.HGet(s.triggersKey(), key.String())
+
+	return err == nil && exists
+}
+
+func (s *RedisJobStore) NumberOfJobs() int {
+	n, _ := s.client.HLen(s.jobsKey())
+
+	return n
+}
+
+func (s *RedisJobStore) NumberOfTriggers() int {
+	n, _ := s.client.HLen(s.triggersKey())
+
+	return n
+}
+
+func (s *RedisJobStore) TriggersForJob(key JobKey) []OperableTrigger {
+	triggerKeys, err := s.client.SMembers(s.jobTriggersKey(key))
+
+	if err != nil {
+		return nil
+	}
+
+	sort.Strings(triggerKeys)
+
+	var triggers []OperableTrigger
+
+	for _, triggerKey := range triggerKeys {
+		if trigger, err := s.RetrieveTrigger(TriggerKey(triggerKey)); err == nil && trigger != nil {
+			triggers = append(triggers, trigger)
+		}
+	}
+
+	return triggers
+}
+
+func (s *RedisJobStore) GetJobKeys(matcher GroupMatcher) ([]JobKey, error) {
+	values, err := s.client.HKeys(s.jobsKey())
+
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []JobKey
+
+	for _, value := range values {
+		if key := JobKey(value); matcher.MatchesGroup(key.Group()) {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool { return groupedKeyLess(keys[i], keys[j]) })
+
+	return keys, nil
+}
+
+func (s *RedisJobStore) GetTriggerKeys(matcher GroupMatcher) ([]TriggerKey, error) {
+	values, err := s.client.HKeys(s.triggersKey())
+
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []TriggerKey
+
+	for _, value := range values {
+		if key := TriggerKey(value); matcher.MatchesGroup(key.Group()) {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool { return groupedKeyLess(keys[i], keys[j]) })
+
+	return keys, nil
+}
+
+// StoreCalendar stores the calendar, only the Daily, Annual, Monthly and Holiday calendars can be persisted,
+// the fire times of the triggers using a replaced calendar are not updated.
+func (s *RedisJobStore) StoreCalendar(name string, calendar Calendar, replaceExisting bool) error {
+	_, exists, err := s.client.HGet(s.calendarsKey(), name)
+
+	if err != nil {
+		return err
+	}
+
+	if exists && !replaceExisting {
+		return calendarAlreadyExistsError(name)
+	}
+
+	data, err := encodeCalendar(name, calendar)
+
+	if err != nil {
+		return err
+	}
+
+	return s.client.HSet(s.calendarsKey(), name, string(data))
+}
+
+func (s *RedisJobStore) RemoveCalendar(name string) (bool, error) {
+	referenced, err := calendarReferenced(s, name)
+
+	if err != nil {
+		return false, err
+	}
+
+	if referenced {
+		return false, calendarReferencedError(name)
+	}
+
+	_, exists, err := s.client.HGet(s.calendarsKey(), name)
+
+	if err != nil || !exists {
+		return false, err
+	}
+
+	return true, s.client.HDel(s.calendarsKey(), name)
+}
+
+// RetrieveCalendar returns the stored calendar, or nil if there is none.
+func (s *RedisJobStore) RetrieveCalendar(name string) (Calendar, error) {
+	data, exists, err := s.client.HGet(s.calendarsKey(), name)
+
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	return decodeCalendar(name, []byte(data))
+}
+
+func (s *RedisJobStore) GetCalendarNames() ([]string, error) {
+	names, err := s.client.HKeys(s.calendarsKey())
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(names)
+
+	return names, nil
+}
+
+func (s *RedisJobStore) PauseTrigger(key TriggerKey) error {
+	trigger, err := s.RetrieveTrigger(key)
+
+	if err != nil || trigger == nil {
+		return err
+	}
+
+	return s.pauseTrigger(trigger)
+}
+
+// pauseTrigger moves the trigger to the paused set, the completed triggers are left untouched.
+func (s *RedisJobStore) pauseTrigger(trigger OperableTrigger) error {
+	state, err := s.GetTriggerState(trigger.Key())
+
+	if err != nil {
+		return err
+	}
+
+	switch state {
+	case STATE_COMPLETE, STATE_PAUSED, STATE_PAUSED_BLOCKED:
+		return nil
+
+	case STATE_BLOCKED:
+		return s.setState(trigger, STATE_PAUSED_BLOCKED)
+	}
+
+	return s.setState(trigger, STATE_PAUSED)
+}
+
+func (s *RedisJobStore) ResumeTrigger(key TriggerKey) error {
+	trigger, err := s.RetrieveTrigger(key)
+
+	if err != nil || trigger == nil {
+		return err
+	}
+
+	return s.resumeTrigger(trigger)
+}
+
+// resumeTrigger moves the paused trigger back to the waiting set, or to the blocked set if its job is executing.
+func (s *RedisJobStore) resumeTrigger(trigger OperableTrigger) error {
+	state, err := s.GetTriggerState(trigger.Key())
+
+	if err != nil {
+		return err
+	}
+
+	switch state {
+	case STATE_PAUSED:
+		return s.setState(trigger, STATE_WAITING)
+
+	case STATE_PAUSED_BLOCKED:
+		return s.setState(trigger, STATE_BLOCKED)
+	}
+
+	return nil
+}
+
+func (s *RedisJobStore) PauseJob(key JobKey) error {
+	for _, trigger := range s.TriggersForJob(key) {
+		if err := s.pauseTrigger(trigger); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *RedisJobStore) ResumeJob(key JobKey) error {
+	for _, trigger := range s.TriggersForJob(key) {
+		if err := s.resumeTrigger(trigger); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// triggersInGroups returns the stored triggers whose group is in the groups.
+func (s *RedisJobStore) triggersInGroups(groups []string, groupOf func(trigger OperableTrigger) string) ([]OperableTrigger, error) {
+	keys, err := s.GetTriggerKeys(AnyGroup())
+
+	if err != nil {
+		return nil, err
+	}
+
+	matched := make(map[string]bool, len(groups))
+
+	for _, group := range groups {
+		matched[group] = true
+	}
+
+	var triggers []OperableTrigger
+
+	for _, key := range keys {
+		trigger, err := s.RetrieveTrigger(key)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if trigger != nil && matched[groupOf(trigger)] {
+			triggers = append(triggers, trigger)
+		}
+	}
+
+	return triggers, nil
+}
+
+func triggerGroupOf(trigger OperableTrigger) string { return trigger.Key().Group() }
+
+func jobGroupOf(trigger OperableTrigger) string { return trigger.JobKey().Group() }
+
+// groupsOf returns the groups of the keys.
+func groupsOf(keys []string, group func(key string) string) []string {
+	var groups []string
+
+	for _, key := range keys {
+		groups = append(groups, group(key))
+	}
+
+	return groups
+}
+
+// PauseTriggers pauses the triggers in the groups matched by the matcher, and returns the paused groups.
+//
+// The triggers stored into a paused group later will be paused too.
+func (s *RedisJobStore) PauseTriggers(matcher GroupMatcher) ([]string, error) {
+	keys, err := s.client.HKeys(s.triggersKey())
+
+	if err != nil {
+		return nil, err
+	}
+
+	groups := matchedGroups(matcher, groupsOf(keys, func(key string) string { return TriggerKey(key).Group() }))
+
+	return groups, s.pauseGroups(pausedTriggerGroup, groups, triggerGroupOf)
+}
+
+// ResumeTriggers resumes the triggers in the groups matched by the matcher, and returns the resumed groups.
+//
+// The triggers of the jobs in a paused job group are kept paused.
+func (s *RedisJobStore) ResumeTriggers(matcher GroupMatcher) ([]string, error) {
+	keys, err := s.client.HKeys(s.triggersKey())
+
+	if err != nil {
+		return nil, err
+	}
+
+	paused, err := s.client.SMembers(s.pausedGroupsKey(pausedTriggerGroup))
+
+	if err != nil {
+		return nil, err
+	}
+
+	groups := matchedGroups(matcher, groupsOf(keys, func(key string) string { return TriggerKey(key).Group() }), paused)
+
+	return groups, s.resumeGroups(pausedTriggerGroup, groups, triggerGroupOf)
+}
+
+// PauseJobs pauses the triggers of the jobs in the groups matched by the matcher, and returns the paused groups.
+//
+// The triggers stored for the jobs in a paused group later will be paused too.
+func (s *RedisJobStore) PauseJobs(matcher GroupMatcher) ([]string, error) {
+	keys, err := s.client.HKeys(s.jobsKey())
+
+	if err != nil {
+		return nil, err
+	}
+
+	groups := matchedGroups(matcher, groupsOf(keys, func(key string) string { return JobKey(key).Group() }))
+
+	return groups, s.pauseGroups(pausedJobGroup, groups, jobGroupOf)
+}
+
+// ResumeJobs resumes the triggers of the jobs in the groups matched by the matcher, and returns the resumed groups.
+func (s *RedisJobStore) ResumeJobs(matcher GroupMatcher) ([]string, error) {
+	keys, err := s.client.HKeys(s.jobsKey())
+
+	if err != nil {
+		return nil, err
+	}
+
+	paused, err := s.client.SMembers(s.pausedGroupsKey(pausedJobGroup))
+
+	if err != nil {
+		return nil, err
+	}
+
+	groups := matchedGroups(matcher, groupsOf(keys, func(key string) string { return JobKey(key).Group() }), paused)
+
+	return groups, s.resumeGroups(pausedJobGroup, groups, jobGroupOf)
+}
+
+func (s *RedisJobStore) pauseGroups(groupType string, groups []string, groupOf func(trigger OperableTrigger) string) error {
+	if len(groups) == 0 {
+		return nil
+	}
+
+	if err := s.client.SAdd(s.pausedGroupsKey(groupType), groups...); err != nil {
+		return err
+	}
+
+	triggers, err := s.triggersInGroups(groups, groupOf)
+
+	if err != nil {
+		return err
+	}
+
+	for _, trigger := range triggers {
+		if err := s.pauseTrigger(trigger); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *RedisJobStore) resumeGroups(groupType string, groups []string, groupOf func(trigger OperableTrigger) string) error {
+	if len(groups) == 0 {
+		return nil
+	}
+
+	if err := s.client.SRem(s.pausedGroupsKey(groupType), groups...); err != nil {
+		return err
+	}
+
+	triggers, err := s.triggersInGroups(groups, groupOf)
+
+	if err != nil {
+		return err
+	}
+
+	for _, trigger := range triggers {
+		if groupType == pausedTriggerGroup {
+			if paused, err := s.client.SIsMember(s.pausedGroupsKey(pausedJobGroup), trigger.JobKey().Group()); err != nil {
+				return err
+			} else if paused {
+				continue
+			}
+		}
+
+		if err := s.resumeTrigger(trigger); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PauseAll pauses all the trigger groups, the triggers stored into them later will be paused too.
+func (s *RedisJobStore) PauseAll() error {
+	_, err := s.PauseTriggers(AnyGroup())
+
+	return err
+}
+
+// ResumeAll resumes all the triggers and forgets the paused groups.
+func (s *RedisJobStore) ResumeAll() error {
+	if err := s.client.Del(s.pausedGroupsKey(pausedTriggerGroup), s.pausedGroupsKey(pausedJobGroup)); err != nil {
+		return err
+	}
+
+	keys, err := s.GetTriggerKeys(AnyGroup())
+
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if err := s.ResumeTrigger(key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ClearAllSchedulingData removes all the jobs and triggers, the paused groups are kept.
+func (s *RedisJobStore) ClearAllSchedulingData() error {
+	jobKeys, err := s.client.HKeys(s.jobsKey())
+
+	if err != nil {
+		return err
+	}
+
+	keys := []string{s.jobsKey(), s.triggersKey(), s.statesKey(), s.waitingKey(), s.pausedKey(), s.blockedKey(),
+		s.blockedJobsKey(), s.calendarsKey()}
+
+	for _, key := range jobKeys {
+		keys = append(keys, s.jobTriggersKey(JobKey(key)))
+	}
+
+	return s.client.Del(keys...)
+}
+
+// AcquireNextTriggers acquires at most maxCount waiting triggers ordered by their next fire time and then by priority,
+// the first one fires no later than noLaterThan, and the others no later than the timeWindow after the first one,
+// or after now if the first one is overdue.
+//
+// Each trigger is acquired by a Lua script removing it from the waiting set,
+// so a trigger acquired by another scheduler in the meantime is skipped.
+// Only one trigger of a job disallowing concurrent execution is acquired in a batch, and none while the job executes.
+func (s *RedisJobStore) AcquireNextTriggers(noLaterThan time.Time, maxCount int, timeWindow time.Duration) ([]OperableTrigger, error) {
+	max := strconv.FormatFloat(fireTimeScore(noLaterThan.Add(timeWindow)), 'f', -1, 64)
+
+	keys, err := s.client.ZRangeByScore(s.waitingKey(), "-inf", max)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []OperableTrigger
+
+	for _, key := range keys {
+		trigger, err := s.RetrieveTrigger(TriggerKey(key))
+
+		if err != nil {
+			return nil, err
+		}
+
+		if trigger != nil {
+			candidates = append(candidates, trigger)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		lhs, rhs := candidates[i], candidates[j]
+
+		if !lhs.NextFireTime().Equal(rhs.NextFireTime()) {
+			return lhs.NextFireTime().Before(rhs.NextFireTime())
+		}
+
+		return lhs.Priority() > rhs.Priority()
+	})
+
+	var triggers []OperableTrigger
+
+	batchEnd := noLaterThan
+
+	acquiredJobs := make(map[string]bool)
+
+	for _, trigger := range candidates {
+		if len(triggers) >= maxCount || trigger.NextFireTime().After(batchEnd) {
+			break
+		}
+
+		if acquiredJobs[trigger.JobKey().String()] {
+			continue
+		}
+
+		acquired, err := s.client.Eval(acquireTriggerScript, []string{s.waitingKey(), s.statesKey(), s.blockedJobsKey()},
+			trigger.Key().String(), strconv.Itoa(int(STATE_ACQUIRED)), trigger.JobKey().String())
+
+		if err != nil {
+			// the scheduler discards the triggers on error, they would be left acquired.
+			for _, trigger := range triggers {
+				s.ReleaseAcquiredTrigger(trigger)
+			}
+
+			return nil, err
+		}
+
+		if acquired == 1 {
+			if job, err := s.RetrieveJob(trigger.JobKey()); err == nil && job != nil && job.ConcurrentExecutionDisallowed() {
+				acquiredJobs[job.Key().String()] = true
+			}
+
+			if len(triggers) == 0 {
+				batchEnd = batchEndAfter(trigger.NextFireTime(), s.clock.Now(), timeWindow)
+			}
+
+			triggers = append(triggers, trigger)
+		}
+	}
+
+	return triggers, nil
+}
+
+func (s *RedisJobStore) ReleaseAcquiredTrigger(trigger OperableTrigger) {
+	if state, err := s.GetTriggerState(trigger.Key()); err == nil && state == STATE_ACQUIRED {
+		if stored, err := s.RetrieveTrigger(trigger.Key()); err == nil && stored != nil {
+			s.setState(stored, STATE_WAITING)
+		}
+	}
+}
+
+// TriggersFired updates the acquired triggers for their next fire time,
+// and returns the bundles to execute their jobs, the triggers no longer acquired are skipped.
+//
+// The triggers of a job disallowing concurrent execution are blocked until the job completed.
+func (s *RedisJobStore) TriggersFired(triggers []OperableTrigger) ([]*TriggerFiredBundle, error) {
+	var bundles []*TriggerFiredBundle
+
+	for _, trigger := range triggers {
+		if state, err := s.GetTriggerState(trigger.Key()); err != nil || state != STATE_ACQUIRED {
+			continue
+		}
+
+		stored, err := s.RetrieveTrigger(trigger.Key())
+
+		if err != nil {
+			return nil, err
+		}
+
+		if stored == nil {
+			continue
+		}
+
+		job, err := s.RetrieveJob(stored.JobKey())
+
+		if err != nil {
+			return nil, err
+		}
+
+		if job == nil {
+			continue
+		}
+
+		state := STATE_WAITING
+
+		if job.ConcurrentExecutionDisallowed() {
+			blocked, err := s.client.Eval(blockJobScript, s.blockingKeys(job.Key()), job.Key().String(),
+				strconv.Itoa(int(STATE_WAITING)), strconv.Itoa(int(STATE_BLOCKED)),
+				strconv.Itoa(int(STATE_PAUSED)), strconv.Itoa(int(STATE_PAUSED_BLOCKED)))
+
+			if err != nil {
+				return nil, err
+			}
+
+			// the trigger acquired before its job was blocked waits for the job to complete.
+			if blocked == 0 {
+				if err := s.setState(stored, STATE_BLOCKED); err != nil {
+					return nil, err
+				}
+
+				continue
+			}
+
+			state = STATE_BLOCKED
+		}
+
+		before := stored.Clone().(OperableTrigger)
+
+		var cal Calendar
+
+		if name := stored.CalendarName(); name != "" {
+			if cal, err = s.RetrieveCalendar(name); err != nil {
+				return nil, err
+			}
+		}
+
+		stored.Triggered(cal)
+
+		if err := s.saveTrigger(stored); err != nil {
+			return nil, err
+		}
+
+		if err := s.setState(stored, state); err != nil {
+			return nil, err
+		}
+
+		bundles = append(bundles, &TriggerFiredBundle{
+			JobDetail:         job,
+			Trigger:           stored.Clone().(OperableTrigger),
+			Calendar:          cloneCalendar(cal),
+			FireTime:          s.clock.Now(),
+			ScheduledFireTime: before.NextFireTime(),
+			PrevFireTime:      before.PreviousFireTime(),
+			NextFireTime:      stored.NextFireTime(),
+		})
+	}
+
+	return bundles, nil
+}
+
+// blockingKeys returns the keys of the scripts blocking and unblocking the triggers of the job.
+func (s *RedisJobStore) blockingKeys(key JobKey) []string {
+	return []string{s.blockedJobsKey(), s.jobTriggersKey(key), s.statesKey(), s.waitingKey(), s.blockedKey()}
+}
+
+func (s *RedisJobStore) TriggeredJobComplete(trigger OperableTrigger, job JobDetail, instruction CompletedExecutionInstruction) {
+	if job.PersistJobDataAfterExecution() {
+		if stored, err := s.RetrieveJob(job.Key()); err == nil {
+			if d, ok := stored.(*jobDetail); ok && job.JobDataMap() != nil {
+				d.dataMap = job.JobDataMap().CloneDataMap()
+
+				s.StoreJob(d, true)
+			}
+		}
+	}
+
+	if job.ConcurrentExecutionDisallowed() {
+		s.client.Eval(unblockJobScript, s.blockingKeys(job.Key()), job.Key().String(),
+			strconv.Itoa(int(STATE_WAITING)), strconv.Itoa(int(STATE_BLOCKED)),
+			strconv.Itoa(int(STATE_PAUSED)), strconv.Itoa(int(STATE_PAUSED_BLOCKED)))
+	}
+
+	stored, err := s.RetrieveTrigger(trigger.Key())
+
+	if err != nil || stored == nil {
+		return
+	}
+
+	switch instruction {
+	case DELETE_TRIGGER:
+		// the trigger may have been rescheduled while its job was executing.
+		if stored.NextFireTime().Equal(trigger.NextFireTime()) {
+			s.removeTrigger(trigger.Key(), true)
+		}
+
+	case SET_TRIGGER_COMPLETE:
+		s.setState(stored, STATE_COMPLETE)
+
+	case SET_TRIGGER_ERROR:
+		s.setState(stored, STATE_ERROR)
+
+	case SET_ALL_JOB_TRIGGERS_COMPLETE, SET_ALL_JOB_TRIGGERS_ERROR:
+		state := STATE_COMPLETE
+
+		if instruction == SET_ALL_JOB_TRIGGERS_ERROR {
+			state = STATE_ERROR
+		}
+
+		for _, t := range s.TriggersForJob(job.Key()) {
+			s.setState(t, state)
+		}
+	}
+}
@@ -0,0 +1,677 @@
+package quartz
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// fakeRedis is an in-memory RedisClient, it runs the Go equivalent of the known scripts.
+type fakeRedis struct {
+	lock   sync.Mutex
+	hashes map[string]map[string]string
+	sets   map[string]map[string]bool
+	zsets  map[string]map[string]float64
+
+	// evalsBeforeFailure fails the Eval calls once they are exhausted, if positive.
+	evalsBeforeFailure int
+}
+
+func newFakeRedis() *fakeRedis {
+	return &fakeRedis{
+		hashes: make(map[string]map[string]string),
+		sets:   make(map[string]map[string]bool),
+		zsets:  make(map[string]map[string]float64),
+	}
+}
+
+func (r *fakeRedis) HGet(key, field string) (string, bool, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	value, exists := r.hashes[key][field]
+
+	return value, exists, nil
+}
+
+func (r *fakeRedis) HSet(key, field, value string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.hset(key, field, value)
+
+	return nil
+}
+
+func (r *fakeRedis) hset(key, field, value string) {
+	if r.hashes[key] == nil {
+		r.hashes[key] = make(map[string]string)
+	}
+
+	r.hashes[key][field] = value
+}
+
+func (r *fakeRedis) HDel(key string, fields ...string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, field := range fields {
+		delete(r.hashes[key], field)
+	}
+
+	return nil
+}
+
+func (r *fakeRedis) HKeys(key string) ([]string, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var keys []string
+
+	for field := range r.hashes[key] {
+		keys = append(keys, field)
+	}
+
+	return keys, nil
+}
+
+func (r *fakeRedis) HLen(key string) (int, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return len(r.hashes[key]), nil
+}
+
+func (r *fakeRedis) SAdd(key string, members ...string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.sets[key] == nil {
+		r.sets[key] = make(map[string]bool)
+	}
+
+	for _, member := range members {
+		r.sets[key][member] = true
+	}
+
+	return nil
+}
+
+func (r *fakeRedis) SRem(key string, members ...string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, member := range members {
+		delete(r.sets[key], member)
+	}
+
+	return nil
+}
+
+func (r *fakeRedis) SMembers(key string) ([]string, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var members []string
+
+	for member := range r.sets[key] {
+		members = append(members, member)
+	}
+
+	return members, nil
+}
+
+func (r *fakeRedis) SIsMember(key, member string) (bool, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.sets[key][member], nil
+}
+
+func (r *fakeRedis) ZAdd(key string, score float64, member string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.zsets[key] == nil {
+		r.zsets[key] = make(map[string]float64)
+	}
+
+	r.zsets[key][member] = score
+
+	return nil
+}
+
+func (r *fakeRedis) ZRem(key string, members ...string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, member := range members {
+		delete(r.zsets[key], member)
+	}
+
+	return nil
+}
+
+func (r *fakeRedis) ZRangeByScore(key, min, max string) ([]string, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	parse := func(s string) (float64, error) {
+		switch s {
+		case "-inf":
+			return math.Inf(-1), nil
+		case "+inf":
+			return math.Inf(1), nil
+		}
+
+		return strconv.ParseFloat(s, 64)
+	}
+
+	lo, err := parse(min)
+
+	if err != nil {
+		return nil, err
+	}
+
+	hi, err := parse(max)
+
+	if err != nil {
+		return nil, err
+	}
+
+	zset := r.zsets[key]
+
+	var members []string
+
+	for member, score := range zset {
+		if score >= lo && score <= hi {
+			members = append(members, member)
+		}
+	}
+
+	sort.Slice(members, func(i, j int) bool {
+		if zset[members[i]] != zset[members[j]] {
+			return zset[members[i]] < zset[members[j]]
+		}
+
+		return members[i] < members[j]
+	})
+
+	return members, nil
+}
+
+func (r *fakeRedis) Del(keys ...string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, key := range keys {
+		delete(r.hashes, key)
+		delete(r.sets, key)
+		delete(r.zsets, key)
+	}
+
+	return nil
+}
+
+func (r *fakeRedis) Eval(script string, keys []string, args ...string) (int64, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.evalsBeforeFailure > 0 {
+		if r.evalsBeforeFailure--; r.evalsBeforeFailure == 0 {
+			return 0, errors.New("connection lost")
+		}
+	}
+
+	switch script {
+	case acquireTriggerScript:
+		if r.sets[keys[2]][args[2]] {
+			return 0, nil
+		}
+
+		if _, exists := r.zsets[keys[0]][args[0]]; !exists {
+			return 0, nil
+		}
+
+		delete(r.zsets[keys[0]], args[0])
+
+		r.hset(keys[1], args[0], args[1])
+
+		return 1, nil
+
+	case blockJobScript:
+		if r.sets[keys[0]][args[0]] {
+			return 0, nil
+		}
+
+		if r.sets[keys[0]] == nil {
+			r.sets[keys[0]] = make(map[string]bool)
+		}
+
+		r.sets[keys[0]][args[0]] = true
+
+		r.moveJobTriggers(keys, args[1], args[2], keys[3], keys[4])
+		r.moveJobTriggers(keys, args[3], args[4], "", "")
+
+		return 1, nil
+
+	case unblockJobScript:
+		delete(r.sets[keys[0]], args[0])
+
+		r.moveJobTriggers(keys, args[2], args[1], keys[4], keys[3])
+		r.moveJobTriggers(keys, args[4], args[3], "", "")
+
+		return 1, nil
+	}
+
+	return 0, errors.New("unknown script")
+}
+
+// moveJobTriggers changes the state of the job triggers in the state from, and moves them to the sorted set to.
+func (r *fakeRedis) moveJobTriggers(keys []string, from, to, fromSet, toSet string) {
+	for trigger := range r.sets[keys[1]] {
+		if r.hashes[keys[2]][trigger] != from {
+			continue
+		}
+
+		if fromSet != "" {
+			if score, exists := r.zsets[fromSet][trigger]; exists {
+				delete(r.zsets[fromSet], trigger)
+
+				if r.zsets[toSet] == nil {
+					r.zsets[toSet] = make(map[string]float64)
+				}
+
+				r.zsets[toSet][trigger] = score
+			}
+		}
+
+		r.hset(keys[2], trigger, to)
+	}
+}
+
+func TestRedisJobStore(t *testing.T) {
+	Convey("Given a RedisJobStore over a fake Redis", t, func() {
+		client := newFakeRedis()
+		store := NewRedisJobStore(client, "")
+
+		So(store.SupportsPersistence(), ShouldBeTrue)
+
+		job := (&JobBuilder{}).WithGroupIdentity("job", "reports").UsingJobData("count", 1).Build()
+
+		startTime := time.Date(2016, time.March, 1, 8, 0, 0, 0, time.UTC)
+
+		newTrigger := func(name, group string, startTime time.Time, priority int) OperableTrigger {
+			trigger := (&TriggerBuilder{}).
+				WithGroupIdentity(name, group).
+				ForJobDetail(job).
+				StartAt(startTime).
+				WithPriority(priority).
+				WithSchedule(&SimpleScheduleBuilder{time.Minute, REPEAT_INDEFINITELY}).
+				Build().(OperableTrigger)
+			trigger.ComputeFirstFireTime(nil)
+
+			return trigger
+		}
+
+		trigger := newTrigger("trigger", "reports", startTime, 5)
+
+		So(store.StoreJobAndTrigger(job, trigger), ShouldBeNil)
+
+		Convey("The job and the trigger should be retrieved", func() {
+			So(store.NumberOfJobs(), ShouldEqual, 1)
+			So(store.NumberOfTriggers(), ShouldEqual, 1)
+			So(store.StoreJob(job, false), ShouldNotBeNil)
+			So(store.StoreTrigger(trigger, false), ShouldNotBeNil)
+
+			stored, err := store.RetrieveJob(job.Key())
+
+			So(err, ShouldBeNil)
+			So(stored.JobDataMap().Get("count"), ShouldEqual, 1)
+
+			retrieved, state, fireTime, err := store.RetrieveTriggerWithState(trigger.Key())
+
+			So(err, ShouldBeNil)
+			So(state, ShouldEqual, STATE_WAITING)
+			So(fireTime, ShouldEqual, startTime)
+			So(retrieved.Priority(), ShouldEqual, 5)
+			So(store.TriggersForJob(job.Key()), ShouldHaveLength, 1)
+		})
+
+		Convey("The triggers should be acquired by their fire time and priority", func() {
+			So(store.StoreTrigger(newTrigger("urgent", "reports", startTime, 9), false), ShouldBeNil)
+			So(store.StoreTrigger(newTrigger("early", "reports", startTime.Add(-time.Second), 1), false), ShouldBeNil)
+			So(store.StoreTrigger(newTrigger("late", "reports", startTime.Add(time.Hour), 9), false), ShouldBeNil)
+
+			acquired, err := store.AcquireNextTriggers(startTime, 2, time.Second)
+
+			So(err, ShouldBeNil)
+			So(acquired, ShouldHaveLength, 2)
+			So(acquired[0].Key(), ShouldResemble, NewGroupTriggerKey("early", "reports"))
+			So(acquired[1].Key(), ShouldResemble, NewGroupTriggerKey("urgent", "reports"))
+
+			acquired, _ = store.AcquireNextTriggers(startTime, 10, time.Second)
+
+			So(acquired, ShouldHaveLength, 1)
+			So(acquired[0].Key(), ShouldResemble, trigger.Key())
+
+			Convey("An acquired trigger should not be acquired by another store", func() {
+				acquired, err := NewRedisJobStore(client, "").AcquireNextTriggers(startTime, 10, time.Second)
+
+				So(err, ShouldBeNil)
+				So(acquired, ShouldBeEmpty)
+
+				store.ReleaseAcquiredTrigger(trigger)
+
+				acquired, _ = NewRedisJobStore(client, "").AcquireNextTriggers(startTime, 10, time.Second)
+
+				So(acquired, ShouldHaveLength, 1)
+			})
+
+			Convey("The fired trigger should be waiting for its next fire time", func() {
+				bundles, err := store.TriggersFired(acquired)
+
+				So(err, ShouldBeNil)
+				So(bundles, ShouldHaveLength, 1)
+				So(bundles[0].ScheduledFireTime, ShouldEqual, startTime)
+				So(bundles[0].NextFireTime, ShouldEqual, startTime.Add(time.Minute))
+
+				state, _ := store.GetTriggerState(trigger.Key())
+
+				So(state, ShouldEqual, STATE_WAITING)
+
+				acquired, _ := store.AcquireNextTriggers(startTime.Add(time.Minute), 10, 0)
+
+				So(acquired, ShouldHaveLength, 1)
+
+				store.TriggeredJobComplete(bundles[0].Trigger, job, DELETE_TRIGGER)
+
+				So(store.CheckTriggerExists(trigger.Key()), ShouldBeFalse)
+			})
+		})
+
+		Convey("The acquired triggers should be released when the acquisition failed", func() {
+			So(store.StoreTrigger(newTrigger("urgent", "reports", startTime, 9), false), ShouldBeNil)
+
+			client.evalsBeforeFailure = 2
+
+			acquired, err := store.AcquireNextTriggers(startTime, 2, time.Second)
+
+			So(err, ShouldNotBeNil)
+			So(acquired, ShouldBeNil)
+
+			for _, key := range []TriggerKey{trigger.Key(), NewGroupTriggerKey("urgent", "reports")} {
+				state, _ := store.GetTriggerState(key)
+
+				So(state, ShouldEqual, STATE_WAITING)
+			}
+
+			acquired, _ = store.AcquireNextTriggers(startTime, 2, time.Second)
+
+			So(acquired, ShouldHaveLength, 2)
+		})
+
+		Convey("The paused triggers should be moved out of the waiting set", func() {
+			groups, err := store.PauseTriggers(GroupEquals("reports"))
+
+			So(err, ShouldBeNil)
+			So(groups, ShouldResemble, []string{"reports"})
+
+			state, _ := store.GetTriggerState(trigger.Key())
+
+			So(state, ShouldEqual, STATE_PAUSED)
+			So(client.zsets[store.pausedKey()], ShouldContainKey, trigger.Key().String())
+
+			later := newTrigger("later", "reports", startTime, 5)
+
+			So(store.StoreTrigger(later, false), ShouldBeNil)
+
+			state, _ = store.GetTriggerState(later.Key())
+
+			So(state, ShouldEqual, STATE_PAUSED)
+
+			acquired, err := store.AcquireNextTriggers(startTime, 10, 0)
+
+			So(err, ShouldBeNil)
+			So(acquired, ShouldBeEmpty)
+
+			groups, err = store.ResumeTriggers(GroupEquals("reports"))
+
+			So(err, ShouldBeNil)
+			So(groups, ShouldResemble, []string{"reports"})
+
+			acquired, _ = store.AcquireNextTriggers(startTime, 10, 0)
+
+			So(acquired, ShouldHaveLength, 2)
+		})
+
+		Convey("The triggers of a paused job group should be kept paused when their group is resumed", func() {
+			_, err := store.PauseJobs(GroupEquals("reports"))
+
+			So(err, ShouldBeNil)
+
+			_, err = store.PauseTriggers(AnyGroup())
+
+			So(err, ShouldBeNil)
+
+			_, err = store.ResumeTriggers(AnyGroup())
+
+			So(err, ShouldBeNil)
+
+			state, _ := store.GetTriggerState(trigger.Key())
+
+			So(state, ShouldEqual, STATE_PAUSED)
+
+			So(store.ResumeAll(), ShouldBeNil)
+
+			state, _ = store.GetTriggerState(trigger.Key())
+
+			So(state, ShouldEqual, STATE_WAITING)
+			So(client.zsets[store.waitingKey()], ShouldContainKey, trigger.Key().String())
+		})
+
+		Convey("The calendars should be shared by the stores over the same Redis", func() {
+			monthly := NewMonthlyCalendar(nil)
+			monthly.SetLocation(time.UTC)
+			monthly.SetDayExcluded(1, true)
+
+			other := NewRedisJobStore(client, "")
+
+			So(other.StoreCalendar("monthly", monthly, false), ShouldBeNil)
+			So(store.StoreCalendar("monthly", monthly, false), ShouldNotBeNil)
+			So(store.StoreCalendar("weekends", &weekendCalendar{}, false), ShouldNotBeNil)
+
+			names, err := store.GetCalendarNames()
+
+			So(err, ShouldBeNil)
+			So(names, ShouldResemble, []string{"monthly"})
+
+			cal, err := store.RetrieveCalendar("missing")
+
+			So(err, ShouldBeNil)
+			So(cal, ShouldBeNil)
+
+			trigger.SetCalendarName("monthly")
+
+			So(store.StoreTrigger(trigger, true), ShouldBeNil)
+
+			acquired, err := other.AcquireNextTriggers(startTime, 1, 0)
+
+			So(err, ShouldBeNil)
+			So(acquired, ShouldHaveLength, 1)
+
+			bundles, err := other.TriggersFired(acquired)
+
+			So(err, ShouldBeNil)
+			So(bundles, ShouldHaveLength, 1)
+			So(bundles[0].Calendar, ShouldNotBeNil)
+			So(bundles[0].NextFireTime, ShouldEqual, time.Date(2016, time.March, 2, 0, 0, 0, 0, time.UTC))
+
+			_, err = store.RemoveCalendar("monthly")
+
+			So(err, ShouldNotBeNil)
+
+			_, err = store.RemoveJob(job.Key())
+
+			So(err, ShouldBeNil)
+
+			found, err := store.RemoveCalendar("monthly")
+
+			So(err, ShouldBeNil)
+			So(found, ShouldBeTrue)
+
+			cal, _ = other.RetrieveCalendar("monthly")
+
+			So(cal, ShouldBeNil)
+		})
+
+		Convey("Removing the last trigger should remove the non-durable job", func() {
+			found, err := store.RemoveTrigger(trigger.Key())
+
+			So(err, ShouldBeNil)
+			So(found, ShouldBeTrue)
+			So(store.CheckJobExists(job.Key()), ShouldBeFalse)
+			So(client.zsets[store.waitingKey()], ShouldBeEmpty)
+		})
+
+		Convey("Storing a trigger without job key should fail", func() {
+			noJob := &simpleTrigger{}
+			noJob.SetKey(NewTriggerKey("nojob"))
+
+			So(errors.Is(store.StoreTrigger(noJob, false), ErrMissingJobKey), ShouldBeTrue)
+			So(store.CheckTriggerExists(noJob.Key()), ShouldBeFalse)
+		})
+
+		Convey("Clearing the data should remove everything", func() {
+			So(store.StoreCalendar("monthly", NewMonthlyCalendar(nil), false), ShouldBeNil)
+			So(store.ClearAllSchedulingData(), ShouldBeNil)
+			So(store.NumberOfJobs(), ShouldEqual, 0)
+			So(store.NumberOfTriggers(), ShouldEqual, 0)
+			So(client.sets, ShouldBeEmpty)
+
+			names, _ := store.GetCalendarNames()
+
+			So(names, ShouldBeEmpty)
+		})
+	})
+}
+
+func TestRedisJobStoreBlockedJobs(t *testing.T) {
+	Convey("Given a RedisJobStore with two triggers of a job disallowing concurrent execution", t, func() {
+		client := newFakeRedis()
+		store := NewRedisJobStore(client, "")
+
+		job := (&JobBuilder{}).WithIdentity("job").DisallowConcurrentExecution(true).Build()
+
+		startTime := time.Date(2016, time.March, 1, 8, 0, 0, 0, time.UTC)
+
+		newTrigger := func(name string) OperableTrigger {
+			trigger := (&TriggerBuilder{}).
+				WithIdentity(name).
+				ForJobDetail(job).
+				StartAt(startTime).
+				WithSchedule(&SimpleScheduleBuilder{time.Minute, 1}).
+				Build().(OperableTrigger)
+			trigger.ComputeFirstFireTime(nil)
+
+			return trigger
+		}
+
+		first, second := newTrigger("first"), newTrigger("second")
+
+		So(store.StoreJobAndTrigger(job, first), ShouldBeNil)
+		So(store.StoreTrigger(second, false), ShouldBeNil)
+
+		Convey("Only one trigger of the job should be acquired in a batch", func() {
+			acquired, err := store.AcquireNextTriggers(startTime, 10, time.Second)
+
+			So(err, ShouldBeNil)
+			So(acquired, ShouldHaveLength, 1)
+
+			other := first
+
+			if acquired[0].Key().String() == first.Key().String() {
+				other = second
+			}
+
+			state, _ := store.GetTriggerState(other.Key())
+
+			So(state, ShouldEqual, STATE_WAITING)
+
+			Convey("When the trigger fired", func() {
+				bundles, err := store.TriggersFired(acquired)
+
+				So(err, ShouldBeNil)
+				So(bundles, ShouldHaveLength, 1)
+
+				Convey("The triggers of the job should be blocked until the job completed", func() {
+					for _, key := range []TriggerKey{first.Key(), second.Key()} {
+						state, _ := store.GetTriggerState(key)
+
+						So(state, ShouldEqual, STATE_BLOCKED)
+					}
+
+					So(client.zsets[store.waitingKey()], ShouldBeEmpty)
+
+					acquired, err := NewRedisJobStore(client, "").AcquireNextTriggers(startTime.Add(time.Hour), 10, time.Second)
+
+					So(err, ShouldBeNil)
+					So(acquired, ShouldBeEmpty)
+
+					store.TriggeredJobComplete(bundles[0].Trigger, bundles[0].JobDetail, NOOP)
+
+					for _, key := range []TriggerKey{first.Key(), second.Key()} {
+						state, _ := store.GetTriggerState(key)
+
+						So(state, ShouldEqual, STATE_WAITING)
+					}
+
+					So(client.zsets[store.blockedKey()], ShouldBeEmpty)
+
+					acquired, err = store.AcquireNextTriggers(startTime, 10, time.Second)
+
+					So(err, ShouldBeNil)
+					So(acquired, ShouldHaveLength, 1)
+					So(acquired[0].Key(), ShouldResemble, other.Key())
+				})
+
+				Convey("The other trigger paused while blocked should be paused after the job completed", func() {
+					So(store.PauseTrigger(other.Key()), ShouldBeNil)
+
+					state, _ := store.GetTriggerState(other.Key())
+
+					So(state, ShouldEqual, STATE_PAUSED_BLOCKED)
+
+					store.TriggeredJobComplete(bundles[0].Trigger, bundles[0].JobDetail, NOOP)
+
+					state, _ = store.GetTriggerState(other.Key())
+
+					So(state, ShouldEqual, STATE_PAUSED)
+				})
+			})
+		})
+
+		Convey("The trigger acquired by another store should be blocked when the job fired", func() {
+			acquired, _ := store.AcquireNextTriggers(startTime, 10, time.Second)
+			other, _ := NewRedisJobStore(client, "").AcquireNextTriggers(startTime, 10, time.Second)
+
+			So(acquired, ShouldHaveLength, 1)
+			So(other, ShouldHaveLength, 1)
+
+			bundles, err := store.TriggersFired(append(acquired, other...))
+
+			So(err, ShouldBeNil)
+			So(bundles, ShouldHaveLength, 1)
+
+			state, _ := store.GetTriggerState(other[0].Key())
+
+			So(state, ShouldEqual, STATE_BLOCKED)
+		})
+	})
+}
@@ -0,0 +1,94 @@
+package quartz
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+func schedulerExistsError(name string) error {
+	return fmt.Errorf("Scheduler with name '%s' already exists.", name)
+}
+
+// SchedulerRepository holds the schedulers by their names, so they can be shared in the process.
+type SchedulerRepository struct {
+	lock       sync.RWMutex
+	schedulers map[string]Scheduler
+}
+
+var schedulerRepository = NewSchedulerRepository()
+
+// DefaultSchedulerRepository returns the repository where StdSchedulerFactory binds the schedulers.
+func DefaultSchedulerRepository() *SchedulerRepository { return schedulerRepository }
+
+func NewSchedulerRepository() *SchedulerRepository {
+	return &SchedulerRepository{schedulers: make(map[string]Scheduler)}
+}
+
+// Bind adds the scheduler with its name, it fails if the name has been bound.
+func (r *SchedulerRepository) Bind(s Scheduler) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	name := s.Name()
+
+	if _, exists := r.schedulers[name]; exists {
+		return schedulerExistsError(name)
+	}
+
+	r.schedulers[name] = s
+
+	return nil
+}
+
+// Remove removes the scheduler with the name, and returns whether it was found.
+func (r *SchedulerRepository) Remove(name string) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	_, exists := r.schedulers[name]
+
+	delete(r.schedulers, name)
+
+	return exists
+}
+
+// unbind removes the scheduler only if it is the one bound with its name.
+func (r *SchedulerRepository) unbind(s Scheduler) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.schedulers[s.Name()] == s {
+		delete(r.schedulers, s.Name())
+	}
+}
+
+// Lookup returns the scheduler with the name, or nil if not found.
+func (r *SchedulerRepository) Lookup(name string) Scheduler {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return r.schedulers[name]
+}
+
+// LookupAll returns all the schedulers ordered by their names.
+func (r *SchedulerRepository) LookupAll() []Scheduler {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	names := make([]string, 0, len(r.schedulers))
+
+	for name := range r.schedulers {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	schedulers := make([]Scheduler, len(names))
+
+	for i, name := range names {
+		schedulers[i] = r.schedulers[name]
+	}
+
+	return schedulers
+}
@@ -0,0 +1,93 @@
+package quartz
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSchedulerRepository(t *testing.T) {
+	Convey("Given a SchedulerRepository", t, func() {
+		repo := NewSchedulerRepository()
+
+		s := NewStdScheduler("scheduler", NewRAMJobStore(), NewSimpleThreadPool(1))
+
+		So(repo.Lookup("scheduler"), ShouldBeNil)
+		So(repo.LookupAll(), ShouldBeEmpty)
+
+		Convey("When bind a scheduler", func() {
+			So(repo.Bind(s), ShouldBeNil)
+
+			So(repo.Lookup("scheduler"), ShouldEqual, s)
+			So(repo.LookupAll(), ShouldResemble, []Scheduler{s})
+
+			Convey("The scheduler with the same name should not be bound", func() {
+				err := repo.Bind(NewStdScheduler("scheduler", NewRAMJobStore(), NewSimpleThreadPool(1)))
+
+				So(err, ShouldNotBeNil)
+				So(repo.Lookup("scheduler"), ShouldEqual, s)
+			})
+
+			Convey("The scheduler could be removed", func() {
+				So(repo.Remove("scheduler"), ShouldBeTrue)
+				So(repo.Remove("scheduler"), ShouldBeFalse)
+				So(repo.Lookup("scheduler"), ShouldBeNil)
+			})
+
+			Convey("The other scheduler with the same name should not be unbound", func() {
+				repo.unbind(NewStdScheduler("scheduler", NewRAMJobStore(), NewSimpleThreadPool(1)))
+
+				So(repo.Lookup("scheduler"), ShouldEqual, s)
+
+				repo.unbind(s)
+
+				So(repo.Lookup("scheduler"), ShouldBeNil)
+			})
+		})
+
+		Convey("When bind and lookup schedulers concurrently", func() {
+			var wg sync.WaitGroup
+
+			errs := make(chan error, 100)
+
+			for i := 0; i < 100; i++ {
+				wg.Add(1)
+
+				go func(i int) {
+					defer wg.Done()
+
+					name := fmt.Sprintf("scheduler-%02d", i%50)
+
+					if err := repo.Bind(NewStdScheduler(name, NewRAMJobStore(), NewSimpleThreadPool(1))); err != nil {
+						errs <- err
+					}
+
+					if repo.Lookup(name) == nil {
+						errs <- fmt.Errorf("scheduler %s not found", name)
+					}
+
+					repo.LookupAll()
+				}(i)
+			}
+
+			wg.Wait()
+			close(errs)
+
+			var failed []error
+
+			for err := range errs {
+				failed = append(failed, err)
+			}
+
+			So(failed, ShouldHaveLength, 50)
+
+			schedulers := repo.LookupAll()
+
+			So(schedulers, ShouldHaveLength, 50)
+			So(schedulers[0].Name(), ShouldEqual, "scheduler-00")
+			So(schedulers[49].Name(), ShouldEqual, "scheduler-49")
+		})
+	})
+}
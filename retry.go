@@ -0,0 +1,61 @@
+package quartz
+
+import (
+	"math"
+	"time"
+)
+
+// RETRYING_JOBS_GROUP is the group of the transient triggers which retry the failed jobs.
+const RETRYING_JOBS_GROUP = "RETRYING_JOBS"
+
+// RetryAttemptKey is the key of the merged JobDataMap holding the attempt number of a retried job, from 2 for the first retry.
+const RetryAttemptKey = "retryAttempt"
+
+// RetryPolicy retries a job which failed with an error, by firing it again after an exponential backoff delay.
+type RetryPolicy struct {
+	// The maximum number of attempts including the first execution, the job isn't retried if it is less than 2.
+	MaxAttempts int
+
+	// The delay before the first retry.
+	InitialDelay time.Duration
+
+	// The factor multiplying the delay after each retry, the delay is constant if it is less than 1.
+	Multiplier float64
+
+	// The maximum delay before a retry, unlimited if zero.
+	MaxDelay time.Duration
+}
+
+// Delay returns the delay before retrying the job which failed at the attempt.
+func (p *RetryPolicy) Delay(attempt int) time.Duration {
+	delay := float64(p.InitialDelay)
+
+	if p.Multiplier > 1 && attempt > 1 {
+		delay *= math.Pow(p.Multiplier, float64(attempt-1))
+	}
+
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		return p.MaxDelay
+	}
+
+	return time.Duration(delay)
+}
+
+func newRetryTrigger(trigger Trigger, jobKey JobKey, attempt int, fireTime time.Time) OperableTrigger {
+	retry := &simpleTrigger{startTime: fireTime, nextFireTime: fireTime}
+	retry.SetKey(NewUniqueTriggerKey(RETRYING_JOBS_GROUP))
+	retry.SetJobKey(jobKey)
+	retry.SetPriority(trigger.Priority())
+
+	dataMap := NewJobDataMap()
+
+	if trigger.JobDataMap() != nil {
+		dataMap.PutAll(trigger.JobDataMap())
+	}
+
+	dataMap.Put(RetryAttemptKey, attempt)
+
+	retry.SetJobDataMap(dataMap)
+
+	return retry
+}
@@ -0,0 +1,34 @@
+package quartz
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	Convey("Given a RetryPolicy doubling its delay up to a maximum", t, func() {
+		policy := &RetryPolicy{MaxAttempts: 5, InitialDelay: time.Second, Multiplier: 2, MaxDelay: 5 * time.Second}
+
+		Convey("The delay should grow exponentially until the maximum", func() {
+			So(policy.Delay(1), ShouldEqual, time.Second)
+			So(policy.Delay(2), ShouldEqual, 2*time.Second)
+			So(policy.Delay(3), ShouldEqual, 4*time.Second)
+			So(policy.Delay(4), ShouldEqual, 5*time.Second)
+		})
+
+		Convey("The delay should be constant without multiplier", func() {
+			policy.Multiplier = 0
+
+			So(policy.Delay(4), ShouldEqual, time.Second)
+		})
+
+		Convey("The policy should be restored with the job record", func() {
+			job := (&JobBuilder{}).WithIdentity("job").WithRetryPolicy(*policy).Build()
+			record := newJobRecord(job)
+
+			So(record.jobDetail().RetryPolicy(), ShouldResemble, policy)
+		})
+	})
+}
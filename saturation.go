@@ -0,0 +1,44 @@
+package quartz
+
+import (
+	"errors"
+)
+
+type saturationMode int
+
+const (
+	saturationBlock saturationMode = iota
+	saturationDropLowestPriority
+	saturationQueue
+)
+
+// SaturationPolicy decides what the scheduling loop does with the due triggers when the workers of the ThreadPool are busy.
+type SaturationPolicy struct {
+	mode      saturationMode
+	queueSize int
+}
+
+// BlockOnSaturation blocks the acquisition of the triggers until a worker is available, it is the default policy.
+func BlockOnSaturation() SaturationPolicy { return SaturationPolicy{mode: saturationBlock} }
+
+// DropLowestPriorityOnSaturation acquires the due triggers without waiting for the workers,
+// and skips the fires of the lowest-priority triggers which have no available worker, as if their jobs were vetoed.
+//
+// The due triggers are compared within a batch, so the max batch size of the scheduler should be more than 1.
+func DropLowestPriorityOnSaturation() SaturationPolicy {
+	return SaturationPolicy{mode: saturationDropLowestPriority}
+}
+
+// QueueOnSaturation queues up to size fired triggers waiting for a worker, the queued triggers are executed
+// in their fire order once a worker is available, and the acquisition is blocked while the queue is full.
+func QueueOnSaturation(size int) SaturationPolicy {
+	return SaturationPolicy{mode: saturationQueue, queueSize: size}
+}
+
+func (p SaturationPolicy) validate() error {
+	if p.mode == saturationQueue && p.queueSize < 1 {
+		return errors.New("The queue size of the saturation policy must be > 0.")
+	}
+
+	return nil
+}
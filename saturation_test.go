@@ -0,0 +1,142 @@
+package quartz
+
+import (
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestStdSchedulerSaturationPolicy(t *testing.T) {
+	Convey("Given a started StdScheduler with a worker busy executing a slow job", t, func() {
+		scheduler := NewStdScheduler("scheduler", NewRAMJobStore(), NewSimpleThreadPool(2))
+
+		logger := &capturingLogger{}
+
+		scheduler.SetLogger(logger)
+		scheduler.maxBatchSize = 3
+
+		release := make(chan struct{})
+		started := make(chan struct{})
+
+		defer scheduler.Shutdown()
+		defer close(release)
+
+		slow := NewJobDetailFromFunc("slow", func(context JobExecutionContext) error {
+			close(started)
+
+			<-release
+
+			return nil
+		})
+
+		fired := make(chan string, 3)
+
+		newJob := func(name string) JobDetail {
+			return NewJobDetailFromFunc(name, func(context JobExecutionContext) error {
+				fired <- name
+
+				return nil
+			})
+		}
+
+		scheduleJobs := func(fireTime time.Time) {
+			for priority, name := range map[int]string{1: "low", 5: "medium", 9: "high"} {
+				trigger := (&TriggerBuilder{}).WithIdentity(name).WithPriority(priority).StartAt(fireTime).Build()
+
+				_, err := scheduler.ScheduleJob(newJob(name), trigger)
+
+				So(err, ShouldBeNil)
+			}
+		}
+
+		Convey("When the lowest priorities are dropped", func() {
+			So(scheduler.SetSaturationPolicy(DropLowestPriorityOnSaturation()), ShouldBeNil)
+			So(scheduler.Start(), ShouldBeNil)
+
+			_, err := scheduler.ScheduleJob(slow, (&TriggerBuilder{}).StartNow().Build())
+
+			So(err, ShouldBeNil)
+
+			<-started
+
+			scheduleJobs(time.Now().Add(100 * time.Millisecond))
+
+			Convey("Only the highest priority should be executed", func() {
+				So(<-fired, ShouldEqual, "high")
+
+				So(waitFor(time.Second, func() bool {
+					return !scheduler.CheckTriggerExists(NewTriggerKey("low")) && !scheduler.CheckTriggerExists(NewTriggerKey("medium"))
+				}), ShouldBeTrue)
+
+				time.Sleep(20 * time.Millisecond)
+
+				So(fired, ShouldBeEmpty)
+
+				var skipped []string
+
+				for _, msg := range logger.Messages() {
+					if strings.Contains(msg, "Trigger skipped") {
+						skipped = append(skipped, msg)
+					}
+				}
+
+				So(skipped, ShouldHaveLength, 2)
+				So(strings.Join(skipped, "\n"), ShouldContainSubstring, "DEFAULT.low")
+				So(strings.Join(skipped, "\n"), ShouldContainSubstring, "DEFAULT.medium")
+			})
+		})
+
+		Convey("When the fired triggers are queued", func() {
+			So(scheduler.SetSaturationPolicy(QueueOnSaturation(1)), ShouldBeNil)
+			So(scheduler.Start(), ShouldBeNil)
+
+			var executing int32
+
+			blocking := NewJobDetailFromFunc("blocking", func(context JobExecutionContext) error {
+				atomic.AddInt32(&executing, 1)
+
+				<-release
+
+				return nil
+			})
+
+			_, err := scheduler.ScheduleJob(slow, (&TriggerBuilder{}).StartNow().Build())
+
+			So(err, ShouldBeNil)
+
+			<-started
+
+			_, err = scheduler.ScheduleJob(blocking, (&TriggerBuilder{}).StartNow().Build())
+
+			So(err, ShouldBeNil)
+			So(waitFor(time.Second, func() bool { return atomic.LoadInt32(&executing) == 1 }), ShouldBeTrue)
+
+			scheduleJobs(time.Now())
+
+			Convey("Only the queue size of triggers should be fired while the workers are busy", func() {
+				So(waitFor(time.Second, func() bool {
+					n := 0
+
+					for _, name := range []string{"low", "medium", "high"} {
+						if trigger, _ := scheduler.store.RetrieveTrigger(NewTriggerKey(name)); trigger.NextFireTime().IsZero() {
+							n++
+						}
+					}
+
+					return n == 1
+				}), ShouldBeTrue)
+
+				time.Sleep(20 * time.Millisecond)
+
+				So(fired, ShouldBeEmpty)
+			})
+		})
+
+		Convey("When the queue size is invalid", func() {
+			So(scheduler.SetSaturationPolicy(QueueOnSaturation(0)), ShouldNotBeNil)
+		})
+	})
+}
@@ -1,6 +1,8 @@
 package quartz
 
 import (
+	"errors"
+	"sync"
 	"time"
 )
 
@@ -11,6 +13,7 @@ type Scheduler interface {
 
 	Start() error
 
+	// Start the Scheduler after the delay, Started returns false until it's actually started.
 	StartDelayed(time.Duration) error
 
 	Started() bool
@@ -25,10 +28,16 @@ type Scheduler interface {
 
 	MetaData() SchedulerMetaData
 
+	// Get the contexts of the jobs currently executing in this Scheduler instance.
 	CurrentlyExecutingJob() ([]JobExecutionContext, error)
 
 	SetJobFactory(factory JobFactory)
 
+	ListenerManager() ListenerManager
+
+	// Get the channel of the SchedulerEvents, which drops the events when the consumer is slow.
+	Events() <-chan SchedulerEvent
+
 	ScheduleJob(jobDetail JobDetail, trigger Trigger) (time.Time, error)
 
 	Schedule(trigger Trigger) (time.Time, error)
@@ -47,8 +56,18 @@ type Scheduler interface {
 
 	DeleteJobs(keys []JobKey) (bool, error)
 
+	// Trigger the stored job now with a one-shot trigger, which is removed once the job completes.
 	TriggerJob(key JobKey) error
 
+	// Trigger the job now like TriggerJob, the data overlays the JobDataMap of the job.
+	TriggerJobWithData(key JobKey, data JobDataMap) error
+
+	// Trigger the jobs now, the one-shot triggers are stored at once and the per-key errors are combined.
+	TriggerJobs(keys []JobKey) error
+
+	// Trigger the job now unless the same idempotency key was used within the ttl.
+	TriggerJobIdempotent(key JobKey, idempotencyKey string, ttl time.Duration) error
+
 	PauseJob(key JobKey) error
 
 	PauseTrigger(key TriggerKey) error
@@ -57,30 +76,260 @@ type Scheduler interface {
 
 	ResumeTrigger(key TriggerKey) error
 
+	// Pause the triggers in the groups matched by the matcher.
+	PauseTriggers(matcher GroupMatcher) error
+
+	// Resume the triggers in the groups matched by the matcher.
+	ResumeTriggers(matcher GroupMatcher) error
+
+	// Pause the jobs in the groups matched by the matcher.
+	PauseJobs(matcher GroupMatcher) error
+
+	// Resume the jobs in the groups matched by the matcher.
+	ResumeJobs(matcher GroupMatcher) error
+
 	PauseAll() error
 
 	ResumeAll() error
 
+	// Get copies of the triggers of the job, the changes of the copies don't affect the stored triggers.
 	GetTriggersOfJob(key JobKey) []Trigger
 
+	// Get a copy of the job, or nil if it doesn't exist, use AddJob to store the changes of the copy.
 	GetJobDetail(key JobKey) JobDetail
 
+	// Get a copy of the trigger, or nil if it doesn't exist, use RescheduleJob to store the changes of the copy.
 	GetTrigger(key TriggerKey) Trigger
 
+	// Get the trigger with its state and next fire time in one call.
+	GetTriggerWithState(key TriggerKey) (Trigger, TriggerState, time.Time, error)
+
+	// Get the current state of the trigger.
+	GetTriggerState(key TriggerKey) (TriggerState, error)
+
+	// Get the next n fire times of the trigger without firing it, fewer if the trigger completes before.
+	GetNextFireTimes(key TriggerKey, n int) ([]time.Time, error)
+
+	// Get the execution statistics of the job, counted since the scheduler was created.
+	GetJobStats(key JobKey) (JobStats, error)
+
+	// Reset the trigger from STATE_ERROR, so it is scheduled again unless its group or job is paused.
+	ResetTriggerFromErrorState(key TriggerKey) error
+
+	// Get the keys of the jobs in the groups matched by the matcher, sorted by their groups and names.
+	GetJobKeys(matcher GroupMatcher) ([]JobKey, error)
+
+	// Get the keys of the triggers in the groups matched by the matcher, sorted by their groups and names.
+	GetTriggerKeys(matcher GroupMatcher) ([]TriggerKey, error)
+
 	CheckJobExists(key JobKey) bool
 
 	CheckTriggerExists(key TriggerKey) bool
 
+	// Add the calendar with the name, which can be used by the triggers with TriggerBuilder.ModifiedByCalendar.
+	AddCalendar(name string, calendar Calendar, replace bool) error
+
+	// Delete the calendar with the name, it's an error to delete a calendar used by a trigger.
+	DeleteCalendar(name string) (bool, error)
+
+	// Get the calendar with the name, or nil if it does not exist.
+	GetCalendar(name string) (Calendar, error)
+
+	// Get the names of the calendars, sorted by their names.
+	GetCalendarNames() ([]string, error)
+
+	// Clear (delete!) all scheduling data - all jobs, triggers and calendars.
 	Clear() error
 }
 
+// SchedulerContext holds the data shared by the jobs of a Scheduler, it is safe for concurrent access.
 type SchedulerContext interface {
 	DirtyFlagMap
 }
 
+type schedulerContext struct {
+	lock sync.RWMutex
+	m    DirtyFlagMap
+}
+
+func NewSchedulerContext() SchedulerContext {
+	return &schedulerContext{m: NewDirtyFlagMap()}
+}
+
+func (c *schedulerContext) Dirty() bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.m.Dirty()
+}
+
+func (c *schedulerContext) ClearDirtyFlag() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.m.ClearDirtyFlag()
+}
+
+func (c *schedulerContext) Empty() bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.m.Empty()
+}
+
+func (c *schedulerContext) Len() int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.m.Len()
+}
+
+func (c *schedulerContext) Keys() []string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.m.Keys()
+}
+
+func (c *schedulerContext) Values() []interface{} {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.m.Values()
+}
+
+func (c *schedulerContext) Entries() []MapEntry {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.m.Entries()
+}
+
+// ForEach holds the read lock of the context while calling fn, which must not change the context.
+func (c *schedulerContext) ForEach(fn func(key string, value interface{}) bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	c.m.ForEach(fn)
+}
+
+func (c *schedulerContext) Contains(key string) bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.m.Contains(key)
+}
+
+func (c *schedulerContext) Get(key string) interface{} {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.m.Get(key)
+}
+
+func (c *schedulerContext) GetOrDefault(key string, def interface{}) interface{} {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.m.GetOrDefault(key, def)
+}
+
+func (c *schedulerContext) Put(key string, value interface{}) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.m.Put(key, value)
+}
+
+func (c *schedulerContext) PutIfAbsent(key string, value interface{}) interface{} {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.m.PutIfAbsent(key, value)
+}
+
+func (c *schedulerContext) PutAll(m Map) {
+	// the entries are taken before locking, in case m is the context itself.
+	entries := m.Entries()
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, entry := range entries {
+		c.m.Put(entry.Key(), entry.Value())
+	}
+}
+
+func (c *schedulerContext) Remove(key string) interface{} {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.m.Remove(key)
+}
+
+func (c *schedulerContext) Clone() interface{} {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return &schedulerContext{m: c.m.Clone().(DirtyFlagMap)}
+}
+
+// Version is the version of the Scheduler implementation.
+const Version = "0.1.0"
+
+// SchedulerMetaData describes the settings and state of a Scheduler at the time it was requested.
 type SchedulerMetaData interface {
+	SchedulerName() string
+
+	Version() string
+
+	Started() bool
+
+	InStandbyMode() bool
+
+	Shutdown() bool
+
+	// The type name of the JobStore.
+	JobStoreClass() string
+
+	ThreadPoolSize() int
+
+	// The number of jobs executed since the Scheduler started.
+	NumberOfJobsExecuted() int
+
+	// The time when the Scheduler started, or zero if not started.
+	RunningSince() time.Time
+}
+
+type schedulerMetaData struct {
+	schedulerName        string
+	started              bool
+	inStandbyMode        bool
+	shutdown             bool
+	jobStoreClass        string
+	threadPoolSize       int
+	numberOfJobsExecuted int
+	runningSince         time.Time
 }
 
+func (m *schedulerMetaData) SchedulerName() string { return m.schedulerName }
+
+func (m *schedulerMetaData) Version() string { return Version }
+
+func (m *schedulerMetaData) Started() bool { return m.started }
+
+func (m *schedulerMetaData) InStandbyMode() bool { return m.inStandbyMode }
+
+func (m *schedulerMetaData) Shutdown() bool { return m.shutdown }
+
+func (m *schedulerMetaData) JobStoreClass() string { return m.jobStoreClass }
+
+func (m *schedulerMetaData) ThreadPoolSize() int { return m.threadPoolSize }
+
+func (m *schedulerMetaData) NumberOfJobsExecuted() int { return m.numberOfJobsExecuted }
+
+func (m *schedulerMetaData) RunningSince() time.Time { return m.runningSince }
+
 type ScheduleBuilder interface {
 	Build() MutableTrigger
 }
@@ -90,15 +339,27 @@ type SimpleScheduleBuilder struct {
 	repeatCount    int
 }
 
+// Build the trigger, panics if the schedule is invalid, use BuildE to check the error.
 func (b *SimpleScheduleBuilder) Build() MutableTrigger {
+	trigger, err := b.BuildE()
+
+	if err != nil {
+		panic(err)
+	}
+
+	return trigger
+}
+
+func (b *SimpleScheduleBuilder) BuildE() (MutableTrigger, error) {
+	if b.repeatCount < 0 && b.repeatCount != REPEAT_INDEFINITELY {
+		return nil, errors.New("Repeat count must be >= 0, use the constant REPEAT_INDEFINITELY for infinite.")
+	}
+
 	return &simpleTrigger{
 		repeatInterval: b.repeatInterval,
 		repeatCount:    b.repeatCount,
-	}
+	}, nil
 }
 
 type QuartzScheduler struct {
 }
-
-type StdScheduler struct {
-}
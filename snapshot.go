@@ -0,0 +1,197 @@
+package quartz
+
+import (
+	"fmt"
+	"sort"
+)
+
+// snapshotVersion is the version of the format written by RAMJobStore.Snapshot,
+// the version 2 added the calendars, a snapshot of the version 1 is restored without calendars.
+const snapshotVersion = 2
+
+type ramStoreSnapshot struct {
+	Version             int
+	Jobs                []jobRecord
+	Triggers            []triggerRecord
+	Calendars           []calendarRecord
+	PausedTriggerGroups []string
+	PausedJobGroups     []string
+	BlockedJobs         []string
+	Fired               []firedRecord
+}
+
+// Snapshot serializes the jobs, the triggers with their states, the calendars, the paused groups,
+// the blocked jobs and the executing jobs of the store.
+//
+// Like the FileJobStore, the JobFactory of the jobs is not serialized, only the simple and NthIncludedDay triggers
+// and the Daily, Annual, Monthly and Holiday calendars are supported.
+func (s *RAMJobStore) Snapshot() ([]byte, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	data := ramStoreSnapshot{
+		Version:             snapshotVersion,
+		PausedTriggerGroups: stringKeys(s.pausedTriggerGroups),
+		PausedJobGroups:     stringKeys(s.pausedJobGroups),
+		BlockedJobs:         stringKeys(s.blockedJobs),
+		Fired:               s.firedRecords(),
+	}
+
+	sort.Strings(data.BlockedJobs)
+
+	var err error
+
+	if data.Jobs, data.Triggers, err = s.records(); err != nil {
+		return nil, err
+	}
+
+	if data.Calendars, err = s.calendars.records(); err != nil {
+		return nil, err
+	}
+
+	return gobEncode(&data)
+}
+
+// RestoreSnapshot replaces the content of the store with the snapshot, the store is left untouched if it failed.
+//
+// The triggers acquired when the snapshot was taken are waiting again, and the jobs requesting recovery
+// which were executing are scheduled again when the scheduler is started.
+func (s *RAMJobStore) RestoreSnapshot(b []byte) error {
+	var data ramStoreSnapshot
+
+	if err := gobDecode(b, &data); err != nil {
+		return fmt.Errorf("Couldn't decode the snapshot: %w", err)
+	}
+
+	if data.Version < 1 || data.Version > snapshotVersion {
+		return fmt.Errorf("The snapshot version %d is not supported.", data.Version)
+	}
+
+	calendars, err := restoreCalendars(data.Calendars)
+
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	restored := NewRAMJobStore()
+	restored.normalizeToUTC = s.normalizeToUTC
+
+	for _, group := range data.PausedTriggerGroups {
+		restored.pausedTriggerGroups.Add(group)
+	}
+
+	for _, group := range data.PausedJobGroups {
+		restored.pausedJobGroups.Add(group)
+	}
+
+	for _, key := range data.BlockedJobs {
+		restored.blockedJobs.Add(key)
+	}
+
+	if err := restored.restoreRecords(data.Jobs, data.Triggers); err != nil {
+		return err
+	}
+
+	restored.restoreFiredRecords(data.Fired)
+
+	s.jobsByKey = restored.jobsByKey
+	s.triggersByKey = restored.triggersByKey
+	s.jobsByGroup = restored.jobsByGroup
+	s.triggersByGroup = restored.triggersByGroup
+	s.timeTriggers = restored.timeTriggers
+	s.triggersByJob = restored.triggersByJob
+	s.pausedTriggerGroups = restored.pausedTriggerGroups
+	s.pausedJobGroups = restored.pausedJobGroups
+	s.blockedJobs = restored.blockedJobs
+	s.fired = restored.fired
+
+	s.calendars.replace(calendars)
+
+	return nil
+}
+
+// firedRecords returns the records of the executing jobs ordered by their trigger keys, the caller must hold the lock.
+func (s *RAMJobStore) firedRecords() []firedRecord {
+	var fired []firedRecord
+
+	for _, r := range s.fired {
+		fired = append(fired, r)
+	}
+
+	sort.Slice(fired, func(i, j int) bool { return fired[i].TriggerKey < fired[j].TriggerKey })
+
+	return fired
+}
+
+// restoreFiredRecords restores the records of the executing jobs, the caller must hold the lock.
+func (s *RAMJobStore) restoreFiredRecords(fired []firedRecord) {
+	for _, r := range fired {
+		s.fired[r.TriggerKey] = r
+	}
+}
+
+// records returns the records of the jobs, and of the triggers with their states, ordered by their keys,
+// the caller must hold the lock.
+func (s *RAMJobStore) records() (jobs []jobRecord, triggers []triggerRecord, err error) {
+	for _, jw := range s.jobsByKey {
+		jobs = append(jobs, newJobRecord(jw.jobDetail))
+	}
+
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].Key < jobs[j].Key })
+
+	for _, tw := range s.triggersByKey {
+		r, err := newTriggerRecord(tw.trigger)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		r.State = tw.state
+
+		triggers = append(triggers, r)
+	}
+
+	sort.Slice(triggers, func(i, j int) bool { return triggers[i].Key < triggers[j].Key })
+
+	return
+}
+
+// restoreRecords stores the jobs and the triggers in their recorded states, the acquired triggers are waiting again,
+// the paused groups and the blocked jobs must be restored first, the caller must hold the lock.
+func (s *RAMJobStore) restoreRecords(jobs []jobRecord, triggers []triggerRecord) error {
+	for _, r := range jobs {
+		job := r.jobDetail()
+
+		jw := &jobWrapper{job}
+
+		if s.jobsByGroup[job.Key().Group()] == nil {
+			s.jobsByGroup[job.Key().Group()] = make(JobMap)
+		}
+
+		s.jobsByGroup[job.Key().Group()][r.Key] = jw
+		s.jobsByKey[r.Key] = jw
+	}
+
+	for _, r := range triggers {
+		if err := s.storeTrigger(r.trigger(), false); err != nil {
+			return err
+		}
+
+		tw := s.triggersByKey[r.Key]
+
+		switch r.State {
+		case STATE_PAUSED, STATE_PAUSED_BLOCKED:
+			s.pauseTrigger(tw)
+
+		case STATE_COMPLETE, STATE_ERROR:
+			tw.state = r.State
+
+			s.timeTriggers.remove(tw.Key())
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,196 @@
+package quartz
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRAMJobStoreSnapshot(t *testing.T) {
+	Convey("Given a populated RAMJobStore", t, func() {
+		store := NewRAMJobStore()
+
+		startTime := time.Date(2016, time.March, 1, 8, 0, 0, 0, time.UTC)
+
+		newTrigger := func(name, group string, job JobDetail, startTime time.Time, priority int) OperableTrigger {
+			trigger := (&TriggerBuilder{}).
+				WithGroupIdentity(name, group).
+				ForJobDetail(job).
+				StartAt(startTime).
+				WithPriority(priority).
+				UsingJobData("name", name).
+				WithSchedule(&SimpleScheduleBuilder{time.Minute, REPEAT_INDEFINITELY}).
+				Build().(OperableTrigger)
+			trigger.ComputeFirstFireTime(nil)
+
+			return trigger
+		}
+
+		report := (&JobBuilder{}).WithGroupIdentity("report", "reports").UsingJobData("count", 1).Build()
+		backup := (&JobBuilder{}).WithGroupIdentity("backup", "backups").Build()
+		durable := (&JobBuilder{}).WithIdentity("durable").StoreDurably(true).Build()
+
+		So(store.StoreJob(report, false), ShouldBeNil)
+		So(store.StoreJob(backup, false), ShouldBeNil)
+		So(store.StoreJob(durable, false), ShouldBeNil)
+
+		triggers := []OperableTrigger{
+			newTrigger("late", "reports", report, startTime.Add(time.Hour), 5),
+			newTrigger("urgent", "reports", report, startTime, 9),
+			newTrigger("early", "reports", report, startTime.Add(-time.Minute), 1),
+			newTrigger("failed", "reports", report, startTime, 5),
+			newTrigger("done", "reports", report, startTime, 5),
+			newTrigger("paused", "paused", report, startTime, 5),
+			newTrigger("nightly", "backups", backup, startTime, 5),
+		}
+
+		for _, trigger := range triggers {
+			So(store.StoreTrigger(trigger, false), ShouldBeNil)
+		}
+
+		store.TriggeredJobComplete(triggers[3], report, SET_TRIGGER_ERROR)
+		store.TriggeredJobComplete(triggers[4], report, SET_TRIGGER_COMPLETE)
+
+		_, err := store.PauseTriggers(GroupEquals("paused"))
+
+		So(err, ShouldBeNil)
+
+		_, err = store.PauseJobs(GroupEquals("backups"))
+
+		So(err, ShouldBeNil)
+
+		store.blockedJobs.Add(durable.Key().String())
+
+		holidays := NewHolidayCalendar(NewMonthlyCalendar(nil))
+		holidays.SetLocation(time.UTC)
+		holidays.AddExcludedDate(time.Date(2016, time.March, 8, 0, 0, 0, 0, time.UTC))
+
+		So(store.StoreCalendar("holidays", holidays, false), ShouldBeNil)
+
+		snapshot, err := store.Snapshot()
+
+		So(err, ShouldBeNil)
+
+		Convey("A fresh store restored from the snapshot should answer the same queries", func() {
+			restored := NewRAMJobStore()
+
+			So(restored.RestoreSnapshot(snapshot), ShouldBeNil)
+
+			jobKeys, _ := store.GetJobKeys(AnyGroup())
+			restoredJobKeys, _ := restored.GetJobKeys(AnyGroup())
+
+			So(restoredJobKeys, ShouldResemble, jobKeys)
+
+			triggerKeys, _ := store.GetTriggerKeys(AnyGroup())
+			restoredTriggerKeys, _ := restored.GetTriggerKeys(AnyGroup())
+
+			So(restoredTriggerKeys, ShouldResemble, triggerKeys)
+
+			for _, key := range triggerKeys {
+				trigger, state, fireTime, err := store.RetrieveTriggerWithState(key)
+
+				So(err, ShouldBeNil)
+
+				restoredTrigger, restoredState, restoredFireTime, err := restored.RetrieveTriggerWithState(key)
+
+				So(err, ShouldBeNil)
+				So(restoredState, ShouldEqual, state)
+				So(restoredFireTime, ShouldEqual, fireTime)
+				So(restoredTrigger.Priority(), ShouldEqual, trigger.Priority())
+				So(restoredTrigger.JobDataMap().Get("name"), ShouldEqual, trigger.JobDataMap().Get("name"))
+			}
+
+			job, _ := restored.RetrieveJob(report.Key())
+
+			So(job.JobDataMap().Get("count"), ShouldEqual, 1)
+			So(restored.pausedTriggerGroups.Contains("paused"), ShouldBeTrue)
+			So(restored.pausedJobGroups.Contains("backups"), ShouldBeTrue)
+			So(restored.blockedJobs.Contains(durable.Key().String()), ShouldBeTrue)
+
+			calendarNames, _ := store.GetCalendarNames()
+			restoredCalendarNames, _ := restored.GetCalendarNames()
+
+			So(restoredCalendarNames, ShouldResemble, calendarNames)
+
+			cal, err := restored.RetrieveCalendar("holidays")
+
+			So(err, ShouldBeNil)
+			So(cal.(*HolidayCalendar).ExcludedDates(), ShouldResemble, holidays.ExcludedDates())
+			So(cal.BaseCalendar(), ShouldHaveSameTypeAs, holidays.BaseCalendar())
+
+			acquired, _ := store.AcquireNextTriggers(startTime, 10, 0)
+			restoredAcquired, _ := restored.AcquireNextTriggers(startTime, 10, 0)
+
+			So(restoredAcquired, ShouldHaveLength, len(acquired))
+
+			for i := range acquired {
+				So(restoredAcquired[i].Key(), ShouldResemble, acquired[i].Key())
+			}
+
+			Convey("The acquired triggers should be waiting again when the snapshot is restored", func() {
+				snapshot, err := restored.Snapshot()
+
+				So(err, ShouldBeNil)
+
+				again := NewRAMJobStore()
+
+				So(again.RestoreSnapshot(snapshot), ShouldBeNil)
+
+				state, _ := again.GetTriggerState(acquired[0].Key())
+
+				So(state, ShouldEqual, STATE_WAITING)
+			})
+		})
+
+		Convey("Restoring the snapshot should replace the content of the store", func() {
+			So(store.StoreJob((&JobBuilder{}).WithIdentity("extra").StoreDurably(true).Build(), false), ShouldBeNil)
+			So(store.ResumeAll(), ShouldBeNil)
+			So(store.RestoreSnapshot(snapshot), ShouldBeNil)
+			So(store.CheckJobExists(NewJobKey("extra")), ShouldBeFalse)
+			So(store.NumberOfTriggers(), ShouldEqual, len(triggers))
+
+			state, _ := store.GetTriggerState(triggers[5].Key())
+
+			So(state, ShouldEqual, STATE_PAUSED)
+		})
+
+		Convey("A snapshot of the version 1 should be restored without calendars", func() {
+			restored := NewRAMJobStore()
+
+			So(restored.StoreCalendar("other", NewMonthlyCalendar(nil), false), ShouldBeNil)
+
+			v1, err := gobEncode(&ramStoreSnapshot{Version: 1})
+
+			So(err, ShouldBeNil)
+			So(restored.RestoreSnapshot(v1), ShouldBeNil)
+
+			names, _ := restored.GetCalendarNames()
+
+			So(names, ShouldBeEmpty)
+		})
+
+		Convey("A calendar which can't be serialized should fail the snapshot", func() {
+			So(store.StoreCalendar("weekends", &weekendCalendar{}, false), ShouldBeNil)
+
+			_, err := store.Snapshot()
+
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("An invalid snapshot should leave the store untouched", func() {
+			So(store.RestoreSnapshot([]byte("garbage")), ShouldNotBeNil)
+
+			future, err := gobEncode(&ramStoreSnapshot{Version: snapshotVersion + 1})
+
+			So(err, ShouldBeNil)
+			So(store.RestoreSnapshot(future), ShouldNotBeNil)
+			So(store.NumberOfJobs(), ShouldEqual, 3)
+			So(store.NumberOfTriggers(), ShouldEqual, len(triggers))
+
+			names, _ := store.GetCalendarNames()
+
+			So(names, ShouldResemble, []string{"holidays"})
+		})
+	})
+}
@@ -0,0 +1,1502 @@
+package quartz
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Dialect adapts the statements of the SQLJobStore to a database.
+type Dialect interface {
+	// Quote quotes the name of a table or a column.
+	Quote(name string) string
+
+	// Placeholder returns the placeholder of the n-th parameter, starting from 1.
+	Placeholder(n int) string
+
+	// BlobType is the column type of the serialized jobs and triggers.
+	BlobType() string
+
+	// ForUpdate is the clause locking the selected rows until the end of the transaction,
+	// or empty if the database serializes the writing transactions.
+	ForUpdate() string
+
+	// CreateIndex returns the statement creating the index if it does not exist,
+	// or empty if the index should be declared by the CREATE TABLE statement.
+	CreateIndex(name, table string, columns ...string) string
+}
+
+var (
+	PostgresDialect Dialect = postgresDialect{}
+	MySQLDialect    Dialect = mysqlDialect{}
+	SQLiteDialect   Dialect = sqliteDialect{}
+)
+
+type postgresDialect struct{}
+
+func (postgresDialect) Quote(name string) string { return `"` + name + `"` }
+
+func (postgresDialect) Placeholder(n int) string { return "$" + strconv.Itoa(n) }
+
+func (postgresDialect) BlobType() string { return "BYTEA" }
+
+func (postgresDialect) ForUpdate() string { return " FOR UPDATE" }
+
+func (d postgresDialect) CreateIndex(name, table string, columns ...string) string {
+	return fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s (%s)", d.Quote(name), table, strings.Join(columns, ", "))
+}
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) Quote(name string) string { return "`" + name + "`" }
+
+func (mysqlDialect) Placeholder(n int) string { return "?" }
+
+func (mysqlDialect) BlobType() string { return "LONGBLOB" }
+
+func (mysqlDialect) ForUpdate() string { return " FOR UPDATE" }
+
+func (mysqlDialect) CreateIndex(name, table string, columns ...string) string { return "" }
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) Quote(name string) string { return `"` + name + `"` }
+
+func (sqliteDialect) Placeholder(n int) string { return "?" }
+
+func (sqliteDialect) BlobType() string { return "BLOB" }
+
+func (sqliteDialect) ForUpdate() string { return "" }
+
+func (d sqliteDialect) CreateIndex(name, table string, columns ...string) string {
+	return fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s (%s)", d.Quote(name), table, strings.Join(columns, ", "))
+}
+
+const (
+	pausedTriggerGroup = "TRIGGER"
+	pausedJobGroup     = "JOB"
+
+	// nonClusteredInstance is the instance which acquires and fires the triggers of a non-clustered store.
+	nonClusteredInstance = "NON_CLUSTERED"
+)
+
+// SQLJobStore is a JobStore which keeps its jobs, triggers, calendars and paused groups in a database,
+// so they survive the restarts and can be shared by the schedulers of several processes.
+//
+// The jobs, triggers and calendars are serialized like the FileJobStore does, the next fire time and
+// the state of the triggers are kept in their own columns to acquire the triggers.
+// Use CreateSchema to create the tables.
+type SQLJobStore struct {
+	db      *sql.DB
+	dialect Dialect
+	clock   Clock
+
+	instanceID      string
+	clustered       bool
+	checkinInterval time.Duration
+	manager         *ClusterManager
+
+	cipher DataMapCipher
+}
+
+func NewSQLJobStore(db *sql.DB, dialect Dialect) *SQLJobStore {
+	return &SQLJobStore{
+		db:         db,
+		dialect:    dialect,
+		clock:      SystemClock,
+		instanceID: nonClusteredInstance,
+	}
+}
+
+// SetClock sets the clock of the fire times and the check-ins, it must be called before the scheduler is started.
+func (s *SQLJobStore) SetClock(clock Clock) {
+	s.clock = clock
+}
+
+// SetDataMapCipher sets the cipher encrypting the JobDataMap values, it must be called before the store is used.
+func (s *SQLJobStore) SetDataMapCipher(cipher DataMapCipher) {
+	s.cipher = cipher
+}
+
+// InstanceID is the identifier of the scheduler instance recorded with the triggers it acquired and fired.
+func (s *SQLJobStore) InstanceID() string { return s.instanceID }
+
+// CreateSchema creates the tables and the indexes of the store if they do not exist.
+func (s *SQLJobStore) CreateSchema() error {
+	indexes := [][]string{
+		{"qrtz_idx_nft_st", "next_fire_time", "state"},
+		{"qrtz_idx_job", "job_key"},
+	}
+
+	var inline string
+
+	for _, index := range indexes {
+		if s.dialect.CreateIndex(index[0], s.table("triggers"), index[1:]...) == "" {
+			inline += fmt.Sprintf(", INDEX %s (%s)", s.dialect.Quote(index[0]), strings.Join(index[1:], ", "))
+		}
+	}
+
+	blob := s.dialect.BlobType()
+
+	stmts := []string{
+		"CREATE TABLE IF NOT EXISTS {job_details} (" +
+			"job_key VARCHAR(400) NOT NULL, job_group VARCHAR(200) NOT NULL, job_data " + blob + " NOT NULL, " +
+			"PRIMARY KEY (job_key))",
+		"CREATE TABLE IF NOT EXISTS {triggers} (" +
+			"trigger_key VARCHAR(400) NOT NULL, trigger_group VARCHAR(200) NOT NULL, " +
+			"job_key VARCHAR(400) NOT NULL, job_group VARCHAR(200) NOT NULL, " +
+			"next_fire_time BIGINT NOT NULL, priority INTEGER NOT NULL, state INTEGER NOT NULL, " +
+			"instance_name VARCHAR(200) NOT NULL, " +
+			"trigger_data " + blob + " NOT NULL, PRIMARY KEY (trigger_key)" + inline + ")",
+		"CREATE TABLE IF NOT EXISTS {calendars} (" +
+			"calendar_name VARCHAR(200) NOT NULL, calendar " + blob + " NOT NULL, PRIMARY KEY (calendar_name))",
+		"CREATE TABLE IF NOT EXISTS {paused_grps} (" +
+			"group_type VARCHAR(16) NOT NULL, group_name VARCHAR(200) NOT NULL, " +
+			"PRIMARY KEY (group_type, group_name))",
+		"CREATE TABLE IF NOT EXISTS {fired_triggers} (" +
+			"trigger_key VARCHAR(400) NOT NULL, job_key VARCHAR(400) NOT NULL, fire_time BIGINT NOT NULL, " +
+			"instance_name VARCHAR(200) NOT NULL, PRIMARY KEY (trigger_key, fire_time))",
+		"CREATE TABLE IF NOT EXISTS {scheduler_state} (" +
+			"instance_name VARCHAR(200) NOT NULL, last_checkin BIGINT NOT NULL, checkin_interval BIGINT NOT NULL, " +
+			"PRIMARY KEY (instance_name))",
+	}
+
+	for _, index := range indexes {
+		if stmt := s.dialect.CreateIndex(index[0], s.table("triggers"), index[1:]...); stmt != "" {
+			stmts = append(stmts, stmt)
+		}
+	}
+
+	for _, stmt := range stmts {
+		if _, err := s.db.Exec(s.sql(stmt)); err != nil {
+			return fmt.Errorf("Couldn't create the schema of the job store: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *SQLJobStore) table(name string) string { return s.dialect.Quote("qrtz_" + name) }
+
+// sql rewrites the {table} names and the '?' placeholders of the statement for the dialect.
+func (s *SQLJobStore) sql(stmt string) string {
+	var b strings.Builder
+
+	n := 0
+
+	for i := 0; i < len(stmt); i++ {
+		switch c := stmt[i]; c {
+		case '?':
+			n++
+
+			b.WriteString(s.dialect.Placeholder(n))
+
+		case '{':
+			end := strings.IndexByte(stmt[i:], '}')
+
+			b.WriteString(s.table(stmt[i+1 : i+end]))
+
+			i += end
+
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String()
+}
+
+// inTx runs the function in a transaction, which is rolled back if the function failed.
+func (s *SQLJobStore) inTx(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SchedulerStarted recovers the jobs interrupted when the previous scheduler of the instance stopped,
+// and starts the ClusterManager of a clustered store.
+func (s *SQLJobStore) SchedulerStarted() error {
+	if s.clustered {
+		if err := s.checkIn(); err != nil {
+			return err
+		}
+	}
+
+	if err := s.inTx(func(tx *sql.Tx) error { return s.recoverJobs(tx, s.instanceID) }); err != nil {
+		return err
+	}
+
+	if s.clustered && s.manager == nil {
+		s.manager = newClusterManager(s)
+		s.manager.start()
+	}
+
+	return nil
+}
+
+// recoverJobs releases the triggers acquired by the instance, unblocks the triggers of the jobs it fired,
+// and stores a one-shot trigger firing now for each job it fired which requests recovery.
+func (s *SQLJobStore) recoverJobs(tx *sql.Tx, instance string) error {
+	if _, err := tx.Exec(s.sql("UPDATE {triggers} SET state = ? WHERE state = ? AND instance_name = ?"),
+		STATE_WAITING, STATE_ACQUIRED, instance); err != nil {
+		return err
+	}
+
+	rows, err := tx.Query(s.sql("SELECT trigger_key, job_key, fire_time FROM {fired_triggers} WHERE instance_name = ? ORDER BY fire_time"), instance)
+
+	if err != nil {
+		return err
+	}
+
+	var fired []firedRecord
+
+	for rows.Next() {
+		var r firedRecord
+		var fireTime int64
+
+		if err := rows.Scan(&r.TriggerKey, &r.JobKey, &fireTime); err != nil {
+			rows.Close()
+
+			return err
+		}
+
+		if fireTime != 0 {
+			r.ScheduledFireTime = time.Unix(0, fireTime)
+		}
+
+		fired = append(fired, r)
+	}
+
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, r := range fired {
+		job, err := s.retrieveJob(tx, JobKey(r.JobKey))
+
+		if err != nil {
+			return err
+		}
+
+		// the job interrupted is no longer executing.
+		if job != nil && job.ConcurrentExecutionDisallowed() {
+			if err := s.unblockJob(tx, job.Key()); err != nil {
+				return err
+			}
+		}
+
+		if job == nil || !job.RequestsRecovery() {
+			continue
+		}
+
+		var dataMap JobDataMap
+
+		if trigger, _, err := s.retrieveTrigger(tx, TriggerKey(r.TriggerKey)); err != nil {
+			return err
+		} else if trigger != nil {
+			dataMap = trigger.JobDataMap()
+		}
+
+		if err := s.storeTrigger(tx, newRecoveryTrigger(TriggerKey(r.TriggerKey), job.Key(), r.ScheduledFireTime, zero, dataMap, s.clock.Now()), false); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(s.sql("DELETE FROM {fired_triggers} WHERE instance_name = ?"), instance)
+
+	return err
+}
+
+func (s *SQLJobStore) SchedulerPaused() {}
+
+func (s *SQLJobStore) SchedulerResumed() {}
+
+// Shutdown stops the ClusterManager, the instance is recovered by the other instances if it was firing triggers.
+func (s *SQLJobStore) Shutdown() {
+	if s.manager != nil {
+		s.manager.stop()
+		s.manager = nil
+	}
+}
+
+func (s *SQLJobStore) SupportsPersistence() bool { return true }
+
+func (s *SQLJobStore) Clustered() bool { return s.clustered }
+
+func (s *SQLJobStore) StoreJobAndTrigger(job JobDetail, trigger OperableTrigger) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		if err := s.storeJob(tx, job, false); err != nil {
+			return err
+		}
+
+		return s.storeTrigger(tx, trigger, false)
+	})
+}
+
+// StoreJobsAndTriggers stores the jobs and their triggers in a transaction, nothing is stored if one of them failed.
+func (s *SQLJobStore) StoreJobsAndTriggers(triggersAndJobs map[JobDetail][]Trigger, replace bool) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		for job, triggers := range triggersAndJobs {
+			if err := s.storeJob(tx, job, replace); err != nil {
+				return err
+			}
+
+			for _, trigger := range triggers {
+				if err := s.storeTrigger(tx, trigger.(OperableTrigger), replace); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+func (s *SQLJobStore) StoreJob(job JobDetail, replaceExisting bool) error {
+	return s.inTx(func(tx *sql.Tx) error { return s.storeJob(tx, job, replaceExisting) })
+}
+
+func (s *SQLJobStore) storeJob(tx *sql.Tx, job JobDetail, replaceExisting bool) error {
+	data, err := encodeJob(job, s.cipher)
+
+	if err != nil {
+		return err
+	}
+
+	exists, err := s.exists(tx, "SELECT COUNT(*) FROM {job_details} WHERE job_key = ?", job.Key().String())
+
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		if !replaceExisting {
+			return jobAlreadyExistsError(job)
+		}
+
+		_, err = tx.Exec(s.sql("UPDATE {job_details} SET job_data = ? WHERE job_key = ?"), data, job.Key().String())
+	} else {
+		_, err = tx.Exec(s.sql("INSERT INTO {job_details} (job_key, job_group, job_data) VALUES (?, ?, ?)"),
+			job.Key().String(), job.Key().Group(), data)
+	}
+
+	return err
+}
+
+func (s *SQLJobStore) exists(tx *sql.Tx, query string, args ...interface{}) (bool, error) {
+	var count int
+
+	if err := tx.QueryRow(s.sql(query), args...).Scan(&count); err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (s *SQLJobStore) StoreTrigger(trigger OperableTrigger, replaceExisting bool) error {
+	return s.inTx(func(tx *sql.Tx) error { return s.storeTrigger(tx, trigger, replaceExisting) })
+}
+
+// StoreTriggers stores the triggers in a transaction, nothing is stored if one of them failed.
+func (s *SQLJobStore) StoreTriggers(triggers []OperableTrigger, replaceExisting bool) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		for _, trigger := range triggers {
+			if err := s.storeTrigger(tx, trigger, replaceExisting); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// storeTrigger stores the trigger as waiting, or as paused if its group or the group of its job is paused.
+func (s *SQLJobStore) storeTrigger(tx *sql.Tx, trigger OperableTrigger, replaceExisting bool) error {
+	if trigger.JobKey() == nil {
+		return missingJobKeyError(trigger)
+	}
+
+	data, err := encodeTrigger(trigger, s.cipher)
+
+	if err != nil {
+		return err
+	}
+
+	exists, err := s.exists(tx, "SELECT COUNT(*) FROM {triggers} WHERE trigger_key = ?", trigger.Key().String())
+
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		if !replaceExisting {
+			return triggerAlreadyExistsError(trigger)
+		}
+
+		if _, err := s.removeTrigger(tx, trigger.Key(), false); err != nil {
+			return err
+		}
+	}
+
+	if exists, err := s.exists(tx, "SELECT COUNT(*) FROM {job_details} WHERE job_key = ?", trigger.JobKey().String()); err != nil {
+		return err
+	} else if !exists {
+		return jobPersistenceError(trigger)
+	}
+
+	paused, err := s.exists(tx, "SELECT COUNT(*) FROM {paused_grps} "+
+		"WHERE (group_type = ? AND group_name = ?) OR (group_type = ? AND group_name = ?)",
+		pausedTriggerGroup, trigger.Key().Group(), pausedJobGroup, trigger.JobKey().Group())
+
+	if err != nil {
+		return err
+	}
+
+	state := STATE_WAITING
+
+	if paused {
+		state = STATE_PAUSED
+	}
+
+	_, err = tx.Exec(s.sql("INSERT INTO {triggers} "+
+		"(trigger_key, trigger_group, job_key, job_group, next_fire_time, priority, state, instance_name, trigger_data) "+
+		"VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)"),
+		trigger.Key().String(), trigger.Key().Group(), trigger.JobKey().String(), trigger.JobKey().Group(),
+		fireTimeColumn(trigger.NextFireTime()), trigger.Priority(), state, "", data)
+
+	return err
+}
+
+// updateTrigger saves the trigger and its state, after it fired.
+func (s *SQLJobStore) updateTrigger(tx *sql.Tx, trigger OperableTrigger, state TriggerState) error {
+	data, err := encodeTrigger(trigger, s.cipher)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(s.sql("UPDATE {triggers} SET next_fire_time = ?, state = ?, trigger_data = ? WHERE trigger_key = ?"),
+		fireTimeColumn(trigger.NextFireTime()), state, data, trigger.Key().String())
+
+	return err
+}
+
+func (s *SQLJobStore) RemoveJob(key JobKey) (found bool, err error) {
+	err = s.inTx(func(tx *sql.Tx) (err error) {
+		found, err = s.removeJob(tx, key)
+
+		return
+	})
+
+	return
+}
+
+func (s *SQLJobStore) RemoveJobs(keys []JobKey) (allFound bool, err error) {
+	allFound = true
+
+	err = s.inTx(func(tx *sql.Tx) error {
+		for _, key := range keys {
+			found, err := s.removeJob(tx, key)
+
+			if err != nil {
+				return err
+			}
+
+			allFound = found && allFound
+		}
+
+		return nil
+	})
+
+	return
+}
+
+// removeJob removes the job and its triggers.
+func (s *SQLJobStore) removeJob(tx *sql.Tx, key JobKey) (bool, error) {
+	if _, err := tx.Exec(s.sql("DELETE FROM {triggers} WHERE job_key = ?"), key.String()); err != nil {
+		return false, err
+	}
+
+	res, err := tx.Exec(s.sql("DELETE FROM {job_details} WHERE job_key = ?"), key.String())
+
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+
+	return n > 0, err
+}
+
+// RetrieveJob returns the job, or nil if it doesn't exist.
+func (s *SQLJobStore) RetrieveJob(key JobKey) (job JobDetail, err error) {
+	err = s.inTx(func(tx *sql.Tx) (err error) {
+		job, err = s.retrieveJob(tx, key)
+
+		return
+	})
+
+	return
+}
+
+func (s *SQLJobStore) retrieveJob(tx *sql.Tx, key JobKey) (JobDetail, error) {
+	var data []byte
+
+	err := tx.QueryRow(s.sql("SELECT job_data FROM {job_details} WHERE job_key = ?"), key.String()).Scan(&data)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return decodeJob(key.String(), data, s.cipher)
+}
+
+func (s *SQLJobStore) RemoveTrigger(key TriggerKey) (found bool, err error) {
+	err = s.inTx(func(tx *sql.Tx) (err error) {
+		found, err = s.removeTrigger(tx, key, true)
+
+		return
+	})
+
+	return
+}
+
+func (s *SQLJobStore) RemoveTriggers(keys []TriggerKey) (allFound bool, err error) {
+	allFound = true
+
+	err = s.inTx(func(tx *sql.Tx) error {
+		for _, key := range keys {
+			found, err := s.removeTrigger(tx, key, true)
+
+			if err != nil {
+				return err
+			}
+
+			allFound = found && allFound
+		}
+
+		return nil
+	})
+
+	return
+}
+
+// removeTrigger removes the trigger, and its job if the job is not durable and has no trigger left.
+func (s *SQLJobStore) removeTrigger(tx *sql.Tx, key TriggerKey, removeOrphanedJob bool) (bool, error) {
+	var jobKey string
+
+	err := tx.QueryRow(s.sql("SELECT job_key FROM {triggers} WHERE trigger_key = ?"), key.String()).Scan(&jobKey)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	if _, err := tx.Exec(s.sql("DELETE FROM {triggers} WHERE trigger_key = ?"), key.String()); err != nil {
+		return false, err
+	}
+
+	if removeOrphanedJob {
+		job, err := s.retrieveJob(tx, JobKey(jobKey))
+
+		if err != nil {
+			return true, err
+		}
+
+		if job != nil && !job.Durable() {
+			orphaned, err := s.exists(tx, "SELECT COUNT(*) FROM {triggers} WHERE job_key = ?", jobKey)
+
+			if err != nil {
+				return true, err
+			}
+
+			if !orphaned {
+				if _, err := s.removeJob(tx, job.Key()); err != nil {
+					return true, err
+				}
+			}
+		}
+	}
+
+	return true, nil
+}
+
+func (s *SQLJobStore) ReplaceTrigger(key TriggerKey, trigger OperableTrigger) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		var jobKey string
+
+		err := tx.QueryRow(s.sql("SELECT job_key FROM {triggers} WHERE trigger_key = ?"), key.String()).Scan(&jobKey)
+
+		if errors.Is(err, sql.ErrNoRows) {
+			return triggerNotFoundError(key)
+		} else if err != nil {
+			return err
+		}
+
+		if !JobKey(jobKey).Equals(trigger.JobKey()) {
+			return errors.New("New trigger is not related to the same job as the old trigger.")
+		}
+
+		if _, err := s.removeTrigger(tx, key, false); err != nil {
+			return err
+		}
+
+		return s.storeTrigger(tx, trigger, false)
+	})
+}
+
+// RetrieveTrigger returns the trigger, or nil if it doesn't exist.
+func (s *SQLJobStore) RetrieveTrigger(key TriggerKey) (trigger OperableTrigger, err error) {
+	err = s.inTx(func(tx *sql.Tx) (err error) {
+		trigger, _, err = s.retrieveTrigger(tx, key)
+
+		return
+	})
+
+	return
+}
+
+func (s *SQLJobStore) RetrieveTriggerWithState(key TriggerKey) (OperableTrigger, TriggerState, time.Time, error) {
+	var trigger OperableTrigger
+	var state TriggerState
+
+	err := s.inTx(func(tx *sql.Tx) (err error) {
+		trigger, state, err = s.retrieveTrigger(tx, key)
+
+		return
+	})
+
+	if err != nil {
+		return nil, STATE_ERROR, zero, err
+	}
+
+	if trigger == nil {
+		return nil, STATE_NONE, zero, triggerNotFoundError(key)
+	}
+
+	return trigger, state, trigger.NextFireTime(), nil
+}
+
+// retrieveTrigger returns the trigger and its state, or nil if it doesn't exist.
+func (s *SQLJobStore) retrieveTrigger(tx *sql.Tx, key TriggerKey) (OperableTrigger, TriggerState, error) {
+	var data []byte
+	var state TriggerState
+
+	err := tx.QueryRow(s.sql("SELECT trigger_data, state FROM {triggers} WHERE trigger_key = ?"), key.String()).Scan(&data, &state)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, STATE_NONE, nil
+	} else if err != nil {
+		return nil, STATE_ERROR, err
+	}
+
+	trigger, err := decodeTrigger(key.String(), data, s.cipher)
+
+	return trigger, state, err
+}
+
+// GetTriggerState returns the current state of the trigger, or STATE_NONE with ErrTriggerNotFound if it does not exist.
+func (s *SQLJobStore) GetTriggerState(key TriggerKey) (TriggerState, error) {
+	var state TriggerState
+
+	err := s.db.QueryRow(s.sql("SELECT state FROM {triggers} WHERE trigger_key = ?"), key.String()).Scan(&state)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return STATE_NONE, triggerNotFoundError(key)
+	} else if err != nil {
+		return STATE_ERROR, err
+	}
+
+	return state, nil
+}
+
+// ResetTriggerFromErrorState moves the trigger out of STATE_ERROR, the trigger in other states is left untouched.
+func (s *SQLJobStore) ResetTriggerFromErrorState(key TriggerKey) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		var jobGroup string
+		var state TriggerState
+
+		err := tx.QueryRow(s.sql("SELECT job_group, state FROM {triggers} WHERE trigger_key = ?"), key.String()).Scan(&jobGroup, &state)
+
+		if errors.Is(err, sql.ErrNoRows) {
+			return triggerNotFoundError(key)
+		} else if err != nil {
+			return err
+		}
+
+		if state != STATE_ERROR {
+			return nil
+		}
+
+		paused, err := s.exists(tx, "SELECT COUNT(*) FROM {paused_grps} "+
+			"WHERE (group_type = ? AND group_name = ?) OR (group_type = ? AND group_name = ?)",
+			pausedTriggerGroup, key.Group(), pausedJobGroup, jobGroup)
+
+		if err != nil {
+			return err
+		}
+
+		if paused {
+			state = STATE_PAUSED
+		} else {
+			state = STATE_WAITING
+		}
+
+		_, err = tx.Exec(s.sql("UPDATE {triggers} SET state = ? WHERE trigger_key = ?"), state, key.String())
+
+		return err
+	})
+}
+
+func (s *SQLJobStore) CheckJobExists(key JobKey) bool {
+	var count int
+
+	err := s.db.QueryRow(s.sql("SELECT COUNT(*) FROM {job_details} WHERE job_key = ?"), key.String()).Scan(&count)
+
+	return err == nil && count > 0
+}
+
+func (s *SQLJobStore) CheckTriggerExists(key TriggerKey) bool {
+	var count int
+
+	err := s.db.QueryRow(s.sql("SELECT COUNT(*) FROM {triggers} WHERE trigger_key = ?"), key.String()).Scan(&count)
+
+	return err == nil && count > 0
+}
+
+func (s *SQLJobStore) NumberOfJobs() int {
+	var count int
+
+	s.db.QueryRow(s.sql("SELECT COUNT(*) FROM {job_details}")).Scan(&count)
+
+	return count
+}
+
+func (s *SQLJobStore) NumberOfTriggers() int {
+	var count int
+
+	s.db.QueryRow(s.sql("SELECT COUNT(*) FROM {triggers}")).Scan(&count)
+
+	return count
+}
+
+func (s *SQLJobStore) TriggersForJob(key JobKey) []OperableTrigger {
+	rows, err := s.db.Query(s.sql("SELECT trigger_key, trigger_data FROM {triggers} WHERE job_key = ? ORDER BY trigger_key"), key.String())
+
+	if err != nil {
+		return nil
+	}
+
+	defer rows.Close()
+
+	var triggers []OperableTrigger
+
+	for rows.Next() {
+		var triggerKey string
+		var data []byte
+
+		if err := rows.Scan(&triggerKey, &data); err != nil {
+			return nil
+		}
+
+		if trigger, err := decodeTrigger(triggerKey, data, s.cipher); err == nil {
+			triggers = append(triggers, trigger)
+		}
+	}
+
+	return triggers
+}
+
+// strings returns the strings of the only column selected by the query.
+func (s *SQLJobStore) strings(tx *sql.Tx, query string, args ...interface{}) ([]string, error) {
+	rows, err := tx.Query(s.sql(query), args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var values []string
+
+	for rows.Next() {
+		var value string
+
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+
+		values = append(values, value)
+	}
+
+	return values, rows.Err()
+}
+
+func (s *SQLJobStore) GetJobKeys(matcher GroupMatcher) (keys []JobKey, err error) {
+	err = s.inTx(func(tx *sql.Tx) error {
+		values, err := s.strings(tx, "SELECT job_key FROM {job_details}")
+
+		for _, value := range values {
+			if key := JobKey(value); matcher.MatchesGroup(key.Group()) {
+				keys = append(keys, key)
+			}
+		}
+
+		return err
+	})
+
+	sort.Slice(keys, func(i, j int) bool { return groupedKeyLess(keys[i], keys[j]) })
+
+	return
+}
+
+func (s *SQLJobStore) GetTriggerKeys(matcher GroupMatcher) (keys []TriggerKey, err error) {
+	err = s.inTx(func(tx *sql.Tx) error {
+		values, err := s.strings(tx, "SELECT trigger_key FROM {triggers}")
+
+		for _, value := range values {
+			if key := TriggerKey(value); matcher.MatchesGroup(key.Group()) {
+				keys = append(keys, key)
+			}
+		}
+
+		return err
+	})
+
+	sort.Slice(keys, func(i, j int) bool { return groupedKeyLess(keys[i], keys[j]) })
+
+	return
+}
+
+// StoreCalendar stores the calendar, only the Daily, Annual, Monthly and Holiday calendars can be persisted,
+// the fire times of the triggers using a replaced calendar are not updated.
+func (s *SQLJobStore) StoreCalendar(name string, calendar Calendar, replaceExisting bool) error {
+	data, err := encodeCalendar(name, calendar)
+
+	if err != nil {
+		return err
+	}
+
+	return s.inTx(func(tx *sql.Tx) error {
+		exists, err := s.exists(tx, "SELECT COUNT(*) FROM {calendars} WHERE calendar_name = ?", name)
+
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			if !replaceExisting {
+				return calendarAlreadyExistsError(name)
+			}
+
+			_, err = tx.Exec(s.sql("UPDATE {calendars} SET calendar = ? WHERE calendar_name = ?"), data, name)
+		} else {
+			_, err = tx.Exec(s.sql("INSERT INTO {calendars} (calendar_name, calendar) VALUES (?, ?)"), name, data)
+		}
+
+		return err
+	})
+}
+
+func (s *SQLJobStore) RemoveCalendar(name string) (found bool, err error) {
+	referenced, err := calendarReferenced(s, name)
+
+	if err != nil {
+		return false, err
+	}
+
+	if referenced {
+		return false, calendarReferencedError(name)
+	}
+
+	err = s.inTx(func(tx *sql.Tx) error {
+		result, err := tx.Exec(s.sql("DELETE FROM {calendars} WHERE calendar_name = ?"), name)
+
+		if err != nil {
+			return err
+		}
+
+		n, err := result.RowsAffected()
+
+		found = n > 0
+
+		return err
+	})
+
+	return
+}
+
+func (s *SQLJobStore) RetrieveCalendar(name string) (calendar Calendar, err error) {
+	err = s.inTx(func(tx *sql.Tx) (err error) {
+		calendar, err = s.retrieveCalendar(tx, name)
+
+		return
+	})
+
+	return
+}
+
+// retrieveCalendar returns the stored calendar, or nil if there is none.
+func (s *SQLJobStore) retrieveCalendar(tx *sql.Tx, name string) (Calendar, error) {
+	var data []byte
+
+	err := tx.QueryRow(s.sql("SELECT calendar FROM {calendars} WHERE calendar_name = ?"), name).Scan(&data)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return decodeCalendar(name, data)
+}
+
+func (s *SQLJobStore) GetCalendarNames() (names []string, err error) {
+	err = s.inTx(func(tx *sql.Tx) (err error) {
+		names, err = s.strings(tx, "SELECT calendar_name FROM {calendars} ORDER BY calendar_name")
+
+		return
+	})
+
+	return
+}
+
+// pauseTriggers pauses the triggers matched by the condition, the completed triggers are left untouched,
+// and the blocked triggers stay blocked until their job completed.
+func (s *SQLJobStore) pauseTriggers(tx *sql.Tx, cond string, args ...interface{}) error {
+	if _, err := tx.Exec(s.sql("UPDATE {triggers} SET state = ? WHERE state = ? AND "+cond),
+		append([]interface{}{STATE_PAUSED_BLOCKED, STATE_BLOCKED}, args...)...); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(s.sql("UPDATE {triggers} SET state = ? WHERE state NOT IN (?, ?, ?) AND "+cond),
+		append([]interface{}{STATE_PAUSED, STATE_COMPLETE, STATE_PAUSED, STATE_PAUSED_BLOCKED}, args...)...)
+
+	return err
+}
+
+// resumeTriggers resumes the paused triggers matched by the condition.
+func (s *SQLJobStore) resumeTriggers(tx *sql.Tx, cond string, args ...interface{}) error {
+	if _, err := tx.Exec(s.sql("UPDATE {triggers} SET state = ? WHERE state = ? AND "+cond),
+		append([]interface{}{STATE_WAITING, STATE_PAUSED}, args...)...); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(s.sql("UPDATE {triggers} SET state = ? WHERE state = ? AND "+cond),
+		append([]interface{}{STATE_BLOCKED, STATE_PAUSED_BLOCKED}, args...)...)
+
+	return err
+}
+
+func (s *SQLJobStore) pauseGroup(tx *sql.Tx, groupType, group string) error {
+	paused, err := s.exists(tx, "SELECT COUNT(*) FROM {paused_grps} WHERE group_type = ? AND group_name = ?", groupType, group)
+
+	if err != nil || paused {
+		return err
+	}
+
+	_, err = tx.Exec(s.sql("INSERT INTO {paused_grps} (group_type, group_name) VALUES (?, ?)"), groupType, group)
+
+	return err
+}
+
+func (s *SQLJobStore) resumeGroup(tx *sql.Tx, groupType, group string) error {
+	_, err := tx.Exec(s.sql("DELETE FROM {paused_grps} WHERE group_type = ? AND group_name = ?"), groupType, group)
+
+	return err
+}
+
+func (s *SQLJobStore) PauseTrigger(key TriggerKey) error {
+	return s.inTx(func(tx *sql.Tx) error { return s.pauseTriggers(tx, "trigger_key = ?", key.String()) })
+}
+
+func (s *SQLJobStore) ResumeTrigger(key TriggerKey) error {
+	return s.inTx(func(tx *sql.Tx) error { return s.resumeTriggers(tx, "trigger_key = ?", key.String()) })
+}
+
+func (s *SQLJobStore) PauseJob(key JobKey) error {
+	return s.inTx(func(tx *sql.Tx) error { return s.pauseTriggers(tx, "job_key = ?", key.String()) })
+}
+
+func (s *SQLJobStore) ResumeJob(key JobKey) error {
+	return s.inTx(func(tx *sql.Tx) error { return s.resumeTriggers(tx, "job_key = ?", key.String()) })
+}
+
+// PauseTriggers pauses the triggers in the groups matched by the matcher, and returns the paused groups.
+//
+// The triggers stored into a paused group later will be paused too.
+func (s *SQLJobStore) PauseTriggers(matcher GroupMatcher) (groups []string, err error) {
+	err = s.inTx(func(tx *sql.Tx) error {
+		triggerGroups, err := s.strings(tx, "SELECT DISTINCT trigger_group FROM {triggers}")
+
+		if err != nil {
+			return err
+		}
+
+		groups = matchedGroups(matcher, triggerGroups)
+
+		for _, group := range groups {
+			if err := s.pauseGroup(tx, pausedTriggerGroup, group); err != nil {
+				return err
+			}
+
+			if err := s.pauseTriggers(tx, "trigger_group = ?", group); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return
+}
+
+// ResumeTriggers resumes the triggers in the groups matched by the matcher, and returns the resumed groups.
+//
+// The triggers of the jobs in a paused job group are kept paused.
+func (s *SQLJobStore) ResumeTriggers(matcher GroupMatcher) (groups []string, err error) {
+	err = s.inTx(func(tx *sql.Tx) error {
+		triggerGroups, err := s.strings(tx, "SELECT DISTINCT trigger_group FROM {triggers}")
+
+		if err != nil {
+			return err
+		}
+
+		pausedGroups, err := s.strings(tx, "SELECT group_name FROM {paused_grps} WHERE group_type = ?", pausedTriggerGroup)
+
+		if err != nil {
+			return err
+		}
+
+		groups = matchedGroups(matcher, triggerGroups, pausedGroups)
+
+		for _, group := range groups {
+			if err := s.resumeGroup(tx, pausedTriggerGroup, group); err != nil {
+				return err
+			}
+
+			if err := s.resumeTriggers(tx, "trigger_group = ? AND job_group NOT IN "+
+				"(SELECT group_name FROM {paused_grps} WHERE group_type = ?)", group, pausedJobGroup); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return
+}
+
+// PauseJobs pauses the triggers of the jobs in the groups matched by the matcher, and returns the paused groups.
+//
+// The triggers stored for the jobs in a paused group later will be paused too.
+func (s *SQLJobStore) PauseJobs(matcher GroupMatcher) (groups []string, err error) {
+	err = s.inTx(func(tx *sql.Tx) error {
+		jobGroups, err := s.strings(tx, "SELECT DISTINCT job_group FROM {job_details}")
+
+		if err != nil {
+			return err
+		}
+
+		groups = matchedGroups(matcher, jobGroups)
+
+		for _, group := range groups {
+			if err := s.pauseGroup(tx, pausedJobGroup, group); err != nil {
+				return err
+			}
+
+			if err := s.pauseTriggers(tx, "job_group = ?", group); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return
+}
+
+// ResumeJobs resumes the triggers of the jobs in the groups matched by the matcher, and returns the resumed groups.
+func (s *SQLJobStore) ResumeJobs(matcher GroupMatcher) (groups []string, err error) {
+	err = s.inTx(func(tx *sql.Tx) error {
+		jobGroups, err := s.strings(tx, "SELECT DISTINCT job_group FROM {job_details}")
+
+		if err != nil {
+			return err
+		}
+
+		pausedGroups, err := s.strings(tx, "SELECT group_name FROM {paused_grps} WHERE group_type = ?", pausedJobGroup)
+
+		if err != nil {
+			return err
+		}
+
+		groups = matchedGroups(matcher, jobGroups, pausedGroups)
+
+		for _, group := range groups {
+			if err := s.resumeGroup(tx, pausedJobGroup, group); err != nil {
+				return err
+			}
+
+			if err := s.resumeTriggers(tx, "job_group = ?", group); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return
+}
+
+// PauseAll pauses all the trigger groups, the triggers stored into them later will be paused too.
+func (s *SQLJobStore) PauseAll() error {
+	return s.inTx(func(tx *sql.Tx) error {
+		groups, err := s.strings(tx, "SELECT DISTINCT trigger_group FROM {triggers}")
+
+		if err != nil {
+			return err
+		}
+
+		for _, group := range groups {
+			if err := s.pauseGroup(tx, pausedTriggerGroup, group); err != nil {
+				return err
+			}
+		}
+
+		return s.pauseTriggers(tx, "1 = 1")
+	})
+}
+
+// ResumeAll resumes all the triggers and forgets the paused groups.
+func (s *SQLJobStore) ResumeAll() error {
+	return s.inTx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(s.sql("DELETE FROM {paused_grps}")); err != nil {
+			return err
+		}
+
+		return s.resumeTriggers(tx, "1 = 1")
+	})
+}
+
+// ClearAllSchedulingData removes all the jobs, triggers, calendars and fired records, the paused groups are kept.
+func (s *SQLJobStore) ClearAllSchedulingData() error {
+	return s.inTx(func(tx *sql.Tx) error {
+		for _, table := range []string{"fired_triggers", "triggers", "job_details", "calendars"} {
+			if _, err := tx.Exec(s.sql("DELETE FROM {" + table + "}")); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// AcquireNextTriggers acquires at most maxCount waiting triggers ordered by their next fire time and then by priority,
+// the first one fires no later than noLaterThan, and the others no later than the timeWindow after the first one,
+// or after now if the first one is overdue.
+//
+// The selected rows are locked when the dialect supports it, and each trigger is acquired by moving it from
+// STATE_WAITING to STATE_ACQUIRED for the instance, so a trigger acquired by another scheduler in the meantime is skipped.
+// Only one trigger of a job disallowing concurrent execution is acquired in a batch, and none while the job executes.
+func (s *SQLJobStore) AcquireNextTriggers(noLaterThan time.Time, maxCount int, timeWindow time.Duration) (triggers []OperableTrigger, err error) {
+	err = s.inTx(func(tx *sql.Tx) error {
+		keys, err := s.strings(tx, "SELECT trigger_key FROM {triggers} "+
+			"WHERE state = ? AND next_fire_time > 0 AND next_fire_time <= ? "+
+			"ORDER BY next_fire_time, priority DESC LIMIT ?"+s.dialect.ForUpdate(),
+			STATE_WAITING, fireTimeColumn(noLaterThan.Add(timeWindow)), maxCount)
+
+		if err != nil {
+			return err
+		}
+
+		batchEnd := noLaterThan
+
+		acquiredJobs := make(map[string]bool)
+
+		for _, key := range keys {
+			trigger, _, err := s.retrieveTrigger(tx, TriggerKey(key))
+
+			if err != nil {
+				return err
+			}
+
+			if trigger == nil {
+				continue
+			}
+
+			if trigger.NextFireTime().After(batchEnd) {
+				break
+			}
+
+			job, err := s.retrieveJob(tx, trigger.JobKey())
+
+			if err != nil {
+				return err
+			}
+
+			noConcurrent := job != nil && job.ConcurrentExecutionDisallowed()
+
+			if noConcurrent {
+				if acquiredJobs[job.Key().String()] {
+					continue
+				}
+
+				if executing, err := s.jobExecuting(tx, job.Key()); err != nil {
+					return err
+				} else if executing {
+					continue
+				}
+			}
+
+			res, err := tx.Exec(s.sql("UPDATE {triggers} SET state = ?, instance_name = ? WHERE trigger_key = ? AND state = ?"),
+				STATE_ACQUIRED, s.instanceID, key, STATE_WAITING)
+
+			if err != nil {
+				return err
+			}
+
+			if n, err := res.RowsAffected(); err != nil {
+				return err
+			} else if n == 0 {
+				continue
+			}
+
+			if noConcurrent {
+				acquiredJobs[job.Key().String()] = true
+			}
+
+			if len(triggers) == 0 {
+				batchEnd = batchEndAfter(trigger.NextFireTime(), s.clock.Now(), timeWindow)
+			}
+
+			triggers = append(triggers, trigger)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return triggers, nil
+}
+
+func (s *SQLJobStore) ReleaseAcquiredTrigger(trigger OperableTrigger) {
+	s.db.Exec(s.sql("UPDATE {triggers} SET state = ? WHERE trigger_key = ? AND state = ?"),
+		STATE_WAITING, trigger.Key().String(), STATE_ACQUIRED)
+}
+
+// TriggersFired updates the acquired triggers for their next fire time, records the fired triggers for the recovery,
+// and returns the bundles to execute their jobs, the triggers no longer acquired are skipped.
+//
+// The other triggers of a job disallowing concurrent execution are blocked until the job completed.
+func (s *SQLJobStore) TriggersFired(triggers []OperableTrigger) (bundles []*TriggerFiredBundle, err error) {
+	err = s.inTx(func(tx *sql.Tx) error {
+		for _, trigger := range triggers {
+			stored, state, err := s.retrieveTrigger(tx, trigger.Key())
+
+			if err != nil {
+				return err
+			}
+
+			if stored == nil || state != STATE_ACQUIRED {
+				continue
+			}
+
+			job, err := s.retrieveJob(tx, stored.JobKey())
+
+			if err != nil {
+				return err
+			}
+
+			if job == nil {
+				continue
+			}
+
+			noConcurrent := job.ConcurrentExecutionDisallowed()
+
+			// the trigger acquired before its job was blocked waits for the job to complete.
+			if noConcurrent {
+				if executing, err := s.jobExecuting(tx, job.Key()); err != nil {
+					return err
+				} else if executing {
+					if _, err := tx.Exec(s.sql("UPDATE {triggers} SET state = ? WHERE trigger_key = ?"),
+						STATE_BLOCKED, stored.Key().String()); err != nil {
+						return err
+					}
+
+					continue
+				}
+			}
+
+			before := stored.Clone().(OperableTrigger)
+
+			var cal Calendar
+
+			if name := stored.CalendarName(); name != "" {
+				if cal, err = s.retrieveCalendar(tx, name); err != nil {
+					return err
+				}
+			}
+
+			stored.Triggered(cal)
+
+			if err := s.updateTrigger(tx, stored, STATE_WAITING); err != nil {
+				return err
+			}
+
+			if _, err := tx.Exec(s.sql("INSERT INTO {fired_triggers} (trigger_key, job_key, fire_time, instance_name) VALUES (?, ?, ?, ?)"),
+				stored.Key().String(), job.Key().String(), fireTimeColumn(before.NextFireTime()), s.instanceID); err != nil {
+				return err
+			}
+
+			if noConcurrent {
+				if err := s.blockJob(tx, job.Key()); err != nil {
+					return err
+				}
+			}
+
+			bundles = append(bundles, &TriggerFiredBundle{
+				JobDetail:         job,
+				Trigger:           stored.Clone().(OperableTrigger),
+				Calendar:          cloneCalendar(cal),
+				FireTime:          s.clock.Now(),
+				ScheduledFireTime: before.NextFireTime(),
+				PrevFireTime:      before.PreviousFireTime(),
+				NextFireTime:      stored.NextFireTime(),
+			})
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return bundles, nil
+}
+
+func (s *SQLJobStore) TriggeredJobComplete(trigger OperableTrigger, job JobDetail, instruction CompletedExecutionInstruction) {
+	s.inTx(func(tx *sql.Tx) error {
+		if job.PersistJobDataAfterExecution() {
+			stored, err := s.retrieveJob(tx, job.Key())
+
+			if err != nil {
+				return err
+			}
+
+			if d, ok := stored.(*jobDetail); ok && job.JobDataMap() != nil {
+				d.dataMap = job.JobDataMap().CloneDataMap()
+
+				if err := s.storeJob(tx, d, true); err != nil {
+					return err
+				}
+			}
+		}
+
+		// the fired trigger was updated to the next fire time, so its previous fire time is the scheduled one.
+		if _, err := tx.Exec(s.sql("DELETE FROM {fired_triggers} WHERE trigger_key = ? AND fire_time = ?"),
+			trigger.Key().String(), fireTimeColumn(trigger.PreviousFireTime())); err != nil {
+			return err
+		}
+
+		if job.ConcurrentExecutionDisallowed() {
+			if err := s.unblockJob(tx, job.Key()); err != nil {
+				return err
+			}
+		}
+
+		var err error
+
+		switch instruction {
+		case DELETE_TRIGGER:
+			// the trigger may have been rescheduled while its job was executing.
+			var nextFireTime int64
+
+			err = tx.QueryRow(s.sql("SELECT next_fire_time FROM {triggers} WHERE trigger_key = ?"),
+				trigger.Key().String()).Scan(&nextFireTime)
+
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			} else if err == nil && nextFireTime == fireTimeColumn(trigger.NextFireTime()) {
+				_, err = s.removeTrigger(tx, trigger.Key(), true)
+			}
+
+		case SET_TRIGGER_COMPLETE:
+			_, err = tx.Exec(s.sql("UPDATE {triggers} SET state = ? WHERE trigger_key = ?"), STATE_COMPLETE, trigger.Key().String())
+
+		case SET_TRIGGER_ERROR:
+			_, err = tx.Exec(s.sql("UPDATE {triggers} SET state = ? WHERE trigger_key = ?"), STATE_ERROR, trigger.Key().String())
+
+		case SET_ALL_JOB_TRIGGERS_COMPLETE:
+			_, err = tx.Exec(s.sql("UPDATE {triggers} SET state = ? WHERE job_key = ?"), STATE_COMPLETE, job.Key().String())
+
+		case SET_ALL_JOB_TRIGGERS_ERROR:
+			_, err = tx.Exec(s.sql("UPDATE {triggers} SET state = ? WHERE job_key = ?"), STATE_ERROR, job.Key().String())
+		}
+
+		return err
+	})
+}
+
+// jobExecuting returns true if a trigger of the job fired and the job did not complete yet.
+func (s *SQLJobStore) jobExecuting(tx *sql.Tx, key JobKey) (bool, error) {
+	return s.exists(tx, "SELECT COUNT(*) FROM {fired_triggers} WHERE job_key = ?", key.String())
+}
+
+// blockJob blocks the waiting and paused triggers of the job executing.
+func (s *SQLJobStore) blockJob(tx *sql.Tx, key JobKey) error {
+	if _, err := tx.Exec(s.sql("UPDATE {triggers} SET state = ? WHERE job_key = ? AND state = ?"),
+		STATE_BLOCKED, key.String(), STATE_WAITING); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(s.sql("UPDATE {triggers} SET state = ? WHERE job_key = ? AND state = ?"),
+		STATE_PAUSED_BLOCKED, key.String(), STATE_PAUSED)
+
+	return err
+}
+
+// unblockJob restores the blocked triggers of the job completed.
+func (s *SQLJobStore) unblockJob(tx *sql.Tx, key JobKey) error {
+	if _, err := tx.Exec(s.sql("UPDATE {triggers} SET state = ? WHERE job_key = ? AND state = ?"),
+		STATE_WAITING, key.String(), STATE_BLOCKED); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(s.sql("UPDATE {triggers} SET state = ? WHERE job_key = ? AND state = ?"),
+		STATE_PAUSED, key.String(), STATE_PAUSED_BLOCKED)
+
+	return err
+}
+
+// fireTimeColumn returns the fire time in nanoseconds since the epoch, or 0 if the trigger will not fire.
+func fireTimeColumn(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+
+	return t.UnixNano()
+}
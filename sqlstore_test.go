@@ -0,0 +1,573 @@
+//go:build sqlite
+
+// The tests need the cgo driver github.com/mattn/go-sqlite3, run them with `go test -tags sqlite`.
+
+package quartz
+
+import (
+	"database/sql"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func openSQLiteJobStore() (*sql.DB, *SQLJobStore) {
+	db, err := sql.Open("sqlite3", ":memory:")
+
+	So(err, ShouldBeNil)
+
+	// each connection has its own in-memory database.
+	db.SetMaxOpenConns(1)
+
+	store := NewSQLJobStore(db, SQLiteDialect)
+
+	So(store.CreateSchema(), ShouldBeNil)
+
+	return db, store
+}
+
+func TestSQLJobStore(t *testing.T) {
+	Convey("Given a SQLJobStore over an in-memory SQLite database", t, func() {
+		db, store := openSQLiteJobStore()
+
+		defer db.Close()
+
+		So(store.CreateSchema(), ShouldBeNil)
+		So(store.SupportsPersistence(), ShouldBeTrue)
+		So(store.NumberOfJobs(), ShouldEqual, 0)
+
+		job := (&JobBuilder{}).
+			WithGroupIdentity("job", "reports").
+			WithDescription("daily report").
+			RequestRecovery(true).
+			UsingJobData("count", 1).
+			Build()
+
+		startTime := time.Date(2016, time.March, 1, 8, 0, 0, 0, time.UTC)
+
+		trigger := (&TriggerBuilder{}).
+			WithGroupIdentity("trigger", "reports").
+			ForJobDetail(job).
+			StartAt(startTime).
+			WithPriority(7).
+			UsingJobData("trigger", true).
+			WithSchedule(&SimpleScheduleBuilder{time.Minute, REPEAT_INDEFINITELY}).
+			Build().(OperableTrigger)
+		trigger.ComputeFirstFireTime(nil)
+
+		So(store.StoreJobAndTrigger(job, trigger), ShouldBeNil)
+
+		Convey("The job and the trigger should be retrieved", func() {
+			So(store.CheckJobExists(job.Key()), ShouldBeTrue)
+			So(store.CheckTriggerExists(trigger.Key()), ShouldBeTrue)
+			So(store.NumberOfJobs(), ShouldEqual, 1)
+			So(store.NumberOfTriggers(), ShouldEqual, 1)
+
+			stored, err := store.RetrieveJob(job.Key())
+
+			So(err, ShouldBeNil)
+			So(stored.Description(), ShouldEqual, "daily report")
+			So(stored.RequestsRecovery(), ShouldBeTrue)
+			So(stored.JobDataMap().Get("count"), ShouldEqual, 1)
+
+			retrieved, state, fireTime, err := store.RetrieveTriggerWithState(trigger.Key())
+
+			So(err, ShouldBeNil)
+			So(state, ShouldEqual, STATE_WAITING)
+			So(fireTime, ShouldEqual, startTime)
+			So(retrieved.Priority(), ShouldEqual, 7)
+			So(retrieved.JobDataMap().Get("trigger"), ShouldEqual, true)
+			So(store.TriggersForJob(job.Key()), ShouldHaveLength, 1)
+
+			missing, err := store.RetrieveJob(NewJobKey("missing"))
+
+			So(err, ShouldBeNil)
+			So(missing, ShouldBeNil)
+
+			keys, err := store.GetJobKeys(GroupEquals("reports"))
+
+			So(err, ShouldBeNil)
+			So(keys, ShouldResemble, []JobKey{job.Key()})
+
+			triggerKeys, err := store.GetTriggerKeys(GroupStartsWith("rep"))
+
+			So(err, ShouldBeNil)
+			So(triggerKeys, ShouldResemble, []TriggerKey{trigger.Key()})
+		})
+
+		Convey("The existing job or trigger should not be stored again", func() {
+			So(errors.Is(store.StoreJob(job, false), &ErrJobAlreadyExists{job.Key()}), ShouldBeTrue)
+			So(errors.Is(store.StoreTrigger(trigger, false), &ErrTriggerAlreadyExists{trigger.Key()}), ShouldBeTrue)
+			So(store.StoreJob(job, true), ShouldBeNil)
+			So(store.StoreTrigger(trigger, true), ShouldBeNil)
+			So(store.NumberOfTriggers(), ShouldEqual, 1)
+
+			orphan := (&TriggerBuilder{}).WithIdentity("orphan").ForJobKey(NewJobKey("missing")).StartNow().Build().(OperableTrigger)
+
+			So(errors.Is(store.StoreTrigger(orphan, false), &ErrUnableToResolveJob{}), ShouldBeTrue)
+		})
+
+		Convey("The jobs and triggers should be stored in a transaction", func() {
+			other := (&JobBuilder{}).WithIdentity("other").Build()
+			otherTrigger := (&TriggerBuilder{}).WithIdentity("other").ForJobDetail(other).StartNow().Build()
+
+			err := store.StoreJobsAndTriggers(map[JobDetail][]Trigger{
+				other: {otherTrigger, trigger},
+			}, false)
+
+			So(err, ShouldNotBeNil)
+			So(store.CheckJobExists(other.Key()), ShouldBeFalse)
+			So(store.CheckTriggerExists(otherTrigger.Key()), ShouldBeFalse)
+		})
+
+		Convey("The triggers in a paused group should be paused", func() {
+			groups, err := store.PauseTriggers(GroupEquals("reports"))
+
+			So(err, ShouldBeNil)
+			So(groups, ShouldResemble, []string{"reports"})
+
+			state, err := store.GetTriggerState(trigger.Key())
+
+			So(err, ShouldBeNil)
+			So(state, ShouldEqual, STATE_PAUSED)
+
+			later := (&TriggerBuilder{}).WithGroupIdentity("later", "reports").ForJobDetail(job).StartNow().Build().(OperableTrigger)
+
+			So(store.StoreTrigger(later, false), ShouldBeNil)
+
+			state, _ = store.GetTriggerState(later.Key())
+
+			So(state, ShouldEqual, STATE_PAUSED)
+
+			acquired, err := store.AcquireNextTriggers(startTime.Add(time.Hour), 10, 0)
+
+			So(err, ShouldBeNil)
+			So(acquired, ShouldBeEmpty)
+
+			groups, err = store.ResumeTriggers(GroupEquals("reports"))
+
+			So(err, ShouldBeNil)
+			So(groups, ShouldResemble, []string{"reports"})
+
+			state, _ = store.GetTriggerState(trigger.Key())
+
+			So(state, ShouldEqual, STATE_WAITING)
+		})
+
+		Convey("The triggers of a paused job group should be kept paused when their group is resumed", func() {
+			_, err := store.PauseJobs(GroupEquals("reports"))
+
+			So(err, ShouldBeNil)
+
+			_, err = store.PauseTriggers(GroupEquals("reports"))
+
+			So(err, ShouldBeNil)
+
+			_, err = store.ResumeTriggers(GroupEquals("reports"))
+
+			So(err, ShouldBeNil)
+
+			state, _ := store.GetTriggerState(trigger.Key())
+
+			So(state, ShouldEqual, STATE_PAUSED)
+
+			So(store.ResumeAll(), ShouldBeNil)
+
+			state, _ = store.GetTriggerState(trigger.Key())
+
+			So(state, ShouldEqual, STATE_WAITING)
+		})
+
+		Convey("The triggers should be acquired by their fire time and priority", func() {
+			urgent := (&TriggerBuilder{}).
+				WithIdentity("urgent").
+				ForJobDetail(job).
+				StartAt(startTime).
+				WithPriority(9).
+				Build().(OperableTrigger)
+			urgent.ComputeFirstFireTime(nil)
+
+			late := (&TriggerBuilder{}).
+				WithIdentity("late").
+				ForJobDetail(job).
+				StartAt(startTime.Add(time.Hour)).
+				Build().(OperableTrigger)
+			late.ComputeFirstFireTime(nil)
+
+			So(store.StoreTrigger(urgent, false), ShouldBeNil)
+			So(store.StoreTrigger(late, false), ShouldBeNil)
+
+			acquired, err := store.AcquireNextTriggers(startTime, 10, time.Second)
+
+			So(err, ShouldBeNil)
+			So(acquired, ShouldHaveLength, 2)
+			So(acquired[0].Key(), ShouldResemble, urgent.Key())
+			So(acquired[1].Key(), ShouldResemble, trigger.Key())
+
+			state, _ := store.GetTriggerState(trigger.Key())
+
+			So(state, ShouldEqual, STATE_ACQUIRED)
+
+			Convey("An acquired trigger should not be acquired again", func() {
+				acquired, err := NewSQLJobStore(db, SQLiteDialect).AcquireNextTriggers(startTime, 10, time.Second)
+
+				So(err, ShouldBeNil)
+				So(acquired, ShouldBeEmpty)
+
+				store.ReleaseAcquiredTrigger(urgent)
+
+				acquired, _ = store.AcquireNextTriggers(startTime, 10, time.Second)
+
+				So(acquired, ShouldHaveLength, 1)
+			})
+
+			Convey("The fired trigger should be updated for its next fire time", func() {
+				bundles, err := store.TriggersFired(acquired[1:])
+
+				So(err, ShouldBeNil)
+				So(bundles, ShouldHaveLength, 1)
+				So(bundles[0].JobDetail.Key(), ShouldResemble, job.Key())
+				So(bundles[0].ScheduledFireTime, ShouldEqual, startTime)
+				So(bundles[0].NextFireTime, ShouldEqual, startTime.Add(time.Minute))
+
+				retrieved, state, fireTime, _ := store.RetrieveTriggerWithState(trigger.Key())
+
+				So(state, ShouldEqual, STATE_WAITING)
+				So(fireTime, ShouldEqual, startTime.Add(time.Minute))
+				So(retrieved.PreviousFireTime(), ShouldEqual, startTime)
+
+				bundles, _ = store.TriggersFired(acquired[1:])
+
+				So(bundles, ShouldBeEmpty)
+
+				Convey("The trigger should be completed with the instruction", func() {
+					store.TriggeredJobComplete(retrieved, job, SET_TRIGGER_COMPLETE)
+
+					state, _ := store.GetTriggerState(trigger.Key())
+
+					So(state, ShouldEqual, STATE_COMPLETE)
+
+					store.TriggeredJobComplete(retrieved, job, DELETE_TRIGGER)
+
+					So(store.CheckTriggerExists(trigger.Key()), ShouldBeFalse)
+				})
+
+				Convey("The interrupted job should be recovered by the next scheduler", func() {
+					So(store.SchedulerStarted(), ShouldBeNil)
+
+					keys, err := store.GetTriggerKeys(GroupEquals(RECOVERING_JOBS_GROUP))
+
+					So(err, ShouldBeNil)
+					So(keys, ShouldHaveLength, 1)
+
+					recovery, _ := store.RetrieveTrigger(keys[0])
+
+					So(recovery.JobKey(), ShouldResemble, job.Key())
+					So(recovery.JobDataMap().Get("trigger"), ShouldEqual, true)
+					So(recovery.JobDataMap().Get(FAILED_JOB_ORIGINAL_TRIGGER_NAME), ShouldEqual, trigger.Key().Name())
+					So(recovery.JobDataMap().Get(FAILED_JOB_ORIGINAL_TRIGGER_GROUP), ShouldEqual, trigger.Key().Group())
+
+					scheduledFireTime, _ := recovery.JobDataMap().GetTime(FAILED_JOB_ORIGINAL_TRIGGER_SCHEDULED_FIRETIME)
+
+					So(scheduledFireTime.Equal(startTime), ShouldBeTrue)
+
+					state, _ := store.GetTriggerState(urgent.Key())
+
+					So(state, ShouldEqual, STATE_WAITING)
+
+					So(store.SchedulerStarted(), ShouldBeNil)
+
+					keys, _ = store.GetTriggerKeys(GroupEquals(RECOVERING_JOBS_GROUP))
+
+					So(keys, ShouldHaveLength, 1)
+				})
+
+				Convey("The completed job should not be recovered", func() {
+					store.TriggeredJobComplete(retrieved, job, NOOP)
+
+					So(store.SchedulerStarted(), ShouldBeNil)
+
+					keys, _ := store.GetTriggerKeys(GroupEquals(RECOVERING_JOBS_GROUP))
+
+					So(keys, ShouldBeEmpty)
+				})
+			})
+		})
+
+		Convey("The calendars should be shared by the stores over the database", func() {
+			monthly := NewMonthlyCalendar(nil)
+			monthly.SetLocation(time.UTC)
+			monthly.SetDayExcluded(1, true)
+
+			other := NewSQLJobStore(db, SQLiteDialect)
+
+			So(other.StoreCalendar("monthly", monthly, false), ShouldBeNil)
+			So(store.StoreCalendar("monthly", monthly, false), ShouldNotBeNil)
+			So(store.StoreCalendar("weekends", &weekendCalendar{}, false), ShouldNotBeNil)
+
+			names, err := store.GetCalendarNames()
+
+			So(err, ShouldBeNil)
+			So(names, ShouldResemble, []string{"monthly"})
+
+			cal, err := store.RetrieveCalendar("monthly")
+
+			So(err, ShouldBeNil)
+			So(cal.(*MonthlyCalendar).DaysExcluded(), ShouldResemble, []int{1})
+
+			cal, err = store.RetrieveCalendar("missing")
+
+			So(err, ShouldBeNil)
+			So(cal, ShouldBeNil)
+
+			Convey("The fired trigger should skip the days excluded by its calendar", func() {
+				trigger.SetCalendarName("monthly")
+
+				So(store.StoreTrigger(trigger, true), ShouldBeNil)
+
+				acquired, err := other.AcquireNextTriggers(startTime, 1, 0)
+
+				So(err, ShouldBeNil)
+				So(acquired, ShouldHaveLength, 1)
+
+				bundles, err := other.TriggersFired(acquired)
+
+				So(err, ShouldBeNil)
+				So(bundles, ShouldHaveLength, 1)
+				So(bundles[0].Calendar, ShouldNotBeNil)
+				So(bundles[0].NextFireTime, ShouldEqual, time.Date(2016, time.March, 2, 0, 0, 0, 0, time.UTC))
+
+				_, err = store.RemoveCalendar("monthly")
+
+				So(err, ShouldNotBeNil)
+			})
+
+			Convey("The removed calendar should not be retrieved anymore", func() {
+				found, err := store.RemoveCalendar("monthly")
+
+				So(err, ShouldBeNil)
+				So(found, ShouldBeTrue)
+
+				cal, _ := other.RetrieveCalendar("monthly")
+
+				So(cal, ShouldBeNil)
+			})
+		})
+
+		Convey("The error state of a trigger should be reset", func() {
+			store.TriggeredJobComplete(trigger, job, SET_TRIGGER_ERROR)
+
+			state, _ := store.GetTriggerState(trigger.Key())
+
+			So(state, ShouldEqual, STATE_ERROR)
+			So(store.ResetTriggerFromErrorState(trigger.Key()), ShouldBeNil)
+
+			state, _ = store.GetTriggerState(trigger.Key())
+
+			So(state, ShouldEqual, STATE_WAITING)
+		})
+
+		Convey("Removing the last trigger should remove the non-durable job", func() {
+			found, err := store.RemoveTrigger(trigger.Key())
+
+			So(err, ShouldBeNil)
+			So(found, ShouldBeTrue)
+			So(store.CheckJobExists(job.Key()), ShouldBeFalse)
+
+			found, _ = store.RemoveTrigger(trigger.Key())
+
+			So(found, ShouldBeFalse)
+		})
+
+		Convey("Removing the job should remove its triggers", func() {
+			found, err := store.RemoveJob(job.Key())
+
+			So(err, ShouldBeNil)
+			So(found, ShouldBeTrue)
+			So(store.NumberOfTriggers(), ShouldEqual, 0)
+		})
+
+		Convey("Clearing the data should remove everything", func() {
+			So(store.StoreCalendar("monthly", NewMonthlyCalendar(nil), false), ShouldBeNil)
+			So(store.ClearAllSchedulingData(), ShouldBeNil)
+			So(store.NumberOfJobs(), ShouldEqual, 0)
+			So(store.NumberOfTriggers(), ShouldEqual, 0)
+
+			names, _ := store.GetCalendarNames()
+
+			So(names, ShouldBeEmpty)
+		})
+	})
+}
+
+func TestSQLJobStoreBlockedJobs(t *testing.T) {
+	Convey("Given a SQLJobStore with two triggers of a job disallowing concurrent execution", t, func() {
+		db, store := openSQLiteJobStore()
+
+		defer db.Close()
+
+		job := (&JobBuilder{}).WithIdentity("job").DisallowConcurrentExecution(true).Build()
+
+		startTime := time.Date(2016, time.March, 1, 8, 0, 0, 0, time.UTC)
+
+		newTrigger := func(name string) OperableTrigger {
+			trigger := (&TriggerBuilder{}).
+				WithIdentity(name).
+				ForJobDetail(job).
+				StartAt(startTime).
+				WithSchedule(&SimpleScheduleBuilder{time.Minute, 1}).
+				Build().(OperableTrigger)
+			trigger.ComputeFirstFireTime(nil)
+
+			return trigger
+		}
+
+		first, second := newTrigger("first"), newTrigger("second")
+
+		So(store.StoreJobAndTrigger(job, first), ShouldBeNil)
+		So(store.StoreTrigger(second, false), ShouldBeNil)
+
+		Convey("Only one trigger of the job should be acquired in a batch", func() {
+			acquired, err := store.AcquireNextTriggers(startTime, 10, time.Second)
+
+			So(err, ShouldBeNil)
+			So(acquired, ShouldHaveLength, 1)
+			So(acquired[0].Key(), ShouldResemble, first.Key())
+
+			state, _ := store.GetTriggerState(second.Key())
+
+			So(state, ShouldEqual, STATE_WAITING)
+
+			Convey("When the trigger fired", func() {
+				bundles, err := store.TriggersFired(acquired)
+
+				So(err, ShouldBeNil)
+				So(bundles, ShouldHaveLength, 1)
+
+				Convey("The other trigger should be blocked until the job completed", func() {
+					state, _ := store.GetTriggerState(second.Key())
+
+					So(state, ShouldEqual, STATE_BLOCKED)
+
+					acquired, err := NewSQLJobStore(db, SQLiteDialect).AcquireNextTriggers(startTime.Add(time.Hour), 10, time.Second)
+
+					So(err, ShouldBeNil)
+					So(acquired, ShouldBeEmpty)
+
+					store.TriggeredJobComplete(bundles[0].Trigger, bundles[0].JobDetail, NOOP)
+
+					state, _ = store.GetTriggerState(second.Key())
+
+					So(state, ShouldEqual, STATE_WAITING)
+
+					acquired, err = store.AcquireNextTriggers(startTime, 10, time.Second)
+
+					So(err, ShouldBeNil)
+					So(acquired, ShouldHaveLength, 1)
+					So(acquired[0].Key(), ShouldResemble, second.Key())
+				})
+
+				Convey("The other trigger paused while blocked should be paused after the job completed", func() {
+					So(store.PauseTrigger(second.Key()), ShouldBeNil)
+
+					state, _ := store.GetTriggerState(second.Key())
+
+					So(state, ShouldEqual, STATE_PAUSED_BLOCKED)
+
+					store.TriggeredJobComplete(bundles[0].Trigger, bundles[0].JobDetail, NOOP)
+
+					state, _ = store.GetTriggerState(second.Key())
+
+					So(state, ShouldEqual, STATE_PAUSED)
+				})
+
+				Convey("The triggers of the interrupted job should be unblocked by the next scheduler", func() {
+					So(store.SchedulerStarted(), ShouldBeNil)
+
+					state, _ := store.GetTriggerState(second.Key())
+
+					So(state, ShouldEqual, STATE_WAITING)
+				})
+			})
+		})
+
+		Convey("The trigger acquired by another instance should be blocked when the job fired", func() {
+			acquired, _ := store.AcquireNextTriggers(startTime, 10, time.Second)
+			other, _ := NewSQLJobStore(db, SQLiteDialect).AcquireNextTriggers(startTime, 10, time.Second)
+
+			So(acquired, ShouldHaveLength, 1)
+			So(other, ShouldHaveLength, 1)
+
+			bundles, err := store.TriggersFired(append(acquired, other...))
+
+			So(err, ShouldBeNil)
+			So(bundles, ShouldHaveLength, 1)
+
+			state, _ := store.GetTriggerState(other[0].Key())
+
+			So(state, ShouldEqual, STATE_BLOCKED)
+		})
+	})
+
+	Convey("Given a started StdScheduler over a SQLJobStore with a job disallowing concurrent execution", t, func() {
+		db, store := openSQLiteJobStore()
+
+		defer db.Close()
+
+		scheduler := NewStdScheduler("scheduler", store, NewSimpleThreadPool(4))
+
+		defer scheduler.Shutdown()
+
+		So(scheduler.Start(), ShouldBeNil)
+
+		var running, maxRunning, executed int32
+
+		// the job is serialized by the store, so it is created from its registered type.
+		RegisterJobType("serialized", func() Job {
+			return JobFunc(func(context JobExecutionContext) error {
+				n := atomic.AddInt32(&running, 1)
+
+				for {
+					max := atomic.LoadInt32(&maxRunning)
+
+					if n <= max || atomic.CompareAndSwapInt32(&maxRunning, max, n) {
+						break
+					}
+				}
+
+				time.Sleep(50 * time.Millisecond)
+
+				atomic.AddInt32(&running, -1)
+				atomic.AddInt32(&executed, 1)
+
+				return nil
+			})
+		})
+
+		job := (&JobBuilder{}).
+			WithIdentity("job").
+			OfType("serialized").
+			StoreDurably(true).
+			DisallowConcurrentExecution(true).
+			Build()
+
+		So(scheduler.AddJob(job, false), ShouldBeNil)
+
+		startTime := time.Now().Add(50 * time.Millisecond)
+
+		for _, name := range []string{"first", "second"} {
+			_, err := scheduler.Schedule((&TriggerBuilder{}).WithIdentity(name).ForJobDetail(job).StartAt(startTime).Build())
+
+			So(err, ShouldBeNil)
+		}
+
+		Convey("The triggers should run one at a time", func() {
+			So(waitFor(2*time.Second, func() bool { return atomic.LoadInt32(&executed) == 2 }), ShouldBeTrue)
+			So(atomic.LoadInt32(&maxRunning), ShouldEqual, 1)
+		})
+	})
+}
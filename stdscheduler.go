@@ -0,0 +1,1570 @@
+package quartz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrSchedulerShutdown is returned when operating a Scheduler which has been shutdown.
+var ErrSchedulerShutdown = errors.New("scheduler has been shutdown")
+
+// ErrNotImplemented is returned by the Scheduler operations which are not supported yet.
+var ErrNotImplemented = errors.New("not implemented")
+
+func notImplementedError(op string) error {
+	return fmt.Errorf("The operation %s is %w.", op, ErrNotImplemented)
+}
+
+func calendarNotFoundError(name string) error {
+	return fmt.Errorf("The calendar (%s) does not exist.", name)
+}
+
+func noJobFactoryError(key JobKey) error {
+	return fmt.Errorf("No JobFactory is able to create the job (%s).", key.String())
+}
+
+// StdScheduler fires the triggers of a JobStore and executes their jobs.
+//
+// It is in standby mode until Start is called, and can't be restarted after Shutdown.
+type StdScheduler struct {
+	name             string
+	root             context.Context
+	cancel           context.CancelFunc
+	store            JobStore
+	threadPool       ThreadPool
+	context          SchedulerContext
+	listeners        *listenerManager
+	stats            *jobStatsCollector
+	events           *eventBus
+	metrics          MetricsCollector
+	tracer           Tracer
+	logger           Logger
+	clock            Clock
+	idleWaitTime     time.Duration
+	misfireThreshold time.Duration
+	maxBatchSize     int
+	batchTimeWindow  time.Duration
+	saturation       SaturationPolicy
+
+	numJobsExecuted int32
+
+	lock         sync.Mutex
+	jobFactory   JobFactory
+	executing    map[*jobExecutionContext]struct{}
+	idempotency  map[manualFireKey]time.Time
+	inflight     int
+	queued       []func()
+	started      bool
+	runningSince time.Time
+	standby      bool
+	shutdown     bool
+	signal       chan struct{}
+	halt         chan struct{}
+	loop         sync.WaitGroup
+}
+
+func NewStdScheduler(name string, store JobStore, threadPool ThreadPool) *StdScheduler {
+	root, cancel := context.WithCancel(context.Background())
+
+	return &StdScheduler{
+		name:             name,
+		root:             root,
+		cancel:           cancel,
+		store:            store,
+		threadPool:       threadPool,
+		context:          NewSchedulerContext(),
+		listeners:        newListenerManager(),
+		stats:            newJobStatsCollector(),
+		events:           newEventBus(DefaultEventBufferSize),
+		metrics:          NoopMetricsCollector{},
+		tracer:           NoopTracer{},
+		logger:           NoopLogger{},
+		clock:            SystemClock,
+		jobFactory:       &DefaultJobFactory{},
+		executing:        make(map[*jobExecutionContext]struct{}),
+		idempotency:      make(map[manualFireKey]time.Time),
+		idleWaitTime:     DefaultIdleWaitTime,
+		misfireThreshold: DefaultMisfireThreshold,
+		maxBatchSize:     DefaultMaxBatchSize,
+		standby:          true,
+		signal:           make(chan struct{}, 1),
+		halt:             make(chan struct{}),
+	}
+}
+
+func (s *StdScheduler) Name() string { return s.name }
+
+// Context returns the SchedulerContext shared by the jobs, which reach it through JobExecutionContext.Scheduler().
+func (s *StdScheduler) Context() SchedulerContext { return s.context }
+
+// Start starts the scheduling loop, or resumes it from the standby mode.
+func (s *StdScheduler) Start() error {
+	if s.IsShutdown() {
+		return ErrSchedulerShutdown
+	}
+
+	s.listeners.notifySchedulerListeners(func(l SchedulerListener) { l.SchedulerStarting() })
+
+	s.lock.Lock()
+	err := s.start()
+	s.lock.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info("Scheduler started.", "scheduler", s.name)
+
+	s.listeners.notifySchedulerListeners(func(l SchedulerListener) { l.SchedulerStarted() })
+
+	return nil
+}
+
+func (s *StdScheduler) start() error {
+	if s.shutdown {
+		return ErrSchedulerShutdown
+	}
+
+	if !s.started {
+		if err := s.threadPool.Initialize(); err != nil {
+			return err
+		}
+
+		if err := s.store.SchedulerStarted(); err != nil {
+			return err
+		}
+
+		s.started = true
+		s.runningSince = s.clock.Now()
+
+		s.loop.Add(1)
+
+		go s.run()
+	} else if s.standby {
+		s.store.SchedulerResumed()
+	}
+
+	s.standby = false
+
+	s.signalSchedulingChange()
+
+	return nil
+}
+
+// StartDelayed calls Start after the delay on the Clock in the background, the pending start is canceled by Shutdown.
+func (s *StdScheduler) StartDelayed(delay time.Duration) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.shutdown {
+		return ErrSchedulerShutdown
+	}
+
+	timer := s.clock.NewTimer(delay)
+
+	s.loop.Add(1)
+
+	go func() {
+		defer s.loop.Done()
+		defer timer.Stop()
+
+		select {
+		case <-timer.C():
+			if err := s.Start(); err != nil && !errors.Is(err, ErrSchedulerShutdown) {
+				s.notifySchedulerError("Couldn't delay start scheduler.", err)
+			}
+
+		case <-s.halt:
+		}
+	}()
+
+	return nil
+}
+
+func (s *StdScheduler) Started() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.started && !s.shutdown
+}
+
+// Standby temporarily halts the firing of triggers, the scheduler can be restarted with Start.
+func (s *StdScheduler) Standby() error {
+	s.lock.Lock()
+
+	if s.shutdown {
+		s.lock.Unlock()
+
+		return ErrSchedulerShutdown
+	}
+
+	if s.standby {
+		s.lock.Unlock()
+
+		return nil
+	}
+
+	s.standby = true
+
+	s.store.SchedulerPaused()
+
+	s.signalSchedulingChange()
+
+	s.lock.Unlock()
+
+	s.listeners.notifySchedulerListeners(func(l SchedulerListener) { l.SchedulerInStandbyMode() })
+
+	return nil
+}
+
+func (s *StdScheduler) InStandbyMode() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.standby
+}
+
+// Shutdown halts the firing of triggers without waiting for the executing jobs.
+func (s *StdScheduler) Shutdown() error { return s.ShutdownAndWait(false) }
+
+// ShutdownAndWait halts the firing of triggers, and waits for the executing jobs if waitForJobsToComplete.
+func (s *StdScheduler) ShutdownAndWait(waitForJobsToComplete bool) error {
+	s.lock.Lock()
+
+	if s.shutdown {
+		s.lock.Unlock()
+
+		return nil
+	}
+
+	s.shutdown = true
+	s.standby = true
+
+	close(s.halt)
+
+	s.lock.Unlock()
+
+	s.logger.Info("Scheduler shutting down.", "scheduler", s.name, "waitForJobsToComplete", waitForJobsToComplete)
+
+	// the executing jobs are canceled before waiting for them.
+	s.cancel()
+
+	s.listeners.notifySchedulerListeners(func(l SchedulerListener) { l.SchedulerShuttingdown() })
+
+	s.threadPool.Shutdown(waitForJobsToComplete)
+
+	s.loop.Wait()
+
+	s.store.Shutdown()
+
+	schedulerRepository.unbind(s)
+
+	s.logger.Info("Scheduler shutdown complete.", "scheduler", s.name)
+
+	s.listeners.notifySchedulerListeners(func(l SchedulerListener) { l.SchedulerShutdown() })
+
+	s.events.close(SchedulerEvent{Type: EventShutdown, Time: s.clock.Now()})
+
+	return nil
+}
+
+func (s *StdScheduler) IsShutdown() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.shutdown
+}
+
+// MetaData returns a snapshot of the scheduler's settings and state.
+func (s *StdScheduler) MetaData() SchedulerMetaData {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return &schedulerMetaData{
+		schedulerName:        s.name,
+		started:              s.started && !s.shutdown,
+		inStandbyMode:        s.standby,
+		shutdown:             s.shutdown,
+		jobStoreClass:        fmt.Sprintf("%T", s.store),
+		threadPoolSize:       s.threadPool.PoolSize(),
+		numberOfJobsExecuted: int(atomic.LoadInt32(&s.numJobsExecuted)),
+		runningSince:         s.runningSince,
+	}
+}
+
+// CurrentlyExecutingJob returns a snapshot of the executing jobs, ordered by their fire times.
+func (s *StdScheduler) CurrentlyExecutingJob() ([]JobExecutionContext, error) {
+	s.lock.Lock()
+
+	executing := make([]JobExecutionContext, 0, len(s.executing))
+
+	for context := range s.executing {
+		executing = append(executing, context)
+	}
+
+	s.lock.Unlock()
+
+	sort.SliceStable(executing, func(i, j int) bool {
+		return executing[i].FireTime().Before(executing[j].FireTime())
+	})
+
+	return executing, nil
+}
+
+func (s *StdScheduler) jobStarted(context *jobExecutionContext) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.executing[context] = struct{}{}
+}
+
+func (s *StdScheduler) jobCompleted(context *jobExecutionContext) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.executing, context)
+}
+
+func (s *StdScheduler) ListenerManager() ListenerManager { return s.listeners }
+
+// Events returns the channel of the SchedulerEvents, which is shared by all the consumers.
+//
+// The channel is buffered with DefaultEventBufferSize events and never blocks the scheduler,
+// the events are dropped when the consumer is slow and the buffer is full, see DroppedEvents.
+// The channel is closed after EventShutdown, which is always delivered.
+func (s *StdScheduler) Events() <-chan SchedulerEvent { return s.events.events }
+
+// DroppedEvents returns the number of the SchedulerEvents dropped because the buffer of the channel was full.
+func (s *StdScheduler) DroppedEvents() int64 { return s.events.droppedEvents() }
+
+func (s *StdScheduler) publishEvent(typ SchedulerEventType, trigger Trigger, fireTime time.Time, err error) {
+	s.events.publish(SchedulerEvent{
+		Type:       typ,
+		Time:       s.clock.Now(),
+		JobKey:     trigger.JobKey(),
+		TriggerKey: trigger.Key(),
+		FireTime:   fireTime,
+		Err:        err,
+	})
+}
+
+// SetClock sets the Clock of the scheduling loop, and of the JobStore if it has one,
+// it must be called before the scheduler is started.
+func (s *StdScheduler) SetClock(clock Clock) {
+	s.clock = clock
+
+	// the fire times and the batches of the store agree with the scheduling loop.
+	if store, ok := s.store.(interface{ SetClock(clock Clock) }); ok {
+		store.SetClock(clock)
+	}
+}
+
+// SetMetricsCollector sets the MetricsCollector notified by the scheduler, it must be called before the scheduler is started.
+func (s *StdScheduler) SetMetricsCollector(metrics MetricsCollector) {
+	if metrics == nil {
+		metrics = NoopMetricsCollector{}
+	}
+
+	s.metrics = metrics
+}
+
+// SetTracer sets the Tracer wrapping the executions of the jobs, it must be called before the scheduler is started.
+func (s *StdScheduler) SetTracer(tracer Tracer) {
+	if tracer == nil {
+		tracer = NoopTracer{}
+	}
+
+	s.tracer = tracer
+}
+
+// SetLogger sets the Logger of the scheduler, it must be called before the scheduler is started.
+func (s *StdScheduler) SetLogger(logger Logger) {
+	if logger == nil {
+		logger = NoopLogger{}
+	}
+
+	s.logger = logger
+	s.listeners.setLogger(logger)
+}
+
+// SetSaturationPolicy sets what the scheduling loop does when the workers of the ThreadPool are busy,
+// it must be called before the scheduler is started.
+func (s *StdScheduler) SetSaturationPolicy(policy SaturationPolicy) error {
+	if err := policy.validate(); err != nil {
+		return err
+	}
+
+	s.saturation = policy
+
+	return nil
+}
+
+// SetJobFactory sets the JobFactory used for the jobs which don't have their own.
+func (s *StdScheduler) SetJobFactory(factory JobFactory) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.jobFactory = factory
+}
+
+func (s *StdScheduler) ScheduleJob(jobDetail JobDetail, trigger Trigger) (time.Time, error) {
+	if trigger.JobKey() != nil && !trigger.JobKey().Equals(jobDetail.Key()) {
+		return zero, errors.New("Trigger does not reference given job!")
+	}
+
+	ot, err := s.prepareTrigger(trigger, jobDetail.Key())
+
+	if err != nil {
+		return zero, err
+	}
+
+	// the stored trigger belongs to the JobStore, which may fire it at once.
+	scheduled := ot.Clone().(Trigger)
+
+	if err := s.store.StoreJobAndTrigger(jobDetail, ot); err != nil {
+		return zero, err
+	}
+
+	s.signalSchedulingChange()
+
+	s.listeners.notifySchedulerListeners(func(l SchedulerListener) {
+		l.JobAdded(jobDetail)
+		l.JobScheduled(scheduled)
+	})
+
+	s.publishEvent(EventJobScheduled, scheduled, zero, nil)
+
+	return scheduled.NextFireTime(), nil
+}
+
+// Schedule the trigger with the job identified by the trigger's JobKey.
+func (s *StdScheduler) Schedule(trigger Trigger) (time.Time, error) {
+	if trigger.JobKey() == nil {
+		return zero, errors.New("Trigger's related Job's name cannot be null")
+	}
+
+	ot, err := s.prepareTrigger(trigger, trigger.JobKey())
+
+	if err != nil {
+		return zero, err
+	}
+
+	// the stored trigger belongs to the JobStore, which may fire it at once.
+	scheduled := ot.Clone().(Trigger)
+
+	if err := s.store.StoreTrigger(ot, false); err != nil {
+		return zero, err
+	}
+
+	s.signalSchedulingChange()
+
+	s.listeners.notifySchedulerListeners(func(l SchedulerListener) { l.JobScheduled(scheduled) })
+
+	s.publishEvent(EventJobScheduled, scheduled, zero, nil)
+
+	return scheduled.NextFireTime(), nil
+}
+
+// prepareTrigger returns a copy of the trigger for the job with its first fire time.
+func (s *StdScheduler) prepareTrigger(trigger Trigger, jobKey JobKey) (OperableTrigger, error) {
+	if s.IsShutdown() {
+		return nil, ErrSchedulerShutdown
+	}
+
+	ot, ok := trigger.(OperableTrigger)
+
+	if !ok {
+		return nil, fmt.Errorf("The trigger (%s) is not an OperableTrigger.", trigger.Key())
+	}
+
+	ot = ot.Clone().(OperableTrigger)
+
+	if ot.JobKey() == nil {
+		ot.SetJobKey(jobKey)
+	}
+
+	if err := ot.Validate(); err != nil {
+		return nil, err
+	}
+
+	cal, err := s.calendarOf(ot)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if ot.ComputeFirstFireTime(cal).IsZero() {
+		return nil, errors.New("Based on configured schedule, the given trigger will never fire.")
+	}
+
+	return ot, nil
+}
+
+func (s *StdScheduler) ScheduleJobs(triggersAndJobs map[JobDetail][]Trigger, replace bool) (time.Time, error) {
+	return zero, notImplementedError("ScheduleJobs")
+}
+
+func (s *StdScheduler) UnscheduleJob(key TriggerKey) (bool, error) {
+	found, err := s.store.RemoveTrigger(key)
+
+	if found {
+		s.signalSchedulingChange()
+
+		s.listeners.notifySchedulerListeners(func(l SchedulerListener) { l.JobUnscheduled(key) })
+	}
+
+	return found, err
+}
+
+// UnscheduleJobs removes the triggers, only the existing ones are notified as unscheduled.
+func (s *StdScheduler) UnscheduleJobs(keys []TriggerKey) (bool, error) {
+	var unscheduled []TriggerKey
+
+	for _, key := range keys {
+		if s.store.CheckTriggerExists(key) {
+			unscheduled = append(unscheduled, key)
+		}
+	}
+
+	found, err := s.store.RemoveTriggers(keys)
+
+	if err != nil {
+		return found, err
+	}
+
+	s.signalSchedulingChange()
+
+	s.listeners.notifySchedulerListeners(func(l SchedulerListener) {
+		for _, key := range unscheduled {
+			l.JobUnscheduled(key)
+		}
+	})
+
+	return found, nil
+}
+
+// RescheduleJob replaces the trigger with the new one for the same job,
+// it returns the zero time without error if the trigger does not exist.
+func (s *StdScheduler) RescheduleJob(key TriggerKey, trigger Trigger) (time.Time, error) {
+	old, err := s.store.RetrieveTrigger(key)
+
+	if err != nil || old == nil {
+		return zero, err
+	}
+
+	ot, err := s.prepareTrigger(trigger, old.JobKey())
+
+	if err != nil {
+		return zero, err
+	}
+
+	// the stored trigger belongs to the JobStore, which may fire it at once.
+	scheduled := ot.Clone().(Trigger)
+
+	if err := s.store.ReplaceTrigger(key, ot); err != nil {
+		return zero, err
+	}
+
+	s.signalSchedulingChange()
+
+	s.listeners.notifySchedulerListeners(func(l SchedulerListener) {
+		l.JobUnscheduled(key)
+		l.JobScheduled(scheduled)
+	})
+
+	s.publishEvent(EventJobScheduled, scheduled, zero, nil)
+
+	return scheduled.NextFireTime(), nil
+}
+
+// AddJob stores the job without trigger, the job must be durable unless it replaces a scheduled one.
+func (s *StdScheduler) AddJob(jobDetail JobDetail, replace bool) error {
+	if s.IsShutdown() {
+		return ErrSchedulerShutdown
+	}
+
+	if !jobDetail.Durable() && (!replace || len(s.store.TriggersForJob(jobDetail.Key())) == 0) {
+		return errors.New("Jobs added with no trigger must be durable.")
+	}
+
+	if err := s.store.StoreJob(jobDetail, replace); err != nil {
+		return err
+	}
+
+	s.listeners.notifySchedulerListeners(func(l SchedulerListener) { l.JobAdded(jobDetail) })
+
+	return nil
+}
+
+// DeleteJob removes the job and its triggers, the executing job is left to complete.
+func (s *StdScheduler) DeleteJob(key JobKey) (bool, error) {
+	var unscheduled []TriggerKey
+
+	for _, trigger := range s.store.TriggersForJob(key) {
+		unscheduled = append(unscheduled, trigger.Key())
+	}
+
+	found, err := s.store.RemoveJob(key)
+
+	if err != nil {
+		return found, err
+	}
+
+	s.signalSchedulingChange()
+
+	s.listeners.notifySchedulerListeners(func(l SchedulerListener) {
+		for _, key := range unscheduled {
+			l.JobUnscheduled(key)
+		}
+
+		if found {
+			l.JobDeleted(key)
+		}
+	})
+
+	return found, nil
+}
+
+// DeleteJobs removes the jobs and their triggers, only the existing ones are notified as deleted.
+func (s *StdScheduler) DeleteJobs(keys []JobKey) (bool, error) {
+	var deleted []JobKey
+	var unscheduled []TriggerKey
+
+	for _, key := range keys {
+		if !s.store.CheckJobExists(key) {
+			continue
+		}
+
+		deleted = append(deleted, key)
+
+		for _, trigger := range s.store.TriggersForJob(key) {
+			unscheduled = append(unscheduled, trigger.Key())
+		}
+	}
+
+	found, err := s.store.RemoveJobs(keys)
+
+	if err != nil {
+		return found, err
+	}
+
+	s.signalSchedulingChange()
+
+	s.listeners.notifySchedulerListeners(func(l SchedulerListener) {
+		for _, key := range unscheduled {
+			l.JobUnscheduled(key)
+		}
+
+		for _, key := range deleted {
+			l.JobDeleted(key)
+		}
+	})
+
+	return found, nil
+}
+
+func (s *StdScheduler) TriggerJob(key JobKey) error {
+	return s.TriggerJobWithData(key, nil)
+}
+
+func (s *StdScheduler) TriggerJobWithData(key JobKey, data JobDataMap) error {
+	_, err := s.Schedule(s.oneShotTrigger(key, data))
+
+	return err
+}
+
+// oneShotTrigger builds the trigger firing the job now, the data overlays the JobDataMap of the job.
+func (s *StdScheduler) oneShotTrigger(key JobKey, data JobDataMap) Trigger {
+	builder := (&TriggerBuilder{}).WithClock(s.clock).ForJobKey(key).StartNow()
+
+	if data != nil {
+		builder.UsingJobDataMap(data)
+	}
+
+	return builder.Build()
+}
+
+// TriggerJobs triggers the existing jobs now, their one-shot triggers are stored at once and the scheduling loop
+// is signaled once, the errors of the jobs which couldn't be triggered are joined.
+func (s *StdScheduler) TriggerJobs(keys []JobKey) error {
+	var errs []error
+	var triggers []OperableTrigger
+
+	for _, key := range keys {
+		if !s.store.CheckJobExists(key) {
+			errs = append(errs, jobNotFoundError(key))
+
+			continue
+		}
+
+		ot, err := s.prepareTrigger(s.oneShotTrigger(key, nil), key)
+
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		triggers = append(triggers, ot)
+	}
+
+	if len(triggers) == 0 {
+		return errors.Join(errs...)
+	}
+
+	// the stored triggers belong to the JobStore, which may fire them at once.
+	scheduled := make([]Trigger, len(triggers))
+
+	for i, trigger := range triggers {
+		scheduled[i] = trigger.Clone().(Trigger)
+	}
+
+	if err := s.store.StoreTriggers(triggers, false); err != nil {
+		return errors.Join(append(errs, err)...)
+	}
+
+	s.signalSchedulingChange()
+
+	s.listeners.notifySchedulerListeners(func(l SchedulerListener) {
+		for _, trigger := range scheduled {
+			l.JobScheduled(trigger)
+		}
+	})
+
+	for _, trigger := range scheduled {
+		s.publishEvent(EventJobScheduled, trigger, zero, nil)
+	}
+
+	return errors.Join(errs...)
+}
+
+// manualFireKey identifies a manual fire of a job by its idempotency key.
+type manualFireKey struct {
+	jobKey string
+	key    string
+}
+
+// TriggerJobIdempotent triggers the job now like TriggerJob, unless it was triggered with the same idempotency key
+// within the ttl, in which case it does nothing, the idempotency key is forgotten if the job couldn't be triggered.
+func (s *StdScheduler) TriggerJobIdempotent(key JobKey, idempotencyKey string, ttl time.Duration) error {
+	if !s.reserveIdempotencyKey(key, idempotencyKey, ttl) {
+		return nil
+	}
+
+	if err := s.TriggerJob(key); err != nil {
+		s.lock.Lock()
+		delete(s.idempotency, manualFireKeyOf(key, idempotencyKey))
+		s.lock.Unlock()
+
+		return err
+	}
+
+	return nil
+}
+
+func manualFireKeyOf(key JobKey, idempotencyKey string) manualFireKey {
+	return manualFireKey{key.String(), idempotencyKey}
+}
+
+// reserveIdempotencyKey records the idempotency key until the ttl expired, and returns false if it was recorded,
+// the expired idempotency keys are pruned.
+func (s *StdScheduler) reserveIdempotencyKey(key JobKey, idempotencyKey string, ttl time.Duration) bool {
+	now := s.clock.Now()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for k, expiry := range s.idempotency {
+		if !now.Before(expiry) {
+			delete(s.idempotency, k)
+		}
+	}
+
+	k := manualFireKeyOf(key, idempotencyKey)
+
+	if _, exists := s.idempotency[k]; exists {
+		return false
+	}
+
+	s.idempotency[k] = now.Add(ttl)
+
+	return true
+}
+
+func (s *StdScheduler) PauseJob(key JobKey) error {
+	defer s.signalSchedulingChange()
+
+	if err := s.store.PauseJob(key); err != nil {
+		return err
+	}
+
+	s.listeners.notifySchedulerListeners(func(l SchedulerListener) { l.JobPaused(key) })
+
+	return nil
+}
+
+func (s *StdScheduler) PauseTrigger(key TriggerKey) error {
+	defer s.signalSchedulingChange()
+
+	if err := s.store.PauseTrigger(key); err != nil {
+		return err
+	}
+
+	s.listeners.notifySchedulerListeners(func(l SchedulerListener) { l.TriggerPaused(key) })
+
+	return nil
+}
+
+func (s *StdScheduler) ResumeJob(key JobKey) error {
+	defer s.signalSchedulingChange()
+
+	if err := s.store.ResumeJob(key); err != nil {
+		return err
+	}
+
+	s.listeners.notifySchedulerListeners(func(l SchedulerListener) { l.JobResumed(key) })
+
+	return nil
+}
+
+func (s *StdScheduler) ResumeTrigger(key TriggerKey) error {
+	defer s.signalSchedulingChange()
+
+	if err := s.store.ResumeTrigger(key); err != nil {
+		return err
+	}
+
+	s.listeners.notifySchedulerListeners(func(l SchedulerListener) { l.TriggerResumed(key) })
+
+	return nil
+}
+
+func (s *StdScheduler) PauseTriggers(matcher GroupMatcher) error {
+	defer s.signalSchedulingChange()
+
+	groups, err := s.store.PauseTriggers(matcher)
+
+	if err != nil {
+		return err
+	}
+
+	s.listeners.notifySchedulerListeners(func(l SchedulerListener) {
+		for _, group := range groups {
+			l.TriggersPaused(group)
+		}
+	})
+
+	return nil
+}
+
+func (s *StdScheduler) ResumeTriggers(matcher GroupMatcher) error {
+	defer s.signalSchedulingChange()
+
+	groups, err := s.store.ResumeTriggers(matcher)
+
+	if err != nil {
+		return err
+	}
+
+	s.listeners.notifySchedulerListeners(func(l SchedulerListener) {
+		for _, group := range groups {
+			l.TriggersResumed(group)
+		}
+	})
+
+	return nil
+}
+
+func (s *StdScheduler) PauseJobs(matcher GroupMatcher) error {
+	defer s.signalSchedulingChange()
+
+	groups, err := s.store.PauseJobs(matcher)
+
+	if err != nil {
+		return err
+	}
+
+	s.listeners.notifySchedulerListeners(func(l SchedulerListener) {
+		for _, group := range groups {
+			l.JobsPaused(group)
+		}
+	})
+
+	return nil
+}
+
+func (s *StdScheduler) ResumeJobs(matcher GroupMatcher) error {
+	defer s.signalSchedulingChange()
+
+	groups, err := s.store.ResumeJobs(matcher)
+
+	if err != nil {
+		return err
+	}
+
+	s.listeners.notifySchedulerListeners(func(l SchedulerListener) {
+		for _, group := range groups {
+			l.JobsResumed(group)
+		}
+	})
+
+	return nil
+}
+
+func (s *StdScheduler) PauseAll() error {
+	defer s.signalSchedulingChange()
+
+	if err := s.store.PauseAll(); err != nil {
+		return err
+	}
+
+	s.listeners.notifySchedulerListeners(func(l SchedulerListener) { l.TriggersPaused("") })
+
+	return nil
+}
+
+func (s *StdScheduler) ResumeAll() error {
+	defer s.signalSchedulingChange()
+
+	if err := s.store.ResumeAll(); err != nil {
+		return err
+	}
+
+	s.listeners.notifySchedulerListeners(func(l SchedulerListener) { l.TriggersResumed("") })
+
+	return nil
+}
+
+func (s *StdScheduler) GetTriggersOfJob(key JobKey) (triggers []Trigger) {
+	for _, trigger := range s.store.TriggersForJob(key) {
+		triggers = append(triggers, trigger)
+	}
+
+	return
+}
+
+func (s *StdScheduler) GetJobDetail(key JobKey) JobDetail {
+	jobDetail, _ := s.store.RetrieveJob(key)
+
+	return jobDetail
+}
+
+func (s *StdScheduler) GetTrigger(key TriggerKey) Trigger {
+	trigger, _ := s.store.RetrieveTrigger(key)
+
+	return trigger
+}
+
+func (s *StdScheduler) GetTriggerWithState(key TriggerKey) (Trigger, TriggerState, time.Time, error) {
+	return s.store.RetrieveTriggerWithState(key)
+}
+
+func (s *StdScheduler) GetTriggerState(key TriggerKey) (TriggerState, error) {
+	return s.store.GetTriggerState(key)
+}
+
+// GetNextFireTimes computes the next fire times on a copy of the stored trigger, the completed trigger has none.
+func (s *StdScheduler) GetNextFireTimes(key TriggerKey, n int) ([]time.Time, error) {
+	trigger, state, _, err := s.store.RetrieveTriggerWithState(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if state == STATE_COMPLETE {
+		return nil, nil
+	}
+
+	cal, err := s.calendarOf(trigger)
+
+	if err != nil {
+		return nil, err
+	}
+
+	preview := trigger.Clone().(OperableTrigger)
+
+	var fireTimes []time.Time
+
+	for fireTime := preview.NextFireTime(); !fireTime.IsZero() && len(fireTimes) < n; fireTime = preview.NextFireTime() {
+		fireTimes = append(fireTimes, fireTime)
+
+		preview.Triggered(cal)
+	}
+
+	return fireTimes, nil
+}
+
+// GetJobStats returns the statistics of the executions of the job, they are kept after the job was removed,
+// until the scheduling data is cleared.
+func (s *StdScheduler) GetJobStats(key JobKey) (JobStats, error) {
+	if stats, exists := s.stats.get(key); exists {
+		return stats, nil
+	}
+
+	if !s.CheckJobExists(key) {
+		return JobStats{}, jobNotFoundError(key)
+	}
+
+	return JobStats{}, nil
+}
+
+func (s *StdScheduler) ResetTriggerFromErrorState(key TriggerKey) error {
+	defer s.signalSchedulingChange()
+
+	return s.store.ResetTriggerFromErrorState(key)
+}
+
+func (s *StdScheduler) GetJobKeys(matcher GroupMatcher) ([]JobKey, error) {
+	return s.store.GetJobKeys(matcher)
+}
+
+func (s *StdScheduler) GetTriggerKeys(matcher GroupMatcher) ([]TriggerKey, error) {
+	return s.store.GetTriggerKeys(matcher)
+}
+
+func (s *StdScheduler) CheckJobExists(key JobKey) bool { return s.store.CheckJobExists(key) }
+
+func (s *StdScheduler) CheckTriggerExists(key TriggerKey) bool {
+	return s.store.CheckTriggerExists(key)
+}
+
+func (s *StdScheduler) AddCalendar(name string, calendar Calendar, replace bool) error {
+	return s.store.StoreCalendar(name, calendar, replace)
+}
+
+func (s *StdScheduler) DeleteCalendar(name string) (bool, error) {
+	return s.store.RemoveCalendar(name)
+}
+
+func (s *StdScheduler) GetCalendar(name string) (Calendar, error) {
+	return s.store.RetrieveCalendar(name)
+}
+
+func (s *StdScheduler) GetCalendarNames() ([]string, error) {
+	return s.store.GetCalendarNames()
+}
+
+// calendarOf returns the stored calendar used by the trigger, or nil if it uses none.
+func (s *StdScheduler) calendarOf(trigger Trigger) (Calendar, error) {
+	if trigger.CalendarName() == "" {
+		return nil, nil
+	}
+
+	cal, err := s.store.RetrieveCalendar(trigger.CalendarName())
+
+	if err != nil {
+		return nil, err
+	}
+
+	if cal == nil {
+		return nil, calendarNotFoundError(trigger.CalendarName())
+	}
+
+	return cal, nil
+}
+
+// Clear removes all the jobs, triggers and calendars, the executing jobs are left to complete.
+func (s *StdScheduler) Clear() error {
+	if err := s.store.ClearAllSchedulingData(); err != nil {
+		return err
+	}
+
+	s.stats.clear()
+
+	s.signalSchedulingChange()
+
+	s.listeners.notifySchedulerListeners(func(l SchedulerListener) { l.SchedulingDataCleared() })
+
+	return nil
+}
+
+// signalSchedulingChange wakes up the scheduling loop to acquire the triggers again.
+func (s *StdScheduler) signalSchedulingChange() {
+	select {
+	case s.signal <- struct{}{}:
+	default:
+	}
+}
+
+// run is the scheduling loop, it acquires the next batch of triggers, waits until the first fire time and fires them.
+func (s *StdScheduler) run() {
+	defer s.loop.Done()
+
+	for {
+		if !s.waitUntilRunning() {
+			return
+		}
+
+		maxCount := s.availableWorkers()
+
+		if maxCount == 0 {
+			continue
+		}
+
+		if maxCount > s.maxBatchSize {
+			maxCount = s.maxBatchSize
+		}
+
+		triggers, err := s.store.AcquireNextTriggers(s.clock.Now().Add(s.idleWaitTime), maxCount, s.batchTimeWindow)
+
+		if err != nil {
+			s.logger.Error("Unable to acquire the next triggers.", "error", err)
+		} else {
+			s.logger.Debug("Acquired the next triggers.", "count", len(triggers))
+			s.metrics.TriggersAcquired(len(triggers))
+		}
+
+		if err != nil || len(triggers) == 0 {
+			if _, halted := s.sleep(s.idleWaitTime); halted {
+				return
+			}
+
+			continue
+		}
+
+		// the misfired triggers are stored again with their updated fire time, and acquired again.
+		if triggers = s.applyMisfires(triggers); len(triggers) == 0 {
+			continue
+		}
+
+		if d := triggers[0].NextFireTime().Sub(s.clock.Now()); d > 0 {
+			if timeout, halted := s.sleep(d); !timeout {
+				s.releaseAcquiredTriggers(triggers)
+
+				if halted {
+					return
+				}
+
+				continue
+			}
+		}
+
+		if s.InStandbyMode() {
+			s.releaseAcquiredTriggers(triggers)
+
+			continue
+		}
+
+		bundles, err := s.store.TriggersFired(triggers)
+
+		if err != nil {
+			s.releaseAcquiredTriggers(triggers)
+
+			continue
+		}
+
+		for _, bundle := range s.dropLowestPriorities(bundles) {
+			s.fire(bundle)
+		}
+	}
+}
+
+// availableWorkers returns how many triggers may be acquired under the saturation policy,
+// it blocks until a worker or a queue slot is available unless the lowest priorities are dropped.
+func (s *StdScheduler) availableWorkers() int {
+	switch s.saturation.mode {
+	case saturationDropLowestPriority:
+		return s.maxBatchSize
+
+	case saturationQueue:
+		for {
+			if n := s.capacity(); n > 0 {
+				return n
+			}
+
+			// the completed jobs signal the scheduling loop.
+			select {
+			case <-s.signal:
+			case <-s.halt:
+				return 0
+			}
+		}
+
+	default:
+		return s.threadPool.BlockForAvailableThreads()
+	}
+}
+
+// capacity returns the number of the idle workers, and of the free slots of the queue.
+func (s *StdScheduler) capacity() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	n := s.threadPool.PoolSize() - s.inflight
+
+	if s.saturation.mode == saturationQueue {
+		n += s.saturation.queueSize - len(s.queued)
+	}
+
+	return n
+}
+
+// dropLowestPriorities skips the fired triggers of the lowest priorities which have no available worker,
+// if the saturation policy drops them, and returns the triggers to execute.
+func (s *StdScheduler) dropLowestPriorities(bundles []*TriggerFiredBundle) []*TriggerFiredBundle {
+	if s.saturation.mode != saturationDropLowestPriority {
+		return bundles
+	}
+
+	n := s.capacity()
+
+	if n < 0 {
+		n = 0
+	}
+
+	if len(bundles) <= n {
+		return bundles
+	}
+
+	sort.SliceStable(bundles, func(i, j int) bool {
+		return bundles[i].Trigger.Priority() > bundles[j].Trigger.Priority()
+	})
+
+	for _, bundle := range bundles[n:] {
+		s.logger.Warn("Trigger skipped, the thread pool is saturated.",
+			"trigger", bundle.Trigger.Key().String(), "job", bundle.JobDetail.Key().String(), "priority", bundle.Trigger.Priority())
+
+		instruction := executionComplete(bundle.Trigger, nil)
+
+		s.store.TriggeredJobComplete(bundle.Trigger, bundle.JobDetail, instruction)
+
+		if instruction == DELETE_TRIGGER {
+			trigger := bundle.Trigger
+
+			s.listeners.notifySchedulerListeners(func(l SchedulerListener) { l.TriggerFinalized(trigger) })
+		}
+	}
+
+	return bundles[:n]
+}
+
+// dispatch executes the task on a worker of the ThreadPool, or queues it while the workers are busy
+// if the saturation policy queues the tasks, it returns false if the pool has been shutdown.
+func (s *StdScheduler) dispatch(task func()) bool {
+	s.lock.Lock()
+
+	if s.saturation.mode == saturationQueue && s.inflight >= s.threadPool.PoolSize() {
+		s.queued = append(s.queued, task)
+		s.lock.Unlock()
+
+		return true
+	}
+
+	s.inflight++
+	s.lock.Unlock()
+
+	// the worker executes the queued tasks after its own.
+	ok := s.threadPool.RunTask(func() {
+		for task != nil {
+			task()
+
+			task = s.nextQueuedTask()
+		}
+	})
+
+	if !ok {
+		s.lock.Lock()
+		s.inflight--
+		s.lock.Unlock()
+	}
+
+	return ok
+}
+
+// nextQueuedTask pops the first queued task, or releases the worker if there is none.
+func (s *StdScheduler) nextQueuedTask() func() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if len(s.queued) == 0 {
+		s.inflight--
+
+		return nil
+	}
+
+	task := s.queued[0]
+	s.queued = s.queued[1:]
+
+	return task
+}
+
+// waitUntilRunning blocks in the standby mode, it returns false when the scheduler is shutdown.
+func (s *StdScheduler) waitUntilRunning() bool {
+	for s.InStandbyMode() {
+		select {
+		case <-s.signal:
+		case <-s.halt:
+			return false
+		}
+	}
+
+	select {
+	case <-s.halt:
+		return false
+	default:
+		return true
+	}
+}
+
+// sleep blocks for the duration, or until the scheduling changed or the scheduler is shutdown.
+func (s *StdScheduler) sleep(d time.Duration) (timeout, halted bool) {
+	timer := s.clock.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C():
+		return true, false
+	case <-s.signal:
+		return false, false
+	case <-s.halt:
+		return false, true
+	}
+}
+
+// applyMisfires updates the acquired triggers whose fire time is older than the misfire threshold,
+// and returns the other triggers, the misfired triggers which will never fire again are removed.
+func (s *StdScheduler) applyMisfires(triggers []OperableTrigger) []OperableTrigger {
+	now := s.clock.Now()
+	misfireTime := now.Add(-s.misfireThreshold)
+
+	var pending []OperableTrigger
+
+	for _, trigger := range triggers {
+		if !trigger.NextFireTime().Before(misfireTime) {
+			pending = append(pending, trigger)
+
+			continue
+		}
+
+		s.logger.Warn("Trigger misfired.", "trigger", trigger.Key().String(), "fireTime", trigger.NextFireTime())
+		s.metrics.TriggerMisfired(trigger.Key())
+		s.listeners.notifyTriggerListeners(trigger.Key(), func(l TriggerListener) { l.TriggerMisfired(trigger) })
+		s.publishEvent(EventMisfire, trigger, trigger.NextFireTime(), nil)
+
+		// the trigger whose calendar was deleted fires without it.
+		cal, _ := s.calendarOf(trigger)
+
+		trigger.UpdateAfterMisfire(cal, now)
+
+		if trigger.NextFireTime().IsZero() {
+			if _, err := s.store.RemoveTrigger(trigger.Key()); err != nil {
+				s.notifySchedulerError(fmt.Sprintf("Unable to remove the misfired trigger (%s).", trigger.Key()), err)
+			} else {
+				s.listeners.notifySchedulerListeners(func(l SchedulerListener) { l.TriggerFinalized(trigger) })
+			}
+		} else if err := s.store.ReplaceTrigger(trigger.Key(), trigger); err != nil {
+			s.store.ReleaseAcquiredTrigger(trigger)
+
+			s.notifySchedulerError(fmt.Sprintf("Unable to update the misfired trigger (%s).", trigger.Key()), err)
+		}
+	}
+
+	return pending
+}
+
+func (s *StdScheduler) releaseAcquiredTriggers(triggers []OperableTrigger) {
+	for _, trigger := range triggers {
+		s.store.ReleaseAcquiredTrigger(trigger)
+	}
+}
+
+// fire executes the job of the fired trigger in the thread pool.
+func (s *StdScheduler) fire(bundle *TriggerFiredBundle) {
+	job, err := s.newJob(bundle)
+
+	if err != nil {
+		s.store.TriggeredJobComplete(bundle.Trigger, bundle.JobDetail, SET_ALL_JOB_TRIGGERS_ERROR)
+
+		s.notifySchedulerError(fmt.Sprintf("An error occurred instantiating job to be executed. job= '%s'", bundle.JobDetail.Key()), err)
+
+		return
+	}
+
+	context := newJobExecutionContext(s.root, s, bundle, job)
+
+	ok := s.dispatch(func() {
+		s.jobStarted(context)
+
+		instruction := s.runJob(context)
+
+		s.jobCompleted(context)
+
+		context.interrupt()
+
+		context.persistMergedJobDataMap()
+
+		// the retry is stored before completing the trigger, so a non-durable job isn't removed as an orphan
+		s.retryJob(context)
+
+		s.store.TriggeredJobComplete(bundle.Trigger, bundle.JobDetail, instruction)
+
+		if instruction == DELETE_TRIGGER {
+			s.listeners.notifySchedulerListeners(func(l SchedulerListener) { l.TriggerFinalized(bundle.Trigger) })
+		}
+
+		s.signalSchedulingChange()
+	})
+
+	if !ok {
+		context.interrupt()
+
+		s.store.TriggeredJobComplete(bundle.Trigger, bundle.JobDetail, SET_ALL_JOB_TRIGGERS_ERROR)
+
+		s.notifySchedulerError(fmt.Sprintf("ThreadPool.RunTask() returned false! job= '%s'", bundle.JobDetail.Key()), ErrSchedulerShutdown)
+	}
+}
+
+// runJob executes the job until it doesn't ask to be refired, and notifies the listeners around each execution.
+func (s *StdScheduler) runJob(context *jobExecutionContext) CompletedExecutionInstruction {
+	trigger := context.Trigger()
+	triggerKey := trigger.Key()
+	jobKey := context.JobDetail().Key()
+
+	for {
+		vetoed := false
+
+		s.listeners.notifyTriggerListeners(triggerKey, func(l TriggerListener) {
+			l.TriggerFired(trigger, context)
+
+			if l.VetoJobExecution(trigger, context) {
+				vetoed = true
+			}
+		})
+
+		if vetoed {
+			s.listeners.notifyJobListeners(jobKey, func(l JobListener) { l.JobExecutionVetoed(context) })
+			s.listeners.notifyTriggerListeners(triggerKey, func(l TriggerListener) { l.TriggerComplete(trigger, context, JOB_VETOED) })
+
+			return executionComplete(trigger, nil)
+		}
+
+		s.publishEvent(EventTriggerFired, trigger, context.FireTime(), nil)
+		s.listeners.notifyJobListeners(jobKey, func(l JobListener) { l.JobToBeExecuted(context) })
+
+		instruction := s.execute(context)
+
+		atomic.AddInt32(&s.numJobsExecuted, 1)
+
+		s.stats.JobWasExecuted(context, context.Exception())
+		s.metrics.JobExecuted(jobKey, context.JobRunTime(), context.Exception())
+		s.listeners.notifyJobListeners(jobKey, func(l JobListener) { l.JobWasExecuted(context, context.Exception()) })
+		s.publishEvent(EventJobExecuted, trigger, context.FireTime(), context.Exception())
+		s.listeners.notifyTriggerListeners(triggerKey, func(l TriggerListener) { l.TriggerComplete(trigger, context, instruction) })
+
+		if instruction != RE_EXECUTE_JOB {
+			return instruction
+		}
+	}
+}
+
+// retryJob schedules a transient trigger to retry the failed job after the backoff delay of its RetryPolicy,
+// or notifies the listeners that the job is given up once it exhausted its attempts.
+func (s *StdScheduler) retryJob(context *jobExecutionContext) {
+	policy := context.JobDetail().RetryPolicy()
+	jee := context.exception
+
+	if policy == nil || jee == nil || jee.UnscheduleFiringTrigger || jee.UnscheduleAllTriggers {
+		return
+	}
+
+	jobKey := context.JobDetail().Key()
+	attempt := context.MergedJobDataMap().GetIntOr(RetryAttemptKey, 1)
+
+	if attempt >= policy.MaxAttempts {
+		s.notifySchedulerError(fmt.Sprintf("Job (%s) failed after %d attempts, giving up.", jobKey, attempt), jee)
+
+		return
+	}
+
+	trigger := newRetryTrigger(context.Trigger(), jobKey, attempt+1, s.clock.Now().Add(policy.Delay(attempt)))
+
+	if err := s.store.StoreTrigger(trigger, false); err != nil {
+		s.notifySchedulerError(fmt.Sprintf("Unable to retry the job (%s).", jobKey), err)
+	}
+}
+
+func (s *StdScheduler) notifySchedulerError(msg string, err error) {
+	s.logger.Error(msg, "error", err)
+
+	s.listeners.notifySchedulerListeners(func(l SchedulerListener) { l.SchedulerError(msg, err) })
+}
+
+func (s *StdScheduler) newJob(bundle *TriggerFiredBundle) (Job, error) {
+	factory := bundle.JobDetail.JobFactory()
+
+	if factory == nil {
+		s.lock.Lock()
+		factory = s.jobFactory
+		s.lock.Unlock()
+	}
+
+	if factory == nil {
+		return nil, noJobFactoryError(bundle.JobDetail.Key())
+	}
+
+	return factory.NewJob(bundle, s)
+}
+
+func (s *StdScheduler) execute(context *jobExecutionContext) CompletedExecutionInstruction {
+	parent := context.Context()
+	ctx, endSpan := s.tracer.StartJobSpan(parent, context.JobDetail(), context.Trigger())
+
+	context.setContext(ctx)
+
+	startTime := time.Now()
+
+	err := executeJob(context.JobInstance(), context)
+
+	context.setJobRunTime(time.Since(startTime))
+	context.setContext(parent)
+
+	endSpan(err)
+
+	if err != nil {
+		s.logger.Error("Job threw an exception.", "job", context.JobDetail().Key().String(), "trigger", context.Trigger().Key().String(), "error", err)
+	}
+
+	jee := asJobExecutionException(err)
+
+	context.setException(jee)
+
+	return executionComplete(context.Trigger(), jee)
+}
+
+// executeJob executes the job, a panic is returned as an error.
+func executeJob(job Job, context JobExecutionContext) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("Job (%s) panicked: %v", context.JobDetail().Key(), r)
+		}
+	}()
+
+	return job.Execute(context)
+}
+
+// executionComplete decides what the JobStore should do with the trigger after its job completed.
+func executionComplete(trigger Trigger, jee *JobExecutionException) CompletedExecutionInstruction {
+	if jee != nil {
+		switch {
+		case jee.RefireImmediately:
+			return RE_EXECUTE_JOB
+
+		case jee.UnscheduleFiringTrigger:
+			return SET_TRIGGER_COMPLETE
+
+		case jee.UnscheduleAllTriggers:
+			return SET_ALL_JOB_TRIGGERS_COMPLETE
+		}
+	}
+
+	if !trigger.MayFireAgain() {
+		return DELETE_TRIGGER
+	}
+
+	return NOOP
+}
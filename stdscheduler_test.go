@@ -0,0 +1,1767 @@
+package quartz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// waitFor polls the condition until it is true or the timeout expired.
+func waitFor(timeout time.Duration, cond func() bool) bool {
+	deadline := time.Now().Add(timeout)
+
+	for !cond() {
+		if time.Now().After(deadline) {
+			return false
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	return true
+}
+
+func TestStdScheduler(t *testing.T) {
+	Convey("Given a StdScheduler with a RAMJobStore", t, func() {
+		store := NewRAMJobStore()
+		scheduler := NewStdScheduler("scheduler", store, NewSimpleThreadPool(4))
+
+		defer scheduler.Shutdown()
+
+		So(scheduler.Name(), ShouldEqual, "scheduler")
+		So(scheduler.Started(), ShouldBeFalse)
+		So(scheduler.InStandbyMode(), ShouldBeTrue)
+
+		var counter int32
+
+		job := NewJobDetailFromFunc("counter", func(context JobExecutionContext) error {
+			atomic.AddInt32(&counter, 1)
+
+			return nil
+		})
+
+		trigger := (&TriggerBuilder{}).
+			WithIdentity("trigger").
+			StartNow().
+			WithSchedule(&SimpleScheduleBuilder{10 * time.Millisecond, 2}).
+			Build()
+
+		Convey("When a job is scheduled and the scheduler started", func() {
+			fireTime, err := scheduler.ScheduleJob(job, trigger)
+
+			So(err, ShouldBeNil)
+			So(fireTime.IsZero(), ShouldBeFalse)
+			So(scheduler.CheckJobExists(job.Key()), ShouldBeTrue)
+			So(scheduler.CheckTriggerExists(trigger.Key()), ShouldBeTrue)
+
+			So(scheduler.Start(), ShouldBeNil)
+			So(scheduler.Started(), ShouldBeTrue)
+			So(scheduler.InStandbyMode(), ShouldBeFalse)
+
+			Convey("The job should fire for each repeat", func() {
+				So(waitFor(time.Second, func() bool { return atomic.LoadInt32(&counter) == 3 }), ShouldBeTrue)
+
+				So(waitFor(time.Second, func() bool { return !scheduler.CheckTriggerExists(trigger.Key()) }), ShouldBeTrue)
+
+				time.Sleep(30 * time.Millisecond)
+
+				So(atomic.LoadInt32(&counter), ShouldEqual, 3)
+				So(scheduler.CheckJobExists(job.Key()), ShouldBeFalse)
+			})
+
+			Convey("The unscheduled job should not fire again", func() {
+				So(waitFor(time.Second, func() bool { return atomic.LoadInt32(&counter) >= 1 }), ShouldBeTrue)
+
+				found, err := scheduler.UnscheduleJob(trigger.Key())
+
+				So(err, ShouldBeNil)
+				So(found, ShouldBeTrue)
+
+				fired := atomic.LoadInt32(&counter)
+
+				time.Sleep(50 * time.Millisecond)
+
+				So(atomic.LoadInt32(&counter), ShouldBeLessThanOrEqualTo, fired+1)
+			})
+		})
+
+		Convey("When a job is scheduled in the standby mode", func() {
+			_, err := scheduler.ScheduleJob(job, trigger)
+
+			So(err, ShouldBeNil)
+
+			time.Sleep(30 * time.Millisecond)
+
+			So(atomic.LoadInt32(&counter), ShouldEqual, 0)
+
+			Convey("The job should fire after the scheduler started", func() {
+				So(scheduler.Start(), ShouldBeNil)
+
+				So(waitFor(time.Second, func() bool { return atomic.LoadInt32(&counter) == 3 }), ShouldBeTrue)
+			})
+		})
+
+		Convey("When the scheduler is shutdown", func() {
+			So(scheduler.Start(), ShouldBeNil)
+			So(scheduler.Shutdown(), ShouldBeNil)
+
+			So(scheduler.IsShutdown(), ShouldBeTrue)
+			So(scheduler.Started(), ShouldBeFalse)
+			So(scheduler.Shutdown(), ShouldBeNil)
+
+			So(errors.Is(scheduler.Start(), ErrSchedulerShutdown), ShouldBeTrue)
+			So(errors.Is(scheduler.Standby(), ErrSchedulerShutdown), ShouldBeTrue)
+
+			_, err := scheduler.ScheduleJob(job, trigger)
+
+			So(errors.Is(err, ErrSchedulerShutdown), ShouldBeTrue)
+		})
+
+		Convey("When the scheduler is shutdown waiting for the jobs", func() {
+			release := make(chan struct{})
+			var started, completed int32
+
+			slow := NewJobDetailFromFunc("slow", func(context JobExecutionContext) error {
+				atomic.StoreInt32(&started, 1)
+
+				<-release
+
+				atomic.StoreInt32(&completed, 1)
+
+				return nil
+			})
+
+			_, err := scheduler.ScheduleJob(slow, (&TriggerBuilder{}).StartNow().Build())
+
+			So(err, ShouldBeNil)
+			So(scheduler.Start(), ShouldBeNil)
+			So(waitFor(time.Second, func() bool { return atomic.LoadInt32(&started) == 1 }), ShouldBeTrue)
+
+			time.AfterFunc(20*time.Millisecond, func() { close(release) })
+
+			So(scheduler.ShutdownAndWait(true), ShouldBeNil)
+			So(atomic.LoadInt32(&completed), ShouldEqual, 1)
+		})
+	})
+}
+
+func TestExecutionComplete(t *testing.T) {
+	Convey("Given a trigger which may fire again", t, func() {
+		trigger := (&TriggerBuilder{}).StartNow().WithSchedule(&SimpleScheduleBuilder{time.Minute, REPEAT_INDEFINITELY}).Build().(OperableTrigger)
+		trigger.SetNextFireTime(time.Now())
+
+		So(executionComplete(trigger, nil), ShouldEqual, NOOP)
+		So(executionComplete(trigger, NewJobExecutionException(errors.New("failed"))), ShouldEqual, NOOP)
+		So(executionComplete(trigger, &JobExecutionException{RefireImmediately: true}), ShouldEqual, RE_EXECUTE_JOB)
+		So(executionComplete(trigger, &JobExecutionException{UnscheduleFiringTrigger: true}), ShouldEqual, SET_TRIGGER_COMPLETE)
+		So(executionComplete(trigger, &JobExecutionException{UnscheduleAllTriggers: true}), ShouldEqual, SET_ALL_JOB_TRIGGERS_COMPLETE)
+
+		Convey("When the trigger will not fire again", func() {
+			trigger.SetNextFireTime(zero)
+
+			So(executionComplete(trigger, nil), ShouldEqual, DELETE_TRIGGER)
+		})
+	})
+
+	Convey("Given a job which panics", t, func() {
+		job := JobFunc(func(context JobExecutionContext) error { panic("boom") })
+
+		bundle := &TriggerFiredBundle{
+			JobDetail: (&JobBuilder{}).WithIdentity("job").Build(),
+			Trigger:   (&TriggerBuilder{}).StartNow().Build().(OperableTrigger),
+		}
+
+		err := executeJob(job, newJobExecutionContext(nil, nil, bundle, job))
+
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldContainSubstring, "boom")
+	})
+}
+
+func TestSchedulerMetaData(t *testing.T) {
+	Convey("Given a StdScheduler", t, func() {
+		scheduler := NewStdScheduler("scheduler", NewRAMJobStore(), NewSimpleThreadPool(3))
+
+		defer scheduler.Shutdown()
+
+		meta := scheduler.MetaData()
+
+		So(meta.SchedulerName(), ShouldEqual, "scheduler")
+		So(meta.Version(), ShouldEqual, Version)
+		So(meta.JobStoreClass(), ShouldEqual, "*quartz.RAMJobStore")
+		So(meta.ThreadPoolSize(), ShouldEqual, 3)
+		So(meta.Started(), ShouldBeFalse)
+		So(meta.InStandbyMode(), ShouldBeTrue)
+		So(meta.Shutdown(), ShouldBeFalse)
+		So(meta.NumberOfJobsExecuted(), ShouldEqual, 0)
+		So(meta.RunningSince().IsZero(), ShouldBeTrue)
+
+		Convey("When the scheduler executed a job", func() {
+			before := time.Now()
+
+			So(scheduler.Start(), ShouldBeNil)
+
+			job := NewJobDetailFromFunc("job", func(context JobExecutionContext) error { return nil })
+
+			_, err := scheduler.ScheduleJob(job, (&TriggerBuilder{}).StartNow().Build())
+
+			So(err, ShouldBeNil)
+			So(waitFor(time.Second, func() bool { return scheduler.MetaData().NumberOfJobsExecuted() == 1 }), ShouldBeTrue)
+
+			meta := scheduler.MetaData()
+
+			So(meta.Started(), ShouldBeTrue)
+			So(meta.InStandbyMode(), ShouldBeFalse)
+			So(meta.RunningSince(), ShouldHappenOnOrBetween, before, time.Now())
+
+			Convey("The metadata should reflect the standby mode", func() {
+				So(scheduler.Standby(), ShouldBeNil)
+
+				meta := scheduler.MetaData()
+
+				So(meta.InStandbyMode(), ShouldBeTrue)
+				So(meta.NumberOfJobsExecuted(), ShouldEqual, 1)
+			})
+
+			Convey("The metadata should reflect the shutdown", func() {
+				So(scheduler.Shutdown(), ShouldBeNil)
+
+				meta := scheduler.MetaData()
+
+				So(meta.Started(), ShouldBeFalse)
+				So(meta.Shutdown(), ShouldBeTrue)
+				So(meta.RunningSince().IsZero(), ShouldBeFalse)
+			})
+		})
+	})
+}
+
+func TestStdSchedulerGetKeys(t *testing.T) {
+	Convey("Given a StdScheduler with jobs in several groups", t, func() {
+		scheduler := NewStdScheduler("scheduler", NewRAMJobStore(), NewSimpleThreadPool(1))
+
+		defer scheduler.Shutdown()
+
+		for _, group := range []string{"reports", "reports-daily", "backup"} {
+			job := (&JobBuilder{}).WithGroupIdentity("job", group).Build()
+			trigger := (&TriggerBuilder{}).WithGroupIdentity("trigger", group).StartAt(time.Now().Add(time.Hour)).Build()
+
+			_, err := scheduler.ScheduleJob(job, trigger)
+
+			So(err, ShouldBeNil)
+		}
+
+		jobKeys, err := scheduler.GetJobKeys(GroupStartsWith("reports"))
+
+		So(err, ShouldBeNil)
+		So(jobKeys, ShouldResemble, []JobKey{NewGroupJobKey("job", "reports"), NewGroupJobKey("job", "reports-daily")})
+
+		triggerKeys, err := scheduler.GetTriggerKeys(GroupEndsWith("up"))
+
+		So(err, ShouldBeNil)
+		So(triggerKeys, ShouldResemble, []TriggerKey{NewGroupTriggerKey("trigger", "backup")})
+	})
+}
+
+func TestStdSchedulerRetrieve(t *testing.T) {
+	Convey("Given a StdScheduler with a scheduled job", t, func() {
+		scheduler := NewStdScheduler("scheduler", NewRAMJobStore(), NewSimpleThreadPool(1))
+
+		job := (&JobBuilder{}).WithIdentity("job").UsingJobData("key", "job").Build()
+		trigger := (&TriggerBuilder{}).
+			WithIdentity("trigger").
+			WithDescription("trigger").
+			StartAt(time.Now().Add(time.Hour)).
+			Build()
+
+		_, err := scheduler.ScheduleJob(job, trigger)
+
+		So(err, ShouldBeNil)
+
+		Convey("The job and the trigger should exist", func() {
+			So(scheduler.CheckJobExists(job.Key()), ShouldBeTrue)
+			So(scheduler.CheckTriggerExists(trigger.Key()), ShouldBeTrue)
+			So(scheduler.CheckJobExists(NewJobKey("missing")), ShouldBeFalse)
+			So(scheduler.CheckTriggerExists(NewTriggerKey("missing")), ShouldBeFalse)
+		})
+
+		Convey("The stored job and trigger should be returned", func() {
+			So(scheduler.GetJobDetail(job.Key()).Key(), ShouldResemble, job.Key())
+			So(scheduler.GetJobDetail(job.Key()).JobDataMap().Get("key"), ShouldEqual, "job")
+			So(scheduler.GetTrigger(trigger.Key()).Key(), ShouldResemble, trigger.Key())
+			So(scheduler.GetTrigger(trigger.Key()).JobKey(), ShouldResemble, job.Key())
+
+			triggers := scheduler.GetTriggersOfJob(job.Key())
+
+			So(triggers, ShouldHaveLength, 1)
+			So(triggers[0].Key(), ShouldResemble, trigger.Key())
+
+			So(scheduler.GetJobDetail(NewJobKey("missing")), ShouldBeNil)
+			So(scheduler.GetTrigger(NewTriggerKey("missing")), ShouldBeNil)
+			So(scheduler.GetTriggersOfJob(NewJobKey("missing")), ShouldBeEmpty)
+		})
+
+		Convey("Changing the returned copies should not affect the stored job and trigger", func() {
+			nextFireTime := scheduler.GetTrigger(trigger.Key()).NextFireTime()
+
+			returned := scheduler.GetTrigger(trigger.Key()).(OperableTrigger)
+			returned.SetDescription("changed")
+			returned.SetNextFireTime(time.Now())
+			returned.JobDataMap().Put("key", "changed")
+
+			for _, t := range scheduler.GetTriggersOfJob(job.Key()) {
+				t.(OperableTrigger).SetDescription("changed")
+			}
+
+			scheduler.GetJobDetail(job.Key()).JobDataMap().Put("key", "changed")
+
+			stored := scheduler.GetTrigger(trigger.Key())
+
+			So(stored.Description(), ShouldEqual, "trigger")
+			So(stored.NextFireTime(), ShouldResemble, nextFireTime)
+			So(stored.JobDataMap().Contains("key"), ShouldBeFalse)
+			So(scheduler.GetJobDetail(job.Key()).JobDataMap().Get("key"), ShouldEqual, "job")
+		})
+	})
+}
+
+func TestStdSchedulerGetNextFireTimes(t *testing.T) {
+	Convey("Given a StdScheduler with triggers scheduled later", t, func() {
+		scheduler := NewStdScheduler("scheduler", NewRAMJobStore(), NewSimpleThreadPool(1))
+
+		defer scheduler.Shutdown()
+
+		job := (&JobBuilder{}).WithIdentity("job").StoreDurably(true).Build()
+
+		So(scheduler.AddJob(job, false), ShouldBeNil)
+
+		startTime := time.Now().Add(time.Hour).Truncate(time.Second)
+
+		schedule := func(name string, sb ScheduleBuilder) TriggerKey {
+			trigger := (&TriggerBuilder{}).WithIdentity(name).ForJobDetail(job).StartAt(startTime).WithSchedule(sb).Build()
+
+			_, err := scheduler.Schedule(trigger)
+
+			So(err, ShouldBeNil)
+
+			return trigger.Key()
+		}
+
+		Convey("The bounded trigger should return fewer fire times when it completes", func() {
+			key := schedule("bounded", &SimpleScheduleBuilder{time.Minute, 2})
+
+			fireTimes, err := scheduler.GetNextFireTimes(key, 5)
+
+			So(err, ShouldBeNil)
+			So(fireTimes, ShouldResemble, []time.Time{startTime, startTime.Add(time.Minute), startTime.Add(2 * time.Minute)})
+		})
+
+		Convey("The unbounded trigger should return the requested fire times without changing", func() {
+			key := schedule("unbounded", &SimpleScheduleBuilder{time.Hour, REPEAT_INDEFINITELY})
+
+			fireTimes, err := scheduler.GetNextFireTimes(key, 3)
+
+			So(err, ShouldBeNil)
+			So(fireTimes, ShouldResemble, []time.Time{startTime, startTime.Add(time.Hour), startTime.Add(2 * time.Hour)})
+			So(scheduler.GetTrigger(key).NextFireTime(), ShouldResemble, startTime)
+
+			fireTimes, _ = scheduler.GetNextFireTimes(key, 0)
+
+			So(fireTimes, ShouldBeEmpty)
+		})
+
+		Convey("A nonexistent trigger should return an error", func() {
+			_, err := scheduler.GetNextFireTimes(NewTriggerKey("nonexists"), 3)
+
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestStdSchedulerCalendar(t *testing.T) {
+	Convey("Given a StdScheduler with a HolidayCalendar", t, func() {
+		scheduler := NewStdScheduler("scheduler", NewRAMJobStore(), NewSimpleThreadPool(1))
+
+		defer scheduler.Shutdown()
+
+		startTime := time.Now().AddDate(1, 0, 0).Truncate(time.Second)
+
+		holidays := NewHolidayCalendar(nil)
+		holidays.AddExcludedDate(startTime.AddDate(0, 0, 1))
+		holidays.AddExcludedDate(startTime.AddDate(0, 0, 2))
+
+		So(scheduler.AddCalendar("holidays", holidays, false), ShouldBeNil)
+
+		names, err := scheduler.GetCalendarNames()
+
+		So(err, ShouldBeNil)
+		So(names, ShouldResemble, []string{"holidays"})
+
+		job := (&JobBuilder{}).WithIdentity("job").Build()
+
+		newTrigger := func(calName string) Trigger {
+			return (&TriggerBuilder{}).
+				WithIdentity("trigger").
+				StartAt(startTime).
+				ModifiedByCalendar(calName).
+				WithSchedule(&SimpleScheduleBuilder{24 * time.Hour, REPEAT_INDEFINITELY}).
+				Build()
+		}
+
+		Convey("The trigger using the calendar should skip the excluded dates", func() {
+			_, err := scheduler.ScheduleJob(job, newTrigger("holidays"))
+
+			So(err, ShouldBeNil)
+			So(scheduler.GetTrigger(NewTriggerKey("trigger")).CalendarName(), ShouldEqual, "holidays")
+
+			fireTimes, err := scheduler.GetNextFireTimes(NewTriggerKey("trigger"), 3)
+
+			So(err, ShouldBeNil)
+			So(fireTimes, ShouldResemble, []time.Time{startTime, startTime.AddDate(0, 0, 3), startTime.AddDate(0, 0, 4)})
+
+			Convey("The calendar should not be deleted while the trigger uses it", func() {
+				deleted, err := scheduler.DeleteCalendar("holidays")
+
+				So(err, ShouldNotBeNil)
+				So(deleted, ShouldBeFalse)
+
+				_, err = scheduler.UnscheduleJob(NewTriggerKey("trigger"))
+
+				So(err, ShouldBeNil)
+
+				deleted, err = scheduler.DeleteCalendar("holidays")
+
+				So(err, ShouldBeNil)
+				So(deleted, ShouldBeTrue)
+
+				cal, err := scheduler.GetCalendar("holidays")
+
+				So(err, ShouldBeNil)
+				So(cal, ShouldBeNil)
+			})
+		})
+
+		Convey("The trigger using a calendar not stored should not be scheduled", func() {
+			_, err := scheduler.ScheduleJob(job, newTrigger("nonexists"))
+
+			So(err, ShouldNotBeNil)
+			So(scheduler.CheckJobExists(job.Key()), ShouldBeFalse)
+			So(scheduler.CheckTriggerExists(NewTriggerKey("trigger")), ShouldBeFalse)
+		})
+	})
+}
+
+func TestStdSchedulerPauseGroups(t *testing.T) {
+	Convey("Given a StdScheduler with jobs in several groups", t, func() {
+		scheduler := NewStdScheduler("scheduler", NewRAMJobStore(), NewSimpleThreadPool(2))
+
+		defer scheduler.Shutdown()
+
+		var reports, backup int32
+
+		schedule := func(group string, counter *int32) {
+			job := (&JobBuilder{}).
+				WithGroupIdentity("job", group).
+				UsingJobFactory(&singletonJobFactory{JobFunc(func(context JobExecutionContext) error {
+					atomic.AddInt32(counter, 1)
+
+					return nil
+				})}).
+				Build()
+
+			trigger := (&TriggerBuilder{}).
+				WithGroupIdentity("trigger", group).
+				StartNow().
+				WithSchedule(&SimpleScheduleBuilder{5 * time.Millisecond, REPEAT_INDEFINITELY}).
+				Build()
+
+			_, err := scheduler.ScheduleJob(job, trigger)
+
+			So(err, ShouldBeNil)
+		}
+
+		schedule("reports", &reports)
+		schedule("backup", &backup)
+
+		Convey("When the trigger group is paused", func() {
+			So(scheduler.PauseTriggers(GroupEquals("reports")), ShouldBeNil)
+			So(scheduler.Start(), ShouldBeNil)
+
+			So(waitFor(time.Second, func() bool { return atomic.LoadInt32(&backup) >= 3 }), ShouldBeTrue)
+			So(atomic.LoadInt32(&reports), ShouldEqual, 0)
+
+			Convey("The resumed group should fire again", func() {
+				So(scheduler.ResumeTriggers(GroupEquals("reports")), ShouldBeNil)
+
+				So(waitFor(time.Second, func() bool { return atomic.LoadInt32(&reports) >= 1 }), ShouldBeTrue)
+			})
+		})
+
+		Convey("When the job groups are paused", func() {
+			So(scheduler.PauseJobs(AnyGroup()), ShouldBeNil)
+			So(scheduler.Start(), ShouldBeNil)
+
+			time.Sleep(30 * time.Millisecond)
+
+			So(atomic.LoadInt32(&reports), ShouldEqual, 0)
+			So(atomic.LoadInt32(&backup), ShouldEqual, 0)
+
+			So(scheduler.ResumeJobs(GroupEquals("backup")), ShouldBeNil)
+
+			So(waitFor(time.Second, func() bool { return atomic.LoadInt32(&backup) >= 1 }), ShouldBeTrue)
+			So(atomic.LoadInt32(&reports), ShouldEqual, 0)
+		})
+	})
+}
+
+func TestStdSchedulerScheduling(t *testing.T) {
+	Convey("Given a started StdScheduler", t, func() {
+		scheduler := NewStdScheduler("scheduler", NewRAMJobStore(), NewSimpleThreadPool(2))
+
+		defer scheduler.Shutdown()
+
+		So(scheduler.Start(), ShouldBeNil)
+
+		var counter int32
+
+		job := NewJobDetailFromFunc("job", func(context JobExecutionContext) error {
+			atomic.AddInt32(&counter, 1)
+
+			return nil
+		})
+
+		later := (&TriggerBuilder{}).WithIdentity("later").StartAt(time.Now().Add(time.Hour)).Build()
+
+		Convey("The trigger referencing another job should be rejected", func() {
+			_, err := scheduler.ScheduleJob(job, (&TriggerBuilder{}).ForJob("other").StartNow().Build())
+
+			So(err, ShouldNotBeNil)
+			So(scheduler.CheckJobExists(job.Key()), ShouldBeFalse)
+		})
+
+		Convey("The invalid trigger should be rejected", func() {
+			_, err := scheduler.ScheduleJob(job, (&TriggerBuilder{}).
+				StartNow().
+				WithSchedule(&SimpleScheduleBuilder{0, REPEAT_INDEFINITELY}).
+				Build())
+
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "Repeat Interval")
+			So(scheduler.CheckJobExists(job.Key()), ShouldBeFalse)
+		})
+
+		Convey("When a job is scheduled to fire later", func() {
+			fireTime, err := scheduler.ScheduleJob(job, later)
+
+			So(err, ShouldBeNil)
+			So(fireTime, ShouldResemble, later.StartTime())
+
+			Convey("A trigger scheduled for the existing job should wake up the scheduler", func() {
+				fireTime, err := scheduler.Schedule((&TriggerBuilder{}).WithIdentity("now").ForJobDetail(job).StartNow().Build())
+
+				So(err, ShouldBeNil)
+				So(fireTime.IsZero(), ShouldBeFalse)
+				So(waitFor(time.Second, func() bool { return atomic.LoadInt32(&counter) == 1 }), ShouldBeTrue)
+			})
+
+			Convey("A trigger for a nonexistent job should be rejected", func() {
+				_, err := scheduler.Schedule((&TriggerBuilder{}).ForJob("nonexists").StartNow().Build())
+
+				So(errors.Is(err, &ErrUnableToResolveJob{}), ShouldBeTrue)
+			})
+
+			Convey("The rescheduled trigger should fire at its new time", func() {
+				fireTime, err := scheduler.RescheduleJob(later.Key(), (&TriggerBuilder{}).WithIdentity("now").StartNow().Build())
+
+				So(err, ShouldBeNil)
+				So(fireTime.IsZero(), ShouldBeFalse)
+				So(scheduler.CheckTriggerExists(later.Key()), ShouldBeFalse)
+				So(waitFor(time.Second, func() bool { return atomic.LoadInt32(&counter) == 1 }), ShouldBeTrue)
+			})
+
+			Convey("Rescheduling a nonexistent trigger should do nothing", func() {
+				fireTime, err := scheduler.RescheduleJob(NewTriggerKey("nonexists"), (&TriggerBuilder{}).StartNow().Build())
+
+				So(err, ShouldBeNil)
+				So(fireTime.IsZero(), ShouldBeTrue)
+			})
+
+			Convey("The unscheduled trigger should be removed with its job", func() {
+				found, err := scheduler.UnscheduleJob(later.Key())
+
+				So(err, ShouldBeNil)
+				So(found, ShouldBeTrue)
+				So(scheduler.CheckTriggerExists(later.Key()), ShouldBeFalse)
+				So(scheduler.CheckJobExists(job.Key()), ShouldBeFalse)
+			})
+		})
+
+		Convey("The trigger starting in the past should fire at once", func() {
+			fireTime, err := scheduler.ScheduleJob(job, (&TriggerBuilder{}).StartAt(time.Now().Add(-time.Minute)).Build())
+
+			So(err, ShouldBeNil)
+			So(fireTime, ShouldHappenBefore, time.Now())
+			So(waitFor(time.Second, func() bool { return atomic.LoadInt32(&counter) == 1 }), ShouldBeTrue)
+		})
+
+		Convey("When a running trigger is rescheduled", func() {
+			release := make(chan struct{})
+			var started int32
+
+			slow := NewJobDetailFromFunc("slow", func(context JobExecutionContext) error {
+				if atomic.AddInt32(&started, 1) == 1 {
+					<-release
+				}
+
+				return nil
+			})
+
+			trigger := (&TriggerBuilder{}).WithIdentity("trigger").StartNow().Build()
+
+			_, err := scheduler.ScheduleJob(slow, trigger)
+
+			So(err, ShouldBeNil)
+			So(waitFor(time.Second, func() bool { return atomic.LoadInt32(&started) == 1 }), ShouldBeTrue)
+
+			_, err = scheduler.RescheduleJob(trigger.Key(), (&TriggerBuilder{}).WithIdentity("trigger").StartAt(time.Now().Add(20*time.Millisecond)).Build())
+
+			So(err, ShouldBeNil)
+
+			close(release)
+
+			Convey("The new trigger should survive the completion of the old one", func() {
+				So(waitFor(time.Second, func() bool { return atomic.LoadInt32(&started) == 2 }), ShouldBeTrue)
+			})
+		})
+	})
+}
+
+func TestStdSchedulerAddAndDeleteJob(t *testing.T) {
+	Convey("Given a started StdScheduler", t, func() {
+		scheduler := NewStdScheduler("scheduler", NewRAMJobStore(), NewSimpleThreadPool(2))
+
+		defer scheduler.Shutdown()
+
+		So(scheduler.Start(), ShouldBeNil)
+
+		release := make(chan struct{})
+		var counter int32
+
+		build := func(durable bool) JobDetail {
+			return (&JobBuilder{}).
+				WithIdentity("job").
+				StoreDurably(durable).
+				UsingJobFactory(&singletonJobFactory{JobFunc(func(context JobExecutionContext) error {
+					atomic.AddInt32(&counter, 1)
+
+					<-release
+
+					return nil
+				})}).
+				Build()
+		}
+
+		Convey("The non-durable job without trigger should be rejected", func() {
+			So(scheduler.AddJob(build(false), false), ShouldNotBeNil)
+			So(scheduler.CheckJobExists(NewJobKey("job")), ShouldBeFalse)
+		})
+
+		Convey("When a durable job is added", func() {
+			job := build(true)
+
+			So(scheduler.AddJob(job, false), ShouldBeNil)
+			So(scheduler.CheckJobExists(job.Key()), ShouldBeTrue)
+			So(scheduler.AddJob(job, false), ShouldNotBeNil)
+
+			Convey("The job should be kept after it was triggered", func() {
+				close(release)
+
+				_, err := scheduler.Schedule((&TriggerBuilder{}).ForJobDetail(job).StartNow().Build())
+
+				So(err, ShouldBeNil)
+				So(waitFor(time.Second, func() bool { return atomic.LoadInt32(&counter) == 1 }), ShouldBeTrue)
+				So(waitFor(time.Second, func() bool { return len(scheduler.GetTriggersOfJob(job.Key())) == 0 }), ShouldBeTrue)
+				So(scheduler.CheckJobExists(job.Key()), ShouldBeTrue)
+
+				found, err := scheduler.DeleteJob(job.Key())
+
+				So(err, ShouldBeNil)
+				So(found, ShouldBeTrue)
+				So(scheduler.CheckJobExists(job.Key()), ShouldBeFalse)
+			})
+
+			Convey("The executing job should be deleted with its triggers", func() {
+				trigger := (&TriggerBuilder{}).
+					ForJobDetail(job).
+					StartNow().
+					WithSchedule(&SimpleScheduleBuilder{time.Hour, REPEAT_INDEFINITELY}).
+					Build()
+
+				_, err := scheduler.Schedule(trigger)
+
+				So(err, ShouldBeNil)
+				So(waitFor(time.Second, func() bool { return atomic.LoadInt32(&counter) == 1 }), ShouldBeTrue)
+
+				found, err := scheduler.DeleteJob(job.Key())
+
+				So(err, ShouldBeNil)
+				So(found, ShouldBeTrue)
+				So(scheduler.CheckJobExists(job.Key()), ShouldBeFalse)
+				So(scheduler.CheckTriggerExists(trigger.Key()), ShouldBeFalse)
+
+				close(release)
+
+				time.Sleep(20 * time.Millisecond)
+
+				So(atomic.LoadInt32(&counter), ShouldEqual, 1)
+				So(scheduler.CheckJobExists(job.Key()), ShouldBeFalse)
+			})
+		})
+
+		Convey("The jobs should be deleted together", func() {
+			So(scheduler.AddJob(build(true), false), ShouldBeNil)
+
+			found, err := scheduler.DeleteJobs([]JobKey{NewJobKey("job"), NewJobKey("nonexists")})
+
+			So(err, ShouldBeNil)
+			So(found, ShouldBeFalse)
+			So(scheduler.CheckJobExists(NewJobKey("job")), ShouldBeFalse)
+		})
+	})
+}
+
+func TestStdSchedulerTriggerJob(t *testing.T) {
+	Convey("Given a started StdScheduler with a durable job", t, func() {
+		scheduler := NewStdScheduler("scheduler", NewRAMJobStore(), NewSimpleThreadPool(2))
+
+		defer scheduler.Shutdown()
+
+		So(scheduler.Start(), ShouldBeNil)
+
+		var counter int32
+		values := make(chan interface{}, 1)
+
+		job := (&JobBuilder{}).
+			WithIdentity("job").
+			StoreDurably(true).
+			UsingJobData("value", "job").
+			UsingJobFactory(&singletonJobFactory{JobFunc(func(context JobExecutionContext) error {
+				atomic.AddInt32(&counter, 1)
+
+				values <- context.MergedJobDataMap().Get("value")
+
+				return nil
+			})}).
+			Build()
+
+		So(scheduler.AddJob(job, false), ShouldBeNil)
+
+		Convey("The triggered job should run exactly once", func() {
+			So(scheduler.TriggerJob(job.Key()), ShouldBeNil)
+
+			So(<-values, ShouldEqual, "job")
+			So(waitFor(time.Second, func() bool { return len(scheduler.GetTriggersOfJob(job.Key())) == 0 }), ShouldBeTrue)
+
+			time.Sleep(20 * time.Millisecond)
+
+			So(atomic.LoadInt32(&counter), ShouldEqual, 1)
+			So(scheduler.CheckJobExists(job.Key()), ShouldBeTrue)
+		})
+
+		Convey("The triggered job should see the data", func() {
+			data := NewJobDataMap()
+			data.Put("value", "trigger")
+
+			So(scheduler.TriggerJobWithData(job.Key(), data), ShouldBeNil)
+
+			So(<-values, ShouldEqual, "trigger")
+		})
+
+		Convey("The nonexistent job should not be triggered", func() {
+			So(scheduler.TriggerJob(NewJobKey("nonexists")), ShouldNotBeNil)
+		})
+	})
+
+	Convey("Given a started StdScheduler with a ManualClock in the past", t, func() {
+		clock := NewManualClock(time.Date(2016, time.March, 1, 8, 0, 0, 0, time.UTC))
+
+		scheduler := NewStdScheduler("scheduler", NewRAMJobStore(), NewSimpleThreadPool(2))
+		scheduler.SetClock(clock)
+
+		defer scheduler.Shutdown()
+
+		So(scheduler.Start(), ShouldBeNil)
+
+		fireTimes := make(chan time.Time, 1)
+
+		job := (&JobBuilder{}).
+			WithIdentity("job").
+			StoreDurably(true).
+			UsingJobFactory(&singletonJobFactory{JobFunc(func(context JobExecutionContext) error {
+				fireTimes <- context.FireTime()
+
+				return nil
+			})}).
+			Build()
+
+		So(scheduler.AddJob(job, false), ShouldBeNil)
+
+		Convey("The triggered job should start at the time of the clock", func() {
+			So(scheduler.TriggerJob(job.Key()), ShouldBeNil)
+
+			select {
+			case fireTime := <-fireTimes:
+				So(fireTime, ShouldResemble, clock.Now())
+			case <-time.After(time.Second):
+				So("the job was not executed", ShouldBeNil)
+			}
+		})
+	})
+}
+
+func TestStdSchedulerTriggerJobIdempotent(t *testing.T) {
+	Convey("Given a started StdScheduler with a ManualClock and a durable job", t, func() {
+		clock := NewManualClock(time.Date(2016, time.March, 1, 8, 0, 0, 0, time.UTC))
+
+		scheduler := NewStdScheduler("scheduler", NewRAMJobStore(), NewSimpleThreadPool(2))
+		scheduler.SetClock(clock)
+
+		defer scheduler.Shutdown()
+
+		So(scheduler.Start(), ShouldBeNil)
+
+		var counter int32
+
+		job := (&JobBuilder{}).
+			WithIdentity("job").
+			StoreDurably(true).
+			UsingJobFactory(&singletonJobFactory{JobFunc(func(context JobExecutionContext) error {
+				atomic.AddInt32(&counter, 1)
+
+				return nil
+			})}).
+			Build()
+
+		So(scheduler.AddJob(job, false), ShouldBeNil)
+
+		Convey("When triggers the job twice with the same idempotency key within the ttl", func() {
+			So(scheduler.TriggerJobIdempotent(job.Key(), "run-now", time.Minute), ShouldBeNil)
+			So(scheduler.TriggerJobIdempotent(job.Key(), "run-now", time.Minute), ShouldBeNil)
+
+			Convey("The job should be executed once", func() {
+				So(waitFor(time.Second, func() bool { return atomic.LoadInt32(&counter) == 1 }), ShouldBeTrue)
+
+				time.Sleep(20 * time.Millisecond)
+
+				So(atomic.LoadInt32(&counter), ShouldEqual, 1)
+			})
+
+			Convey("Another idempotency key should fire the job again", func() {
+				So(scheduler.TriggerJobIdempotent(job.Key(), "other", time.Minute), ShouldBeNil)
+
+				So(waitFor(time.Second, func() bool { return atomic.LoadInt32(&counter) == 2 }), ShouldBeTrue)
+			})
+
+			Convey("The same idempotency key should fire the job again after the ttl", func() {
+				So(waitFor(time.Second, func() bool { return atomic.LoadInt32(&counter) == 1 }), ShouldBeTrue)
+
+				clock.Advance(time.Minute)
+
+				So(scheduler.TriggerJobIdempotent(job.Key(), "run-now", time.Minute), ShouldBeNil)
+
+				So(waitFor(time.Second, func() bool { return atomic.LoadInt32(&counter) == 2 }), ShouldBeTrue)
+			})
+		})
+
+		Convey("When the job couldn't be triggered", func() {
+			So(scheduler.TriggerJobIdempotent(NewJobKey("missing"), "run-now", time.Minute), ShouldNotBeNil)
+
+			Convey("The idempotency key should be forgotten", func() {
+				So(scheduler.TriggerJobIdempotent(NewJobKey("missing"), "run-now", time.Minute), ShouldNotBeNil)
+			})
+		})
+	})
+}
+
+func TestStdSchedulerTriggerJobs(t *testing.T) {
+	Convey("Given a started StdScheduler with durable jobs", t, func() {
+		scheduler := NewStdScheduler("scheduler", NewRAMJobStore(), NewSimpleThreadPool(4))
+
+		defer scheduler.Shutdown()
+
+		So(scheduler.Start(), ShouldBeNil)
+
+		fired := make(chan string, 4)
+
+		var keys []JobKey
+
+		for _, name := range []string{"foo", "bar", "baz"} {
+			job := (&JobBuilder{}).
+				WithIdentity(name).
+				StoreDurably(true).
+				UsingJobFactory(&singletonJobFactory{JobFunc(func(context JobExecutionContext) error {
+					fired <- context.JobDetail().Key().Name()
+
+					return nil
+				})}).
+				Build()
+
+			So(scheduler.AddJob(job, false), ShouldBeNil)
+
+			keys = append(keys, job.Key())
+		}
+
+		Convey("When triggers the jobs in a batch with the missing jobs", func() {
+			err := scheduler.TriggerJobs(append(keys, NewJobKey("missing"), NewJobKey("other")))
+
+			Convey("The existing jobs should be fired once", func() {
+				names := make(map[string]int)
+
+				for range keys {
+					select {
+					case name := <-fired:
+						names[name]++
+					case <-time.After(time.Second):
+					}
+				}
+
+				So(names, ShouldResemble, map[string]int{"foo": 1, "bar": 1, "baz": 1})
+			})
+
+			Convey("The errors of the missing jobs should be joined", func() {
+				So(err, ShouldNotBeNil)
+				So(errors.Is(err, &ErrJobNotFound{NewJobKey("missing")}), ShouldBeTrue)
+				So(errors.Is(err, &ErrJobNotFound{NewJobKey("other")}), ShouldBeTrue)
+				So(errors.Is(err, &ErrJobNotFound{keys[0]}), ShouldBeFalse)
+			})
+		})
+
+		Convey("When triggers the existing jobs", func() {
+			So(scheduler.TriggerJobs(keys), ShouldBeNil)
+		})
+	})
+}
+
+func TestStdSchedulerJobContext(t *testing.T) {
+	Convey("Given a started StdScheduler executing a job waiting for its context", t, func() {
+		scheduler := NewStdScheduler("scheduler", NewRAMJobStore(), NewSimpleThreadPool(2))
+
+		defer scheduler.Shutdown()
+
+		So(scheduler.Start(), ShouldBeNil)
+
+		started := make(chan struct{})
+		canceled := make(chan error, 1)
+
+		job := NewJobDetailFromFunc("job", func(context JobExecutionContext) error {
+			close(started)
+
+			<-context.Context().Done()
+
+			canceled <- context.Context().Err()
+
+			return nil
+		})
+
+		_, err := scheduler.ScheduleJob(job, (&TriggerBuilder{}).StartNow().Build())
+
+		So(err, ShouldBeNil)
+
+		<-started
+
+		Convey("The context should be canceled when the scheduler is shutdown", func() {
+			So(scheduler.ShutdownAndWait(true), ShouldBeNil)
+
+			So(canceled, ShouldHaveLength, 1)
+			So(<-canceled, ShouldEqual, context.Canceled)
+		})
+	})
+}
+
+func TestStdSchedulerMergedJobDataMap(t *testing.T) {
+	Convey("Given a started StdScheduler", t, func() {
+		scheduler := NewStdScheduler("scheduler", NewRAMJobStore(), NewSimpleThreadPool(2))
+
+		defer scheduler.Shutdown()
+
+		So(scheduler.Start(), ShouldBeNil)
+
+		run := func(persist bool) JobDataMap {
+			job := (&JobBuilder{}).
+				WithIdentity("job").
+				StoreDurably(true).
+				PersistJobDataAfterExecution(persist).
+				UsingJobData("count", 1).
+				UsingJobFactory(&singletonJobFactory{JobFunc(func(context JobExecutionContext) error {
+					context.MergedJobDataMap().Put("merged", true)
+					context.MergedJobDataMap().Put("count", context.MergedJobDataMap().GetIntOr("count", 0)+1)
+
+					return nil
+				})}).
+				Build()
+
+			trigger := (&TriggerBuilder{}).WithIdentity("trigger").UsingJobData("count", 10).StartNow().Build()
+
+			_, err := scheduler.ScheduleJob(job, trigger)
+
+			So(err, ShouldBeNil)
+			So(waitFor(time.Second, func() bool { return !scheduler.CheckTriggerExists(trigger.Key()) }), ShouldBeTrue)
+
+			return scheduler.GetJobDetail(job.Key()).JobDataMap()
+		}
+
+		Convey("The changed merged map should replace the JobDataMap of the job if it persists its data", func() {
+			dataMap := run(true)
+
+			So(dataMap.GetIntOr("count", 0), ShouldEqual, 11)
+			So(dataMap.Get("merged"), ShouldEqual, true)
+		})
+
+		Convey("The changes should not be stored if the job doesn't persist its data", func() {
+			dataMap := run(false)
+
+			So(dataMap.GetIntOr("count", 0), ShouldEqual, 1)
+			So(dataMap.Contains("merged"), ShouldBeFalse)
+		})
+
+		Convey("When a job persisting its data is executed twice", func() {
+			counts := make(chan int, 2)
+
+			job := (&JobBuilder{}).
+				WithIdentity("counter").
+				PersistJobDataAfterExecution(true).
+				DisallowConcurrentExecution(true).
+				UsingJobData("count", 0).
+				UsingJobFactory(&singletonJobFactory{JobFunc(func(context JobExecutionContext) error {
+					count := context.MergedJobDataMap().GetIntOr("count", -1)
+
+					context.MergedJobDataMap().Put("count", count+1)
+
+					counts <- count
+
+					return nil
+				})}).
+				Build()
+
+			trigger := (&TriggerBuilder{}).
+				WithIdentity("counter").
+				StartNow().
+				WithSchedule(&SimpleScheduleBuilder{20 * time.Millisecond, 1}).
+				Build()
+
+			_, err := scheduler.ScheduleJob(job, trigger)
+
+			So(err, ShouldBeNil)
+
+			Convey("The next execution should see the persisted value", func() {
+				for _, expected := range []int{0, 1} {
+					select {
+					case count := <-counts:
+						So(count, ShouldEqual, expected)
+					case <-time.After(time.Second):
+						So("the job was not executed", ShouldBeNil)
+					}
+				}
+			})
+		})
+	})
+}
+
+func TestStdSchedulerDisallowConcurrentExecution(t *testing.T) {
+	Convey("Given a started StdScheduler with a job disallowing concurrent execution", t, func() {
+		scheduler := NewStdScheduler("scheduler", NewRAMJobStore(), NewSimpleThreadPool(4))
+
+		defer scheduler.Shutdown()
+
+		So(scheduler.Start(), ShouldBeNil)
+
+		var running, maxRunning, executed int32
+
+		job := (&JobBuilder{}).
+			WithIdentity("job").
+			StoreDurably(true).
+			DisallowConcurrentExecution(true).
+			UsingJobFactory(&singletonJobFactory{JobFunc(func(context JobExecutionContext) error {
+				n := atomic.AddInt32(&running, 1)
+
+				for {
+					max := atomic.LoadInt32(&maxRunning)
+
+					if n <= max || atomic.CompareAndSwapInt32(&maxRunning, max, n) {
+						break
+					}
+				}
+
+				time.Sleep(50 * time.Millisecond)
+
+				atomic.AddInt32(&running, -1)
+				atomic.AddInt32(&executed, 1)
+
+				return nil
+			})}).
+			Build()
+
+		So(scheduler.AddJob(job, false), ShouldBeNil)
+
+		startTime := time.Now().Add(50 * time.Millisecond)
+
+		for _, name := range []string{"first", "second"} {
+			_, err := scheduler.Schedule((&TriggerBuilder{}).WithIdentity(name).ForJobDetail(job).StartAt(startTime).Build())
+
+			So(err, ShouldBeNil)
+		}
+
+		Convey("The executions of the triggers should be serialized", func() {
+			So(waitFor(2*time.Second, func() bool { return atomic.LoadInt32(&executed) == 2 }), ShouldBeTrue)
+			So(atomic.LoadInt32(&maxRunning), ShouldEqual, 1)
+		})
+	})
+}
+
+func TestStdSchedulerCurrentlyExecutingJob(t *testing.T) {
+	Convey("Given a started StdScheduler executing a slow job", t, func() {
+		scheduler := NewStdScheduler("scheduler", NewRAMJobStore(), NewSimpleThreadPool(2))
+
+		defer scheduler.Shutdown()
+
+		So(scheduler.Start(), ShouldBeNil)
+
+		executing, err := scheduler.CurrentlyExecutingJob()
+
+		So(err, ShouldBeNil)
+		So(executing, ShouldBeEmpty)
+
+		started := make(chan struct{})
+		release := make(chan struct{})
+		var completed int32
+
+		job := NewJobDetailFromFunc("slow", func(context JobExecutionContext) error {
+			close(started)
+
+			<-release
+
+			atomic.StoreInt32(&completed, 1)
+
+			return nil
+		})
+
+		_, err = scheduler.ScheduleJob(job, (&TriggerBuilder{}).StartNow().Build())
+
+		So(err, ShouldBeNil)
+
+		<-started
+
+		executing, err = scheduler.CurrentlyExecutingJob()
+
+		So(err, ShouldBeNil)
+		So(executing, ShouldHaveLength, 1)
+		So(executing[0].JobDetail().Key(), ShouldResemble, job.Key())
+
+		close(release)
+
+		So(waitFor(time.Second, func() bool {
+			executing, _ := scheduler.CurrentlyExecutingJob()
+
+			return len(executing) == 0
+		}), ShouldBeTrue)
+		So(atomic.LoadInt32(&completed), ShouldEqual, 1)
+	})
+}
+
+func TestStdSchedulerStandby(t *testing.T) {
+	Convey("Given a StdScheduler with a ManualClock", t, func() {
+		clock := NewManualClock(time.Date(2016, time.March, 1, 8, 0, 0, 0, time.UTC))
+
+		scheduler := NewStdScheduler("scheduler", NewRAMJobStore(), NewSimpleThreadPool(2))
+		scheduler.SetClock(clock)
+
+		defer scheduler.Shutdown()
+
+		var counter int32
+
+		scheduledFireTimes := make(chan time.Time, 1)
+
+		job := NewJobDetailFromFunc("job", func(context JobExecutionContext) error {
+			atomic.AddInt32(&counter, 1)
+
+			scheduledFireTimes <- context.ScheduledFireTime()
+
+			return nil
+		})
+
+		listener := &recordingListener{name: "recorder"}
+
+		scheduler.ListenerManager().AddTriggerListener(listener)
+
+		trigger := (&TriggerBuilder{}).WithIdentity("trigger").StartAt(clock.Now().Add(time.Minute)).Build()
+
+		_, err := scheduler.ScheduleJob(job, trigger)
+
+		So(err, ShouldBeNil)
+		So(scheduler.Start(), ShouldBeNil)
+
+		time.Sleep(20 * time.Millisecond)
+
+		So(atomic.LoadInt32(&counter), ShouldEqual, 0)
+
+		Convey("When the trigger came due in the standby mode", func() {
+			So(scheduler.Standby(), ShouldBeNil)
+			So(scheduler.InStandbyMode(), ShouldBeTrue)
+
+			// the trigger is late by more than the misfire threshold.
+			clock.Advance(time.Minute + DefaultMisfireThreshold + time.Second)
+
+			time.Sleep(20 * time.Millisecond)
+
+			So(atomic.LoadInt32(&counter), ShouldEqual, 0)
+			So(scheduler.CheckTriggerExists(trigger.Key()), ShouldBeTrue)
+
+			Convey("The missed fire should be handled once the scheduler resumed", func() {
+				So(scheduler.Start(), ShouldBeNil)
+				So(scheduler.InStandbyMode(), ShouldBeFalse)
+
+				So(waitFor(time.Second, func() bool { return atomic.LoadInt32(&counter) == 1 }), ShouldBeTrue)
+				So(listener.Events()[0], ShouldEqual, "TriggerMisfired")
+
+				// the misfired one-shot trigger is rescheduled to fire at the time the scheduler resumed.
+				So((<-scheduledFireTimes).Equal(clock.Now()), ShouldBeTrue)
+				So(waitFor(time.Second, func() bool { return !scheduler.CheckTriggerExists(trigger.Key()) }), ShouldBeTrue)
+			})
+		})
+	})
+}
+
+func TestStdSchedulerStartDelayed(t *testing.T) {
+	Convey("Given a StdScheduler with a ManualClock", t, func() {
+		clock := NewManualClock(time.Date(2016, time.March, 1, 8, 0, 0, 0, time.UTC))
+
+		scheduler := NewStdScheduler("scheduler", NewRAMJobStore(), NewSimpleThreadPool(2))
+		scheduler.SetClock(clock)
+
+		defer scheduler.Shutdown()
+
+		var counter int32
+
+		job := NewJobDetailFromFunc("job", func(context JobExecutionContext) error {
+			atomic.AddInt32(&counter, 1)
+
+			return nil
+		})
+
+		_, err := scheduler.ScheduleJob(job, (&TriggerBuilder{}).StartAt(clock.Now()).Build())
+
+		So(err, ShouldBeNil)
+		So(scheduler.StartDelayed(time.Minute), ShouldBeNil)
+
+		time.Sleep(20 * time.Millisecond)
+
+		So(scheduler.Started(), ShouldBeFalse)
+		So(atomic.LoadInt32(&counter), ShouldEqual, 0)
+
+		Convey("The scheduler should start after the delay", func() {
+			clock.Advance(time.Minute)
+
+			So(waitFor(time.Second, scheduler.Started), ShouldBeTrue)
+			So(waitFor(time.Second, func() bool { return atomic.LoadInt32(&counter) == 1 }), ShouldBeTrue)
+		})
+
+		Convey("The pending start should be canceled by shutdown", func() {
+			So(scheduler.Shutdown(), ShouldBeNil)
+
+			clock.Advance(time.Minute)
+
+			time.Sleep(20 * time.Millisecond)
+
+			So(scheduler.Started(), ShouldBeFalse)
+			So(atomic.LoadInt32(&counter), ShouldEqual, 0)
+			So(errors.Is(scheduler.StartDelayed(time.Minute), ErrSchedulerShutdown), ShouldBeTrue)
+		})
+	})
+}
+
+func TestStdSchedulerContext(t *testing.T) {
+	Convey("Given a started StdScheduler with a counter in its context", t, func() {
+		scheduler := NewStdScheduler("scheduler", NewRAMJobStore(), NewSimpleThreadPool(4))
+
+		defer scheduler.Shutdown()
+
+		scheduler.Context().Put("counter", 0)
+
+		So(scheduler.Start(), ShouldBeNil)
+
+		Convey("The jobs should access the context concurrently", func() {
+			const jobs = 8
+
+			var executed int32
+			var lock sync.Mutex
+
+			for i := 0; i < jobs; i++ {
+				name := fmt.Sprintf("job-%d", i)
+
+				job := NewJobDetailFromFunc(name, func(context JobExecutionContext) error {
+					ctx := context.Scheduler().Context()
+
+					ctx.Put(name, true)
+
+					lock.Lock()
+					ctx.Put("counter", ctx.Get("counter").(int)+1)
+					lock.Unlock()
+
+					_ = ctx.Keys()
+					ctx.ForEach(func(key string, value interface{}) bool { return true })
+
+					atomic.AddInt32(&executed, 1)
+
+					return nil
+				})
+
+				trigger := (&TriggerBuilder{}).
+					StartNow().
+					WithSchedule(&SimpleScheduleBuilder{time.Millisecond, 4}).
+					Build()
+
+				_, err := scheduler.ScheduleJob(job, trigger)
+
+				So(err, ShouldBeNil)
+			}
+
+			So(waitFor(time.Second, func() bool { return atomic.LoadInt32(&executed) == jobs*5 }), ShouldBeTrue)
+
+			ctx := scheduler.Context()
+
+			So(ctx.Get("counter"), ShouldEqual, jobs*5)
+			So(ctx.Len(), ShouldEqual, jobs+1)
+			So(ctx.Dirty(), ShouldBeTrue)
+		})
+	})
+}
+
+func TestStdSchedulerClear(t *testing.T) {
+	Convey("Given a started StdScheduler with several jobs and triggers", t, func() {
+		store := NewRAMJobStore()
+		scheduler := NewStdScheduler("scheduler", store, NewSimpleThreadPool(2))
+
+		defer scheduler.Shutdown()
+
+		listener := &recordingListener{name: "listener"}
+
+		scheduler.ListenerManager().AddSchedulerListener(listener)
+
+		So(scheduler.Start(), ShouldBeNil)
+
+		started := make(chan struct{})
+		release := make(chan struct{})
+		var completed int32
+
+		slow := NewJobDetailFromFunc("slow", func(context JobExecutionContext) error {
+			close(started)
+
+			<-release
+
+			atomic.StoreInt32(&completed, 1)
+
+			return nil
+		})
+
+		_, err := scheduler.ScheduleJob(slow, (&TriggerBuilder{}).StartNow().Build())
+
+		So(err, ShouldBeNil)
+
+		<-started
+
+		for _, group := range []string{"reports", "backup"} {
+			job := (&JobBuilder{}).WithGroupIdentity("job", group).StoreDurably(true).Build()
+
+			So(scheduler.AddJob(job, false), ShouldBeNil)
+
+			_, err := scheduler.Schedule((&TriggerBuilder{}).ForJobDetail(job).StartAt(time.Now().Add(time.Hour)).Build())
+
+			So(err, ShouldBeNil)
+		}
+
+		So(store.NumberOfJobs(), ShouldEqual, 3)
+		So(store.NumberOfTriggers(), ShouldEqual, 3)
+
+		Convey("The scheduler should be empty after it is cleared", func() {
+			So(scheduler.Clear(), ShouldBeNil)
+
+			So(store.NumberOfJobs(), ShouldEqual, 0)
+			So(store.NumberOfTriggers(), ShouldEqual, 0)
+
+			keys, err := scheduler.GetJobKeys(AnyGroup())
+
+			So(err, ShouldBeNil)
+			So(keys, ShouldBeEmpty)
+			So(listener.Events(), ShouldContain, "SchedulingDataCleared")
+
+			Convey("The executing job should be left to complete", func() {
+				close(release)
+
+				So(waitFor(time.Second, func() bool { return atomic.LoadInt32(&completed) == 1 }), ShouldBeTrue)
+				So(waitFor(time.Second, func() bool {
+					executing, _ := scheduler.CurrentlyExecutingJob()
+
+					return len(executing) == 0
+				}), ShouldBeTrue)
+				So(store.NumberOfJobs(), ShouldEqual, 0)
+			})
+		})
+	})
+}
+
+func TestStdSchedulerRetryJob(t *testing.T) {
+	Convey("Given a started StdScheduler with a job retried 3 times", t, func() {
+		scheduler := NewStdScheduler("scheduler", NewRAMJobStore(), NewSimpleThreadPool(2))
+
+		defer scheduler.Shutdown()
+
+		listener := &recordingListener{name: "listener"}
+
+		scheduler.ListenerManager().AddSchedulerListener(listener)
+
+		So(scheduler.Start(), ShouldBeNil)
+
+		newJob := func(failures int, attempts chan int) JobDetail {
+			return (&JobBuilder{}).
+				WithIdentity("job").
+				WithRetryPolicy(RetryPolicy{MaxAttempts: 3, InitialDelay: 10 * time.Millisecond, Multiplier: 2}).
+				UsingJobFactory(&singletonJobFactory{JobFunc(func(context JobExecutionContext) error {
+					attempt := context.MergedJobDataMap().GetIntOr(RetryAttemptKey, 1)
+
+					attempts <- attempt
+
+					if attempt <= failures {
+						return fmt.Errorf("attempt %d failed", attempt)
+					}
+
+					return nil
+				})}).
+				Build()
+		}
+
+		Convey("The job failing twice should succeed at the third attempt", func() {
+			attempts := make(chan int, 10)
+
+			_, err := scheduler.ScheduleJob(newJob(2, attempts), (&TriggerBuilder{}).StartNow().Build())
+
+			So(err, ShouldBeNil)
+
+			So(<-attempts, ShouldEqual, 1)
+			So(<-attempts, ShouldEqual, 2)
+			So(<-attempts, ShouldEqual, 3)
+
+			So(waitFor(time.Second, func() bool { return !scheduler.CheckJobExists(NewJobKey("job")) }), ShouldBeTrue)
+			So(attempts, ShouldBeEmpty)
+			So(listener.Events(), ShouldNotContain, "SchedulerError")
+		})
+
+		Convey("The job always failing should be given up after its attempts", func() {
+			attempts := make(chan int, 10)
+
+			_, err := scheduler.ScheduleJob(newJob(3, attempts), (&TriggerBuilder{}).StartNow().Build())
+
+			So(err, ShouldBeNil)
+
+			So(<-attempts, ShouldEqual, 1)
+			So(<-attempts, ShouldEqual, 2)
+			So(<-attempts, ShouldEqual, 3)
+
+			So(waitFor(time.Second, func() bool { return !scheduler.CheckJobExists(NewJobKey("job")) }), ShouldBeTrue)
+
+			time.Sleep(50 * time.Millisecond)
+
+			So(attempts, ShouldBeEmpty)
+			So(listener.Events(), ShouldContain, "SchedulerError")
+		})
+
+		Convey("The job persisting its data should not keep the attempt of its retry", func() {
+			attempts := make(chan int, 10)
+
+			job := (&JobBuilder{}).
+				WithIdentity("job").
+				StoreDurably(true).
+				PersistJobDataAfterExecution(true).
+				WithRetryPolicy(RetryPolicy{MaxAttempts: 2, InitialDelay: 10 * time.Millisecond}).
+				UsingJobFactory(&singletonJobFactory{JobFunc(func(context JobExecutionContext) error {
+					data := context.MergedJobDataMap()
+					attempt := data.GetIntOr(RetryAttemptKey, 1)
+
+					data.Put("runs", data.GetIntOr("runs", 0)+1)
+
+					attempts <- attempt
+
+					if attempt == 1 && data.GetIntOr("runs", 0) == 1 {
+						return fmt.Errorf("attempt %d failed", attempt)
+					}
+
+					return nil
+				})}).
+				Build()
+
+			So(scheduler.AddJob(job, false), ShouldBeNil)
+			So(scheduler.TriggerJob(job.Key()), ShouldBeNil)
+
+			So(<-attempts, ShouldEqual, 1)
+			So(<-attempts, ShouldEqual, 2)
+
+			So(waitFor(time.Second, func() bool {
+				stored := scheduler.GetJobDetail(job.Key())
+
+				return stored.JobDataMap().GetIntOr("runs", 0) == 2
+			}), ShouldBeTrue)
+
+			stored := scheduler.GetJobDetail(job.Key())
+
+			So(stored.JobDataMap().Contains(RetryAttemptKey), ShouldBeFalse)
+
+			So(scheduler.TriggerJob(job.Key()), ShouldBeNil)
+
+			So(<-attempts, ShouldEqual, 1)
+		})
+	})
+}
+
+func TestStdSchedulerMisfire(t *testing.T) {
+	Convey("Given a started StdScheduler with a ManualClock", t, func() {
+		clock := NewManualClock(time.Date(2016, time.March, 1, 8, 0, 0, 0, time.UTC))
+
+		scheduler := NewStdScheduler("scheduler", NewRAMJobStore(), NewSimpleThreadPool(2))
+		scheduler.SetClock(clock)
+
+		defer scheduler.Shutdown()
+
+		listener := &recordingListener{name: "listener"}
+
+		scheduler.ListenerManager().AddTriggerListener(listener)
+		scheduler.ListenerManager().AddSchedulerListener(listener)
+
+		var counter int32
+
+		job := NewJobDetailFromFunc("job", func(context JobExecutionContext) error {
+			atomic.AddInt32(&counter, 1)
+
+			return nil
+		})
+
+		schedule := func(b *TriggerBuilder) Trigger {
+			trigger := b.WithIdentity("trigger").StartAt(clock.Now().Add(time.Minute)).Build()
+
+			_, err := scheduler.ScheduleJob(job, trigger)
+
+			So(err, ShouldBeNil)
+			So(scheduler.Start(), ShouldBeNil)
+
+			time.Sleep(20 * time.Millisecond)
+
+			return trigger
+		}
+
+		Convey("The one-shot trigger later than the threshold should misfire and fire now", func() {
+			schedule(&TriggerBuilder{})
+
+			clock.Advance(5 * time.Minute)
+
+			So(waitFor(time.Second, func() bool { return atomic.LoadInt32(&counter) == 1 }), ShouldBeTrue)
+			So(listener.Events(), ShouldContain, "TriggerMisfired")
+		})
+
+		Convey("The repeating trigger later than the threshold should be rescheduled to its next fire time", func() {
+			trigger := schedule((&TriggerBuilder{}).WithSchedule(&SimpleScheduleBuilder{time.Minute, REPEAT_INDEFINITELY}))
+
+			clock.Advance(5*time.Minute + 30*time.Second)
+
+			So(waitFor(time.Second, func() bool {
+				return scheduler.GetTrigger(trigger.Key()).NextFireTime().Equal(clock.Now().Add(30 * time.Second))
+			}), ShouldBeTrue)
+			So(listener.Events(), ShouldContain, "TriggerMisfired")
+
+			time.Sleep(20 * time.Millisecond)
+
+			So(atomic.LoadInt32(&counter), ShouldEqual, 0)
+
+			clock.Advance(30 * time.Second)
+
+			So(waitFor(time.Second, func() bool { return atomic.LoadInt32(&counter) == 1 }), ShouldBeTrue)
+		})
+
+		Convey("The misfired trigger past its end time should be finalized", func() {
+			trigger := schedule((&TriggerBuilder{}).
+				EndAt(clock.Now().Add(3 * time.Minute)).
+				WithSchedule(&SimpleScheduleBuilder{time.Minute, REPEAT_INDEFINITELY}))
+
+			clock.Advance(5 * time.Minute)
+
+			So(waitFor(time.Second, func() bool { return !scheduler.CheckTriggerExists(trigger.Key()) }), ShouldBeTrue)
+			So(listener.Events(), ShouldContain, "TriggerMisfired")
+			So(listener.Events(), ShouldContain, "TriggerFinalized")
+			So(atomic.LoadInt32(&counter), ShouldEqual, 0)
+		})
+
+		Convey("The trigger later than less than the threshold should not misfire", func() {
+			schedule(&TriggerBuilder{})
+
+			clock.Advance(90 * time.Second)
+
+			So(waitFor(time.Second, func() bool { return atomic.LoadInt32(&counter) == 1 }), ShouldBeTrue)
+			So(listener.Events(), ShouldNotContain, "TriggerMisfired")
+		})
+	})
+}
+
+// countingJobStore counts the acquisitions of the scheduling loop, and records the sizes of the fired batches.
+type countingJobStore struct {
+	*RAMJobStore
+
+	acquisitions int32
+
+	lock    sync.Mutex
+	batches []int
+}
+
+func (s *countingJobStore) AcquireNextTriggers(noLaterThan time.Time, maxCount int, timeWindow time.Duration) ([]OperableTrigger, error) {
+	atomic.AddInt32(&s.acquisitions, 1)
+
+	return s.RAMJobStore.AcquireNextTriggers(noLaterThan, maxCount, timeWindow)
+}
+
+func (s *countingJobStore) TriggersFired(triggers []OperableTrigger) ([]*TriggerFiredBundle, error) {
+	bundles, err := s.RAMJobStore.TriggersFired(triggers)
+
+	s.lock.Lock()
+	s.batches = append(s.batches, len(bundles))
+	s.lock.Unlock()
+
+	return bundles, err
+}
+
+func (s *countingJobStore) Batches() []int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return append([]int(nil), s.batches...)
+}
+
+func TestStdSchedulerIdleWait(t *testing.T) {
+	Convey("Given a started StdScheduler with a long idle wait time", t, func() {
+		store := &countingJobStore{RAMJobStore: NewRAMJobStore()}
+
+		scheduler := NewStdScheduler("scheduler", store, NewSimpleThreadPool(2))
+		scheduler.idleWaitTime = time.Hour
+
+		defer scheduler.Shutdown()
+
+		So(scheduler.Start(), ShouldBeNil)
+
+		fired := make(chan time.Time, 1)
+
+		job := NewJobDetailFromFunc("job", func(context JobExecutionContext) error {
+			fired <- time.Now()
+
+			return nil
+		})
+
+		Convey("The idle loop should not poll the store", func() {
+			time.Sleep(20 * time.Millisecond)
+
+			acquisitions := atomic.LoadInt32(&store.acquisitions)
+
+			time.Sleep(50 * time.Millisecond)
+
+			So(atomic.LoadInt32(&store.acquisitions), ShouldEqual, acquisitions)
+		})
+
+		Convey("The trigger scheduled while idle should fire promptly", func() {
+			time.Sleep(20 * time.Millisecond)
+
+			startTime := time.Now().Add(50 * time.Millisecond)
+
+			_, err := scheduler.ScheduleJob(job, (&TriggerBuilder{}).StartAt(startTime).Build())
+
+			So(err, ShouldBeNil)
+
+			select {
+			case fireTime := <-fired:
+				So(fireTime, ShouldHappenOnOrBetween, startTime, startTime.Add(500*time.Millisecond))
+			case <-time.After(time.Second):
+				So("the trigger fired", ShouldBeNil)
+			}
+		})
+
+		Convey("The trigger scheduled before the awaited one should fire promptly", func() {
+			later := NewJobDetailFromFunc("later", func(context JobExecutionContext) error { return nil })
+
+			_, err := scheduler.ScheduleJob(later, (&TriggerBuilder{}).StartAt(time.Now().Add(10*time.Minute)).Build())
+
+			So(err, ShouldBeNil)
+
+			time.Sleep(20 * time.Millisecond)
+
+			startTime := time.Now().Add(50 * time.Millisecond)
+
+			_, err = scheduler.ScheduleJob(job, (&TriggerBuilder{}).StartAt(startTime).Build())
+
+			So(err, ShouldBeNil)
+
+			select {
+			case fireTime := <-fired:
+				So(fireTime, ShouldHappenOnOrBetween, startTime, startTime.Add(500*time.Millisecond))
+			case <-time.After(time.Second):
+				So("the trigger fired", ShouldBeNil)
+			}
+		})
+	})
+}
+
+func TestStdSchedulerBatchAcquisition(t *testing.T) {
+	Convey("Given a StdScheduler acquiring the triggers in batches", t, func() {
+		store := &countingJobStore{RAMJobStore: NewRAMJobStore()}
+
+		scheduler := NewStdScheduler("scheduler", store, NewSimpleThreadPool(4))
+		scheduler.maxBatchSize = 2
+		scheduler.batchTimeWindow = 100 * time.Millisecond
+
+		defer scheduler.Shutdown()
+
+		var counter int32
+
+		job := (&JobBuilder{}).
+			WithIdentity("job").
+			StoreDurably(true).
+			UsingJobFactory(&singletonJobFactory{JobFunc(func(context JobExecutionContext) error {
+				atomic.AddInt32(&counter, 1)
+
+				return nil
+			})}).
+			Build()
+
+		So(scheduler.AddJob(job, false), ShouldBeNil)
+
+		startTime := time.Now().Add(100 * time.Millisecond)
+
+		for i, delay := range []time.Duration{0, 10 * time.Millisecond, 20 * time.Millisecond, time.Second} {
+			trigger := (&TriggerBuilder{}).
+				WithIdentity(fmt.Sprintf("trigger-%d", i)).
+				ForJobDetail(job).
+				StartAt(startTime.Add(delay)).
+				Build()
+
+			_, err := scheduler.Schedule(trigger)
+
+			So(err, ShouldBeNil)
+		}
+
+		So(scheduler.Start(), ShouldBeNil)
+
+		Convey("The batches should be limited by the batch size and the time window", func() {
+			So(waitFor(2*time.Second, func() bool { return atomic.LoadInt32(&counter) == 4 }), ShouldBeTrue)
+
+			So(store.Batches(), ShouldResemble, []int{2, 1, 1})
+		})
+	})
+}
@@ -1,5 +1,10 @@
 package quartz
 
+import (
+	"fmt"
+	"time"
+)
+
 //
 // The interface to be implemented by classes that want to provide a Job and Trigger storage mechanism for the QuartzScheduler's use.
 type JobStore interface {
@@ -23,10 +28,14 @@ type JobStore interface {
 
 	StoreTrigger(trigger OperableTrigger, replaceExisting bool) error
 
+	// Store the triggers at once, nothing is stored if one of them failed.
+	StoreTriggers(triggers []OperableTrigger, replaceExisting bool) error
+
 	RemoveJob(key JobKey) (bool, error)
 
 	RemoveJobs(keys []JobKey) (bool, error)
 
+	// Retrieve a copy of the job, or nil if it doesn't exist, the caller may change the copy.
 	RetrieveJob(key JobKey) (JobDetail, error)
 
 	RemoveTrigger(key TriggerKey) (bool, error)
@@ -35,8 +44,17 @@ type JobStore interface {
 
 	ReplaceTrigger(key TriggerKey, trigger OperableTrigger) error
 
+	// Retrieve a copy of the trigger, or nil if it doesn't exist, the caller may change the copy.
 	RetrieveTrigger(key TriggerKey) (OperableTrigger, error)
 
+	RetrieveTriggerWithState(key TriggerKey) (OperableTrigger, TriggerState, time.Time, error)
+
+	// Get the current state of the trigger.
+	GetTriggerState(key TriggerKey) (TriggerState, error)
+
+	// Reset the trigger from STATE_ERROR, so it may be acquired again.
+	ResetTriggerFromErrorState(key TriggerKey) error
+
 	CheckJobExists(key JobKey) bool
 
 	CheckTriggerExists(key TriggerKey) bool
@@ -45,8 +63,27 @@ type JobStore interface {
 
 	NumberOfTriggers() int
 
+	// Get copies of the triggers of the job.
 	TriggersForJob(key JobKey) []OperableTrigger
 
+	// Get the keys of the jobs in the groups matched by the matcher, sorted by their groups and names.
+	GetJobKeys(matcher GroupMatcher) ([]JobKey, error)
+
+	// Get the keys of the triggers in the groups matched by the matcher, sorted by their groups and names.
+	GetTriggerKeys(matcher GroupMatcher) ([]TriggerKey, error)
+
+	// Store the calendar with the name, it's an error to replace an existing calendar unless replaceExisting is set.
+	StoreCalendar(name string, calendar Calendar, replaceExisting bool) error
+
+	// Remove the calendar with the name, it's an error to remove a calendar referenced by a trigger.
+	RemoveCalendar(name string) (bool, error)
+
+	// Retrieve the calendar with the name, or nil if it does not exist.
+	RetrieveCalendar(name string) (Calendar, error)
+
+	// Get the names of the stored calendars, sorted by their names.
+	GetCalendarNames() ([]string, error)
+
 	PauseJob(key JobKey) error
 
 	PauseTrigger(key TriggerKey) error
@@ -55,7 +92,126 @@ type JobStore interface {
 
 	ResumeTrigger(key TriggerKey) error
 
+	// Pause the triggers in the groups matched by the matcher, and return the paused groups.
+	PauseTriggers(matcher GroupMatcher) ([]string, error)
+
+	// Resume the triggers in the groups matched by the matcher, and return the resumed groups.
+	ResumeTriggers(matcher GroupMatcher) ([]string, error)
+
+	// Pause the triggers of the jobs in the groups matched by the matcher, and return the paused groups.
+	PauseJobs(matcher GroupMatcher) ([]string, error)
+
+	// Resume the triggers of the jobs in the groups matched by the matcher, and return the resumed groups.
+	ResumeJobs(matcher GroupMatcher) ([]string, error)
+
 	PauseAll() error
 
 	ResumeAll() error
+
+	// Clear (delete!) all scheduling data - all jobs, triggers and calendars, the paused groups are kept.
+	ClearAllSchedulingData() error
+
+	// Get a handle to the next triggers to be fired, and mark them as 'reserved' by the calling scheduler.
+	//
+	// At most maxCount triggers are acquired, the first one no later than noLaterThan,
+	// and the others no later than the timeWindow after the first one, or after now if it is overdue,
+	// so they are fired together.
+	AcquireNextTriggers(noLaterThan time.Time, maxCount int, timeWindow time.Duration) ([]OperableTrigger, error)
+
+	// Inform the JobStore that the scheduler no longer plans to fire the given trigger, that it had previously acquired.
+	ReleaseAcquiredTrigger(trigger OperableTrigger)
+
+	// Inform the JobStore that the scheduler is now firing the given triggers, that it had previously acquired.
+	TriggersFired(triggers []OperableTrigger) ([]*TriggerFiredBundle, error)
+
+	// Inform the JobStore that the scheduler has completed the firing of the given trigger and its job,
+	// the JobDataMap of the JobDetail is stored again if the job persists its data after execution.
+	TriggeredJobComplete(trigger OperableTrigger, jobDetail JobDetail, instruction CompletedExecutionInstruction)
+}
+
+// ErrJobAlreadyExists is returned when a job is stored with the key of an existing job, without replacing it.
+//
+// It matches any ErrJobAlreadyExists without key with errors.Is, use errors.As to get the key.
+type ErrJobAlreadyExists struct {
+	Key JobKey
+}
+
+func (e *ErrJobAlreadyExists) Error() string {
+	return fmt.Sprintf("Unable to store Job : '%s', because one already exists with this identification.", e.Key)
+}
+
+func (e *ErrJobAlreadyExists) Is(target error) bool {
+	t, ok := target.(*ErrJobAlreadyExists)
+
+	return ok && (t.Key == nil || t.Key.Equals(e.Key))
+}
+
+// ErrTriggerAlreadyExists is returned when a trigger is stored with the key of an existing trigger, without replacing it.
+//
+// It matches any ErrTriggerAlreadyExists without key with errors.Is, use errors.As to get the key.
+type ErrTriggerAlreadyExists struct {
+	Key TriggerKey
+}
+
+func (e *ErrTriggerAlreadyExists) Error() string {
+	return fmt.Sprintf("Unable to store Trigger with name: '%s' and group: '%s', "+
+		"because one already exists with this identification.", e.Key.Name(), e.Key.Group())
+}
+
+func (e *ErrTriggerAlreadyExists) Is(target error) bool {
+	t, ok := target.(*ErrTriggerAlreadyExists)
+
+	return ok && (t.Key == nil || t.Key.Equals(e.Key))
+}
+
+// ErrJobNotFound is returned when the requested job does not exist.
+//
+// It matches any ErrJobNotFound without key with errors.Is, use errors.As to get the key.
+type ErrJobNotFound struct {
+	Key JobKey
+}
+
+func (e *ErrJobNotFound) Error() string {
+	return fmt.Sprintf("The job (%s) does not exist.", e.Key)
+}
+
+func (e *ErrJobNotFound) Is(target error) bool {
+	t, ok := target.(*ErrJobNotFound)
+
+	return ok && (t.Key == nil || t.Key.Equals(e.Key))
+}
+
+// ErrTriggerNotFound is returned when the requested trigger does not exist.
+//
+// It matches any ErrTriggerNotFound without key with errors.Is, use errors.As to get the key.
+type ErrTriggerNotFound struct {
+	Key TriggerKey
+}
+
+func (e *ErrTriggerNotFound) Error() string {
+	return fmt.Sprintf("The trigger (%s) does not exist.", e.Key)
+}
+
+func (e *ErrTriggerNotFound) Is(target error) bool {
+	t, ok := target.(*ErrTriggerNotFound)
+
+	return ok && (t.Key == nil || t.Key.Equals(e.Key))
+}
+
+// ErrUnableToResolveJob is returned when a trigger is stored for a job which does not exist.
+//
+// It matches any ErrUnableToResolveJob without key with errors.Is, use errors.As to get the keys.
+type ErrUnableToResolveJob struct {
+	Key        JobKey
+	TriggerKey TriggerKey
+}
+
+func (e *ErrUnableToResolveJob) Error() string {
+	return fmt.Sprintf("The job (%s) referenced by the trigger (%s) does not exist.", e.Key, e.TriggerKey)
+}
+
+func (e *ErrUnableToResolveJob) Is(target error) bool {
+	t, ok := target.(*ErrUnableToResolveJob)
+
+	return ok && (t.Key == nil || t.Key.Equals(e.Key))
 }
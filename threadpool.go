@@ -0,0 +1,155 @@
+package quartz
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ThreadPool provides the goroutines for the Scheduler to execute jobs.
+type ThreadPool interface {
+	// RunTask executes the task on an available worker, blocking until one is available.
+	//
+	// It returns false if the pool has been shutdown.
+	RunTask(task func()) bool
+
+	// BlockForAvailableThreads blocks until at least one worker is available,
+	// and returns the number of available workers, or 0 if the pool has been shutdown.
+	BlockForAvailableThreads() int
+
+	// Initialize starts the workers of the pool.
+	Initialize() error
+
+	// Shutdown stops the workers, and waits for the executing tasks if waitForJobsToComplete.
+	Shutdown(waitForJobsToComplete bool)
+
+	// PoolSize returns the number of workers in the pool.
+	PoolSize() int
+}
+
+func invalidThreadCountError(count int) error {
+	return fmt.Errorf("Thread count must be > 0, but was %d.", count)
+}
+
+// SimpleThreadPool is a ThreadPool with a fixed number of workers.
+type SimpleThreadPool struct {
+	count int
+
+	lock        sync.Mutex
+	cond        *sync.Cond
+	busy        int
+	initialized bool
+	shutdown    bool
+	tasks       chan func()
+	workers     sync.WaitGroup
+}
+
+func NewSimpleThreadPool(count int) *SimpleThreadPool {
+	pool := &SimpleThreadPool{count: count}
+
+	pool.cond = sync.NewCond(&pool.lock)
+
+	return pool
+}
+
+func (p *SimpleThreadPool) PoolSize() int { return p.count }
+
+// Initialize starts the workers, it does nothing if the pool has been initialized.
+func (p *SimpleThreadPool) Initialize() error {
+	if p.count < 1 {
+		return invalidThreadCountError(p.count)
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.shutdown {
+		return ErrSchedulerShutdown
+	}
+
+	if p.initialized {
+		return nil
+	}
+
+	p.initialized = true
+	p.tasks = make(chan func(), p.count)
+
+	for i := 0; i < p.count; i++ {
+		p.workers.Add(1)
+
+		go p.work()
+	}
+
+	return nil
+}
+
+func (p *SimpleThreadPool) work() {
+	defer p.workers.Done()
+
+	for task := range p.tasks {
+		task()
+
+		p.lock.Lock()
+		p.busy--
+		p.cond.Broadcast()
+		p.lock.Unlock()
+	}
+}
+
+func (p *SimpleThreadPool) RunTask(task func()) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for p.initialized && !p.shutdown && p.busy >= p.count {
+		p.cond.Wait()
+	}
+
+	if !p.initialized || p.shutdown {
+		return false
+	}
+
+	p.busy++
+
+	// the buffer never blocks because the pending and executing tasks are less than count.
+	p.tasks <- task
+
+	return true
+}
+
+func (p *SimpleThreadPool) BlockForAvailableThreads() int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for p.initialized && !p.shutdown && p.busy >= p.count {
+		p.cond.Wait()
+	}
+
+	if !p.initialized || p.shutdown {
+		return 0
+	}
+
+	return p.count - p.busy
+}
+
+func (p *SimpleThreadPool) Shutdown(waitForJobsToComplete bool) {
+	p.lock.Lock()
+
+	if p.shutdown {
+		p.lock.Unlock()
+
+		return
+	}
+
+	p.shutdown = true
+
+	if p.initialized {
+		close(p.tasks)
+	}
+
+	p.cond.Broadcast()
+
+	p.lock.Unlock()
+
+	if waitForJobsToComplete {
+		p.workers.Wait()
+	}
+}
@@ -0,0 +1,77 @@
+package quartz
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSimpleThreadPool(t *testing.T) {
+	Convey("Given a SimpleThreadPool", t, func() {
+		pool := NewSimpleThreadPool(2)
+
+		So(pool.PoolSize(), ShouldEqual, 2)
+		So(pool.RunTask(func() {}), ShouldBeFalse)
+		So(pool.BlockForAvailableThreads(), ShouldEqual, 0)
+
+		So(pool.Initialize(), ShouldBeNil)
+		So(pool.Initialize(), ShouldBeNil)
+
+		defer pool.Shutdown(false)
+
+		So(pool.BlockForAvailableThreads(), ShouldEqual, 2)
+
+		Convey("When all the workers are busy", func() {
+			release := make(chan struct{})
+			var executed int32
+
+			task := func() {
+				<-release
+
+				atomic.AddInt32(&executed, 1)
+			}
+
+			So(pool.RunTask(task), ShouldBeTrue)
+			So(pool.RunTask(task), ShouldBeTrue)
+
+			blocked := make(chan int)
+
+			go func() { blocked <- pool.BlockForAvailableThreads() }()
+
+			Convey("The pool should block until a worker is available", func() {
+				select {
+				case <-blocked:
+					So("BlockForAvailableThreads", ShouldBeEmpty)
+				case <-time.After(20 * time.Millisecond):
+				}
+
+				release <- struct{}{}
+
+				So(<-blocked, ShouldEqual, 1)
+				So(pool.RunTask(task), ShouldBeTrue)
+
+				close(release)
+
+				So(waitFor(time.Second, func() bool { return atomic.LoadInt32(&executed) == 3 }), ShouldBeTrue)
+				So(pool.BlockForAvailableThreads(), ShouldEqual, 2)
+			})
+
+			Convey("The shutdown should wait for the executing tasks", func() {
+				time.AfterFunc(20*time.Millisecond, func() { close(release) })
+
+				pool.Shutdown(true)
+
+				So(atomic.LoadInt32(&executed), ShouldEqual, 2)
+				So(<-blocked, ShouldEqual, 0)
+				So(pool.RunTask(task), ShouldBeFalse)
+				So(pool.Initialize(), ShouldNotBeNil)
+			})
+		})
+	})
+
+	Convey("Given a SimpleThreadPool without workers", t, func() {
+		So(NewSimpleThreadPool(0).Initialize(), ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,18 @@
+package quartz
+
+import "context"
+
+// Tracer wraps each execution of a job in a span, it can be implemented with a tracing system, e.g. OpenTelemetry.
+type Tracer interface {
+	// StartJobSpan starts the span of the execution of the job fired by the trigger,
+	// the returned context is passed to the job by JobExecutionContext.Context(),
+	// and the returned function ends the span with the error returned by the job.
+	StartJobSpan(ctx context.Context, detail JobDetail, trigger Trigger) (context.Context, func(err error))
+}
+
+// NoopTracer is the default Tracer which doesn't trace the executions.
+type NoopTracer struct{}
+
+func (NoopTracer) StartJobSpan(ctx context.Context, detail JobDetail, trigger Trigger) (context.Context, func(err error)) {
+	return ctx, func(err error) {}
+}
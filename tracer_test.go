@@ -0,0 +1,69 @@
+package quartz
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type spanKey struct{}
+
+type recordingTracer struct {
+	lock  sync.Mutex
+	spans []string
+	ended chan error
+}
+
+func (t *recordingTracer) StartJobSpan(ctx context.Context, detail JobDetail, trigger Trigger) (context.Context, func(err error)) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	span := detail.Key().String() + " by " + trigger.Key().String()
+
+	t.spans = append(t.spans, span)
+
+	return context.WithValue(ctx, spanKey{}, span), func(err error) { t.ended <- err }
+}
+
+func TestStdSchedulerTracer(t *testing.T) {
+	Convey("Given a started StdScheduler with a recording Tracer", t, func() {
+		scheduler := NewStdScheduler("scheduler", NewRAMJobStore(), NewSimpleThreadPool(2))
+
+		defer scheduler.Shutdown()
+
+		tracer := &recordingTracer{ended: make(chan error, 1)}
+
+		scheduler.SetTracer(tracer)
+
+		So(scheduler.Start(), ShouldBeNil)
+
+		spans := make(chan interface{}, 1)
+
+		job := NewJobDetailFromFunc("job", func(context JobExecutionContext) error {
+			spans <- context.Context().Value(spanKey{})
+
+			return errors.New("failed")
+		})
+
+		_, err := scheduler.ScheduleJob(job, (&TriggerBuilder{}).WithIdentity("trigger").StartNow().Build())
+
+		So(err, ShouldBeNil)
+
+		Convey("The job should be executed in the span ended with its error", func() {
+			So(<-spans, ShouldEqual, "DEFAULT.job by DEFAULT.trigger")
+
+			err := <-tracer.ended
+
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, "failed")
+
+			tracer.lock.Lock()
+			defer tracer.lock.Unlock()
+
+			So(tracer.spans, ShouldResemble, []string{"DEFAULT.job by DEFAULT.trigger"})
+		})
+	})
+}
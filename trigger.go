@@ -16,6 +16,23 @@ const (
 	REPEAT_INDEFINITELY = -1
 )
 
+// CompletedExecutionInstruction tells the JobStore what to do with the trigger after the job completed.
+type CompletedExecutionInstruction int
+
+const (
+	NOOP CompletedExecutionInstruction = iota
+	RE_EXECUTE_JOB
+	SET_TRIGGER_COMPLETE
+	DELETE_TRIGGER
+	SET_ALL_JOB_TRIGGERS_COMPLETE
+	SET_TRIGGER_ERROR
+	SET_ALL_JOB_TRIGGERS_ERROR
+
+	// JOB_VETOED is reported to the TriggerListener when a listener vetoed the job execution,
+	// the JobStore still completes the trigger as if the job had been executed.
+	JOB_VETOED
+)
+
 // The base interface with properties common to all Triggers -
 // use TriggerBuilder to instantiate an actual Trigger.
 type Trigger interface {
@@ -35,6 +52,12 @@ type Trigger interface {
 
 	EndTime() time.Time
 
+	// TimeZone returns the location of the fire times, defaults to the location of the start time.
+	TimeZone() *time.Location
+
+	// CalendarName returns the name of the stored Calendar excluding the fire times of the trigger, if any.
+	CalendarName() string
+
 	NextFireTime() time.Time
 
 	PreviousFireTime() time.Time
@@ -46,6 +69,44 @@ type Trigger interface {
 	TriggerBuilder() *TriggerBuilder
 
 	ScheduleBuilder() ScheduleBuilder
+
+	// CompareTo orders the triggers by their next fire times, then by their priorities descending and then by their keys,
+	// the triggers without next fire time are ordered last since they will never fire.
+	CompareTo(other Trigger) int
+}
+
+// TriggerComparator compares the triggers with Trigger.CompareTo, it can be used as a CompareFunc.
+func TriggerComparator(lhs, rhs interface{}) int {
+	return lhs.(Trigger).CompareTo(rhs.(Trigger))
+}
+
+func compareTriggers(lhs, rhs Trigger) int {
+	lhsTime, rhsTime := lhs.NextFireTime(), rhs.NextFireTime()
+
+	switch {
+	case lhsTime.IsZero() != rhsTime.IsZero():
+		if lhsTime.IsZero() {
+			return 1
+		}
+
+		return -1
+
+	case !lhsTime.Equal(rhsTime):
+		if lhsTime.Before(rhsTime) {
+			return -1
+		}
+
+		return 1
+
+	case lhs.Priority() != rhs.Priority():
+		if lhs.Priority() > rhs.Priority() {
+			return -1
+		}
+
+		return 1
+	}
+
+	return groupedKeyCompare(lhs.Key(), rhs.Key())
 }
 
 type MutableTrigger interface {
@@ -63,6 +124,10 @@ type MutableTrigger interface {
 
 	SetEndTime(endTime time.Time) error
 
+	SetTimeZone(loc *time.Location)
+
+	SetCalendarName(name string)
+
 	SetJobDataMap(dataMap JobDataMap)
 }
 
@@ -73,6 +138,20 @@ type OperableTrigger interface {
 	SetNextFireTime(nextFireTime time.Time)
 
 	SetPreviousFireTime(previousFireTime time.Time)
+
+	// Called when the Scheduler has decided to 'fire' the trigger, the trigger updates itself for its next fire time.
+	Triggered(cal Calendar)
+
+	// Called when the trigger is first added to the Scheduler, the trigger computes its first fire time
+	// excluded by the calendar, and returns the zero time if it will never fire.
+	ComputeFirstFireTime(cal Calendar) time.Time
+
+	// Called when the Scheduler has found the trigger misfired, the trigger updates its next fire time after now,
+	// which is the time of the Scheduler's Clock, or to the zero time if it will never fire again.
+	UpdateAfterMisfire(cal Calendar, now time.Time)
+
+	// Validates whether the properties of the trigger are configured properly, the invalid trigger is not scheduled.
+	Validate() error
 }
 
 type TriggerKey []byte
@@ -85,6 +164,7 @@ func NewGroupTriggerKey(name, group string) TriggerKey {
 	return TriggerKey(fmt.Sprintf("%s.%s", group, name))
 }
 
+// NewUniqueTriggerKey creates a TriggerKey with a unique name in the group, or in the DEFAULT_GROUP if the group is empty.
 func NewUniqueTriggerKey(group string) TriggerKey {
 	if len(group) == 0 {
 		group = DEFAULT_GROUP
@@ -107,6 +187,8 @@ type abstractTrigger struct {
 	dataMap  JobDataMap
 	priority int
 	key      TriggerKey
+	timeZone *time.Location
+	calName  string
 }
 
 func (t *abstractTrigger) Key() TriggerKey {
@@ -162,6 +244,42 @@ func (t *abstractTrigger) Priority() int { return t.priority }
 
 func (t *abstractTrigger) SetPriority(priority int) { t.priority = priority }
 
+// timeZoneOr returns the time zone of the trigger, or the location of the start time if it wasn't set.
+func (t *abstractTrigger) timeZoneOr(startTime time.Time) *time.Location {
+	if t.timeZone == nil {
+		return startTime.Location()
+	}
+
+	return t.timeZone
+}
+
+func (t *abstractTrigger) SetTimeZone(loc *time.Location) { t.timeZone = loc }
+
+func (t *abstractTrigger) CalendarName() string { return t.calName }
+
+func (t *abstractTrigger) SetCalendarName(name string) { t.calName = name }
+
+// inTimeZone converts the time to the time zone of the trigger, if it was set.
+func (t *abstractTrigger) inTimeZone(tm time.Time) time.Time {
+	if t.timeZone == nil || tm.IsZero() {
+		return tm
+	}
+
+	return tm.In(t.timeZone)
+}
+
+func (t *abstractTrigger) validate() error {
+	if t.name == "" {
+		return errors.New("Trigger's name cannot be null")
+	}
+
+	if t.jobName == "" {
+		return errors.New("Trigger's related Job's name cannot be null")
+	}
+
+	return nil
+}
+
 type simpleTrigger struct {
 	abstractTrigger
 
@@ -175,8 +293,20 @@ type simpleTrigger struct {
 	complete         bool
 }
 
+func (t *simpleTrigger) Clone() interface{} {
+	clone := *t
+
+	if t.dataMap != nil {
+		clone.dataMap = t.dataMap.CloneDataMap()
+	}
+
+	return &clone
+}
+
 func (t *simpleTrigger) StartTime() time.Time { return t.startTime }
 
+func (t *simpleTrigger) TimeZone() *time.Location { return t.timeZoneOr(t.startTime) }
+
 func (t *simpleTrigger) SetStartTime(startTime time.Time) error {
 	if startTime.IsZero() {
 		return errors.New("Start time cannot be null")
@@ -205,6 +335,8 @@ func (t *simpleTrigger) SetEndTime(endTime time.Time) error {
 
 func (t *simpleTrigger) NextFireTime() time.Time { return t.nextFireTime }
 
+func (t *simpleTrigger) CompareTo(other Trigger) int { return compareTriggers(t, other) }
+
 func (t *simpleTrigger) SetNextFireTime(nextFireTime time.Time) { t.nextFireTime = nextFireTime }
 
 func (t *simpleTrigger) PreviousFireTime() time.Time { return t.previousFireTime }
@@ -213,6 +345,8 @@ func (t *simpleTrigger) SetPreviousFireTime(previousFireTime time.Time) {
 	t.previousFireTime = previousFireTime
 }
 
+// FireTimeAfter returns the next fire time after the given time in the time zone of the trigger,
+// the trigger repeats at a fixed interval, so a daylight saving time change shifts its wall clock times.
 func (t *simpleTrigger) FireTimeAfter(afterTime time.Time) time.Time {
 	if t.complete {
 		return zero
@@ -235,7 +369,11 @@ func (t *simpleTrigger) FireTimeAfter(afterTime time.Time) time.Time {
 	}
 
 	if afterTime.Before(t.startTime) {
-		return t.startTime
+		return t.inTimeZone(t.startTime)
+	}
+
+	if t.repeatInterval <= 0 {
+		return zero
 	}
 
 	numberOfTimesExecuted := int(afterTime.Sub(t.startTime)/t.repeatInterval) + 1
@@ -246,11 +384,88 @@ func (t *simpleTrigger) FireTimeAfter(afterTime time.Time) time.Time {
 
 	fireTime := t.startTime.Add(time.Duration(numberOfTimesExecuted) * t.repeatInterval)
 
-	if t.endTime.Before(fireTime) {
+	if !t.endTime.IsZero() && t.endTime.Before(fireTime) {
 		return zero
 	}
 
-	return fireTime
+	return t.inTimeZone(fireTime)
+}
+
+// yearToGiveUpSchedulingAt stops looking for a fire time included by the calendar.
+const yearToGiveUpSchedulingAt = 2299
+
+func (t *simpleTrigger) Triggered(cal Calendar) {
+	t.timesTriggered++
+	t.previousFireTime = t.nextFireTime
+	t.nextFireTime = t.FireTimeAfter(t.nextFireTime)
+
+	for !t.nextFireTime.IsZero() && cal != nil && !cal.IsTimeIncluded(t.nextFireTime) {
+		t.nextFireTime = t.FireTimeAfter(t.nextFireTime)
+
+		if t.nextFireTime.Year() > yearToGiveUpSchedulingAt {
+			t.nextFireTime = zero
+		}
+	}
+}
+
+// UpdateAfterMisfire fires a one-shot trigger now, and reschedules a repeating trigger
+// to its next fire time after now, the missed fire times are counted as triggered.
+func (t *simpleTrigger) UpdateAfterMisfire(cal Calendar, now time.Time) {
+	if t.repeatCount == 0 {
+		t.nextFireTime = now
+
+		return
+	}
+
+	newFireTime := t.FireTimeAfter(now)
+
+	for !newFireTime.IsZero() && cal != nil && !cal.IsTimeIncluded(newFireTime) {
+		newFireTime = t.FireTimeAfter(newFireTime)
+
+		if newFireTime.Year() > yearToGiveUpSchedulingAt {
+			newFireTime = zero
+		}
+	}
+
+	if !newFireTime.IsZero() && !t.nextFireTime.IsZero() {
+		t.timesTriggered += int(newFireTime.Sub(t.nextFireTime) / t.repeatInterval)
+	}
+
+	t.nextFireTime = newFireTime
+}
+
+func (t *simpleTrigger) ComputeFirstFireTime(cal Calendar) time.Time {
+	t.nextFireTime = t.inTimeZone(t.startTime)
+
+	for !t.nextFireTime.IsZero() && cal != nil && !cal.IsTimeIncluded(t.nextFireTime) {
+		t.nextFireTime = t.FireTimeAfter(t.nextFireTime)
+
+		if t.nextFireTime.Year() > yearToGiveUpSchedulingAt {
+			t.nextFireTime = zero
+		}
+	}
+
+	return t.nextFireTime
+}
+
+func (t *simpleTrigger) Validate() error {
+	if err := t.abstractTrigger.validate(); err != nil {
+		return err
+	}
+
+	if t.repeatCount < 0 && t.repeatCount != REPEAT_INDEFINITELY {
+		return errors.New("Repeat count must be >= 0, use the constant REPEAT_INDEFINITELY for infinite.")
+	}
+
+	if t.repeatCount != 0 && t.repeatInterval <= 0 {
+		return errors.New("Repeat Interval cannot be zero.")
+	}
+
+	if !t.endTime.IsZero() && t.endTime.Before(t.startTime) {
+		return errors.New("End time cannot be before start time")
+	}
+
+	return nil
 }
 
 func (t *simpleTrigger) FireTimeBefore(endTime time.Time) time.Time {
@@ -302,6 +517,8 @@ func (t *simpleTrigger) TriggerBuilder() *TriggerBuilder {
 		StartTime:       t.startTime,
 		EndTime:         t.endTime,
 		Priority:        t.priority,
+		TimeZone:        t.timeZone,
+		CalendarName:    t.calName,
 		JobKey:          t.JobKey(),
 		DataMap:         t.dataMap,
 		ScheduleBuilder: t.ScheduleBuilder(),
@@ -320,10 +537,15 @@ type TriggerBuilder struct {
 	Key                TriggerKey
 	Description        string
 	StartTime, EndTime time.Time
+	TimeZone           *time.Location
+	CalendarName       string
 	Priority           int
 	JobKey             JobKey
 	DataMap            JobDataMap
 	ScheduleBuilder    ScheduleBuilder
+
+	// Clock provides the start time of StartNow and of the trigger without start time, defaults to SystemClock.
+	Clock Clock
 }
 
 func (b *TriggerBuilder) WithIdentity(name string) *TriggerBuilder {
@@ -362,18 +584,49 @@ func (b *TriggerBuilder) StartAt(startTime time.Time) *TriggerBuilder {
 	return b
 }
 
+// StartNow starts the trigger at the current time of the Clock, so WithClock must be called before it.
 func (b *TriggerBuilder) StartNow() *TriggerBuilder {
-	b.StartTime = time.Now()
+	b.StartTime = b.now()
 
 	return b
 }
 
+// WithClock sets the Clock providing the current time, e.g. the Clock of the Scheduler.
+func (b *TriggerBuilder) WithClock(clock Clock) *TriggerBuilder {
+	b.Clock = clock
+
+	return b
+}
+
+func (b *TriggerBuilder) now() time.Time {
+	if b.Clock == nil {
+		return SystemClock.Now()
+	}
+
+	return b.Clock.Now()
+}
+
 func (b *TriggerBuilder) EndAt(endTime time.Time) *TriggerBuilder {
 	b.EndTime = endTime
 
 	return b
 }
 
+// InTimeZone sets the location of the fire times, defaults to the location of the start time.
+func (b *TriggerBuilder) InTimeZone(loc *time.Location) *TriggerBuilder {
+	b.TimeZone = loc
+
+	return b
+}
+
+// ModifiedByCalendar sets the name of the stored Calendar excluding the fire times of the trigger,
+// the Calendar must be stored before the trigger is scheduled.
+func (b *TriggerBuilder) ModifiedByCalendar(name string) *TriggerBuilder {
+	b.CalendarName = name
+
+	return b
+}
+
 func (b *TriggerBuilder) WithSchedule(scheduleBuilder ScheduleBuilder) *TriggerBuilder {
 	b.ScheduleBuilder = scheduleBuilder
 
@@ -430,16 +683,59 @@ func (b *TriggerBuilder) SetJobDataMap(dataMap JobDataMap) *TriggerBuilder {
 	return b
 }
 
+// Build the trigger, panics if the trigger is invalid, use BuildE to check the error.
 func (b *TriggerBuilder) Build() Trigger {
+	trigger, err := b.BuildE()
+
+	if err != nil {
+		panic(err)
+	}
+
+	return trigger
+}
+
+// BuildE builds the trigger, it returns the error of an invalid schedule, or of the end time before the start time.
+//
+// The trigger without start time starts at the current time of the Clock,
+// and the trigger is validated again when it is scheduled, since the Scheduler may give its job.
+func (b *TriggerBuilder) BuildE() (Trigger, error) {
 	if b.ScheduleBuilder == nil {
 		b.ScheduleBuilder = &SimpleScheduleBuilder{}
 	}
 
-	trigger := b.ScheduleBuilder.Build()
+	var trigger MutableTrigger
+
+	if sb, ok := b.ScheduleBuilder.(interface {
+		BuildE() (MutableTrigger, error)
+	}); ok {
+		var err error
+
+		if trigger, err = sb.BuildE(); err != nil {
+			return nil, err
+		}
+	} else {
+		trigger = b.ScheduleBuilder.Build()
+	}
 
 	trigger.SetDescription(b.Description)
-	trigger.SetStartTime(b.StartTime)
-	trigger.SetEndTime(b.EndTime)
+
+	// the builder keeps no start time, so each build without it starts now.
+	startTime := b.StartTime
+
+	if startTime.IsZero() {
+		startTime = b.now()
+	}
+
+	if err := trigger.SetStartTime(startTime); err != nil {
+		return nil, err
+	}
+
+	if err := trigger.SetEndTime(b.EndTime); err != nil {
+		return nil, err
+	}
+
+	trigger.SetTimeZone(b.TimeZone)
+	trigger.SetCalendarName(b.CalendarName)
 
 	if b.Key == nil {
 		b.Key = NewUniqueTriggerKey("")
@@ -457,5 +753,5 @@ func (b *TriggerBuilder) Build() Trigger {
 		trigger.SetJobDataMap(b.DataMap)
 	}
 
-	return trigger
+	return trigger, nil
 }
@@ -1,6 +1,7 @@
 package quartz
 
 import (
+	"sort"
 	"testing"
 	"time"
 
@@ -25,12 +26,15 @@ func TestTriggerKey(t *testing.T) {
 	})
 
 	Convey("Given a uniqued TriggerKey", t, func(c C) {
-		key := NewUniqueKey("")
+		key := NewUniqueTriggerKey("")
 
 		c.Printf("unique key: %s", key)
 
+		So(key, ShouldHaveSameTypeAs, TriggerKey(nil))
 		So(len(key.Name()), ShouldEqual, 41)
 		So(key.Group(), ShouldEqual, DEFAULT_GROUP)
+		So(NewUniqueTriggerKey(""), ShouldNotResemble, key)
+		So(NewUniqueTriggerKey("group").Group(), ShouldEqual, "group")
 	})
 }
 
@@ -39,7 +43,22 @@ func TestTriggerBuilder(t *testing.T) {
 		b := &TriggerBuilder{}
 
 		Convey("TriggerBuilder -> Trigger.TriggerBuilder()", func() {
-			So(b.Build().TriggerBuilder(), ShouldResemble, b)
+			trigger := b.Build()
+
+			// the start time defaulted to now is only kept by the trigger.
+			So(b.StartTime.IsZero(), ShouldBeTrue)
+
+			b.StartTime = trigger.StartTime()
+
+			So(trigger.TriggerBuilder(), ShouldResemble, b)
+		})
+
+		Convey("No identity -> unique Trigger.Key()", func() {
+			key := b.Build().Key()
+
+			So(key, ShouldHaveSameTypeAs, TriggerKey(nil))
+			So(len(key.Name()), ShouldEqual, 41)
+			So(key.Group(), ShouldEqual, DEFAULT_GROUP)
 		})
 
 		Convey("WithIdentity -> Trigger.Key()", func() {
@@ -72,6 +91,40 @@ func TestTriggerBuilder(t *testing.T) {
 			So(b.Build().Priority(), ShouldEqual, 1)
 		})
 
+		Convey("ModifiedByCalendar -> Trigger.CalendarName()", func() {
+			b.ModifiedByCalendar("holidays")
+
+			So(b.Build().CalendarName(), ShouldEqual, "holidays")
+			So(b.Build().TriggerBuilder().CalendarName, ShouldEqual, "holidays")
+		})
+
+		Convey("No start -> Trigger.StartTime() now", func() {
+			before := time.Now()
+
+			startTime := b.Build().StartTime()
+
+			So(startTime, ShouldHappenOnOrBetween, before, time.Now())
+
+			Convey("The next build should start now again", func() {
+				time.Sleep(time.Millisecond)
+
+				So(b.StartTime.IsZero(), ShouldBeTrue)
+				So(b.Build().StartTime().After(startTime), ShouldBeTrue)
+			})
+		})
+
+		Convey("WithClock -> Trigger.StartTime() at the time of the clock", func() {
+			clock := NewManualClock(time.Date(2016, time.March, 1, 8, 0, 0, 0, time.UTC))
+
+			b.WithClock(clock)
+
+			So(b.Build().StartTime(), ShouldResemble, clock.Now())
+
+			clock.Advance(time.Minute)
+
+			So(b.StartNow().Build().StartTime(), ShouldResemble, clock.Now())
+		})
+
 		Convey("StartAt -> Trigger.StartTime()", func() {
 			ts := time.Now()
 
@@ -81,13 +134,34 @@ func TestTriggerBuilder(t *testing.T) {
 		})
 
 		Convey("EndAt -> Trigger.EndTime()", func() {
-			ts := time.Now()
+			ts := time.Now().Add(time.Hour)
 
 			b.EndAt(ts)
 
 			So(b.Build().EndTime(), ShouldResemble, ts)
 		})
 
+		Convey("EndAt before StartAt -> error", func() {
+			ts := time.Now()
+
+			b.StartAt(ts).EndAt(ts.Add(-time.Second))
+
+			trigger, err := b.BuildE()
+
+			So(err, ShouldNotBeNil)
+			So(trigger, ShouldBeNil)
+			So(func() { b.Build() }, ShouldPanic)
+		})
+
+		Convey("Invalid schedule -> error", func() {
+			b.WithSchedule(&SimpleScheduleBuilder{time.Second, -2})
+
+			trigger, err := b.BuildE()
+
+			So(err, ShouldNotBeNil)
+			So(trigger, ShouldBeNil)
+		})
+
 		Convey("WithSchedule -> Trigger.ScheduleBuilder()", func() {
 			sb := &SimpleScheduleBuilder{10 * time.Second, 100}
 
@@ -150,3 +224,340 @@ func TestTriggerBuilder(t *testing.T) {
 		})
 	})
 }
+
+func TestSimpleScheduleBuilder(t *testing.T) {
+	Convey("Given a SimpleScheduleBuilder", t, func() {
+		Convey("Repeat indefinitely", func() {
+			trigger, err := (&SimpleScheduleBuilder{time.Second, REPEAT_INDEFINITELY}).BuildE()
+
+			So(err, ShouldBeNil)
+			So(trigger, ShouldNotBeNil)
+		})
+
+		Convey("Never repeat", func() {
+			trigger, err := (&SimpleScheduleBuilder{time.Second, 0}).BuildE()
+
+			So(err, ShouldBeNil)
+			So(trigger, ShouldNotBeNil)
+		})
+
+		Convey("Invalid negative repeat count", func() {
+			b := &SimpleScheduleBuilder{time.Second, -2}
+
+			trigger, err := b.BuildE()
+
+			So(err, ShouldNotBeNil)
+			So(trigger, ShouldBeNil)
+			So(func() { b.Build() }, ShouldPanic)
+		})
+	})
+}
+
+func TestSimpleTriggerFireTimes(t *testing.T) {
+	Convey("Given a simple trigger repeating twice without end time", t, func() {
+		startTime := time.Date(2016, time.March, 1, 8, 0, 0, 0, time.UTC)
+
+		trigger := (&TriggerBuilder{}).
+			StartAt(startTime).
+			WithSchedule(&SimpleScheduleBuilder{time.Minute, 2}).
+			Build().(OperableTrigger)
+
+		So(trigger.FireTimeAfter(startTime.Add(-time.Second)), ShouldResemble, startTime)
+		So(trigger.FireTimeAfter(startTime), ShouldResemble, startTime.Add(time.Minute))
+		So(trigger.FireTimeAfter(startTime.Add(90*time.Second)), ShouldResemble, startTime.Add(2*time.Minute))
+		So(trigger.FireTimeAfter(startTime.Add(2*time.Minute)), ShouldBeZeroValue)
+
+		Convey("When it is triggered", func() {
+			trigger.SetNextFireTime(startTime)
+
+			var fireTimes []time.Time
+
+			for trigger.MayFireAgain() {
+				fireTimes = append(fireTimes, trigger.NextFireTime())
+
+				trigger.Triggered(nil)
+
+				So(trigger.PreviousFireTime(), ShouldResemble, fireTimes[len(fireTimes)-1])
+			}
+
+			So(fireTimes, ShouldResemble, []time.Time{startTime, startTime.Add(time.Minute), startTime.Add(2 * time.Minute)})
+		})
+
+		Convey("When it is triggered with a calendar", func() {
+			trigger.SetNextFireTime(startTime)
+
+			cal := NewDailyCalendar(nil, TimeOfDay{8, 0, 30}, TimeOfDay{8, 1, 30})
+
+			trigger.Triggered(cal)
+
+			So(trigger.NextFireTime(), ShouldResemble, startTime.Add(2*time.Minute))
+		})
+	})
+
+	Convey("Given a simple trigger without repeat interval", t, func() {
+		startTime := time.Date(2016, time.March, 1, 8, 0, 0, 0, time.UTC)
+
+		trigger := (&TriggerBuilder{}).
+			StartAt(startTime).
+			WithSchedule(&SimpleScheduleBuilder{0, REPEAT_INDEFINITELY}).
+			Build()
+
+		So(trigger.FireTimeAfter(startTime), ShouldBeZeroValue)
+	})
+}
+
+func TestSimpleTriggerUpdateAfterMisfire(t *testing.T) {
+	Convey("Given a simple trigger repeating 10 times", t, func() {
+		startTime := time.Date(2016, time.March, 1, 8, 0, 0, 0, time.UTC)
+
+		trigger := (&TriggerBuilder{}).
+			StartAt(startTime).
+			WithSchedule(&SimpleScheduleBuilder{time.Minute, 10}).
+			Build().(OperableTrigger)
+
+		trigger.ComputeFirstFireTime(nil)
+
+		Convey("The misfired trigger should skip the missed fire times", func() {
+			trigger.UpdateAfterMisfire(nil, startTime.Add(5*time.Minute+30*time.Second))
+
+			So(trigger.NextFireTime(), ShouldResemble, startTime.Add(6*time.Minute))
+
+			var fireTimes int
+
+			for trigger.MayFireAgain() {
+				fireTimes++
+
+				trigger.Triggered(nil)
+			}
+
+			So(fireTimes, ShouldEqual, 5)
+		})
+
+		Convey("The misfired trigger past its last fire time should never fire again", func() {
+			trigger.UpdateAfterMisfire(nil, startTime.Add(time.Hour))
+
+			So(trigger.NextFireTime(), ShouldBeZeroValue)
+		})
+	})
+
+	Convey("Given a one-shot trigger", t, func() {
+		startTime := time.Date(2016, time.March, 1, 8, 0, 0, 0, time.UTC)
+
+		trigger := (&TriggerBuilder{}).StartAt(startTime).Build().(OperableTrigger)
+
+		trigger.ComputeFirstFireTime(nil)
+
+		Convey("The misfired trigger should fire now", func() {
+			now := startTime.Add(time.Hour)
+
+			trigger.UpdateAfterMisfire(nil, now)
+
+			So(trigger.NextFireTime(), ShouldResemble, now)
+		})
+	})
+}
+
+func TestSimpleTriggerValidate(t *testing.T) {
+	Convey("Given a valid simple trigger", t, func() {
+		startTime := time.Date(2016, time.March, 1, 8, 0, 0, 0, time.UTC)
+
+		trigger := (&TriggerBuilder{}).
+			WithIdentity("trigger").
+			ForJob("job").
+			StartAt(startTime).
+			WithSchedule(&SimpleScheduleBuilder{time.Minute, 2}).
+			Build().(*simpleTrigger)
+
+		So(trigger.Validate(), ShouldBeNil)
+
+		Convey("The trigger without name should be invalid", func() {
+			trigger.name = ""
+			trigger.key = nil
+
+			So(trigger.Validate(), ShouldNotBeNil)
+		})
+
+		Convey("The trigger without job should be invalid", func() {
+			trigger.jobName = ""
+
+			So(trigger.Validate(), ShouldNotBeNil)
+		})
+
+		Convey("The trigger ending before its start should be invalid", func() {
+			trigger.endTime = startTime.Add(-time.Minute)
+
+			So(trigger.Validate(), ShouldNotBeNil)
+		})
+
+		Convey("The trigger with a negative repeat count should be invalid", func() {
+			trigger.repeatCount = -2
+
+			So(trigger.Validate(), ShouldNotBeNil)
+		})
+
+		Convey("The repeating trigger without repeat interval should be invalid", func() {
+			trigger.repeatInterval = 0
+
+			So(trigger.Validate(), ShouldNotBeNil)
+
+			trigger.repeatCount = 0
+
+			So(trigger.Validate(), ShouldBeNil)
+		})
+	})
+}
+
+func TestSimpleTriggerTimeZone(t *testing.T) {
+	Convey("Given a simple trigger repeating hourly in US/Eastern", t, func() {
+		loc, err := time.LoadLocation("America/New_York")
+
+		So(err, ShouldBeNil)
+
+		newTrigger := func(startTime time.Time) OperableTrigger {
+			return (&TriggerBuilder{}).
+				ForJob("job").
+				StartAt(startTime).
+				InTimeZone(loc).
+				WithSchedule(&SimpleScheduleBuilder{time.Hour, 3}).
+				Build().(OperableTrigger)
+		}
+
+		fireTimes := func(trigger OperableTrigger, cal Calendar) (times []string) {
+			for fireTime := trigger.ComputeFirstFireTime(cal); !fireTime.IsZero(); fireTime = trigger.NextFireTime() {
+				times = append(times, fireTime.Format("15:04 MST"))
+
+				trigger.Triggered(cal)
+			}
+
+			return
+		}
+
+		Convey("The fire times should be in its time zone", func() {
+			trigger := newTrigger(time.Date(2016, time.March, 1, 5, 0, 0, 0, time.UTC))
+
+			So(trigger.TimeZone(), ShouldEqual, loc)
+			So(trigger.ComputeFirstFireTime(nil).Location(), ShouldEqual, loc)
+			So(trigger.FireTimeAfter(trigger.StartTime()).Format("15:04 MST"), ShouldEqual, "01:00 EST")
+		})
+
+		Convey("The nonexistent hour should be skipped when the clocks spring forward", func() {
+			trigger := newTrigger(time.Date(2016, time.March, 13, 0, 0, 0, 0, loc))
+
+			So(fireTimes(trigger, nil), ShouldResemble, []string{"00:00 EST", "01:00 EST", "03:00 EDT", "04:00 EDT"})
+		})
+
+		Convey("The repeated hour should fire twice when the clocks fall back", func() {
+			trigger := newTrigger(time.Date(2016, time.November, 6, 0, 0, 0, 0, loc))
+
+			So(fireTimes(trigger, nil), ShouldResemble, []string{"00:00 EDT", "01:00 EDT", "01:00 EST", "02:00 EST"})
+		})
+
+		Convey("The daily window of a calendar should be in its wall clock time", func() {
+			cal := NewDailyCalendar(nil, TimeOfDay{1, 0, 0}, TimeOfDay{3, 0, 0})
+			cal.SetLocation(loc)
+
+			trigger := newTrigger(time.Date(2016, time.March, 13, 0, 0, 0, 0, loc))
+
+			So(fireTimes(trigger, cal), ShouldResemble, []string{"00:00 EST", "03:00 EDT", "04:00 EDT"})
+		})
+
+		Convey("The time zone should be kept by the persisted record", func() {
+			record, err := newTriggerRecord(newTrigger(time.Date(2016, time.March, 13, 0, 0, 0, 0, loc)))
+
+			So(err, ShouldBeNil)
+			So(record.trigger().TimeZone().String(), ShouldEqual, "America/New_York")
+		})
+	})
+
+	Convey("Given a simple trigger without time zone", t, func() {
+		startTime := time.Date(2016, time.March, 1, 8, 0, 0, 0, time.UTC)
+
+		trigger := (&TriggerBuilder{}).StartAt(startTime).Build().(OperableTrigger)
+
+		So(trigger.TimeZone(), ShouldEqual, time.UTC)
+		So(trigger.ComputeFirstFireTime(nil), ShouldResemble, startTime)
+		So(trigger.ComputeFirstFireTime(nil).Location(), ShouldEqual, trigger.TimeZone())
+	})
+}
+
+func TestSimpleTriggerComputeFirstFireTime(t *testing.T) {
+	Convey("Given a simple trigger repeating twice", t, func() {
+		startTime := time.Date(2016, time.March, 1, 8, 0, 0, 0, time.UTC)
+
+		trigger := (&TriggerBuilder{}).
+			StartAt(startTime).
+			WithSchedule(&SimpleScheduleBuilder{time.Minute, 2}).
+			Build().(OperableTrigger)
+
+		Convey("The first fire time should be its start time, even in the past", func() {
+			So(trigger.ComputeFirstFireTime(nil), ShouldResemble, startTime)
+			So(trigger.NextFireTime(), ShouldResemble, startTime)
+		})
+
+		Convey("The first fire time should skip the times excluded by the calendar", func() {
+			cal := NewDailyCalendar(nil, TimeOfDay{7, 0, 0}, TimeOfDay{8, 0, 30})
+
+			So(trigger.ComputeFirstFireTime(cal), ShouldResemble, startTime.Add(time.Minute))
+			So(trigger.NextFireTime(), ShouldResemble, startTime.Add(time.Minute))
+		})
+
+		Convey("The trigger excluded by the calendar should never fire", func() {
+			cal := NewDailyCalendar(nil, TimeOfDay{7, 0, 0}, TimeOfDay{9, 0, 0})
+
+			So(trigger.ComputeFirstFireTime(cal), ShouldBeZeroValue)
+			So(trigger.MayFireAgain(), ShouldBeFalse)
+		})
+	})
+}
+
+func TestTriggerCompareTo(t *testing.T) {
+	Convey("Given some triggers with mixed fire times, priorities and keys", t, func() {
+		fireTime := time.Date(2016, time.March, 1, 8, 0, 0, 0, time.UTC)
+
+		newTrigger := func(name string, nextFireTime time.Time, priority int) Trigger {
+			trigger := (&TriggerBuilder{}).WithIdentity(name).StartAt(fireTime).WithPriority(priority).Build()
+
+			trigger.(OperableTrigger).SetNextFireTime(nextFireTime)
+
+			return trigger
+		}
+
+		triggers := []Trigger{
+			newTrigger("never", time.Time{}, 10),
+			newTrigger("later", fireTime.Add(time.Minute), 10),
+			newTrigger("low", fireTime, 1),
+			newTrigger("b", fireTime, 5),
+			newTrigger("high", fireTime, 10),
+			newTrigger("a", fireTime, 5),
+			newTrigger("finished", time.Time{}, 1),
+		}
+
+		names := func(triggers []Trigger) (names []string) {
+			for _, trigger := range triggers {
+				names = append(names, trigger.Key().Name())
+			}
+
+			return
+		}
+
+		Convey("The triggers should be sorted by their next fire time, priority and key", func() {
+			sort.Slice(triggers, func(i, j int) bool { return triggers[i].CompareTo(triggers[j]) < 0 })
+
+			So(names(triggers), ShouldResemble, []string{"high", "a", "b", "low", "later", "never", "finished"})
+		})
+
+		Convey("The comparator should compare the triggers the same way", func() {
+			So(TriggerComparator(triggers[2], triggers[1]), ShouldEqual, -1)
+			So(TriggerComparator(triggers[1], triggers[2]), ShouldEqual, 1)
+			So(TriggerComparator(triggers[0], triggers[1]), ShouldEqual, 1)
+			So(TriggerComparator(triggers[1], triggers[0]), ShouldEqual, -1)
+			So(TriggerComparator(triggers[5], triggers[3]), ShouldEqual, -1)
+			So(TriggerComparator(triggers[3], triggers[3]), ShouldEqual, 0)
+		})
+
+		Convey("The triggers without next fire time should be ordered by their priorities", func() {
+			So(triggers[0].CompareTo(triggers[6]), ShouldEqual, -1)
+			So(triggers[6].CompareTo(triggers[0]), ShouldEqual, 1)
+		})
+	})
+}
@@ -0,0 +1,110 @@
+package quartz
+
+import (
+	"container/heap"
+)
+
+// timeTriggerQueue is a priority queue of the waiting triggers, ordered by their next fire time and then by priority,
+// the triggers without next fire time are ordered last since they will never fire.
+//
+// The queue tracks the index of each trigger in its wrapper, so an arbitrary trigger can be removed by its key.
+type timeTriggerQueue struct {
+	items  []*triggerWrapper
+	queued TriggerMap
+}
+
+func newTimeTriggerQueue() *timeTriggerQueue {
+	return &timeTriggerQueue{queued: make(TriggerMap)}
+}
+
+func (q *timeTriggerQueue) Len() int { return len(q.items) }
+
+func (q *timeTriggerQueue) Less(i, j int) bool {
+	return compareTriggers(q.items[i].trigger, q.items[j].trigger) < 0
+}
+
+func (q *timeTriggerQueue) Swap(i, j int) {
+	q.items[i], q.items[j] = q.items[j], q.items[i]
+
+	q.items[i].index = i
+	q.items[j].index = j
+}
+
+func (q *timeTriggerQueue) Push(x interface{}) {
+	tw := x.(*triggerWrapper)
+
+	tw.index = len(q.items)
+	q.items = append(q.items, tw)
+}
+
+func (q *timeTriggerQueue) Pop() interface{} {
+	n := len(q.items) - 1
+	tw := q.items[n]
+
+	q.items[n] = nil
+	q.items = q.items[:n]
+
+	return tw
+}
+
+// push adds the trigger to the queue, or moves it to the position of its next fire time if it is already queued.
+func (q *timeTriggerQueue) push(tw *triggerWrapper) {
+	key := tw.Key().String()
+
+	if queued, exists := q.queued[key]; exists {
+		tw.index = queued.index
+		q.items[tw.index] = tw
+
+		heap.Fix(q, tw.index)
+	} else {
+		heap.Push(q, tw)
+	}
+
+	q.queued[key] = tw
+}
+
+// peek returns the earliest trigger without removing it, or nil if the queue is empty.
+func (q *timeTriggerQueue) peek() *triggerWrapper {
+	if len(q.items) == 0 {
+		return nil
+	}
+
+	return q.items[0]
+}
+
+// pop removes and returns the earliest trigger, or nil if the queue is empty.
+func (q *timeTriggerQueue) pop() *triggerWrapper {
+	if len(q.items) == 0 {
+		return nil
+	}
+
+	tw := heap.Pop(q).(*triggerWrapper)
+
+	delete(q.queued, tw.Key().String())
+
+	return tw
+}
+
+// remove removes the trigger of the key, and returns false if it was not queued.
+func (q *timeTriggerQueue) remove(key TriggerKey) bool {
+	tw, exists := q.queued[key.String()]
+
+	if exists {
+		heap.Remove(q, tw.index)
+
+		delete(q.queued, key.String())
+	}
+
+	return exists
+}
+
+func (q *timeTriggerQueue) contains(key TriggerKey) bool {
+	_, exists := q.queued[key.String()]
+
+	return exists
+}
+
+func (q *timeTriggerQueue) clear() {
+	q.items = nil
+	q.queued = make(TriggerMap)
+}
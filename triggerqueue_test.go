@@ -0,0 +1,80 @@
+package quartz
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTimeTriggerQueue(t *testing.T) {
+	Convey("Given a timeTriggerQueue with triggers of mixed fire times", t, func() {
+		q := newTimeTriggerQueue()
+
+		startTime := time.Date(2016, time.March, 1, 8, 0, 0, 0, time.UTC)
+
+		newTrigger := func(name string, fireTime time.Time, priority int) *triggerWrapper {
+			trigger := (&TriggerBuilder{}).WithIdentity(name).ForJob("job").WithPriority(priority).Build().(OperableTrigger)
+			trigger.SetNextFireTime(fireTime)
+
+			return &triggerWrapper{trigger: trigger}
+		}
+
+		for _, tw := range []*triggerWrapper{
+			newTrigger("a", startTime.Add(time.Hour), 5),
+			newTrigger("b", startTime, 1),
+			newTrigger("never", zero, 10),
+			newTrigger("c", startTime.Add(-time.Minute), 5),
+			newTrigger("d", startTime, 9),
+			newTrigger("e", startTime.Add(time.Minute), 5),
+			newTrigger("f", startTime, 9),
+		} {
+			q.push(tw)
+		}
+
+		drain := func() (names []string) {
+			for tw := q.pop(); tw != nil; tw = q.pop() {
+				names = append(names, tw.Key().Name())
+			}
+
+			return
+		}
+
+		So(q.Len(), ShouldEqual, 7)
+		So(q.peek().Key().Name(), ShouldEqual, "c")
+
+		Convey("The triggers should be popped by fire time, then priority, the triggers never firing last", func() {
+			So(drain(), ShouldResemble, []string{"c", "d", "f", "b", "e", "a", "never"})
+			So(q.peek(), ShouldBeNil)
+			So(q.pop(), ShouldBeNil)
+		})
+
+		Convey("A trigger removed from the middle should not be popped", func() {
+			So(q.remove(NewTriggerKey("b")), ShouldBeTrue)
+			So(q.remove(NewTriggerKey("e")), ShouldBeTrue)
+			So(q.remove(NewTriggerKey("e")), ShouldBeFalse)
+			So(q.contains(NewTriggerKey("e")), ShouldBeFalse)
+			So(q.Len(), ShouldEqual, 5)
+
+			So(drain(), ShouldResemble, []string{"c", "d", "f", "a", "never"})
+		})
+
+		Convey("A trigger pushed again should be moved to its new fire time", func() {
+			tw := newTrigger("a", startTime.Add(-time.Hour), 5)
+
+			q.push(tw)
+
+			So(q.Len(), ShouldEqual, 7)
+			So(q.peek(), ShouldEqual, tw)
+
+			So(drain(), ShouldResemble, []string{"a", "c", "d", "f", "b", "e", "never"})
+		})
+
+		Convey("The cleared queue should be empty", func() {
+			q.clear()
+
+			So(q.Len(), ShouldEqual, 0)
+			So(q.contains(NewTriggerKey("a")), ShouldBeFalse)
+		})
+	})
+}
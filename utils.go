@@ -4,7 +4,9 @@ import (
 	"crypto/md5"
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"reflect"
 	"sort"
 	"strings"
 )
@@ -19,6 +21,8 @@ type Sortable interface {
 
 type CompareFunc func(lhs, rhs interface{}) int
 
+type LessFunc func(lhs, rhs interface{}) bool
+
 type StringKeys []interface{}
 
 func (keys StringKeys) Len() int { return len(keys) }
@@ -50,12 +54,21 @@ type Map interface {
 
 	Entries() []MapEntry
 
+	// ForEach calls fn with the entries in the order of Keys, until fn returns false.
+	ForEach(fn func(key string, value interface{}) bool)
+
 	Contains(key string) bool
 
 	Get(key string) interface{}
 
+	// GetOrDefault returns the value of the key, or def if the key is missing.
+	GetOrDefault(key string, def interface{}) interface{}
+
 	Put(key string, value interface{})
 
+	// PutIfAbsent puts the value only if the key is missing, and returns the existing value if present.
+	PutIfAbsent(key string, value interface{}) interface{}
+
 	PutAll(m Map)
 
 	Remove(key string) interface{}
@@ -70,6 +83,8 @@ type DirtyFlagMap interface {
 }
 
 type Set interface {
+	Cloneable
+
 	Empty() bool
 
 	Len() int
@@ -81,6 +96,37 @@ type Set interface {
 	Add(item interface{})
 
 	Remove(item interface{}) bool
+
+	// AddAll adds the items and returns the number of items which were not already in the set.
+	AddAll(items ...interface{}) int
+
+	// RemoveAll removes the items and returns the number of items which were in the set.
+	RemoveAll(items ...interface{}) int
+}
+
+type SortedSet interface {
+	Set
+
+	// Returns the first (lowest) item currently in this set, or nil if the set is empty.
+	First() interface{}
+
+	// Returns the last (highest) item currently in this set, or nil if the set is empty.
+	Last() interface{}
+
+	// Retrieves and removes the first (lowest) item, or returns nil if the set is empty.
+	PollFirst() interface{}
+
+	// Returns the least item greater than or equal to the given item, or nil if there is no such item.
+	Ceiling(item interface{}) interface{}
+
+	// Returns the greatest item less than or equal to the given item, or nil if there is no such item.
+	Floor(item interface{}) interface{}
+
+	// Returns the least item strictly greater than the given item, or nil if there is no such item.
+	Higher(item interface{}) interface{}
+
+	// Returns the greatest item strictly less than the given item, or nil if there is no such item.
+	Lower(item interface{}) interface{}
 }
 
 type mapEntry struct {
@@ -95,12 +141,20 @@ func (e *mapEntry) Value() interface{} { return e.value }
 type dirtyFlagMap struct {
 	entries map[string]interface{}
 	dirty   bool
+	ordered bool
+	order   []string
 }
 
 func NewDirtyFlagMap() DirtyFlagMap {
 	return &dirtyFlagMap{entries: make(map[string]interface{})}
 }
 
+// NewOrderedDataMap returns a JobDataMap which iterates the entries in the order their keys were first put,
+// overwriting a key keeps its position, and removing it forgets the position.
+func NewOrderedDataMap() JobDataMap {
+	return &dirtyFlagMap{entries: make(map[string]interface{}), ordered: true}
+}
+
 func (m *dirtyFlagMap) Dirty() bool { return m.dirty }
 
 func (m *dirtyFlagMap) ClearDirtyFlag() { m.dirty = false }
@@ -110,6 +164,14 @@ func (m *dirtyFlagMap) Empty() bool { return len(m.entries) == 0 }
 func (m *dirtyFlagMap) Len() int { return len(m.entries) }
 
 func (m *dirtyFlagMap) Keys() (keys []string) {
+	if m.ordered {
+		if len(m.order) > 0 {
+			keys = append(keys, m.order...)
+		}
+
+		return
+	}
+
 	for key, _ := range m.entries {
 		keys = append(keys, key)
 	}
@@ -137,6 +199,14 @@ func (m *dirtyFlagMap) Entries() (entries []MapEntry) {
 	return
 }
 
+func (m *dirtyFlagMap) ForEach(fn func(key string, value interface{}) bool) {
+	for _, key := range m.Keys() {
+		if !fn(key, m.entries[key]) {
+			return
+		}
+	}
+}
+
 func (m *dirtyFlagMap) Contains(key string) bool {
 	_, exists := m.entries[key]
 
@@ -146,16 +216,61 @@ func (m *dirtyFlagMap) Contains(key string) bool {
 func (m *dirtyFlagMap) Get(key string) interface{} { return m.entries[key] }
 
 func (m *dirtyFlagMap) Put(key string, value interface{}) {
-	if v, exists := m.entries[key]; !exists || v != value {
+	if v, exists := m.entries[key]; !exists || !sameValue(v, value) {
+		if !exists && m.ordered {
+			m.order = append(m.order, key)
+		}
+
 		m.entries[key] = value
 		m.dirty = true
 	}
 }
 
-func (m *dirtyFlagMap) PutAll(o Map) {
-	for _, entry := range o.Entries() {
-		m.Put(entry.Key(), entry.Value())
+// sameValue compares the values only if both are comparable, the uncomparable values such as the slices and
+// the maps may have been changed in place, so they are never the same.
+func sameValue(v, value interface{}) bool {
+	if !comparableValue(v) || !comparableValue(value) {
+		return false
 	}
+
+	return v == value
+}
+
+func comparableValue(v interface{}) bool {
+	t := reflect.TypeOf(v)
+
+	return t == nil || t.Comparable()
+}
+
+func (m *dirtyFlagMap) GetOrDefault(key string, def interface{}) interface{} {
+	if value, exists := m.entries[key]; exists {
+		return value
+	}
+
+	return def
+}
+
+func (m *dirtyFlagMap) PutIfAbsent(key string, value interface{}) interface{} {
+	if v, exists := m.entries[key]; exists {
+		return v
+	}
+
+	if m.ordered {
+		m.order = append(m.order, key)
+	}
+
+	m.entries[key] = value
+	m.dirty = true
+
+	return nil
+}
+
+func (m *dirtyFlagMap) PutAll(o Map) {
+	o.ForEach(func(key string, value interface{}) bool {
+		m.Put(key, value)
+
+		return true
+	})
 }
 
 func (m *dirtyFlagMap) Remove(key string) interface{} {
@@ -165,24 +280,170 @@ func (m *dirtyFlagMap) Remove(key string) interface{} {
 
 	if exists {
 		m.dirty = true
+
+		if m.ordered {
+			for i, k := range m.order {
+				if k == key {
+					m.order = append(m.order[:i], m.order[i+1:]...)
+
+					break
+				}
+			}
+		}
 	}
 
 	return value
 }
 
-func (m *dirtyFlagMap) Clone() interface{} {
-	clone := dirtyFlagMap{
-		entries: make(map[string]interface{}),
+func (m *dirtyFlagMap) Clone() interface{} { return m.CloneDataMap() }
+
+// CloneDataMap returns a deep copy of the map with the same dirty flag.
+func (m *dirtyFlagMap) CloneDataMap() JobDataMap {
+	clone := &dirtyFlagMap{
+		entries: make(map[string]interface{}, len(m.entries)),
 		dirty:   m.dirty,
+		ordered: m.ordered,
+	}
+
+	if m.ordered {
+		clone.order = append([]string(nil), m.order...)
 	}
 
-	for _, entry := range m.Entries() {
-		clone.Put(entry.Key(), entry.Value())
+	for key, value := range m.entries {
+		clone.entries[key] = deepCopy(value)
 	}
 
-	return &clone
+	return clone
 }
 
+// deepCopy copies the slices, arrays and maps recursively, and clones the Cloneable values,
+// so the copy doesn't share them with the original. Pointers and other values are copied as is.
+func deepCopy(value interface{}) interface{} {
+	if c, ok := value.(Cloneable); ok {
+		return c.Clone()
+	}
+
+	if value == nil {
+		return nil
+	}
+
+	return deepCopyValue(reflect.ValueOf(value)).Interface()
+}
+
+func deepCopyValue(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+
+		c := reflect.New(v.Type()).Elem()
+		c.Set(reflect.ValueOf(deepCopy(v.Interface())))
+
+		return c
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopyValue(v.Index(i)))
+		}
+
+		return c
+
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopyValue(v.Index(i)))
+		}
+
+		return c
+
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+
+		for iter := v.MapRange(); iter.Next(); {
+			c.SetMapIndex(iter.Key(), deepCopyValue(iter.Value()))
+		}
+
+		return c
+	}
+
+	return v
+}
+
+// ErrUnmodifiable is the panic value of the methods changing an unmodifiable Map or Set.
+var ErrUnmodifiable = errors.New("unmodifiable")
+
+// UnmodifiableMap returns a read-only view of the map, the read methods delegate to the map,
+// and the methods changing it panic with ErrUnmodifiable. Clone returns a modifiable copy.
+//
+// The view of a JobDataMap is a JobDataMap.
+func UnmodifiableMap(m Map) Map {
+	if dataMap, ok := m.(JobDataMap); ok {
+		return &unmodifiableDataMap{dataMap}
+	}
+
+	return &unmodifiableMap{m}
+}
+
+type unmodifiableMap struct {
+	Map
+}
+
+func (m *unmodifiableMap) Put(key string, value interface{}) { panic(ErrUnmodifiable) }
+
+func (m *unmodifiableMap) PutIfAbsent(key string, value interface{}) interface{} {
+	panic(ErrUnmodifiable)
+}
+
+func (m *unmodifiableMap) PutAll(o Map) { panic(ErrUnmodifiable) }
+
+func (m *unmodifiableMap) Remove(key string) interface{} { panic(ErrUnmodifiable) }
+
+type unmodifiableDataMap struct {
+	JobDataMap
+}
+
+func (m *unmodifiableDataMap) Put(key string, value interface{}) { panic(ErrUnmodifiable) }
+
+func (m *unmodifiableDataMap) PutIfAbsent(key string, value interface{}) interface{} {
+	panic(ErrUnmodifiable)
+}
+
+func (m *unmodifiableDataMap) PutAll(o Map) { panic(ErrUnmodifiable) }
+
+func (m *unmodifiableDataMap) Remove(key string) interface{} { panic(ErrUnmodifiable) }
+
+func (m *unmodifiableDataMap) ClearDirtyFlag() { panic(ErrUnmodifiable) }
+
+// UnmodifiableSet returns a read-only view of the set, the read methods delegate to the set,
+// and the methods changing it panic with ErrUnmodifiable. Clone returns a modifiable copy.
+func UnmodifiableSet(s Set) Set {
+	return &unmodifiableSet{s}
+}
+
+type unmodifiableSet struct {
+	Set
+}
+
+func (s *unmodifiableSet) Add(item interface{}) { panic(ErrUnmodifiable) }
+
+func (s *unmodifiableSet) Remove(item interface{}) bool { panic(ErrUnmodifiable) }
+
+func (s *unmodifiableSet) AddAll(items ...interface{}) int { panic(ErrUnmodifiable) }
+
+func (s *unmodifiableSet) RemoveAll(items ...interface{}) int { panic(ErrUnmodifiable) }
+
 type hashSet map[interface{}]bool
 
 func NewHashSet() Set { return make(hashSet) }
@@ -217,73 +478,494 @@ func (s hashSet) Remove(key interface{}) bool {
 	return exists
 }
 
+func (s hashSet) AddAll(items ...interface{}) (added int) {
+	for _, item := range items {
+		if !s[item] {
+			s[item] = true
+			added++
+		}
+	}
+
+	return
+}
+
+func (s hashSet) RemoveAll(items ...interface{}) (removed int) {
+	for _, item := range items {
+		if s.Remove(item) {
+			removed++
+		}
+	}
+
+	return
+}
+
+func (s hashSet) Clone() interface{} {
+	clone := make(hashSet, len(s))
+
+	for key := range s {
+		clone[key] = true
+	}
+
+	return clone
+}
+
+type sortedHashSet struct {
+	hashSet
+
+	less LessFunc
+}
+
+// NewSortedHashSet returns a hash based Set whose Keys() are sorted with the less function,
+// so the iteration order is stable across calls.
+func NewSortedHashSet(less LessFunc) Set {
+	return &sortedHashSet{make(hashSet), less}
+}
+
+func (s *sortedHashSet) Keys() []interface{} {
+	keys := s.hashSet.Keys()
+
+	sort.Slice(keys, func(i, j int) bool {
+		return s.less(keys[i], keys[j])
+	})
+
+	return keys
+}
+
+func (s *sortedHashSet) Clone() interface{} {
+	return &sortedHashSet{s.hashSet.Clone().(hashSet), s.less}
+}
+
+func StringLess(lhs, rhs interface{}) bool {
+	return strings.Compare(lhs.(string), rhs.(string)) < 0
+}
+
+type treeNode struct {
+	item        interface{}
+	left, right *treeNode
+	height      int
+}
+
+func (n *treeNode) balanceFactor() int { return n.left.getHeight() - n.right.getHeight() }
+
+func (n *treeNode) getHeight() int {
+	if n == nil {
+		return 0
+	}
+
+	return n.height
+}
+
+func (n *treeNode) updateHeight() {
+	if l, r := n.left.getHeight(), n.right.getHeight(); l > r {
+		n.height = l + 1
+	} else {
+		n.height = r + 1
+	}
+}
+
+func (n *treeNode) rotateLeft() *treeNode {
+	r := n.right
+
+	n.right = r.left
+	r.left = n
+
+	n.updateHeight()
+	r.updateHeight()
+
+	return r
+}
+
+func (n *treeNode) rotateRight() *treeNode {
+	l := n.left
+
+	n.left = l.right
+	l.right = n
+
+	n.updateHeight()
+	l.updateHeight()
+
+	return l
+}
+
+func (n *treeNode) rebalance() *treeNode {
+	n.updateHeight()
+
+	switch bf := n.balanceFactor(); {
+	case bf > 1:
+		if n.left.balanceFactor() < 0 {
+			n.left = n.left.rotateLeft()
+		}
+
+		return n.rotateRight()
+
+	case bf < -1:
+		if n.right.balanceFactor() > 0 {
+			n.right = n.right.rotateRight()
+		}
+
+		return n.rotateLeft()
+	}
+
+	return n
+}
+
+// treeSet is a Set ordered by the compare function, backed by an AVL tree.
 type treeSet struct {
-	items   []interface{}
+	root    *treeNode
+	size    int
 	compare CompareFunc
 }
 
-func NewTreeSet(compare CompareFunc) Set {
+func NewTreeSet(compare CompareFunc) SortedSet {
 	return &treeSet{
 		compare: compare,
 	}
 }
 
-func (s *treeSet) Empty() bool { return len(s.items) == 0 }
+func (s *treeSet) Empty() bool { return s.size == 0 }
 
-func (s *treeSet) Len() int { return len(s.items) }
+func (s *treeSet) Len() int { return s.size }
 
-func (s *treeSet) Less(i, j int) bool {
-	return s.compare(s.items[i], s.items[j]) <= 0
-}
+func (s *treeSet) Keys() (keys []interface{}) {
+	if s.size > 0 {
+		keys = make([]interface{}, 0, s.size)
+	}
 
-func (s *treeSet) Swap(i, j int) {
-	s.items[i], s.items[j] = s.items[j], s.items[i]
+	var walk func(n *treeNode)
+
+	walk = func(n *treeNode) {
+		if n != nil {
+			walk(n.left)
+			keys = append(keys, n.item)
+			walk(n.right)
+		}
+	}
+
+	walk(s.root)
+
+	return
 }
 
-func (s *treeSet) Keys() (keys []interface{}) {
-	return s.items
+func (s *treeSet) Clone() interface{} {
+	var clone func(n *treeNode) *treeNode
+
+	clone = func(n *treeNode) *treeNode {
+		if n == nil {
+			return nil
+		}
+
+		return &treeNode{n.item, clone(n.left), clone(n.right), n.height}
+	}
+
+	return &treeSet{clone(s.root), s.size, s.compare}
 }
 
 func (s *treeSet) Contains(item interface{}) bool {
-	n := sort.Search(len(s.items), func(i int) bool {
-		return s.compare(item, s.items[i]) <= 0
-	})
+	for n := s.root; n != nil; {
+		switch c := s.compare(item, n.item); {
+		case c < 0:
+			n = n.left
+		case c > 0:
+			n = n.right
+		default:
+			return true
+		}
+	}
 
-	return n < len(s.items) && s.compare(s.items[n], item) == 0
+	return false
 }
 
 func (s *treeSet) Add(item interface{}) {
-	n := sort.Search(len(s.items), func(i int) bool {
-		return s.compare(item, s.items[i]) <= 0
+	var added bool
+
+	s.root, added = s.insert(s.root, item)
+
+	if added {
+		s.size++
+	}
+}
+
+// AddAll sorts the batch once and merges it with the existing items,
+// rebuilding the tree in linear time unless the batch is small compared to the set.
+func (s *treeSet) AddAll(items ...interface{}) int {
+	if len(items) == 0 {
+		return 0
+	}
+
+	if len(items) < s.size/8 {
+		size := s.size
+
+		for _, item := range items {
+			s.Add(item)
+		}
+
+		return s.size - size
+	}
+
+	batch := make([]interface{}, len(items))
+	copy(batch, items)
+
+	sort.SliceStable(batch, func(i, j int) bool {
+		return s.compare(batch[i], batch[j]) < 0
 	})
 
-	fmt.Printf("Add %s @ %d, %v", item, n, s.items)
+	existing := s.Keys()
+	merged := make([]interface{}, 0, len(existing)+len(batch))
+
+	i, j := 0, 0
+
+	for i < len(existing) || j < len(batch) {
+		var c int
+
+		switch {
+		case i == len(existing):
+			c = 1
+		case j == len(batch):
+			c = -1
+		default:
+			c = s.compare(existing[i], batch[j])
+		}
+
+		switch {
+		case c < 0:
+			merged = append(merged, existing[i])
+			i++
+		case c > 0:
+			if last := len(merged) - 1; last < 0 || s.compare(merged[last], batch[j]) != 0 {
+				merged = append(merged, batch[j])
+			}
+			j++
+		default:
+			j++
+		}
+	}
+
+	added := len(merged) - s.size
+
+	s.root = buildTree(merged)
+	s.size = len(merged)
+
+	return added
+}
+
+// buildTree builds a balanced tree from the sorted items.
+func buildTree(items []interface{}) *treeNode {
+	if len(items) == 0 {
+		return nil
+	}
+
+	mid := len(items) / 2
+
+	n := &treeNode{item: items[mid], left: buildTree(items[:mid]), right: buildTree(items[mid+1:])}
+	n.updateHeight()
+
+	return n
+}
+
+func (s *treeSet) RemoveAll(items ...interface{}) (removed int) {
+	for _, item := range items {
+		if s.Remove(item) {
+			removed++
+		}
+	}
+
+	return
+}
+
+func (s *treeSet) insert(n *treeNode, item interface{}) (*treeNode, bool) {
+	if n == nil {
+		return &treeNode{item: item, height: 1}, true
+	}
+
+	var added bool
+
+	switch c := s.compare(item, n.item); {
+	case c < 0:
+		n.left, added = s.insert(n.left, item)
+	case c > 0:
+		n.right, added = s.insert(n.right, item)
+	default:
+		return n, false
+	}
+
+	return n.rebalance(), added
+}
+
+func (s *treeSet) First() interface{} {
+	if s.root == nil {
+		return nil
+	}
+
+	n := s.root
+
+	for n.left != nil {
+		n = n.left
+	}
+
+	return n.item
+}
+
+func (s *treeSet) Last() interface{} {
+	if s.root == nil {
+		return nil
+	}
+
+	n := s.root
+
+	for n.right != nil {
+		n = n.right
+	}
+
+	return n.item
+}
+
+func (s *treeSet) Ceiling(item interface{}) interface{} {
+	return s.higher(item, true)
+}
+
+func (s *treeSet) Higher(item interface{}) interface{} {
+	return s.higher(item, false)
+}
+
+func (s *treeSet) higher(item interface{}, inclusive bool) (found interface{}) {
+	for n := s.root; n != nil; {
+		switch c := s.compare(item, n.item); {
+		case c == 0 && inclusive:
+			return n.item
+		case c < 0:
+			found = n.item
+			n = n.left
+		default:
+			n = n.right
+		}
+	}
+
+	return
+}
+
+func (s *treeSet) Floor(item interface{}) interface{} {
+	return s.lower(item, true)
+}
+
+func (s *treeSet) Lower(item interface{}) interface{} {
+	return s.lower(item, false)
+}
+
+func (s *treeSet) lower(item interface{}, inclusive bool) (found interface{}) {
+	for n := s.root; n != nil; {
+		switch c := s.compare(item, n.item); {
+		case c == 0 && inclusive:
+			return n.item
+		case c > 0:
+			found = n.item
+			n = n.right
+		default:
+			n = n.left
+		}
+	}
+
+	return
+}
 
-	if n == len(s.items) {
-		s.items = append(s.items, item)
-	} else if s.compare(item, s.items[n]) != 0 {
-		items := make([]interface{}, len(s.items)+1)
+func (s *treeSet) PollFirst() interface{} {
+	if s.root == nil {
+		return nil
+	}
+
+	var item interface{}
 
-		copy(items[:n], s.items[:n])
-		items[n] = item
-		copy(items[n+1:], s.items[n:])
+	s.root, item = s.deleteMin(s.root)
+	s.size--
 
-		s.items = items
+	return item
+}
+
+func (s *treeSet) deleteMin(n *treeNode) (*treeNode, interface{}) {
+	if n.left == nil {
+		return n.right, n.item
 	}
+
+	var item interface{}
+
+	n.left, item = s.deleteMin(n.left)
+
+	return n.rebalance(), item
 }
 
 func (s *treeSet) Remove(item interface{}) bool {
-	n := sort.Search(len(s.items), func(i int) bool {
-		return s.compare(item, s.items[i]) <= 0
-	})
+	var removed bool
 
-	if n < len(s.items) && s.compare(s.items[n], item) == 0 {
-		s.items = append(s.items[:n], s.items[n+1:]...)
+	s.root, removed = s.delete(s.root, item)
 
-		return true
+	if removed {
+		s.size--
 	}
 
-	return false
+	return removed
+}
+
+func (s *treeSet) delete(n *treeNode, item interface{}) (*treeNode, bool) {
+	if n == nil {
+		return nil, false
+	}
+
+	var removed bool
+
+	switch c := s.compare(item, n.item); {
+	case c < 0:
+		n.left, removed = s.delete(n.left, item)
+	case c > 0:
+		n.right, removed = s.delete(n.right, item)
+	default:
+		if n.left == nil {
+			return n.right, true
+		}
+
+		if n.right == nil {
+			return n.left, true
+		}
+
+		n.right, n.item = s.deleteMin(n.right)
+
+		removed = true
+	}
+
+	return n.rebalance(), removed
+}
+
+// Union returns a new Set of the items in either set, it is a clone of lhs with the same semantics.
+func Union(lhs, rhs Set) Set {
+	s := lhs.Clone().(Set)
+
+	for _, item := range rhs.Keys() {
+		s.Add(item)
+	}
+
+	return s
+}
+
+// Intersection returns a new Set of the items in both sets, it is a clone of lhs with the same semantics.
+func Intersection(lhs, rhs Set) Set {
+	s := lhs.Clone().(Set)
+
+	for _, item := range lhs.Keys() {
+		if !rhs.Contains(item) {
+			s.Remove(item)
+		}
+	}
+
+	return s
+}
+
+// Difference returns a new Set of the items in lhs but not in rhs, it is a clone of lhs with the same semantics.
+func Difference(lhs, rhs Set) Set {
+	s := lhs.Clone().(Set)
+
+	for _, item := range rhs.Keys() {
+		s.Remove(item)
+	}
+
+	return s
 }
 
 const (
@@ -1,9 +1,16 @@
 package quartz
 
 import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"os"
 	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	. "github.com/smartystreets/goconvey/convey"
 )
@@ -46,6 +53,56 @@ func TestDirtyFlagMap(t *testing.T) {
 				So(m.Remove("key"), ShouldEqual, nil)
 				So(m.Dirty(), ShouldBeFalse)
 			})
+
+			Convey("Get the item or a default", func() {
+				m.ClearDirtyFlag()
+
+				So(m.GetOrDefault("key", "def"), ShouldEqual, "value")
+				So(m.GetOrDefault("missing", "def"), ShouldEqual, "def")
+				So(m.Contains("missing"), ShouldBeFalse)
+				So(m.Dirty(), ShouldBeFalse)
+			})
+
+			Convey("Put an item if absent", func() {
+				m.ClearDirtyFlag()
+
+				So(m.PutIfAbsent("key", "another"), ShouldEqual, "value")
+				So(m.Get("key"), ShouldEqual, "value")
+				So(m.Dirty(), ShouldBeFalse)
+
+				So(m.PutIfAbsent("foo", "bar"), ShouldBeNil)
+				So(m.Get("foo"), ShouldEqual, "bar")
+				So(m.Dirty(), ShouldBeTrue)
+			})
+
+			Convey("Put the same item again", func() {
+				m.ClearDirtyFlag()
+				m.Put("key", "value")
+
+				So(m.Dirty(), ShouldBeFalse)
+			})
+
+			Convey("Put the uncomparable items", func() {
+				tags := []string{"a", "b"}
+
+				m.Put("tags", tags)
+				m.Put("attrs", map[string]int{"a": 1})
+				m.ClearDirtyFlag()
+
+				So(func() { m.Put("tags", tags) }, ShouldNotPanic)
+				So(m.Dirty(), ShouldBeTrue)
+
+				m.ClearDirtyFlag()
+
+				So(func() { m.Put("attrs", map[string]int{"a": 2}) }, ShouldNotPanic)
+				So(m.Dirty(), ShouldBeTrue)
+
+				m.ClearDirtyFlag()
+
+				So(func() { m.Put("key", []string{"value"}) }, ShouldNotPanic)
+				So(m.Dirty(), ShouldBeTrue)
+				So(m.Get("key"), ShouldResemble, []string{"value"})
+			})
 		})
 
 		Convey("Given another map", func() {
@@ -73,10 +130,49 @@ func TestDirtyFlagMap(t *testing.T) {
 				So(entities[0].Value(), ShouldEqual, 1)
 			})
 
+			Convey("Iterate the items", func() {
+				var keys []string
+				var values []interface{}
+
+				other.ForEach(func(key string, value interface{}) bool {
+					keys = append(keys, key)
+					values = append(values, value)
+
+					return true
+				})
+
+				So(keys, ShouldResemble, []string{"bar", "foo", "key"})
+				So(values, ShouldResemble, []interface{}{1, 0, "value"})
+
+				Convey("Stop the iteration early", func() {
+					keys = nil
+
+					other.ForEach(func(key string, value interface{}) bool {
+						keys = append(keys, key)
+
+						return key != "foo"
+					})
+
+					So(keys, ShouldResemble, []string{"bar", "foo"})
+				})
+
+				Convey("Iterate an empty map", func() {
+					called := false
+
+					NewDirtyFlagMap().ForEach(func(key string, value interface{}) bool {
+						called = true
+
+						return true
+					})
+
+					So(called, ShouldBeFalse)
+				})
+			})
+
 			Convey("Clone a map", func() {
 				m := other.Clone().(*dirtyFlagMap)
 
-				So(m.Dirty(), ShouldBeTrue)
+				So(m.Dirty(), ShouldEqual, other.Dirty())
 				So(m.Empty(), ShouldBeFalse)
 				So(m.Len(), ShouldEqual, 3)
 				So(m.Contains("key"), ShouldBeTrue)
@@ -86,6 +182,138 @@ func TestDirtyFlagMap(t *testing.T) {
 				So(m.entries, ShouldNotEqual, other.entries)
 				So(m.entries, ShouldResemble, other.entries)
 			})
+
+			Convey("Clone a map with reference values", func() {
+				nested := NewDirtyFlagMap()
+				nested.Put("key", "value")
+
+				other.Put("slice", []string{"a", "b"})
+				other.Put("map", map[string]int{"a": 1})
+				other.Put("items", []interface{}{"a", []int{1, 2}})
+				other.Put("nested", nested)
+
+				m := other.Clone().(*dirtyFlagMap)
+
+				m.Get("slice").([]string)[0] = "x"
+				m.Get("map").(map[string]int)["a"] = 2
+				m.Get("items").([]interface{})[1].([]int)[0] = 3
+				m.Get("nested").(DirtyFlagMap).Put("key", "another")
+
+				So(other.Get("slice"), ShouldResemble, []string{"a", "b"})
+				So(other.Get("map"), ShouldResemble, map[string]int{"a": 1})
+				So(other.Get("items"), ShouldResemble, []interface{}{"a", []int{1, 2}})
+				So(nested.Get("key"), ShouldEqual, "value")
+
+				So(m.Get("slice"), ShouldResemble, []string{"x", "b"})
+				So(m.Get("items"), ShouldResemble, []interface{}{"a", []int{3, 2}})
+			})
+		})
+	})
+}
+
+func TestOrderedDataMap(t *testing.T) {
+	Convey("Given an ordered data map", t, func() {
+		m := NewOrderedDataMap()
+
+		So(m.Empty(), ShouldBeTrue)
+		So(m.Keys(), ShouldBeEmpty)
+		So(m.Dirty(), ShouldBeFalse)
+
+		m.Put("foo", 1)
+		m.Put("bar", 2)
+		m.PutIfAbsent("baz", 3)
+
+		So(m.Dirty(), ShouldBeTrue)
+		So(m.Keys(), ShouldResemble, []string{"foo", "bar", "baz"})
+		So(m.Values(), ShouldResemble, []interface{}{1, 2, 3})
+
+		m.ClearDirtyFlag()
+
+		Convey("When overwrites a key", func() {
+			m.Put("foo", 4)
+			m.PutIfAbsent("bar", 5)
+
+			Convey("The key should keep its position", func() {
+				So(m.Dirty(), ShouldBeTrue)
+				So(m.Keys(), ShouldResemble, []string{"foo", "bar", "baz"})
+				So(m.Values(), ShouldResemble, []interface{}{4, 2, 3})
+			})
+		})
+
+		Convey("When puts the same value", func() {
+			m.Put("foo", 1)
+
+			Convey("The map should not be dirty", func() {
+				So(m.Dirty(), ShouldBeFalse)
+			})
+		})
+
+		Convey("When removes and puts back a key", func() {
+			So(m.Remove("foo"), ShouldEqual, 1)
+			So(m.Remove("missing"), ShouldBeNil)
+
+			So(m.Keys(), ShouldResemble, []string{"bar", "baz"})
+
+			m.Put("foo", 1)
+
+			Convey("The key should be moved to the end", func() {
+				So(m.Dirty(), ShouldBeTrue)
+				So(m.Keys(), ShouldResemble, []string{"bar", "baz", "foo"})
+
+				var keys []string
+
+				for _, entry := range m.Entries() {
+					keys = append(keys, entry.Key())
+				}
+
+				So(keys, ShouldResemble, m.Keys())
+			})
+		})
+
+		Convey("When iterates the entries", func() {
+			var keys []string
+
+			m.ForEach(func(key string, value interface{}) bool {
+				keys = append(keys, key)
+
+				return key != "bar"
+			})
+
+			Convey("The entries should be visited in insertion order", func() {
+				So(keys, ShouldResemble, []string{"foo", "bar"})
+			})
+		})
+
+		Convey("When clones the map", func() {
+			clone := m.CloneDataMap()
+
+			clone.Put("qux", 4)
+			clone.Remove("foo")
+
+			Convey("The clone should keep the order", func() {
+				So(clone.Keys(), ShouldResemble, []string{"bar", "baz", "qux"})
+				So(m.Keys(), ShouldResemble, []string{"foo", "bar", "baz"})
+			})
+		})
+
+		Convey("When marshals the map to JSON", func() {
+			data, err := json.Marshal(m)
+
+			So(err, ShouldBeNil)
+			So(string(data), ShouldEqual, `{"foo":1,"bar":2,"baz":3}`)
+
+			Convey("The unmarshaled map should keep the order of the keys", func() {
+				other := NewOrderedDataMap()
+
+				So(json.Unmarshal([]byte(`{"zoo":1,"foo":2,"bar":3,"foo":4}`), other), ShouldBeNil)
+				So(other.Keys(), ShouldResemble, []string{"zoo", "foo", "bar"})
+				So(other.Values(), ShouldResemble, []interface{}{int64(1), int64(4), int64(3)})
+				So(other.Dirty(), ShouldBeFalse)
+
+				So(json.Unmarshal(data, other), ShouldBeNil)
+				So(other.Keys(), ShouldResemble, m.Keys())
+				So(json.Unmarshal([]byte(`[1]`), other), ShouldNotBeNil)
+			})
 		})
 	})
 }
@@ -141,6 +369,38 @@ func TestHashSet(t *testing.T) {
 	})
 }
 
+func TestSortedHashSet(t *testing.T) {
+	Convey("Given a SortedHashSet", t, func() {
+		s := NewSortedHashSet(StringLess)
+
+		So(s, ShouldNotBeNil)
+		So(s.Empty(), ShouldBeTrue)
+		So(s.Keys(), ShouldBeNil)
+
+		Convey("Give some keys", func() {
+			for _, key := range []string{"key", "foo", "bar", "abc", "xyz", "foo"} {
+				s.Add(key)
+			}
+
+			So(s.Len(), ShouldEqual, 5)
+			So(s.Contains("foo"), ShouldBeTrue)
+
+			Convey("The keys should be sorted and stable", func() {
+				for i := 0; i < 10; i++ {
+					So(s.Keys(), ShouldResemble, []interface{}{"abc", "bar", "foo", "key", "xyz"})
+				}
+			})
+
+			Convey("Remove a key", func() {
+				So(s.Remove("foo"), ShouldBeTrue)
+				So(s.Remove("foo"), ShouldBeFalse)
+
+				So(s.Keys(), ShouldResemble, []interface{}{"abc", "bar", "key", "xyz"})
+			})
+		})
+	})
+}
+
 func TestTreeSet(t *testing.T) {
 	Convey("Given a TreeSet", t, func() {
 		s := NewTreeSet(func(lhs, rhs interface{}) int {
@@ -191,6 +451,409 @@ func TestTreeSet(t *testing.T) {
 	})
 }
 
+func TestSortedSet(t *testing.T) {
+	Convey("Given an empty SortedSet", t, func() {
+		s := NewTreeSet(compareInt)
+
+		So(s.First(), ShouldBeNil)
+		So(s.Last(), ShouldBeNil)
+		So(s.PollFirst(), ShouldBeNil)
+		So(s.Len(), ShouldEqual, 0)
+
+		Convey("Add some items", func() {
+			for _, item := range []int{5, 3, 9, 1, 7} {
+				s.Add(item)
+			}
+
+			So(s.First(), ShouldEqual, 1)
+			So(s.Last(), ShouldEqual, 9)
+			So(s.Len(), ShouldEqual, 5)
+
+			Convey("Poll the items in order", func() {
+				for _, item := range []int{1, 3, 5, 7, 9} {
+					So(s.PollFirst(), ShouldEqual, item)
+				}
+
+				So(s.PollFirst(), ShouldBeNil)
+				So(s.Empty(), ShouldBeTrue)
+			})
+
+			Convey("Poll the first item", func() {
+				So(s.PollFirst(), ShouldEqual, 1)
+				So(s.Contains(1), ShouldBeFalse)
+				So(s.First(), ShouldEqual, 3)
+				So(s.Keys(), ShouldResemble, []interface{}{3, 5, 7, 9})
+			})
+		})
+	})
+}
+
+func TestSortedSetNavigation(t *testing.T) {
+	Convey("Given a SortedSet of times", t, func() {
+		s := NewTreeSet(func(lhs, rhs interface{}) int {
+			switch l, r := lhs.(time.Time), rhs.(time.Time); {
+			case l.Before(r):
+				return -1
+			case l.After(r):
+				return 1
+			default:
+				return 0
+			}
+		})
+
+		base := time.Date(2016, time.March, 1, 8, 0, 0, 0, time.UTC)
+
+		at := func(minutes int) time.Time { return base.Add(time.Duration(minutes) * time.Minute) }
+
+		for _, minutes := range []int{10, 20, 30} {
+			s.Add(at(minutes))
+		}
+
+		Convey("Ceiling should return the least item >= the given item", func() {
+			So(s.Ceiling(at(5)), ShouldResemble, at(10))
+			So(s.Ceiling(at(10)), ShouldResemble, at(10))
+			So(s.Ceiling(at(15)), ShouldResemble, at(20))
+			So(s.Ceiling(at(35)), ShouldBeNil)
+		})
+
+		Convey("Floor should return the greatest item <= the given item", func() {
+			So(s.Floor(at(5)), ShouldBeNil)
+			So(s.Floor(at(10)), ShouldResemble, at(10))
+			So(s.Floor(at(15)), ShouldResemble, at(10))
+			So(s.Floor(at(35)), ShouldResemble, at(30))
+		})
+
+		Convey("Higher should return the least item > the given item", func() {
+			So(s.Higher(at(5)), ShouldResemble, at(10))
+			So(s.Higher(at(10)), ShouldResemble, at(20))
+			So(s.Higher(at(30)), ShouldBeNil)
+		})
+
+		Convey("Lower should return the greatest item < the given item", func() {
+			So(s.Lower(at(10)), ShouldBeNil)
+			So(s.Lower(at(20)), ShouldResemble, at(10))
+			So(s.Lower(at(35)), ShouldResemble, at(30))
+		})
+
+		Convey("An empty set has no neighbours", func() {
+			e := NewTreeSet(compareInt)
+
+			So(e.Ceiling(1), ShouldBeNil)
+			So(e.Floor(1), ShouldBeNil)
+			So(e.Higher(1), ShouldBeNil)
+			So(e.Lower(1), ShouldBeNil)
+		})
+	})
+}
+
+func TestSetAlgebra(t *testing.T) {
+	newSet := func(s Set, items ...interface{}) Set {
+		for _, item := range items {
+			s.Add(item)
+		}
+
+		return s
+	}
+
+	Convey("Given two overlapping HashSets", t, func() {
+		lhs := newSet(NewSortedHashSet(StringLess), "a", "b", "c")
+		rhs := newSet(NewHashSet(), "b", "c", "d")
+
+		So(Union(lhs, rhs).Keys(), ShouldResemble, []interface{}{"a", "b", "c", "d"})
+		So(Intersection(lhs, rhs).Keys(), ShouldResemble, []interface{}{"b", "c"})
+		So(Difference(lhs, rhs).Keys(), ShouldResemble, []interface{}{"a"})
+
+		Convey("The inputs should not be mutated", func() {
+			So(lhs.Keys(), ShouldResemble, []interface{}{"a", "b", "c"})
+			So(rhs.Len(), ShouldEqual, 3)
+		})
+	})
+
+	Convey("Given two disjoint TreeSets", t, func() {
+		lhs := newSet(NewTreeSet(compareInt), 3, 1, 2)
+		rhs := newSet(NewTreeSet(compareInt), 5, 4)
+
+		So(Union(lhs, rhs).Keys(), ShouldResemble, []interface{}{1, 2, 3, 4, 5})
+		So(Intersection(lhs, rhs).Empty(), ShouldBeTrue)
+		So(Difference(lhs, rhs).Keys(), ShouldResemble, []interface{}{1, 2, 3})
+		So(Difference(rhs, lhs).Keys(), ShouldResemble, []interface{}{4, 5})
+
+		Convey("The TreeSet semantics should be kept", func() {
+			s := Union(lhs, rhs)
+
+			_, sorted := s.(SortedSet)
+
+			So(sorted, ShouldBeTrue)
+			So(s.(SortedSet).First(), ShouldEqual, 1)
+		})
+	})
+
+	Convey("Given a TreeSet with a custom equality", t, func() {
+		byLen := NewTreeSet(func(lhs, rhs interface{}) int { return len(lhs.(string)) - len(rhs.(string)) })
+
+		lhs := newSet(byLen, "a", "bb", "ccc")
+		rhs := newSet(NewTreeSet(byLen.(*treeSet).compare), "x", "yy")
+
+		So(Difference(lhs, rhs).Keys(), ShouldResemble, []interface{}{"ccc"})
+		So(Intersection(lhs, rhs).Keys(), ShouldResemble, []interface{}{"a", "bb"})
+	})
+}
+
+func TestSetClone(t *testing.T) {
+	Convey("Given a set", t, func() {
+		for _, s := range []Set{NewHashSet(), NewSortedHashSet(StringLess), NewTreeSet(func(lhs, rhs interface{}) int {
+			return strings.Compare(lhs.(string), rhs.(string))
+		})} {
+			s.Add("foo")
+			s.Add("bar")
+
+			clone := s.Clone().(Set)
+
+			clone.Add("key")
+			clone.Remove("foo")
+
+			So(s.Len(), ShouldEqual, 2)
+			So(s.Contains("foo"), ShouldBeTrue)
+			So(s.Contains("key"), ShouldBeFalse)
+
+			So(clone.Len(), ShouldEqual, 2)
+			So(clone.Contains("foo"), ShouldBeFalse)
+			So(clone.Contains("key"), ShouldBeTrue)
+		}
+	})
+}
+
+func TestUnmodifiable(t *testing.T) {
+	Convey("Given an unmodifiable view of a map", t, func() {
+		m := NewJobDataMap()
+		m.Put("foo", 123)
+
+		view := UnmodifiableMap(m)
+
+		So(view.Get("foo"), ShouldEqual, 123)
+		So(view.Contains("foo"), ShouldBeTrue)
+		So(view.Len(), ShouldEqual, 1)
+
+		So(func() { view.Put("bar", 456) }, ShouldPanicWith, ErrUnmodifiable)
+		So(func() { view.PutIfAbsent("bar", 456) }, ShouldPanicWith, ErrUnmodifiable)
+		So(func() { view.PutAll(NewJobDataMap()) }, ShouldPanicWith, ErrUnmodifiable)
+		So(func() { view.Remove("foo") }, ShouldPanicWith, ErrUnmodifiable)
+
+		Convey("The view should reflect the changes of the map", func() {
+			m.Put("bar", 456)
+
+			So(view.Get("bar"), ShouldEqual, 456)
+		})
+
+		Convey("The view of a JobDataMap should be a JobDataMap", func() {
+			dataMap, ok := view.(JobDataMap)
+
+			So(ok, ShouldBeTrue)
+			So(dataMap.GetIntOr("foo", 0), ShouldEqual, 123)
+			So(func() { dataMap.ClearDirtyFlag() }, ShouldPanicWith, ErrUnmodifiable)
+		})
+
+		Convey("The clone should be modifiable", func() {
+			clone := view.Clone().(Map)
+
+			clone.Put("bar", 456)
+
+			So(clone.Get("bar"), ShouldEqual, 456)
+			So(m.Contains("bar"), ShouldBeFalse)
+		})
+	})
+
+	Convey("Given an unmodifiable view of a set", t, func() {
+		s := NewHashSet()
+		s.Add("foo")
+
+		view := UnmodifiableSet(s)
+
+		So(view.Contains("foo"), ShouldBeTrue)
+		So(view.Len(), ShouldEqual, 1)
+
+		So(func() { view.Add("bar") }, ShouldPanicWith, ErrUnmodifiable)
+		So(func() { view.Remove("foo") }, ShouldPanicWith, ErrUnmodifiable)
+		So(func() { view.AddAll("bar") }, ShouldPanicWith, ErrUnmodifiable)
+		So(func() { view.RemoveAll("foo") }, ShouldPanicWith, ErrUnmodifiable)
+
+		So(s.Len(), ShouldEqual, 1)
+	})
+}
+
+func TestSetBatch(t *testing.T) {
+	Convey("Given the Set implementations", t, func() {
+		for _, s := range []Set{NewHashSet(), NewSortedHashSet(compareIntLess), NewTreeSet(compareInt)} {
+			So(s.AddAll(), ShouldEqual, 0)
+			So(s.AddAll(3, 1, 2, 1, 3), ShouldEqual, 3)
+			So(s.AddAll(2, 4, 4, 5), ShouldEqual, 2)
+			So(s.Len(), ShouldEqual, 5)
+
+			for i := 1; i <= 5; i++ {
+				So(s.Contains(i), ShouldBeTrue)
+			}
+
+			So(s.RemoveAll(1, 1, 6, 5), ShouldEqual, 2)
+			So(s.Len(), ShouldEqual, 3)
+			So(s.Contains(1), ShouldBeFalse)
+			So(s.Contains(5), ShouldBeFalse)
+		}
+	})
+
+	Convey("Given a TreeSet merging batches", t, func() {
+		s := NewTreeSet(compareInt)
+		ref := &sliceSet{compare: compareInt}
+
+		r := rand.New(rand.NewSource(1))
+
+		for round := 0; round < 50; round++ {
+			batch := make([]interface{}, r.Intn(200))
+
+			for i := range batch {
+				batch[i] = r.Intn(2000)
+			}
+
+			size := len(ref.items)
+
+			for _, item := range batch {
+				ref.Add(item)
+			}
+
+			So(s.AddAll(batch...), ShouldEqual, len(ref.items)-size)
+			So(s.Keys(), ShouldResemble, ref.items)
+			So(s.Len(), ShouldEqual, len(ref.items))
+			So(s.(*treeSet).root.height, ShouldBeLessThanOrEqualTo, int(1.45*math.Log2(float64(s.Len()+2))))
+		}
+	})
+}
+
+// sliceSet is the former sorted slice based treeSet, kept as a reference for the balanced tree.
+type sliceSet struct {
+	items   []interface{}
+	compare CompareFunc
+}
+
+func (s *sliceSet) search(item interface{}) int {
+	return sort.Search(len(s.items), func(i int) bool {
+		return s.compare(item, s.items[i]) <= 0
+	})
+}
+
+func (s *sliceSet) Add(item interface{}) {
+	n := s.search(item)
+
+	if n == len(s.items) {
+		s.items = append(s.items, item)
+	} else if s.compare(item, s.items[n]) != 0 {
+		s.items = append(s.items[:n], append([]interface{}{item}, s.items[n:]...)...)
+	}
+}
+
+func (s *sliceSet) Remove(item interface{}) bool {
+	n := s.search(item)
+
+	if n < len(s.items) && s.compare(s.items[n], item) == 0 {
+		s.items = append(s.items[:n], s.items[n+1:]...)
+
+		return true
+	}
+
+	return false
+}
+
+func compareInt(lhs, rhs interface{}) int { return lhs.(int) - rhs.(int) }
+
+func compareIntLess(lhs, rhs interface{}) bool { return lhs.(int) < rhs.(int) }
+
+func TestTreeSetBalanced(t *testing.T) {
+	Convey("Given a TreeSet and the sorted slice reference", t, func() {
+		s := NewTreeSet(compareInt)
+		ref := &sliceSet{compare: compareInt}
+
+		r := rand.New(rand.NewSource(42))
+
+		Convey("Random adds and removes should behave the same", func() {
+			for i := 0; i < 5000; i++ {
+				item := r.Intn(1000)
+
+				if r.Intn(3) == 0 {
+					So(s.Remove(item), ShouldEqual, ref.Remove(item))
+				} else {
+					s.Add(item)
+					ref.Add(item)
+				}
+
+				So(s.Len(), ShouldEqual, len(ref.items))
+			}
+
+			So(s.Keys(), ShouldResemble, ref.items)
+
+			for i := 0; i < 1000; i++ {
+				So(s.Contains(i), ShouldEqual, ref.search(i) < len(ref.items) && ref.items[ref.search(i)] == i)
+			}
+		})
+
+		Convey("The tree should stay balanced with sorted inserts", func() {
+			for i := 0; i < 10000; i++ {
+				s.Add(i)
+			}
+
+			So(s.Len(), ShouldEqual, 10000)
+			So(s.(*treeSet).root.height, ShouldBeLessThanOrEqualTo, int(1.45*math.Log2(10000+2)))
+		})
+	})
+}
+
+func BenchmarkTreeSetAdd(b *testing.B) {
+	items := rand.New(rand.NewSource(42)).Perm(100000)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		s := NewTreeSet(compareInt)
+
+		for _, item := range items {
+			s.Add(item)
+		}
+	}
+}
+
+func TestTreeSetQuiet(t *testing.T) {
+	Convey("Given a TreeSet with stdout captured", t, func() {
+		r, w, err := os.Pipe()
+
+		So(err, ShouldBeNil)
+
+		stdout := os.Stdout
+		os.Stdout = w
+
+		s := NewTreeSet(func(lhs, rhs interface{}) int {
+			return strings.Compare(lhs.(string), rhs.(string))
+		})
+
+		for i := 999; i >= 0; i-- {
+			s.Add(fmt.Sprintf("key%03d", i))
+			s.Add(fmt.Sprintf("key%03d", i))
+		}
+
+		os.Stdout = stdout
+		w.Close()
+
+		output, err := ioutil.ReadAll(r)
+
+		So(err, ShouldBeNil)
+		So(string(output), ShouldBeEmpty)
+
+		So(s.Len(), ShouldEqual, 1000)
+
+		keys := s.Keys()
+
+		So(keys[0], ShouldEqual, "key000")
+		So(keys[999], ShouldEqual, "key999")
+		So(sort.IsSorted(StringKeys(keys)), ShouldBeTrue)
+	})
+}
+
 func TestUniqueName(t *testing.T) {
 	Convey("Given a unique name", t, func() {
 		name := newUniqueName("test")